@@ -19,6 +19,7 @@ export enum QueryEditorMode {
 
 export enum LokiQueryType {
   Instant = 'instant',
+  Patterns = 'patterns',
   Range = 'range',
   Stream = 'stream',
 }
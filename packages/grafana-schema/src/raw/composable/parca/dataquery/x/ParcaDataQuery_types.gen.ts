@@ -12,11 +12,37 @@ import * as common from '@grafana/schema';
 
 export const pluginVersion = "%VERSION%";
 
-export type ParcaQueryType = ('metrics' | 'profile' | 'both');
+export type ParcaQueryType = ('metrics' | 'profile' | 'diff' | 'both');
 
 export const defaultParcaQueryType: ParcaQueryType = 'both';
 
+/**
+ * Selects a profile by its label selector and time range.
+ */
+export interface ProfileSelection {
+  /**
+   * Start of the time range of the profile in milliseconds since epoch.
+   */
+  from?: number;
+  /**
+   * Specifies the label selectors of the profile.
+   */
+  labelSelector?: string;
+  /**
+   * End of the time range of the profile in milliseconds since epoch.
+   */
+  to?: number;
+}
+
 export interface ParcaDataQuery extends common.DataQuery {
+  /**
+   * Selects the baseline profile of diff queries, the label selector and the time range of the query are used when not set.
+   */
+  baseline?: ProfileSelection;
+  /**
+   * Selects the comparison profile of diff queries, the label selector and the time range of the query are used when not set.
+   */
+  comparison?: ProfileSelection;
   /**
    * Specifies the query label selectors.
    */
@@ -140,6 +140,11 @@ type UnauthorisedPublicError PublicErrorResponse
 // swagger:response forbiddenPublicError
 type ForbiddenPublicError PublicErrorResponse
 
+// TooManyRequestsPublicError is returned when the requests exceed a rate or concurrency limit.
+//
+// swagger:response tooManyRequestsPublicError
+type TooManyRequestsPublicError PublicErrorResponse
+
 // InternalServerPublicError is a general error indicating something went wrong internally.
 //
 // swagger:response internalServerPublicError
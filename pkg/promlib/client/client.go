@@ -82,6 +82,43 @@ func (c *Client) QueryExemplars(ctx context.Context, q *models.Query) (*http.Res
 	return c.doer.Do(req)
 }
 
+// QuerySeries returns the label sets of the series matching the selector in the time range
+func (c *Client) QuerySeries(ctx context.Context, selector string, start, end time.Time) (*http.Response, error) {
+	qv := map[string]string{
+		"match[]": selector,
+		"start":   formatTime(start),
+		"end":     formatTime(end),
+	}
+
+	req, err := c.createQueryRequest(ctx, "api/v1/series", qv)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doer.Do(req)
+}
+
+// QueryMetadata returns the type, help and unit of the metrics, or of the given metric if not empty
+func (c *Client) QueryMetadata(ctx context.Context, metric string) (*http.Response, error) {
+	qv := map[string]string{}
+	if metric != "" {
+		qv["metric"] = metric
+	}
+
+	// the metadata endpoint only supports GET
+	u, err := c.createUrl("api/v1/metadata", qv)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := createRequest(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doer.Do(req)
+}
+
 func (c *Client) QueryResource(ctx context.Context, req *backend.CallResourceRequest) (*http.Response, error) {
 	// The way URL is represented in CallResourceRequest and what we need for the fetch function is different
 	// so here we have to do a bit of parsing, so we can then compose it with the base url in correct way.
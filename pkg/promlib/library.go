@@ -123,7 +123,15 @@ func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceReq
 		return sender.Send(vResp)
 	}
 
-	resp, err := i.resource.Execute(ctx, req)
+	var resp *backend.CallResourceResponse
+	switch {
+	case strings.EqualFold(req.Path, resource.CardinalityPath):
+		resp, err = i.resource.Cardinality(ctx, req)
+	case strings.EqualFold(req.Path, resource.MetadataPath):
+		resp, err = i.resource.Metadata(ctx, req)
+	default:
+		resp, err = i.resource.Execute(ctx, req)
+	}
 	if err != nil {
 		return err
 	}
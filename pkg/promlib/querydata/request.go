@@ -45,6 +45,7 @@ type QueryData struct {
 	URL                string
 	TimeInterval       string
 	exemplarSampler    func() exemplar.Sampler
+	querySplit         querySplitOptions
 }
 
 func New(
@@ -67,6 +68,11 @@ func New(
 		httpMethod = http.MethodPost
 	}
 
+	querySplit, err := parseQuerySplitOptions(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
 	// standard deviation sampler is the default for backwards compatibility
@@ -81,6 +87,7 @@ func New(
 		ID:                 settings.ID,
 		URL:                settings.URL,
 		exemplarSampler:    exemplarSampler,
+		querySplit:         querySplit,
 	}, nil
 }
 
@@ -228,6 +235,13 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 }
 
 func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	if s.querySplit.shouldSplit(q) {
+		return s.splitRangeQuery(ctx, c, q, enablePrometheusDataplaneFlag)
+	}
+	return s.singleRangeQuery(ctx, c, q, enablePrometheusDataplaneFlag)
+}
+
+func (s *QueryData) singleRangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
 	res, err := c.QueryRange(ctx, q)
 	if err != nil {
 		return backend.DataResponse{
@@ -0,0 +1,218 @@
+package querydata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const defaultQuerySplitParallelism = 4
+
+// querySplitOptions configures how long range queries are split into smaller range queries,
+// splitting is disabled when the interval is zero
+type querySplitOptions struct {
+	Interval    time.Duration
+	Parallelism int
+}
+
+func parseQuerySplitOptions(jsonData map[string]any) (querySplitOptions, error) {
+	opts := querySplitOptions{Parallelism: defaultQuerySplitParallelism}
+
+	if interval, ok := jsonData["querySplitInterval"].(string); ok && interval != "" {
+		d, err := gtime.ParseIntervalStringToTimeDuration(interval)
+		if err != nil {
+			return opts, fmt.Errorf("invalid querySplitInterval %q: %w", interval, err)
+		}
+		opts.Interval = d
+	}
+
+	switch parallelism := jsonData["querySplitParallelism"].(type) {
+	case float64:
+		opts.Parallelism = int(parallelism)
+	case int:
+		opts.Parallelism = parallelism
+	}
+	if opts.Parallelism < 1 {
+		opts.Parallelism = 1
+	}
+
+	return opts, nil
+}
+
+// shouldSplit reports whether the range query spans more than one split interval
+func (o querySplitOptions) shouldSplit(q *models.Query) bool {
+	return o.Interval > 0 && q.RangeQuery && q.Step > 0 && q.End.Sub(q.Start) > o.Interval
+}
+
+type timeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// splitTimeRange splits the range of the query into consecutive sub-ranges of about the given interval. Every
+// sub-range starts at a multiple of the step from the start of the query, so the sub-queries evaluate exactly
+// the timestamps the original query evaluates and no timestamp is evaluated twice.
+func splitTimeRange(start, end time.Time, step, interval time.Duration) []timeRange {
+	chunk := (interval / step) * step
+	if chunk < step {
+		chunk = step
+	}
+
+	ranges := make([]timeRange, 0, end.Sub(start)/chunk+1)
+	for chunkStart := start; !chunkStart.After(end); chunkStart = chunkStart.Add(chunk) {
+		chunkEnd := chunkStart.Add(chunk - step)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		ranges = append(ranges, timeRange{Start: chunkStart, End: chunkEnd})
+	}
+	return ranges
+}
+
+// splitRangeQuery runs the range query as multiple smaller range queries in parallel and merges their frames.
+// When only some of the sub-queries fail, the frames of the successful ones are returned with a warning notice.
+func (s *QueryData) splitRangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	logger := s.log.FromContext(ctx)
+	// split the step aligned range the client sends, so the sub-ranges stay aligned
+	tr := q.TimeRange()
+	ranges := splitTimeRange(tr.Start, tr.End, tr.Step, s.querySplit.Interval)
+	logger.Debug("Splitting range query", "query", q.Expr, "splits", len(ranges), "parallelism", s.querySplit.Parallelism)
+
+	responses := make([]backend.DataResponse, len(ranges))
+	var m sync.Mutex
+	_ = concurrency.ForEachJob(ctx, len(ranges), s.querySplit.Parallelism, func(ctx context.Context, idx int) error {
+		subQuery := *q
+		subQuery.Start = ranges[idx].Start
+		subQuery.End = ranges[idx].End
+
+		res := s.singleRangeQuery(ctx, c, &subQuery, enablePrometheusDataplaneFlag)
+		m.Lock()
+		responses[idx] = res
+		m.Unlock()
+		return nil
+	})
+
+	var (
+		chunks  = make([]data.Frames, 0, len(ranges))
+		notices []data.Notice
+		failed  []backend.DataResponse
+	)
+	for i, res := range responses {
+		if res.Error != nil {
+			failed = append(failed, res)
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text: fmt.Sprintf("Partial data: the query for %s to %s failed: %s",
+					ranges[i].Start.UTC().Format(time.RFC3339), ranges[i].End.UTC().Format(time.RFC3339), res.Error),
+			})
+			continue
+		}
+		chunks = append(chunks, res.Frames)
+	}
+
+	// nothing to stitch together, fail like an unsplit query would
+	if len(failed) == len(responses) {
+		return failed[0]
+	}
+
+	frames := mergeFrames(chunks)
+	if len(frames) == 0 {
+		frames = data.Frames{data.NewFrame("")}
+	}
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.ExecutedQueryString = ""
+	}
+	frames[0].Meta.ExecutedQueryString = executedQueryString(q) + fmt.Sprintf("\nSplits: %d", len(ranges))
+	frames[0].Meta.Notices = append(frames[0].Meta.Notices, notices...)
+
+	if len(failed) > 0 {
+		logger.Warn("Range query returned partial data", "query", q.Expr, "failedSplits", len(failed), "splits", len(ranges))
+	}
+
+	return backend.DataResponse{Frames: frames}
+}
+
+// mergeFrames concatenates the frames of the same series from consecutive time ranges. Series are identified by
+// the type of the frame and the names, types and labels of the fields, the order of the first occurrence of each
+// series is kept.
+func mergeFrames(chunks []data.Frames) data.Frames {
+	var (
+		merged data.Frames
+		index  = map[string]*data.Frame{}
+	)
+
+	for _, frames := range chunks {
+		for _, frame := range frames {
+			if len(frame.Fields) == 0 {
+				// frames without fields only carry metadata, keep the first one
+				if len(merged) == 0 {
+					merged = append(merged, frame)
+				}
+				continue
+			}
+
+			key := seriesKey(frame)
+			existing, ok := index[key]
+			if !ok {
+				index[key] = frame
+				merged = append(merged, frame)
+				continue
+			}
+
+			for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
+				for fieldIdx, field := range frame.Fields {
+					existing.Fields[fieldIdx].Append(field.CopyAt(rowIdx))
+				}
+			}
+			if frame.Meta != nil && len(frame.Meta.Notices) > 0 {
+				if existing.Meta == nil {
+					existing.Meta = &data.FrameMeta{}
+				}
+				existing.Meta.Notices = append(existing.Meta.Notices, frame.Meta.Notices...)
+			}
+		}
+	}
+
+	// drop the metadata only frame if any series was returned
+	if len(merged) > 1 && len(merged[0].Fields) == 0 {
+		meta := merged[0].Meta
+		merged = merged[1:]
+		if meta != nil && len(meta.Notices) > 0 {
+			if merged[0].Meta == nil {
+				merged[0].Meta = &data.FrameMeta{}
+			}
+			merged[0].Meta.Notices = append(merged[0].Meta.Notices, meta.Notices...)
+		}
+	}
+
+	return merged
+}
+
+func seriesKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	if frame.Meta != nil {
+		sb.WriteString("|")
+		sb.WriteString(string(frame.Meta.Type))
+	}
+	for _, field := range frame.Fields {
+		sb.WriteString("|")
+		sb.WriteString(field.Name)
+		sb.WriteString(field.Type().ItemTypeString())
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
@@ -0,0 +1,160 @@
+package querydata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+	"github.com/grafana/grafana/pkg/promlib/querydata/exemplar"
+)
+
+func TestParseQuerySplitOptions(t *testing.T) {
+	opts, err := parseQuerySplitOptions(map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, querySplitOptions{Parallelism: defaultQuerySplitParallelism}, opts)
+
+	opts, err = parseQuerySplitOptions(map[string]any{"querySplitInterval": "1d", "querySplitParallelism": float64(8)})
+	require.NoError(t, err)
+	assert.Equal(t, querySplitOptions{Interval: 24 * time.Hour, Parallelism: 8}, opts)
+
+	_, err = parseQuerySplitOptions(map[string]any{"querySplitInterval": "one day"})
+	require.Error(t, err)
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	start := time.Unix(0, 0)
+
+	t.Run("splits into step aligned ranges", func(t *testing.T) {
+		ranges := splitTimeRange(start, start.Add(10*time.Hour), time.Hour, 4*time.Hour)
+		require.Equal(t, []timeRange{
+			{Start: start, End: start.Add(3 * time.Hour)},
+			{Start: start.Add(4 * time.Hour), End: start.Add(7 * time.Hour)},
+			{Start: start.Add(8 * time.Hour), End: start.Add(10 * time.Hour)},
+		}, ranges)
+	})
+
+	t.Run("rounds the interval down to a multiple of the step", func(t *testing.T) {
+		ranges := splitTimeRange(start, start.Add(10*time.Minute), 3*time.Minute, 7*time.Minute)
+		require.Equal(t, []timeRange{
+			{Start: start, End: start.Add(3 * time.Minute)},
+			{Start: start.Add(6 * time.Minute), End: start.Add(9 * time.Minute)},
+		}, ranges)
+	})
+
+	t.Run("uses at least one step per range", func(t *testing.T) {
+		ranges := splitTimeRange(start, start.Add(2*time.Hour), time.Hour, time.Minute)
+		require.Len(t, ranges, 3)
+		for _, r := range ranges {
+			assert.Equal(t, r.Start, r.End)
+		}
+	})
+}
+
+func TestMergeFrames(t *testing.T) {
+	series := func(labels data.Labels, start int64, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		for i := range values {
+			times[i] = time.Unix(start+int64(i), 0)
+		}
+		return data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, labels, values),
+		).SetMeta(&data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti})
+	}
+	a := data.Labels{"job": "a"}
+	b := data.Labels{"job": "b"}
+
+	merged := mergeFrames([]data.Frames{
+		{series(a, 0, 1, 2)},
+		{series(b, 2, 30), series(a, 2, 3)},
+		{data.NewFrame("")},
+	})
+
+	require.Len(t, merged, 2)
+	assert.Equal(t, a, merged[0].Fields[1].Labels)
+	assert.Equal(t, 3, merged[0].Rows())
+	assert.Equal(t, 3.0, merged[0].Fields[1].At(2))
+	assert.Equal(t, time.Unix(2, 0), merged[0].Fields[0].At(2))
+	assert.Equal(t, b, merged[1].Fields[1].Labels)
+	assert.Equal(t, 1, merged[1].Rows())
+}
+
+func TestQueryData_splitRangeQuery(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		start, _ := strconv.ParseFloat(r.FormValue("start"), 64)
+		end, _ := strconv.ParseFloat(r.FormValue("end"), 64)
+		step, _ := strconv.ParseFloat(r.FormValue("step"), 64)
+		// the second hour fails
+		if start == 3600 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"execution","error":"query processing would load too many samples into memory"}`))
+			return
+		}
+		values := ""
+		for ts := start; ts <= end; ts += step {
+			if values != "" {
+				values += ","
+			}
+			values += fmt.Sprintf(`[%v,"%v"]`, ts, ts)
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"a"},"values":[%s]}]}}`, values)
+	}))
+	defer srv.Close()
+
+	qd := &QueryData{
+		tracer:          tracing.DefaultTracer(),
+		log:             log.New(),
+		exemplarSampler: exemplar.NewStandardDeviationSampler,
+		querySplit:      querySplitOptions{Interval: time.Hour, Parallelism: 2},
+	}
+	c := client.NewClient(srv.Client(), http.MethodGet, srv.URL)
+	q := &models.Query{
+		Expr:       "up",
+		Step:       15 * time.Minute,
+		Start:      time.Unix(0, 0),
+		End:        time.Unix(3*3600, 0),
+		RangeQuery: true,
+		RefId:      "A",
+	}
+
+	t.Run("returns the data of the successful splits with a partial data notice", func(t *testing.T) {
+		res := qd.rangeQuery(context.Background(), c, q, true)
+		require.NoError(t, res.Error)
+		assert.Equal(t, int32(4), requests.Load())
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		// 13 timestamps in 3 hours, 4 of them in the failed hour
+		assert.Equal(t, 9, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		assert.Contains(t, frame.Meta.Notices[0].Text, "Partial data")
+		assert.Contains(t, frame.Meta.ExecutedQueryString, "Splits: 4")
+	})
+
+	t.Run("does not split short queries", func(t *testing.T) {
+		requests.Store(0)
+		short := *q
+		short.End = time.Unix(1800, 0)
+		res := qd.rangeQuery(context.Background(), c, &short, true)
+		require.NoError(t, res.Error)
+		assert.Equal(t, int32(1), requests.Load())
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, 3, res.Frames[0].Rows())
+	})
+}
@@ -0,0 +1,315 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	// CardinalityPath is the resource path of the label cardinality of a selector
+	CardinalityPath = "cardinality"
+	// MetadataPath is the resource path of the metric metadata
+	MetadataPath = "metadata"
+
+	defaultCardinalityLimit = 10
+	defaultCardinalityRange = time.Hour
+)
+
+// CardinalityResponse describes how the series matching a selector are distributed over label names and values
+type CardinalityResponse struct {
+	Selector    string             `json:"selector"`
+	TotalSeries int                `json:"totalSeries"`
+	Labels      []LabelCardinality `json:"labels"`
+}
+
+type LabelCardinality struct {
+	Name string `json:"name"`
+	// SeriesCount is the number of series with the label
+	SeriesCount int `json:"seriesCount"`
+	// ValueCount is the number of distinct values of the label
+	ValueCount int `json:"valueCount"`
+	// TopValues are the values of the label with the most series
+	TopValues []LabelValueCardinality `json:"topValues"`
+}
+
+type LabelValueCardinality struct {
+	Value       string `json:"value"`
+	SeriesCount int    `json:"seriesCount"`
+}
+
+type MetricMetadata struct {
+	Metric string `json:"metric"`
+	Type   string `json:"type"`
+	Help   string `json:"help"`
+	Unit   string `json:"unit"`
+}
+
+type apiResponse[T any] struct {
+	Status    string `json:"status"`
+	Data      T      `json:"data"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+}
+
+// Cardinality counts the series per label name and the top label values by series count of the series
+// matching the selector. The selector is required, start and end default to the last hour, rounded down to the
+// cache TTL.
+func (r *Resource) Cardinality(ctx context.Context, req *backend.CallResourceRequest) (*backend.CallResourceResponse, error) {
+	params, err := resourceParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := params.Get("selector")
+	if selector == "" {
+		return errorResponse(http.StatusBadRequest, "selector is required")
+	}
+
+	// the default end is truncated to the cache TTL, otherwise requests without an end would never hit the cache
+	end, err := parseTimeParam(params.Get("end"), time.Now().Truncate(cacheTTL))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, fmt.Sprintf("invalid end: %s", err))
+	}
+	start, err := parseTimeParam(params.Get("start"), end.Add(-defaultCardinalityRange))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, fmt.Sprintf("invalid start: %s", err))
+	}
+
+	limit := defaultCardinalityLimit
+	if l := params.Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			return errorResponse(http.StatusBadRequest, "limit must be a positive number")
+		}
+	}
+
+	cacheKey := fmt.Sprintf("%s|%s|%d|%d|%d", CardinalityPath, selector, start.Unix(), end.Unix(), limit)
+	return r.cached(req, cacheKey, func() (*backend.CallResourceResponse, error) {
+		r.log.FromContext(ctx).Debug("Computing label cardinality", "selector", selector, "start", start, "end", end)
+		resp, err := r.promClient.QuerySeries(ctx, selector, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("error querying series: %v", err)
+		}
+
+		var series []map[string]string
+		if failed, err := decodeAPIResponse(resp, &series); failed != nil || err != nil {
+			return failed, err
+		}
+
+		return jsonResponse(computeCardinality(selector, series, limit))
+	})
+}
+
+// Metadata returns the type, help and unit of the metrics from /api/v1/metadata, optionally of a single metric
+func (r *Resource) Metadata(ctx context.Context, req *backend.CallResourceRequest) (*backend.CallResourceResponse, error) {
+	params, err := resourceParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	metric := params.Get("metric")
+	return r.cached(req, MetadataPath+"|"+metric, func() (*backend.CallResourceResponse, error) {
+		resp, err := r.promClient.QueryMetadata(ctx, metric)
+		if err != nil {
+			return nil, fmt.Errorf("error querying metadata: %v", err)
+		}
+
+		var metadata map[string][]struct {
+			Type string `json:"type"`
+			Help string `json:"help"`
+			Unit string `json:"unit"`
+		}
+		if failed, err := decodeAPIResponse(resp, &metadata); failed != nil || err != nil {
+			return failed, err
+		}
+
+		result := make([]MetricMetadata, 0, len(metadata))
+		for name, entries := range metadata {
+			// targets can disagree about the metadata of a metric, the first entry is what Prometheus shows too
+			if len(entries) == 0 {
+				continue
+			}
+			result = append(result, MetricMetadata{Metric: name, Type: entries[0].Type, Help: entries[0].Help, Unit: entries[0].Unit})
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Metric < result[j].Metric
+		})
+
+		return jsonResponse(result)
+	})
+}
+
+func computeCardinality(selector string, series []map[string]string, limit int) CardinalityResponse {
+	seriesPerLabel := map[string]int{}
+	seriesPerValue := map[string]map[string]int{}
+	for _, labels := range series {
+		for name, value := range labels {
+			seriesPerLabel[name]++
+			if seriesPerValue[name] == nil {
+				seriesPerValue[name] = map[string]int{}
+			}
+			seriesPerValue[name][value]++
+		}
+	}
+
+	result := CardinalityResponse{
+		Selector:    selector,
+		TotalSeries: len(series),
+		Labels:      make([]LabelCardinality, 0, len(seriesPerLabel)),
+	}
+	for name, count := range seriesPerLabel {
+		values := make([]LabelValueCardinality, 0, len(seriesPerValue[name]))
+		for value, valueCount := range seriesPerValue[name] {
+			values = append(values, LabelValueCardinality{Value: value, SeriesCount: valueCount})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].SeriesCount != values[j].SeriesCount {
+				return values[i].SeriesCount > values[j].SeriesCount
+			}
+			return values[i].Value < values[j].Value
+		})
+
+		valueCount := len(values)
+		if len(values) > limit {
+			values = values[:limit]
+		}
+		result.Labels = append(result.Labels, LabelCardinality{
+			Name:        name,
+			SeriesCount: count,
+			ValueCount:  valueCount,
+			TopValues:   values,
+		})
+	}
+
+	// labels with the most distinct values are the usual suspects of cardinality problems
+	sort.Slice(result.Labels, func(i, j int) bool {
+		if result.Labels[i].ValueCount != result.Labels[j].ValueCount {
+			return result.Labels[i].ValueCount > result.Labels[j].ValueCount
+		}
+		return result.Labels[i].Name < result.Labels[j].Name
+	})
+
+	return result
+}
+
+// forwardedIdentityHeaders are the headers with which the identity of the user is forwarded to Prometheus
+var forwardedIdentityHeaders = []string{"Authorization", "X-Id-Token", "X-Grafana-Id", "Cookie"}
+
+// cached returns the cached response for the key or computes and caches it. Only successful responses are cached.
+// The responses are cached per user, Prometheus can authorize each user with the identity forwarded to it.
+func (r *Resource) cached(req *backend.CallResourceRequest, key string, compute func() (*backend.CallResourceResponse, error)) (*backend.CallResourceResponse, error) {
+	key = identityCacheKey(req) + "|" + key
+	if resp, found := r.cache.Get(key); found {
+		return resp.(*backend.CallResourceResponse), nil
+	}
+
+	resp, err := compute()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Status == http.StatusOK {
+		r.cache.SetDefault(key, resp)
+	}
+	return resp, nil
+}
+
+// identityCacheKey identifies the caller by org, user and a hash of the forwarded identity headers
+func identityCacheKey(req *backend.CallResourceRequest) string {
+	login := ""
+	if req.PluginContext.User != nil {
+		login = req.PluginContext.User.Login
+	}
+
+	h := sha256.New()
+	for _, name := range forwardedIdentityHeaders {
+		for key, values := range req.Headers {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			for _, v := range values {
+				_, _ = fmt.Fprintf(h, "%s:%s\n", name, v)
+			}
+		}
+	}
+	return fmt.Sprintf("%d|%s|%x", req.PluginContext.OrgID, login, h.Sum(nil))
+}
+
+func resourceParams(req *backend.CallResourceRequest) (url.Values, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	return u.Query(), nil
+}
+
+// parseTimeParam parses a time in the formats the Prometheus API accepts, unix seconds or RFC3339
+func parseTimeParam(value string, defaultValue time.Time) (time.Time, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// decodeAPIResponse decodes the data of a Prometheus API response. When Prometheus responds with an error, the
+// error is returned as resource response with the status code of Prometheus.
+func decodeAPIResponse[T any](resp *http.Response, data *T) (*backend.CallResourceResponse, error) {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	apiResp := apiResponse[T]{}
+	if err := json.Unmarshal(body, &apiResp); err != nil || apiResp.Status != "success" {
+		status := resp.StatusCode
+		if status == http.StatusOK {
+			status = http.StatusBadGateway
+		}
+		return &backend.CallResourceResponse{Status: status, Headers: resp.Header, Body: body}, nil
+	}
+
+	*data = apiResp.Data
+	return nil, nil
+}
+
+func jsonResponse(v any) (*backend.CallResourceResponse, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}, nil
+}
+
+func errorResponse(status int, message string) (*backend.CallResourceResponse, error) {
+	body, err := json.Marshal(map[string]string{"error": message})
+	if err != nil {
+		return nil, err
+	}
+	return &backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeCardinality(t *testing.T) {
+	series := []map[string]string{
+		{"__name__": "http_requests_total", "job": "api", "pod": "api-1"},
+		{"__name__": "http_requests_total", "job": "api", "pod": "api-2"},
+		{"__name__": "http_requests_total", "job": "api", "pod": "api-3"},
+		{"__name__": "http_requests_total", "job": "web"},
+	}
+
+	result := computeCardinality(`http_requests_total`, series, 2)
+	assert.Equal(t, 4, result.TotalSeries)
+	require.Len(t, result.Labels, 3)
+
+	assert.Equal(t, LabelCardinality{
+		Name:        "pod",
+		SeriesCount: 3,
+		ValueCount:  3,
+		TopValues:   []LabelValueCardinality{{Value: "api-1", SeriesCount: 1}, {Value: "api-2", SeriesCount: 1}},
+	}, result.Labels[0])
+	assert.Equal(t, LabelCardinality{
+		Name:        "job",
+		SeriesCount: 4,
+		ValueCount:  2,
+		TopValues:   []LabelValueCardinality{{Value: "api", SeriesCount: 3}, {Value: "web", SeriesCount: 1}},
+	}, result.Labels[1])
+	assert.Equal(t, "__name__", result.Labels[2].Name)
+}
+
+func TestResource_Cardinality(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/api/v1/series":
+			require.Equal(t, `up{job="api"}`, r.FormValue("match[]"))
+			_, _ = w.Write([]byte(`{"status":"success","data":[{"__name__":"up","job":"api","pod":"a"},{"__name__":"up","job":"api","pod":"b"}]}`))
+		case "/api/v1/metadata":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"up":[{"type":"gauge","help":"Target is up","unit":""}],"http_requests_total":[{"type":"counter","help":"Requests","unit":"requests"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := New(srv.Client(), backend.DataSourceInstanceSettings{URL: srv.URL, JSONData: []byte(`{}`)}, log.New())
+	require.NoError(t, err)
+
+	t.Run("computes the cardinality and caches the result", func(t *testing.T) {
+		req := &backend.CallResourceRequest{Path: CardinalityPath, URL: `cardinality?selector=up%7Bjob%3D%22api%22%7D&start=0&end=3600`}
+		for i := 0; i < 2; i++ {
+			resp, err := r.Cardinality(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.Status)
+
+			var result CardinalityResponse
+			require.NoError(t, json.Unmarshal(resp.Body, &result))
+			assert.Equal(t, 2, result.TotalSeries)
+			assert.Equal(t, "pod", result.Labels[0].Name)
+		}
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("caches the result without start and end", func(t *testing.T) {
+		requests = 0
+		req := &backend.CallResourceRequest{Path: CardinalityPath, URL: `cardinality?selector=up%7Bjob%3D%22api%22%7D`}
+		for i := 0; i < 2; i++ {
+			resp, err := r.Cardinality(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.Status)
+		}
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("caches the result per user", func(t *testing.T) {
+		requests = 0
+		send := func(login string, token string) {
+			req := &backend.CallResourceRequest{
+				Path:          CardinalityPath,
+				URL:           `cardinality?selector=up%7Bjob%3D%22api%22%7D&start=0&end=7200`,
+				PluginContext: backend.PluginContext{OrgID: 1, User: &backend.User{Login: login}},
+				Headers:       map[string][]string{"Authorization": {"Bearer " + token}},
+			}
+			resp, err := r.Cardinality(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.Status)
+		}
+
+		send("alice", "a")
+		send("alice", "a")
+		assert.Equal(t, 1, requests)
+		send("bob", "b")
+		assert.Equal(t, 2, requests)
+		send("alice", "refreshed")
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("requires a selector", func(t *testing.T) {
+		resp, err := r.Cardinality(context.Background(), &backend.CallResourceRequest{Path: CardinalityPath, URL: `cardinality`})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("returns the metric metadata sorted by metric", func(t *testing.T) {
+		resp, err := r.Metadata(context.Background(), &backend.CallResourceRequest{Path: MetadataPath, URL: `metadata`})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result []MetricMetadata
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		assert.Equal(t, []MetricMetadata{
+			{Metric: "http_requests_total", Type: "counter", Help: "Requests", Unit: "requests"},
+			{Metric: "up", Type: "gauge", Help: "Target is up"},
+		}, result)
+	})
+}
@@ -5,18 +5,25 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+	"github.com/patrickmn/go-cache"
 
 	"github.com/grafana/grafana/pkg/promlib/client"
 	"github.com/grafana/grafana/pkg/promlib/utils"
 )
 
+// cacheTTL is how long computed responses are cached
+const cacheTTL = 5 * time.Minute
+
 type Resource struct {
 	promClient *client.Client
 	log        log.Logger
+	// cache holds the computed cardinality and metadata responses of the datasource
+	cache *cache.Cache
 }
 
 func New(
@@ -37,6 +44,7 @@ func New(
 	return &Resource{
 		log:        plog,
 		promClient: client.NewClient(httpClient, httpMethod, settings.URL),
+		cache:      cache.New(cacheTTL, time.Minute*10),
 	}, nil
 }
 
@@ -169,6 +169,10 @@ func (s *CorrelationsService) updateHandler(c *contextmodel.ReqContext) response
 			return response.Error(http.StatusForbidden, "Correlation can only be edited via provisioning", err)
 		}
 
+		if errors.Is(err, ErrInvalidCorrelation) {
+			return response.Error(http.StatusBadRequest, "Invalid correlation", err)
+		}
+
 		return response.Error(http.StatusInternalServerError, "Failed to update correlation", err)
 	}
 
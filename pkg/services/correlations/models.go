@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 )
@@ -18,6 +19,11 @@ var (
 	ErrInvalidTransformationType     = errors.New("invalid transformation type")
 	ErrTransformationNotNested       = errors.New("transformations must be nested under config")
 	ErrTransformationRegexReqExp     = errors.New("regex transformations require expression")
+	ErrTransformationLabelsReqField  = errors.New("labels transformations require field")
+	ErrExternalTargetURLRequired     = errors.New("correlations of type \"external\" require config.target.url")
+	ErrExternalTargetURLInvalid      = errors.New("correlations of type \"external\" require an absolute http(s) or relative url")
+	ErrExternalTargetUIDNotAllowed   = errors.New("correlations of type \"external\" must not have a targetUID")
+	ErrInvalidCorrelation            = errors.New("invalid correlation")
 	ErrCorrelationsQuotaFailed       = errors.New("error getting correlations quota")
 	ErrCorrelationsQuotaReached      = errors.New("correlations quota reached")
 )
@@ -30,7 +36,7 @@ const (
 type CorrelationType string
 
 type Transformation struct {
-	//Enum: regex,logfmt
+	//Enum: regex,logfmt,json,labels
 	Type       string `json:"type"`
 	Expression string `json:"expression,omitempty"`
 	Field      string `json:"field,omitempty"`
@@ -39,10 +45,24 @@ type Transformation struct {
 
 const (
 	TypeQuery CorrelationType = "query"
+	// TypeExternal correlations open a templated URL built from field values instead of querying a data source
+	TypeExternal CorrelationType = "external"
 )
 
+const (
+	TransformationRegex  = "regex"
+	TransformationLogfmt = "logfmt"
+	// TransformationJSON extracts values from a JSON encoded field. Expression is an optional path to a nested value.
+	TransformationJSON = "json"
+	// TransformationLabels extracts values from the labels of the given field
+	TransformationLabels = "labels"
+)
+
+// ExternalTargetURLKey is the key in config.target holding the templated URL of an external correlation
+const ExternalTargetURLKey = "url"
+
 func (t CorrelationType) Validate() error {
-	if t != TypeQuery {
+	if t != TypeQuery && t != TypeExternal {
 		return fmt.Errorf("%s: \"%s\"", ErrInvalidConfigType, t)
 	}
 	return nil
@@ -50,10 +70,18 @@ func (t CorrelationType) Validate() error {
 
 func (t Transformations) Validate() error {
 	for _, v := range t {
-		if v.Type != "regex" && v.Type != "logfmt" {
+		switch v.Type {
+		case TransformationRegex:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+			}
+		case TransformationLabels:
+			if len(v.Field) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationLabelsReqField, t)
+			}
+		case TransformationLogfmt, TransformationJSON:
+		default:
 			return fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, t)
-		} else if v.Type == "regex" && len(v.Expression) == 0 {
-			return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
 		}
 	}
 	return nil
@@ -81,6 +109,31 @@ type CorrelationConfig struct {
 	Transformations Transformations `json:"transformations,omitempty"`
 }
 
+// ExternalURL returns the templated URL of an external correlation, e.g. https://tickets.example.com/new?service=${service}
+func (c CorrelationConfig) ExternalURL() string {
+	url, _ := c.Target[ExternalTargetURLKey].(string)
+	return url
+}
+
+// validateExternalTarget checks the target of an external correlation holds a URL that can be safely opened
+// in the browser once the template variables are interpolated.
+func (c CorrelationConfig) validateExternalTarget() error {
+	url := strings.TrimSpace(c.ExternalURL())
+	if url == "" {
+		return ErrExternalTargetURLRequired
+	}
+
+	lower := strings.ToLower(url)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return nil
+	}
+	// Relative URLs point to Grafana itself. Protocol relative URLs ("//host") could point anywhere.
+	if strings.HasPrefix(lower, "/") && !strings.HasPrefix(lower, "//") {
+		return nil
+	}
+	return fmt.Errorf("%s: \"%s\"", ErrExternalTargetURLInvalid, url)
+}
+
 func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
 	target := c.Target
 	transformations := c.Transformations
@@ -123,7 +176,7 @@ type Correlation struct {
 	Config CorrelationConfig `json:"config" xorm:"jsonb config"`
 	// Provisioned True if the correlation was created during provisioning
 	Provisioned bool `json:"provisioned"`
-	// The type of correlation. Currently, only valid value is "query"
+	// The type of correlation. Valid values are "query" and "external"
 	Type CorrelationType `json:"type" binding:"Required"`
 }
 
@@ -161,19 +214,35 @@ type CreateCorrelationCommand struct {
 	Config CorrelationConfig `json:"config" binding:"Required"`
 	// True if correlation was created with provisioning. This makes it read-only.
 	Provisioned bool `json:"provisioned"`
-	// correlation type, currently only valid value is "query"
+	// correlation type, valid values are "query" and "external"
 	Type CorrelationType `json:"type" binding:"Required"`
 }
 
 func (c CreateCorrelationCommand) Validate() error {
-	if err := c.Type.Validate(); err != nil {
+	return validateCorrelation(c.Type, c.TargetUID, c.Config)
+}
+
+// validateCorrelation validates the parts of a correlation that depend on its type
+func validateCorrelation(t CorrelationType, targetUID *string, config CorrelationConfig) error {
+	if err := t.Validate(); err != nil {
 		return err
 	}
-	if c.TargetUID == nil && c.Type == TypeQuery {
-		return fmt.Errorf("correlations of type \"%s\" must have a targetUID", TypeQuery)
+
+	switch t {
+	case TypeQuery:
+		if targetUID == nil {
+			return fmt.Errorf("correlations of type \"%s\" must have a targetUID", TypeQuery)
+		}
+	case TypeExternal:
+		if targetUID != nil && *targetUID != "" {
+			return ErrExternalTargetUIDNotAllowed
+		}
+		if err := config.validateExternalTarget(); err != nil {
+			return err
+		}
 	}
 
-	if err := c.Config.Transformations.Validate(); err != nil {
+	if err := config.Transformations.Validate(); err != nil {
 		return err
 	}
 	return nil
@@ -238,6 +307,18 @@ func (c UpdateCorrelationCommand) Validate() error {
 		return ErrUpdateCorrelationEmptyParams
 	}
 
+	if c.Type != nil {
+		if err := c.Type.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Config != nil {
+		if err := Transformations(c.Config.Transformations).Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
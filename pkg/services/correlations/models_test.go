@@ -55,6 +55,82 @@ func TestCorrelationModels(t *testing.T) {
 
 			require.Error(t, cmd.Validate())
 		})
+
+		t.Run("Successfully validates an external correlation", func(t *testing.T) {
+			config := &CorrelationConfig{
+				Field:  "field",
+				Target: map[string]any{"url": "https://tickets.example.com/new?service=${service}"},
+			}
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config:    *config,
+				Type:      TypeExternal,
+			}
+
+			require.NoError(t, cmd.Validate())
+		})
+
+		t.Run("Validates the url of external correlations", func(t *testing.T) {
+			type test struct {
+				target    map[string]any
+				assertion require.ErrorAssertionFunc
+			}
+
+			tests := []test{
+				{target: map[string]any{"url": "http://runbooks.example.com/${service}"}, assertion: require.NoError},
+				{target: map[string]any{"url": "/d/dashboard?var-service=${service}"}, assertion: require.NoError},
+				{target: map[string]any{}, assertion: require.Error},
+				{target: map[string]any{"url": ""}, assertion: require.Error},
+				{target: map[string]any{"url": 42}, assertion: require.Error},
+				{target: map[string]any{"url": "javascript:alert(1)"}, assertion: require.Error},
+				{target: map[string]any{"url": "//example.com/${service}"}, assertion: require.Error},
+			}
+
+			for _, tc := range tests {
+				cmd := &CreateCorrelationCommand{
+					Config: CorrelationConfig{Field: "field", Target: tc.target},
+					Type:   TypeExternal,
+				}
+				tc.assertion(t, cmd.Validate())
+			}
+		})
+
+		t.Run("Fails if an external correlation has a target UID", func(t *testing.T) {
+			targetUid := "targetUid"
+			cmd := &CreateCorrelationCommand{
+				TargetUID: &targetUid,
+				Config: CorrelationConfig{
+					Field:  "field",
+					Target: map[string]any{"url": "https://example.com"},
+				},
+				Type: TypeExternal,
+			}
+
+			require.ErrorIs(t, cmd.Validate(), ErrExternalTargetUIDNotAllowed)
+		})
+	})
+
+	t.Run("Transformations Validate", func(t *testing.T) {
+		type test struct {
+			input     Transformation
+			assertion require.ErrorAssertionFunc
+		}
+
+		tests := []test{
+			{input: Transformation{Type: "logfmt"}, assertion: require.NoError},
+			{input: Transformation{Type: "regex", Expression: "id=(\\w+)"}, assertion: require.NoError},
+			{input: Transformation{Type: "regex"}, assertion: require.Error},
+			{input: Transformation{Type: "json"}, assertion: require.NoError},
+			{input: Transformation{Type: "json", Expression: "$.trace.id", MapValue: "traceId"}, assertion: require.NoError},
+			{input: Transformation{Type: "labels", Field: "labels"}, assertion: require.NoError},
+			{input: Transformation{Type: "labels"}, assertion: require.Error},
+			{input: Transformation{Type: "xml"}, assertion: require.Error},
+		}
+
+		for _, tc := range tests {
+			tc.assertion(t, Transformations{tc.input}.Validate())
+		}
 	})
 
 	t.Run("CorrelationConfigType Validate", func(t *testing.T) {
@@ -66,6 +142,7 @@ func TestCorrelationModels(t *testing.T) {
 
 			tests := []test{
 				{input: "query", assertion: require.NoError},
+				{input: "external", assertion: require.NoError},
 				{input: "link", assertion: require.Error},
 			}
 
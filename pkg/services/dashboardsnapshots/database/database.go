@@ -61,6 +61,7 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 			External:           cmd.External,
 			ExternalURL:        cmd.ExternalURL,
 			ExternalDeleteURL:  cmd.ExternalDeleteURL,
+			ScheduleUID:        cmd.ScheduleUID,
 			Dashboard:          simplejson.New(),
 			DashboardEncrypted: cmd.DashboardEncrypted,
 			Expires:            expires,
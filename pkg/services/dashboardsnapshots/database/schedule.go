@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DashboardSnapshotStore implements the ScheduleStore interface
+var _ dashboardsnapshots.ScheduleStore = (*DashboardSnapshotStore)(nil)
+
+func (d *DashboardSnapshotStore) CreateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.CreateSnapshotScheduleCommand) (*dashboardsnapshots.SnapshotSchedule, error) {
+	now := time.Now()
+	schedule := &dashboardsnapshots.SnapshotSchedule{
+		UID:          util.GenerateShortUID(),
+		OrgID:        cmd.OrgID,
+		UserID:       cmd.UserID,
+		DashboardUID: cmd.DashboardUID,
+		Name:         cmd.Name,
+		Cron:         cmd.Cron,
+		KeepLast:     cmd.KeepLast,
+		TimeFrom:     cmd.TimeFrom,
+		TimeTo:       cmd.TimeTo,
+		Enabled:      cmd.IsEnabled(),
+		Created:      now,
+		Updated:      now,
+	}
+
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(schedule)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (d *DashboardSnapshotStore) UpdateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleCommand) (*dashboardsnapshots.SnapshotSchedule, error) {
+	schedule := &dashboardsnapshots.SnapshotSchedule{}
+	err := d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Get(schedule)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashboardsnapshots.ErrScheduleNotFound
+		}
+
+		schedule.DashboardUID = cmd.DashboardUID
+		schedule.Name = cmd.Name
+		schedule.Cron = cmd.Cron
+		schedule.KeepLast = cmd.KeepLast
+		schedule.TimeFrom = cmd.TimeFrom
+		schedule.TimeTo = cmd.TimeTo
+		if cmd.Enabled != nil {
+			schedule.Enabled = *cmd.Enabled
+		}
+		schedule.Updated = time.Now()
+
+		_, err = sess.ID(schedule.ID).AllCols().Update(schedule)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (d *DashboardSnapshotStore) DeleteSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.DeleteSnapshotScheduleCommand) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		// snapshots created by the schedule are kept, they are an immutable record of the dashboard
+		deleted, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Delete(&dashboardsnapshots.SnapshotSchedule{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return dashboardsnapshots.ErrScheduleNotFound
+		}
+		return nil
+	})
+}
+
+func (d *DashboardSnapshotStore) GetSnapshotSchedule(ctx context.Context, query *dashboardsnapshots.GetSnapshotScheduleQuery) (*dashboardsnapshots.SnapshotSchedule, error) {
+	schedule := &dashboardsnapshots.SnapshotSchedule{}
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(schedule)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashboardsnapshots.ErrScheduleNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (d *DashboardSnapshotStore) SearchSnapshotSchedules(ctx context.Context, query *dashboardsnapshots.SearchSnapshotSchedulesQuery) ([]*dashboardsnapshots.SnapshotSchedule, error) {
+	schedules := make([]*dashboardsnapshots.SnapshotSchedule, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.UserID != 0 {
+			sess.And("user_id = ?", query.UserID)
+		}
+		return sess.Asc("name").Find(&schedules)
+	})
+	return schedules, err
+}
+
+func (d *DashboardSnapshotStore) GetEnabledSnapshotSchedules(ctx context.Context) ([]*dashboardsnapshots.SnapshotSchedule, error) {
+	schedules := make([]*dashboardsnapshots.SnapshotSchedule, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		// bool in a struct needs to be in Where
+		return sess.Where("enabled = ?", true).Find(&schedules)
+	})
+	return schedules, err
+}
+
+func (d *DashboardSnapshotStore) UpdateSnapshotScheduleRun(ctx context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleRunCommand) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table("dashboard_snapshot_schedule").Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Update(map[string]any{
+			"last_run":   cmd.LastRun,
+			"last_error": cmd.Error,
+		})
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) DeleteScheduledSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteScheduledSnapshotsCommand) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var ids []int64
+		err := sess.SQL("SELECT id FROM dashboard_snapshot WHERE org_id = ? AND schedule_uid = ? ORDER BY created DESC, id DESC", cmd.OrgID, cmd.ScheduleUID).Find(&ids)
+		if err != nil {
+			return err
+		}
+		// never delete every snapshot of a schedule, schedules saved without a limit keep the default number
+		keepLast := cmd.KeepLast
+		if keepLast <= 0 {
+			keepLast = dashboardsnapshots.DefaultScheduleKeepLast
+		}
+		if len(ids) <= keepLast {
+			return nil
+		}
+
+		deleted, err := sess.Table("dashboard_snapshot").In("id", ids[keepLast:]).Delete(&dashboardsnapshots.DashboardSnapshot{})
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows = deleted
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationSnapshotScheduleDBAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	store := ProvideStore(sqlstore, setting.NewCfg())
+	ctx := context.Background()
+
+	schedule, err := store.CreateSnapshotSchedule(ctx, &dashboardsnapshots.CreateSnapshotScheduleCommand{
+		SnapshotScheduleSpec: dashboardsnapshots.SnapshotScheduleSpec{
+			DashboardUID: "dash",
+			Cron:         "0 6 * * *",
+			KeepLast:     2,
+		},
+		OrgID:  1,
+		UserID: 10,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, schedule.UID)
+	require.True(t, schedule.Enabled)
+
+	t.Run("Should get and search schedules of the org", func(t *testing.T) {
+		result, err := store.GetSnapshotSchedule(ctx, &dashboardsnapshots.GetSnapshotScheduleQuery{UID: schedule.UID, OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "dash", result.DashboardUID)
+		assert.Equal(t, int64(10), result.UserID)
+
+		_, err = store.GetSnapshotSchedule(ctx, &dashboardsnapshots.GetSnapshotScheduleQuery{UID: schedule.UID, OrgID: 2})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrScheduleNotFound)
+
+		schedules, err := store.SearchSnapshotSchedules(ctx, &dashboardsnapshots.SearchSnapshotSchedulesQuery{OrgID: 1, DashboardUID: "dash"})
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+
+		schedules, err = store.SearchSnapshotSchedules(ctx, &dashboardsnapshots.SearchSnapshotSchedulesQuery{OrgID: 1, DashboardUID: "other"})
+		require.NoError(t, err)
+		require.Len(t, schedules, 0)
+	})
+
+	t.Run("Should only return enabled schedules", func(t *testing.T) {
+		schedules, err := store.GetEnabledSnapshotSchedules(ctx)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+
+		updated, err := store.UpdateSnapshotSchedule(ctx, &dashboardsnapshots.UpdateSnapshotScheduleCommand{
+			SnapshotScheduleSpec: dashboardsnapshots.SnapshotScheduleSpec{DashboardUID: "dash", Cron: "0 7 * * *", KeepLast: 2},
+			UID:                  schedule.UID,
+			OrgID:                1,
+		})
+		require.NoError(t, err)
+		require.True(t, updated.Enabled)
+
+		_, err = store.UpdateSnapshotSchedule(ctx, &dashboardsnapshots.UpdateSnapshotScheduleCommand{
+			SnapshotScheduleSpec: dashboardsnapshots.SnapshotScheduleSpec{DashboardUID: "dash", Cron: "0 6 * * *", KeepLast: 2, Enabled: util.Pointer(false)},
+			UID:                  schedule.UID,
+			OrgID:                1,
+		})
+		require.NoError(t, err)
+
+		schedules, err = store.GetEnabledSnapshotSchedules(ctx)
+		require.NoError(t, err)
+		require.Len(t, schedules, 0)
+	})
+
+	t.Run("Should record the last run", func(t *testing.T) {
+		lastRun := time.Now().Truncate(time.Second)
+		err := store.UpdateSnapshotScheduleRun(ctx, &dashboardsnapshots.UpdateSnapshotScheduleRunCommand{UID: schedule.UID, OrgID: 1, LastRun: lastRun, Error: "panel 1: failed"})
+		require.NoError(t, err)
+
+		result, err := store.GetSnapshotSchedule(ctx, &dashboardsnapshots.GetSnapshotScheduleQuery{UID: schedule.UID, OrgID: 1})
+		require.NoError(t, err)
+		assert.True(t, lastRun.Equal(result.LastRun))
+		assert.Equal(t, "panel 1: failed", result.LastError)
+	})
+
+	t.Run("Should only keep the last snapshots of the schedule", func(t *testing.T) {
+		for _, key := range []string{"first", "second", "third"} {
+			_, err := store.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+				Key:         key,
+				DeleteKey:   key + "-delete",
+				OrgID:       1,
+				UserID:      10,
+				ScheduleUID: schedule.UID,
+			})
+			require.NoError(t, err)
+		}
+		_, err := store.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{Key: "manual", DeleteKey: "manual-delete", OrgID: 1, UserID: 10})
+		require.NoError(t, err)
+
+		cmd := &dashboardsnapshots.DeleteScheduledSnapshotsCommand{ScheduleUID: schedule.UID, OrgID: 1, KeepLast: 0}
+		require.NoError(t, store.DeleteScheduledSnapshots(ctx, cmd))
+		assert.Equal(t, int64(0), cmd.DeletedRows, "a schedule without a limit keeps the default number of snapshots")
+
+		cmd = &dashboardsnapshots.DeleteScheduledSnapshotsCommand{ScheduleUID: schedule.UID, OrgID: 1, KeepLast: 2}
+		require.NoError(t, store.DeleteScheduledSnapshots(ctx, cmd))
+		assert.Equal(t, int64(1), cmd.DeletedRows)
+
+		_, err = store.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "first"})
+		require.Error(t, err)
+		for _, key := range []string{"second", "third", "manual"} {
+			_, err = store.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key})
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Should delete the schedule but keep its snapshots", func(t *testing.T) {
+		err := store.DeleteSnapshotSchedule(ctx, &dashboardsnapshots.DeleteSnapshotScheduleCommand{UID: schedule.UID, OrgID: 1})
+		require.NoError(t, err)
+
+		err = store.DeleteSnapshotSchedule(ctx, &dashboardsnapshots.DeleteSnapshotScheduleCommand{UID: schedule.UID, OrgID: 1})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrScheduleNotFound)
+
+		_, err = store.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "third"})
+		require.NoError(t, err)
+	})
+}
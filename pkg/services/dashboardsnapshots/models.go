@@ -19,6 +19,8 @@ type DashboardSnapshot struct {
 	External          bool
 	ExternalURL       string `xorm:"external_url"`
 	ExternalDeleteURL string `xorm:"external_delete_url"`
+	// ScheduleUID is the UID of the schedule that created the snapshot, empty for snapshots created by users
+	ScheduleUID string `xorm:"schedule_uid"`
 
 	Expires time.Time
 	Created time.Time
@@ -37,6 +39,7 @@ type DashboardSnapshotDTO struct {
 	UserID      int64  `json:"-" xorm:"user_id"`
 	External    bool   `json:"external"`
 	ExternalURL string `json:"externalUrl" xorm:"external_url"`
+	ScheduleUID string `json:"scheduleUid,omitempty" xorm:"schedule_uid"`
 
 	Expires time.Time `json:"expires"`
 	Created time.Time `json:"created"`
@@ -66,6 +69,9 @@ type CreateDashboardSnapshotCommand struct {
 	OrgID  int64 `json:"-"`
 	UserID int64 `json:"-"`
 
+	// ScheduleUID is set when the snapshot is created by a snapshot schedule
+	ScheduleUID string `json:"-"`
+
 	DashboardEncrypted []byte `json:"-"`
 }
 
@@ -0,0 +1,186 @@
+package dashboardsnapshots
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// DefaultScheduleKeepLast is the number of snapshots kept per schedule when none is configured
+	DefaultScheduleKeepLast = 7
+	// MaxScheduleKeepLast limits the number of snapshots a single schedule can keep
+	MaxScheduleKeepLast = 365
+)
+
+var (
+	ErrScheduleNotFound        = errors.New("dashboard snapshot schedule not found")
+	ErrScheduleInvalidCron     = errors.New("invalid cron expression")
+	ErrScheduleInvalidKeepLast = fmt.Errorf("keepLast must be between 1 and %d", MaxScheduleKeepLast)
+	ErrScheduleInvalidTime     = errors.New("timeFrom and timeTo must either both be set or both be empty")
+	ErrScheduleInvalidRange    = errors.New("timeFrom and timeTo must be valid times and timeFrom must be before timeTo")
+	ErrScheduleInvalidName     = errors.New("name must not be longer than 255 characters")
+	ErrScheduleDashboardUID    = errors.New("invalid dashboard UID")
+)
+
+// SnapshotSchedule periodically stores a snapshot of a dashboard together with the data of its panels
+type SnapshotSchedule struct {
+	ID           int64  `json:"-" xorm:"pk autoincr 'id'"`
+	UID          string `json:"uid" xorm:"uid"`
+	OrgID        int64  `json:"-" xorm:"org_id"`
+	UserID       int64  `json:"userId" xorm:"user_id"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	Name         string `json:"name"`
+	// Cron expression in the standard five field format, e.g. "0 6 * * *"
+	Cron     string `json:"cron"`
+	KeepLast int    `json:"keepLast" xorm:"keep_last"`
+	// TimeFrom and TimeTo override the time range of the dashboard, e.g. "now-24h" and "now"
+	TimeFrom string `json:"timeFrom" xorm:"time_from"`
+	TimeTo   string `json:"timeTo" xorm:"time_to"`
+	Enabled  bool   `json:"enabled"`
+
+	LastRun   time.Time `json:"lastRun" xorm:"last_run"`
+	LastError string    `json:"lastError,omitempty" xorm:"last_error"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+func (s SnapshotSchedule) TableName() string {
+	return "dashboard_snapshot_schedule"
+}
+
+// NextRun returns the first time after the last run, or after the creation of the schedule, when the schedule is due
+func (s *SnapshotSchedule) NextRun() (time.Time, error) {
+	sched, err := cron.ParseStandard(s.Cron)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrScheduleInvalidCron, err)
+	}
+
+	from := s.LastRun
+	if from.IsZero() {
+		from = s.Created
+	}
+	return sched.Next(from), nil
+}
+
+// SnapshotScheduleSpec are the user defined fields of a snapshot schedule
+type SnapshotScheduleSpec struct {
+	// UID of the dashboard to snapshot
+	// required:true
+	DashboardUID string `json:"dashboardUid"`
+	// Name of the created snapshots, defaults to the dashboard title
+	Name string `json:"name"`
+	// Cron expression in the standard five field format
+	// required:true
+	// example: 0 6 * * *
+	Cron string `json:"cron"`
+	// Number of snapshots to keep, older snapshots of the schedule are deleted
+	// default:7
+	KeepLast int `json:"keepLast"`
+	// Start of the time range of the snapshot, defaults to the dashboard time range
+	// example: now-24h
+	TimeFrom string `json:"timeFrom"`
+	// End of the time range of the snapshot, defaults to the dashboard time range
+	// example: now
+	TimeTo string `json:"timeTo"`
+	// Disabled schedules do not create snapshots. New schedules are enabled unless set to false, updates without
+	// the field keep the current value.
+	// default:true
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// IsEnabled returns whether a schedule created from the spec is enabled
+func (spec *SnapshotScheduleSpec) IsEnabled() bool {
+	return spec.Enabled == nil || *spec.Enabled
+}
+
+func (spec *SnapshotScheduleSpec) Validate() error {
+	if !util.IsValidShortUID(spec.DashboardUID) {
+		return ErrScheduleDashboardUID
+	}
+	if len(spec.Name) > 255 {
+		return ErrScheduleInvalidName
+	}
+	if _, err := cron.ParseStandard(spec.Cron); err != nil {
+		return fmt.Errorf("%w: %s", ErrScheduleInvalidCron, err)
+	}
+	if spec.KeepLast == 0 {
+		spec.KeepLast = DefaultScheduleKeepLast
+	}
+	if spec.KeepLast < 0 || spec.KeepLast > MaxScheduleKeepLast {
+		return ErrScheduleInvalidKeepLast
+	}
+	if (spec.TimeFrom == "") != (spec.TimeTo == "") {
+		return ErrScheduleInvalidTime
+	}
+	if spec.TimeFrom != "" {
+		tr := gtime.NewTimeRange(spec.TimeFrom, spec.TimeTo)
+		from, err := tr.ParseFrom()
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrScheduleInvalidRange, err)
+		}
+		to, err := tr.ParseTo()
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrScheduleInvalidRange, err)
+		}
+		if !from.Before(to) {
+			return ErrScheduleInvalidRange
+		}
+	}
+	return nil
+}
+
+// swagger:model
+type CreateSnapshotScheduleCommand struct {
+	SnapshotScheduleSpec
+
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+// swagger:model
+type UpdateSnapshotScheduleCommand struct {
+	SnapshotScheduleSpec
+
+	UID   string `json:"-"`
+	OrgID int64  `json:"-"`
+}
+
+type DeleteSnapshotScheduleCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type GetSnapshotScheduleQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type SearchSnapshotSchedulesQuery struct {
+	OrgID        int64
+	DashboardUID string
+	// UserID limits the result to schedules created by the user, 0 returns the schedules of all users
+	UserID int64
+}
+
+// UpdateSnapshotScheduleRunCommand records the outcome of a run of a schedule
+type UpdateSnapshotScheduleRunCommand struct {
+	UID     string
+	OrgID   int64
+	LastRun time.Time
+	Error   string
+}
+
+// DeleteScheduledSnapshotsCommand deletes the snapshots created by a schedule except the last KeepLast ones
+type DeleteScheduledSnapshotsCommand struct {
+	ScheduleUID string
+	OrgID       int64
+	KeepLast    int
+
+	DeletedRows int64
+}
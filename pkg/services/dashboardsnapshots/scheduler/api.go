@@ -0,0 +1,291 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/snapshot-schedules", func(schedules routing.RouteRegister) {
+		schedules.Get("/", middleware.ReqSignedIn, routing.Wrap(s.searchHandler))
+		schedules.Post("/", middleware.ReqSignedIn, routing.Wrap(s.createHandler))
+		schedules.Get("/:uid", middleware.ReqSignedIn, routing.Wrap(s.getHandler))
+		schedules.Put("/:uid", middleware.ReqSignedIn, routing.Wrap(s.updateHandler))
+		schedules.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(s.deleteHandler))
+		schedules.Post("/:uid/run", middleware.ReqSignedIn, routing.Wrap(s.runHandler))
+	})
+}
+
+// swagger:route GET /snapshot-schedules snapshots searchSnapshotSchedules
+//
+// Get the snapshot schedules of the dashboards the user can read.
+//
+// Responses:
+// 200: searchSnapshotSchedulesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) searchHandler(c *contextmodel.ReqContext) response.Response {
+	schedules, err := s.store.SearchSnapshotSchedules(c.Req.Context(), &dashboardsnapshots.SearchSnapshotSchedulesQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: c.Query("dashboardUid"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get snapshot schedules", err)
+	}
+
+	result := make([]*dashboardsnapshots.SnapshotSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if ok, err := s.canAccessDashboard(c, dashboards.ActionDashboardsRead, schedule.DashboardUID); err == nil && ok {
+			result = append(result, schedule)
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /snapshot-schedules snapshots createSnapshotSchedule
+//
+// Create a schedule taking snapshots of a dashboard.
+//
+// The snapshots are created with the permissions of the user creating the schedule.
+//
+// Responses:
+// 200: getSnapshotScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := dashboardsnapshots.CreateSnapshotScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := cmd.Validate(); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	if resp := s.authorizeDashboardWrite(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+	schedule, err := s.store.CreateSnapshotSchedule(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to create snapshot schedule", err)
+	}
+
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route GET /snapshot-schedules/{uid} snapshots getSnapshotSchedule
+//
+// Get a snapshot schedule.
+//
+// Responses:
+// 200: getSnapshotScheduleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	schedule, resp := s.getSchedule(c, dashboards.ActionDashboardsRead)
+	if resp != nil {
+		return resp
+	}
+
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route PUT /snapshot-schedules/{uid} snapshots updateSnapshotSchedule
+//
+// Update a snapshot schedule.
+//
+// Responses:
+// 200: getSnapshotScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := dashboardsnapshots.UpdateSnapshotScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := cmd.Validate(); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	existing, resp := s.getSchedule(c, dashboards.ActionDashboardsWrite)
+	if resp != nil {
+		return resp
+	}
+	if cmd.DashboardUID != existing.DashboardUID {
+		if resp := s.authorizeDashboardWrite(c, cmd.DashboardUID); resp != nil {
+			return resp
+		}
+	}
+
+	cmd.UID = existing.UID
+	cmd.OrgID = existing.OrgID
+	schedule, err := s.store.UpdateSnapshotSchedule(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, dashboardsnapshots.ErrScheduleNotFound) {
+			return response.Error(http.StatusNotFound, "Snapshot schedule not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update snapshot schedule", err)
+	}
+
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route DELETE /snapshot-schedules/{uid} snapshots deleteSnapshotSchedule
+//
+// Delete a snapshot schedule.
+//
+// The snapshots created by the schedule are kept.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	schedule, resp := s.getSchedule(c, dashboards.ActionDashboardsWrite)
+	if resp != nil {
+		return resp
+	}
+
+	err := s.store.DeleteSnapshotSchedule(c.Req.Context(), &dashboardsnapshots.DeleteSnapshotScheduleCommand{UID: schedule.UID, OrgID: schedule.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboardsnapshots.ErrScheduleNotFound) {
+			return response.Error(http.StatusNotFound, "Snapshot schedule not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete snapshot schedule", err)
+	}
+
+	return response.Success("Snapshot schedule deleted")
+}
+
+// swagger:route POST /snapshot-schedules/{uid}/run snapshots runSnapshotSchedule
+//
+// Create a snapshot of a schedule immediately.
+//
+// Responses:
+// 200: createDashboardSnapshotResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) runHandler(c *contextmodel.ReqContext) response.Response {
+	schedule, resp := s.getSchedule(c, dashboards.ActionDashboardsWrite)
+	if resp != nil {
+		return resp
+	}
+
+	snapshot, err := s.runSchedule(c.Req.Context(), schedule)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to create scheduled snapshot", err)
+	}
+
+	return response.JSON(http.StatusOK, dashboardsnapshot.DashboardCreateResponse{
+		Key:       snapshot.Key,
+		DeleteKey: snapshot.DeleteKey,
+		URL:       setting.ToAbsUrl("dashboard/snapshot/" + snapshot.Key),
+		DeleteURL: setting.ToAbsUrl("api/snapshots-delete/" + snapshot.DeleteKey),
+	})
+}
+
+// getSchedule returns the schedule of the request if the user is allowed to perform the action on its dashboard
+func (s *Service) getSchedule(c *contextmodel.ReqContext, action string) (*dashboardsnapshots.SnapshotSchedule, response.Response) {
+	schedule, err := s.store.GetSnapshotSchedule(c.Req.Context(), &dashboardsnapshots.GetSnapshotScheduleQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		if errors.Is(err, dashboardsnapshots.ErrScheduleNotFound) {
+			return nil, response.Error(http.StatusNotFound, "Snapshot schedule not found", err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Failed to get snapshot schedule", err)
+	}
+
+	ok, err := s.canAccessDashboard(c, action, schedule.DashboardUID)
+	if err != nil {
+		return nil, response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return nil, response.Error(http.StatusForbidden, "Access denied to the dashboard of the snapshot schedule", nil)
+	}
+
+	return schedule, nil
+}
+
+func (s *Service) authorizeDashboardWrite(c *contextmodel.ReqContext, dashboardUID string) response.Response {
+	ok, err := s.canAccessDashboard(c, dashboards.ActionDashboardsWrite, dashboardUID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard", nil)
+	}
+	return nil
+}
+
+func (s *Service) canAccessDashboard(c *contextmodel.ReqContext, action string, dashboardUID string) (bool, error) {
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)
+	return s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(action, scope))
+}
+
+// swagger:parameters getSnapshotSchedule updateSnapshotSchedule deleteSnapshotSchedule runSnapshotSchedule
+type SnapshotScheduleUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters searchSnapshotSchedules
+type SearchSnapshotSchedulesParams struct {
+	// Only return the schedules of the dashboard
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// swagger:parameters createSnapshotSchedule
+type CreateSnapshotScheduleParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.CreateSnapshotScheduleCommand
+}
+
+// swagger:parameters updateSnapshotSchedule
+type UpdateSnapshotScheduleParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.UpdateSnapshotScheduleCommand
+}
+
+// swagger:response searchSnapshotSchedulesResponse
+type SearchSnapshotSchedulesResponse struct {
+	// in:body
+	Body []*dashboardsnapshots.SnapshotSchedule `json:"body"`
+}
+
+// swagger:response getSnapshotScheduleResponse
+type GetSnapshotScheduleResponse struct {
+	// in:body
+	Body *dashboardsnapshots.SnapshotSchedule `json:"body"`
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type fakeScheduleStore struct {
+	dashboardsnapshots.ScheduleStore
+
+	created *dashboardsnapshots.CreateSnapshotScheduleCommand
+	updated *dashboardsnapshots.UpdateSnapshotScheduleCommand
+}
+
+func (f *fakeScheduleStore) CreateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.CreateSnapshotScheduleCommand) (*dashboardsnapshots.SnapshotSchedule, error) {
+	f.created = cmd
+	return &dashboardsnapshots.SnapshotSchedule{UID: "schedule", DashboardUID: cmd.DashboardUID, Cron: cmd.Cron, KeepLast: cmd.KeepLast}, nil
+}
+
+func (f *fakeScheduleStore) GetSnapshotSchedule(ctx context.Context, query *dashboardsnapshots.GetSnapshotScheduleQuery) (*dashboardsnapshots.SnapshotSchedule, error) {
+	return &dashboardsnapshots.SnapshotSchedule{UID: query.UID, OrgID: query.OrgID, DashboardUID: "dash", Cron: "0 6 * * *", KeepLast: 3}, nil
+}
+
+func (f *fakeScheduleStore) UpdateSnapshotSchedule(ctx context.Context, cmd *dashboardsnapshots.UpdateSnapshotScheduleCommand) (*dashboardsnapshots.SnapshotSchedule, error) {
+	f.updated = cmd
+	return &dashboardsnapshots.SnapshotSchedule{UID: cmd.UID, DashboardUID: cmd.DashboardUID, Cron: cmd.Cron, KeepLast: cmd.KeepLast}, nil
+}
+
+func TestSnapshotScheduleAPI(t *testing.T) {
+	store := &fakeScheduleStore{}
+	s := &Service{store: store, accessControl: actest.FakeAccessControl{ExpectedEvaluate: true}}
+	routeRegister := routing.NewRouteRegister()
+	s.registerAPIEndpoints(routeRegister)
+	server := webtest.NewServer(t, routeRegister)
+
+	send := func(method string, url string, body string) *http.Response {
+		req := server.NewRequest(method, url, strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1})
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+
+	invalid := map[string]string{
+		"invalid cron":            `{"dashboardUid": "dash", "cron": "every day"}`,
+		"negative keepLast":       `{"dashboardUid": "dash", "cron": "0 6 * * *", "keepLast": -1}`,
+		"keepLast above the max":  `{"dashboardUid": "dash", "cron": "0 6 * * *", "keepLast": 1000}`,
+		"timeFrom after timeTo":   `{"dashboardUid": "dash", "cron": "0 6 * * *", "timeFrom": "now", "timeTo": "now-24h"}`,
+		"invalid timeFrom":        `{"dashboardUid": "dash", "cron": "0 6 * * *", "timeFrom": "yesterday", "timeTo": "now"}`,
+		"timeFrom without timeTo": `{"dashboardUid": "dash", "cron": "0 6 * * *", "timeFrom": "now-24h"}`,
+	}
+
+	t.Run("Should reject invalid schedules on create", func(t *testing.T) {
+		for name, body := range invalid {
+			resp := send(http.MethodPost, "/api/snapshot-schedules", body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+		}
+		assert.Nil(t, store.created)
+	})
+
+	t.Run("Should reject invalid schedules on update", func(t *testing.T) {
+		for name, body := range invalid {
+			resp := send(http.MethodPut, "/api/snapshot-schedules/schedule", body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+		}
+		assert.Nil(t, store.updated)
+	})
+
+	t.Run("Should default keepLast when it is omitted", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/snapshot-schedules", `{"dashboardUid": "dash", "cron": "0 6 * * *", "timeFrom": "now-24h", "timeTo": "now"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotNil(t, store.created)
+		assert.Equal(t, dashboardsnapshots.DefaultScheduleKeepLast, store.created.KeepLast)
+
+		var schedule dashboardsnapshots.SnapshotSchedule
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&schedule))
+		assert.Equal(t, dashboardsnapshots.DefaultScheduleKeepLast, schedule.KeepLast)
+
+		resp = send(http.MethodPut, "/api/snapshot-schedules/schedule", `{"dashboardUid": "dash", "cron": "0 6 * * *"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotNil(t, store.updated)
+		assert.Equal(t, dashboardsnapshots.DefaultScheduleKeepLast, store.updated.KeepLast)
+	})
+
+	t.Run("Should enable schedules created without enabled", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/snapshot-schedules", `{"dashboardUid": "dash", "cron": "0 6 * * *"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotNil(t, store.created)
+		assert.Nil(t, store.created.Enabled)
+		assert.True(t, store.created.IsEnabled())
+
+		resp = send(http.MethodPost, "/api/snapshot-schedules", `{"dashboardUid": "dash", "cron": "0 6 * * *", "enabled": false}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.False(t, store.created.IsEnabled())
+	})
+}
@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// checkInterval is how often the schedules are checked for due runs. Cron expressions have a minute resolution.
+const checkInterval = time.Minute
+
+// Service runs the queries of dashboards on a cron schedule and stores the dashboards together with the
+// query results as snapshots, keeping only the last snapshots of each schedule.
+type Service struct {
+	cfg              *setting.Cfg
+	store            dashboardsnapshots.ScheduleStore
+	snapshotService  dashboardsnapshots.Service
+	dashboardService dashboards.DashboardService
+	queryService     query.Service
+	userService      user.Service
+	acService        accesscontrol.Service
+	accessControl    accesscontrol.AccessControl
+	serverLock       *serverlock.ServerLockService
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	store dashboardsnapshots.ScheduleStore,
+	snapshotService dashboardsnapshots.Service,
+	dashboardService dashboards.DashboardService,
+	queryService query.Service,
+	userService user.Service,
+	acService accesscontrol.Service,
+	accessControl accesscontrol.AccessControl,
+	serverLock *serverlock.ServerLockService,
+	routeRegister routing.RouteRegister,
+) *Service {
+	s := &Service{
+		cfg:              cfg,
+		store:            store,
+		snapshotService:  snapshotService,
+		dashboardService: dashboardService,
+		queryService:     queryService,
+		userService:      userService,
+		acService:        acService,
+		accessControl:    accessControl,
+		serverLock:       serverLock,
+		log:              log.New("dashboardsnapshots.scheduler"),
+		now:              time.Now,
+	}
+
+	if !s.IsDisabled() {
+		s.registerAPIEndpoints(routeRegister)
+	}
+
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.SnapshotEnabled || !s.cfg.ScheduledSnapshotsEnabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			// the lock makes sure only one instance runs the schedules in HA setups
+			err := s.serverLock.LockAndExecute(ctx, "run scheduled dashboard snapshots", checkInterval/2, func(ctx context.Context) {
+				s.runDueSchedules(ctx)
+			})
+			if err != nil {
+				s.log.Error("Failed to run scheduled dashboard snapshots", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) runDueSchedules(ctx context.Context) {
+	schedules, err := s.store.GetEnabledSnapshotSchedules(ctx)
+	if err != nil {
+		s.log.Error("Failed to get dashboard snapshot schedules", "error", err)
+		return
+	}
+
+	now := s.now()
+	for _, schedule := range schedules {
+		next, err := schedule.NextRun()
+		if err != nil {
+			s.log.Warn("Invalid dashboard snapshot schedule", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+			continue
+		}
+		if next.After(now) {
+			continue
+		}
+
+		if _, err := s.runSchedule(ctx, schedule); err != nil {
+			s.log.Error("Failed to create scheduled dashboard snapshot", "uid", schedule.UID, "orgId", schedule.OrgID, "dashboardUid", schedule.DashboardUID, "error", err)
+		}
+	}
+}
+
+// runSchedule creates a snapshot for the schedule, removes the snapshots exceeding the retention of the schedule
+// and records the outcome of the run.
+func (s *Service) runSchedule(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule) (*dashboardsnapshots.DashboardSnapshot, error) {
+	ranAt := s.now()
+	snapshot, panelErrors, runErr := s.createSnapshot(ctx, schedule, ranAt)
+
+	if runErr == nil {
+		cmd := &dashboardsnapshots.DeleteScheduledSnapshotsCommand{ScheduleUID: schedule.UID, OrgID: schedule.OrgID, KeepLast: schedule.KeepLast}
+		if err := s.store.DeleteScheduledSnapshots(ctx, cmd); err != nil {
+			s.log.Warn("Failed to delete old scheduled dashboard snapshots", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+		} else if cmd.DeletedRows > 0 {
+			s.log.Debug("Deleted old scheduled dashboard snapshots", "uid", schedule.UID, "orgId", schedule.OrgID, "rows", cmd.DeletedRows)
+		}
+	}
+
+	runCmd := &dashboardsnapshots.UpdateSnapshotScheduleRunCommand{UID: schedule.UID, OrgID: schedule.OrgID, LastRun: ranAt}
+	if runErr != nil {
+		runCmd.Error = runErr.Error()
+	} else if len(panelErrors) > 0 {
+		s.log.Warn("Some panels of the scheduled dashboard snapshot failed", "uid", schedule.UID, "orgId", schedule.OrgID, "errors", len(panelErrors))
+		runCmd.Error = strings.Join(panelErrors, "; ")
+	}
+	if err := s.store.UpdateSnapshotScheduleRun(ctx, runCmd); err != nil {
+		s.log.Error("Failed to update dashboard snapshot schedule", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+	}
+
+	return snapshot, runErr
+}
+
+func (s *Service) createSnapshot(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule, now time.Time) (*dashboardsnapshots.DashboardSnapshot, []string, error) {
+	usr, err := s.getScheduleUser(ctx, schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	canRead, err := s.accessControl.Evaluate(ctx, usr, accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(schedule.DashboardUID)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canRead {
+		return nil, nil, fmt.Errorf("user %d is not allowed to read dashboard %s", schedule.UserID, schedule.DashboardUID)
+	}
+
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: schedule.DashboardUID, OrgID: schedule.OrgID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	model, panelErrors, err := buildSnapshotModel(ctx, s.queryService, usr, dashboard, schedule, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := schedule.Name
+	if name == "" {
+		name = dashboard.Title
+	}
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, nil, err
+	}
+	deleteKey, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshot, err := s.snapshotService.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Name:      fmt.Sprintf("%s - %s", name, now.UTC().Format(time.RFC3339)),
+			Dashboard: &common.Unstructured{Object: model},
+		},
+		Key:         key,
+		DeleteKey:   deleteKey,
+		OrgID:       schedule.OrgID,
+		UserID:      schedule.UserID,
+		ScheduleUID: schedule.UID,
+	})
+	return snapshot, panelErrors, err
+}
+
+// getScheduleUser returns the creator of the schedule, the queries of the dashboard run with their permissions
+func (s *Service) getScheduleUser(ctx context.Context, schedule *dashboardsnapshots.SnapshotSchedule) (*user.SignedInUser, error) {
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: schedule.UserID, OrgID: schedule.OrgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user of the schedule: %w", err)
+	}
+	if usr.IsDisabled {
+		return nil, fmt.Errorf("user %d of the schedule is disabled", schedule.UserID)
+	}
+
+	if usr.Permissions == nil {
+		usr.Permissions = make(map[int64]map[string][]string)
+	}
+	if _, ok := usr.Permissions[schedule.OrgID]; !ok {
+		permissions, err := s.acService.GetUserPermissions(ctx, usr, accesscontrol.Options{ReloadCache: false})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions of the user of the schedule: %w", err)
+		}
+		usr.Permissions[schedule.OrgID] = accesscontrol.GroupScopesByActionContext(ctx, permissions)
+	}
+
+	return usr, nil
+}
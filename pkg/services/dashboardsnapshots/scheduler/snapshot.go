@@ -0,0 +1,224 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+const (
+	mixedDatasourceUID   = "-- Mixed --"
+	defaultMaxDataPoints = 1000
+	snapshotQueryType    = "snapshot"
+)
+
+// timeRange returns the time range of the snapshot as epoch milliseconds, the schedule time range takes precedence
+// over the time range of the dashboard
+func timeRange(dashboard *dashboards.Dashboard, schedule *dashboardsnapshots.SnapshotSchedule, now time.Time) (time.Time, time.Time) {
+	from := dashboard.Data.GetPath("time", "from").MustString("now-6h")
+	to := dashboard.Data.GetPath("time", "to").MustString("now")
+	if schedule.TimeFrom != "" && schedule.TimeTo != "" {
+		from, to = schedule.TimeFrom, schedule.TimeTo
+	}
+
+	// if the timezone of the dashboard is blank or invalid default is UTC
+	location, err := time.LoadLocation(dashboard.Data.Get("timezone").MustString())
+	if err != nil {
+		location = time.UTC
+	}
+
+	tr := gtime.NewTimeRange(from, to)
+	tr.Now = now
+	timeFrom, err := tr.ParseFrom(gtime.WithLocation(location))
+	if err != nil {
+		timeFrom = now.Add(-6 * time.Hour)
+	}
+	timeTo, err := tr.ParseTo(gtime.WithLocation(location))
+	if err != nil {
+		timeTo = now
+	}
+	return timeFrom, timeTo
+}
+
+// buildSnapshotModel runs the queries of every panel of the dashboard and returns a copy of the dashboard model in
+// which the queries are replaced by their results, the same way snapshots created from the browser store the data.
+// Template variables are not interpolated, queries referencing them are sent to the datasources as they are.
+// Failing panels do not fail the snapshot, their errors are returned next to the model.
+func buildSnapshotModel(ctx context.Context, queryService query.Service, usr *user.SignedInUser, dashboard *dashboards.Dashboard, schedule *dashboardsnapshots.SnapshotSchedule, now time.Time) (map[string]any, []string, error) {
+	// work on a copy so the cached dashboard is not modified
+	raw, err := dashboard.Data.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	model, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from, to := timeRange(dashboard, schedule, now)
+	fromMs, toMs := strconv.FormatInt(from.UnixMilli(), 10), strconv.FormatInt(to.UnixMilli(), 10)
+
+	var panelErrors []string
+	for _, panel := range getFlattenedPanels(model) {
+		queries := panelQueries(panel)
+		if len(queries) == 0 {
+			continue
+		}
+
+		frames := []any{}
+		resp, err := queryService.QueryData(ctx, usr, false, dtos.MetricRequest{From: fromMs, To: toMs, Queries: queries})
+		if err != nil {
+			panelErrors = append(panelErrors, fmt.Sprintf("panel %d: %s", panel.Get("id").MustInt64(), err))
+		} else {
+			frames, err = responseFrames(resp)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, refID := range sortedRefIDs(resp) {
+				if dr := resp.Responses[refID]; dr.Error != nil {
+					panelErrors = append(panelErrors, fmt.Sprintf("panel %d, query %s: %s", panel.Get("id").MustInt64(), refID, dr.Error))
+				}
+			}
+		}
+
+		datasource := map[string]any{"type": "datasource", "uid": grafanads.DatasourceUID}
+		panel.Set("datasource", datasource)
+		panel.Set("targets", []any{map[string]any{
+			"refId":      "A",
+			"datasource": datasource,
+			"queryType":  snapshotQueryType,
+			"snapshot":   frames,
+		}})
+	}
+
+	model.Set("time", map[string]any{"from": from.UTC().Format(time.RFC3339Nano), "to": to.UTC().Format(time.RFC3339Nano)})
+	model.Set("snapshot", map[string]any{
+		"timestamp":   now.UTC().Format(time.RFC3339Nano),
+		"originalUrl": "/d/" + dashboard.UID,
+	})
+
+	return model.MustMap(), panelErrors, nil
+}
+
+// getFlattenedPanels returns the panels of the dashboard, including the panels of collapsed rows
+func getFlattenedPanels(model *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	for _, panelObj := range model.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("type").MustString() == "row" {
+			if panel.Get("collapsed").MustBool() {
+				panels = append(panels, getFlattenedPanels(panel)...)
+			}
+			continue
+		}
+		panels = append(panels, panel)
+	}
+	return panels
+}
+
+// panelQueries returns the queries of the panel to run, hidden queries are kept when the panel has an expression as
+// the expression could depend on them
+func panelQueries(panel *simplejson.Json) []*simplejson.Json {
+	targets := panel.Get("targets").MustArray()
+	panelDatasource := panel.Get("datasource").Interface()
+
+	hasExpression := false
+	for _, targetObj := range targets {
+		if getDataSourceUID(simplejson.NewFromAny(targetObj)) == "__expr__" {
+			hasExpression = true
+		}
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+	queries := make([]*simplejson.Json, 0, len(targets))
+	for _, targetObj := range targets {
+		target := simplejson.NewFromAny(targetObj)
+		if !hasExpression && target.Get("hide").MustBool() {
+			continue
+		}
+
+		// copy the query, the target is replaced by the snapshot data afterwards
+		q, err := target.MarshalJSON()
+		if err != nil {
+			continue
+		}
+		query, err := simplejson.NewJson(q)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := query.CheckGet("datasource"); !ok || getDataSourceUID(query) == "" {
+			if panelDatasource == nil || getDataSourceUID(panel) == mixedDatasourceUID {
+				continue
+			}
+			query.Set("datasource", panelDatasource)
+		}
+		if _, ok := query.CheckGet("maxDataPoints"); !ok {
+			query.Set("maxDataPoints", maxDataPoints)
+		}
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+func getDataSourceUID(query *simplejson.Json) string {
+	uid := query.Get("datasource").Get("uid").MustString()
+
+	// before 8.3 special types could be sent as datasource (expr)
+	if uid == "" {
+		uid = query.Get("datasource").MustString()
+	}
+
+	return uid
+}
+
+// responseFrames encodes the frames of the response the way the snapshot query type of the grafana datasource
+// expects them
+func responseFrames(resp *backend.QueryDataResponse) ([]any, error) {
+	frames := []any{}
+	for _, refID := range sortedRefIDs(resp) {
+		for _, frame := range resp.Responses[refID].Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			if frame.Meta != nil {
+				frame.Meta.ExecutedQueryString = ""
+			}
+
+			b, err := data.FrameToJSON(frame, data.IncludeAll)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode data frame: %w", err)
+			}
+			var encoded map[string]any
+			if err := json.Unmarshal(b, &encoded); err != nil {
+				return nil, err
+			}
+			frames = append(frames, encoded)
+		}
+	}
+	return frames, nil
+}
+
+func sortedRefIDs(resp *backend.QueryDataResponse) []string {
+	refIDs := make([]string, 0, len(resp.Responses))
+	for refID := range resp.Responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+	return refIDs
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const dashboardJSON = `{
+	"title": "Scheduled",
+	"time": {"from": "now-1h", "to": "now"},
+	"panels": [
+		{
+			"id": 1,
+			"type": "timeseries",
+			"datasource": {"type": "prometheus", "uid": "prom"},
+			"targets": [
+				{"refId": "A", "expr": "up"},
+				{"refId": "B", "expr": "down", "hide": true}
+			]
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"collapsed": true,
+			"panels": [
+				{
+					"id": 3,
+					"type": "stat",
+					"datasource": {"type": "prometheus", "uid": "prom"},
+					"targets": [{"refId": "A", "expr": "rate(x[5m])"}]
+				}
+			]
+		},
+		{"id": 4, "type": "text"}
+	]
+}`
+
+func TestBuildSnapshotModel(t *testing.T) {
+	dashboardData, err := simplejson.NewJson([]byte(dashboardJSON))
+	require.NoError(t, err)
+	dashboard := &dashboards.Dashboard{UID: "dash", OrgID: 1, Data: dashboardData}
+	schedule := &dashboardsnapshots.SnapshotSchedule{UID: "schedule", OrgID: 1, DashboardUID: "dash"}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	queryService := query.NewFakeQueryService(t)
+	var requests []dtos.MetricRequest
+	queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(func(_ context.Context, _ identity.Requester, _ bool, req dtos.MetricRequest) *backend.QueryDataResponse {
+		requests = append(requests, req)
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}},
+		}}
+	}, nil)
+
+	model, panelErrors, err := buildSnapshotModel(context.Background(), queryService, &user.SignedInUser{}, dashboard, schedule, now)
+	require.NoError(t, err)
+	require.Empty(t, panelErrors)
+
+	t.Run("Should run the visible queries of every panel", func(t *testing.T) {
+		require.Len(t, requests, 2)
+		assert.Equal(t, "1714561200000", requests[0].From)
+		assert.Equal(t, "1714564800000", requests[0].To)
+		require.Len(t, requests[0].Queries, 1)
+		assert.Equal(t, "prom", requests[0].Queries[0].Get("datasource").Get("uid").MustString())
+		assert.Equal(t, int64(defaultMaxDataPoints), requests[0].Queries[0].Get("maxDataPoints").MustInt64())
+		assert.Equal(t, "rate(x[5m])", requests[1].Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("Should replace the queries with the data", func(t *testing.T) {
+		snapshot := simplejson.NewFromAny(model)
+		for _, panel := range getFlattenedPanels(snapshot)[:2] {
+			target := panel.Get("targets").GetIndex(0)
+			assert.Equal(t, snapshotQueryType, target.Get("queryType").MustString())
+			assert.Len(t, target.Get("snapshot").MustArray(), 1)
+			assert.Equal(t, "A", target.Get("snapshot").GetIndex(0).GetPath("schema", "refId").MustString())
+		}
+		assert.Equal(t, "/d/dash", snapshot.GetPath("snapshot", "originalUrl").MustString())
+		assert.Equal(t, "2024-05-01T11:00:00Z", snapshot.GetPath("time", "from").MustString())
+	})
+
+	t.Run("Should not modify the dashboard", func(t *testing.T) {
+		assert.Equal(t, "up", dashboard.Data.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Get("expr").MustString())
+	})
+}
+
+func TestSnapshotScheduleNextRun(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	schedule := &dashboardsnapshots.SnapshotSchedule{Cron: "0 6 * * *", Created: created}
+
+	next, err := schedule.NextRun()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 2, 6, 0, 0, 0, time.UTC), next)
+
+	schedule.LastRun = next
+	next, err = schedule.NextRun()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 3, 6, 0, 0, 0, time.UTC), next)
+
+	schedule.Cron = "not a cron"
+	_, err = schedule.NextRun()
+	require.ErrorIs(t, err, dashboardsnapshots.ErrScheduleInvalidCron)
+}
@@ -11,3 +11,15 @@ type Store interface {
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
 }
+
+// ScheduleStore stores snapshot schedules
+type ScheduleStore interface {
+	CreateSnapshotSchedule(context.Context, *CreateSnapshotScheduleCommand) (*SnapshotSchedule, error)
+	UpdateSnapshotSchedule(context.Context, *UpdateSnapshotScheduleCommand) (*SnapshotSchedule, error)
+	DeleteSnapshotSchedule(context.Context, *DeleteSnapshotScheduleCommand) error
+	GetSnapshotSchedule(context.Context, *GetSnapshotScheduleQuery) (*SnapshotSchedule, error)
+	SearchSnapshotSchedules(context.Context, *SearchSnapshotSchedulesQuery) ([]*SnapshotSchedule, error)
+	GetEnabledSnapshotSchedules(context.Context) ([]*SnapshotSchedule, error)
+	UpdateSnapshotScheduleRun(context.Context, *UpdateSnapshotScheduleRunCommand) error
+	DeleteScheduledSnapshots(context.Context, *DeleteScheduledSnapshotsCommand) error
+}
@@ -1483,6 +1483,12 @@ var (
 			HideFromDocs:      true,
 			HideFromAdminPage: true,
 		},
+		{
+			Name:        "lokiBackendQuerySplitting",
+			Description: "Split long Loki range queries into time chunks in the backend, for alert rules and other server-side queries",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaObservabilityLogsSquad,
+		},
 	}
 )
 
@@ -794,4 +794,8 @@ const (
 	// FlagImprovedExternalSessionHandling
 	// Enable improved support for external sessions in Grafana
 	FlagImprovedExternalSessionHandling = "improvedExternalSessionHandling"
+
+	// FlagLokiBackendQuerySplitting
+	// Split long Loki range queries into time chunks in the backend, for alert rules and other server-side queries
+	FlagLokiBackendQuerySplitting = "lokiBackendQuerySplitting"
 )
@@ -33,6 +33,7 @@ var (
 
 	oneDatasourceWithTwoCorrelations   = "testdata/one-datasource-two-correlations"
 	correlationsDifferentOrganizations = "testdata/correlations-different-organizations"
+	externalCorrelation                = "testdata/external-correlation"
 )
 
 func TestDatasourceAsConfig(t *testing.T) {
@@ -367,6 +368,27 @@ func TestDatasourceAsConfig(t *testing.T) {
 			require.Equal(t, int64(2), correlationsStore.deletedBySourceUID[1].OrgId)
 			require.Equal(t, int64(3), correlationsStore.deletedBySourceUID[2].OrgId)
 		})
+
+		t.Run("Creates external correlation", func(t *testing.T) {
+			store := &spyStore{}
+			orgFake := &orgtest.FakeOrgService{}
+			correlationsStore := &mockCorrelationsStore{}
+			dc := newDatasourceProvisioner(logger, store, correlationsStore, orgFake)
+			err := dc.applyChanges(context.Background(), externalCorrelation)
+			if err != nil {
+				t.Fatalf("applyChanges return an error %v", err)
+			}
+
+			require.Equal(t, 1, len(correlationsStore.created))
+			created := correlationsStore.created[0]
+			require.Equal(t, correlations.TypeExternal, created.Type)
+			require.Nil(t, created.TargetUID)
+			require.Equal(t, "https://tickets.example.com/new?service=${service}", created.Config.ExternalURL())
+			require.Equal(t, correlations.Transformations{
+				{Type: correlations.TransformationJSON, Field: "line"},
+				{Type: correlations.TransformationLabels, Field: "labels"},
+			}, created.Config.Transformations)
+		})
 	})
 }
 
@@ -194,8 +194,14 @@ func (dc *DatasourceProvisioner) applyChanges(ctx context.Context, configPath st
 func makeCreateCorrelationCommand(correlation map[string]any, SourceUID string, OrgId int64) (correlations.CreateCorrelationCommand, error) {
 	// we look for a correlation type at the root if it is defined, if not use default
 	// we ignore the legacy config.type value - the only valid value at that version was "query"
-	var corrType = correlation["type"]
-	if corrType == nil || corrType == "" {
+	var corrType correlations.CorrelationType
+	switch t := correlation["type"].(type) {
+	case string:
+		corrType = correlations.CorrelationType(t)
+	case correlations.CorrelationType:
+		corrType = t
+	}
+	if corrType == "" {
 		corrType = correlations.TypeQuery
 	}
 
@@ -206,7 +212,7 @@ func makeCreateCorrelationCommand(correlation map[string]any, SourceUID string,
 		Description: correlation["description"].(string),
 		OrgId:       OrgId,
 		Provisioned: true,
-		Type:        corrType.(correlations.CorrelationType),
+		Type:        corrType,
 	}
 
 	targetUID, ok := correlation["targetUID"].(string)
@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -17,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/usage"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -32,6 +35,7 @@ type Api struct {
 	license       licensing.Licensing
 	log           log.Logger
 	routeRegister routing.RouteRegister
+	usage         *usage.Service
 }
 
 func ProvideApi(
@@ -42,6 +46,7 @@ func ProvideApi(
 	md publicdashboards.Middleware,
 	cfg *setting.Cfg,
 	license licensing.Licensing,
+	usageService *usage.Service,
 ) *Api {
 	api := &Api{
 		PublicDashboardService: pd,
@@ -52,6 +57,7 @@ func ProvideApi(
 		license:                license,
 		log:                    log.New("publicdashboards.api"),
 		routeRegister:          rr,
+		usage:                  usageService,
 	}
 
 	// register endpoints if the feature is enabled
@@ -89,6 +95,11 @@ func (api *Api) RegisterAPIEndpoints() {
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
 		routing.Wrap(api.GetPublicDashboard))
 
+	// Get usage of public dashboard
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/usage",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+		routing.Wrap(api.GetPublicDashboardUsage))
+
 	// Create Public Dashboard
 	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
@@ -103,6 +114,25 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// Rotate the access token of a public dashboard
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/rotate-token",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RotatePublicDashboardAccessToken))
+
+	// Additional access tokens of a public dashboard
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.ListPublicDashboardAccessTokens))
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.CreatePublicDashboardAccessToken))
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens/:tokenUid/rotate",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RotatePublicDashboardAdditionalAccessToken))
+	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens/:tokenUid",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RevokePublicDashboardAccessToken))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboard_public listPublicDashboards
@@ -169,6 +199,71 @@ func (api *Api) GetPublicDashboard(c *contextmodel.ReqContext) response.Response
 	return response.JSON(http.StatusOK, pd)
 }
 
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/usage dashboard_public getPublicDashboardUsage
+//
+//	Get the hourly views, queries, errors and rate limited queries of a public dashboard
+//
+// Responses:
+// 200: getPublicDashboardUsageResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetPublicDashboardUsage(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardUsage: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardUsage: invalid Uid %s", uid))
+	}
+
+	pd, err := api.PublicDashboardService.Find(c.Req.Context(), uid)
+	if err != nil {
+		return response.Err(err)
+	}
+	if pd == nil || pd.DashboardUid != dashboardUid || pd.OrgId != c.SignedInUser.GetOrgID() {
+		return response.Err(ErrPublicDashboardNotFound.Errorf("GetPublicDashboardUsage: public dashboard not found"))
+	}
+
+	timeRange := gtime.NewTimeRange(valueOrDefault(c.Query("from"), "now-7d"), valueOrDefault(c.Query("to"), "now"))
+	timeFrom, err := timeRange.ParseFrom()
+	if err != nil {
+		return response.Err(ErrInvalidTimeRange.Errorf("GetPublicDashboardUsage: invalid from: %w", err))
+	}
+	timeTo, err := timeRange.ParseTo()
+	if err != nil {
+		return response.Err(ErrInvalidTimeRange.Errorf("GetPublicDashboardUsage: invalid to: %w", err))
+	}
+
+	if api.usage == nil {
+		return response.JSON(http.StatusOK, &UsageResponse{Uid: uid, From: timeFrom.UnixMilli(), To: timeTo.UnixMilli(), Buckets: []*UsageBucket{}})
+	}
+
+	resp, err := api.usage.GetUsage(c.Req.Context(), UsageQuery{
+		OrgID:              pd.OrgId,
+		PublicDashboardUid: pd.Uid,
+		// include the bucket the start of the range falls in
+		From: timeFrom.Truncate(time.Hour),
+		To:   timeTo,
+	})
+	if err != nil {
+		return response.Err(ErrInternalServerError.Errorf("GetPublicDashboardUsage: failed to get usage: %w", err))
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+	return defaultValue
+}
+
 // swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards dashboard_public createPublicDashboard
 //
 //	Create public dashboard for a dashboard
@@ -331,6 +426,30 @@ type GetPublicDashboardResponse struct {
 	Body PublicDashboard `json:"body"`
 }
 
+// swagger:parameters getPublicDashboardUsage
+type GetPublicDashboardUsageParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// Start of the time range, defaults to now-7d
+	// in:query
+	// required:false
+	From string `json:"from"`
+	// End of the time range, defaults to now
+	// in:query
+	// required:false
+	To string `json:"to"`
+}
+
+// swagger:response getPublicDashboardUsageResponse
+type GetPublicDashboardUsageResponse struct {
+	// in: body
+	Body UsageResponse `json:"body"`
+}
+
 // swagger:parameters createPublicDashboard
 type CreatePublicDashboardParams struct {
 	// in:path
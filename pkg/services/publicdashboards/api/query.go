@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -50,6 +51,7 @@ func (api *Api) ViewPublicDashboard(c *contextmodel.ReqContext) response.Respons
 // 404: panelNotFoundPublicError
 // 404: notFoundPublicError
 // 403: forbiddenPublicError
+// 429: tooManyRequestsPublicError
 // 500: internalServerPublicError
 func (api *Api) QueryPublicDashboard(c *contextmodel.ReqContext) response.Response {
 	accessToken := web.Params(c.Req)[":accessToken"]
@@ -69,6 +71,12 @@ func (api *Api) QueryPublicDashboard(c *contextmodel.ReqContext) response.Respon
 
 	resp, err := api.PublicDashboardService.GetQueryDataResponse(c.Req.Context(), c.SkipDSCache, reqDTO, panelId, accessToken)
 	if err != nil {
+		switch {
+		case errors.Is(err, ErrQueryRateLimited):
+			return response.Err(err).SetHeader("Retry-After", "60")
+		case errors.Is(err, ErrTooManyConcurrentQueries):
+			return response.Err(err).SetHeader("Retry-After", "1")
+		}
 		return response.Err(err)
 	}
 
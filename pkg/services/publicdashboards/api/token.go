@@ -0,0 +1,230 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/rotate-token dashboard_public rotatePublicDashboardAccessToken
+//
+//	Replace the access token of a public dashboard, the previous access token stops working immediately
+//
+// Responses:
+// 200: rotatePublicDashboardAccessTokenResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RotatePublicDashboardAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid, uid, err := publicDashboardParams(c, "RotatePublicDashboardAccessToken")
+	if err != nil {
+		return response.Err(err)
+	}
+
+	pd, err := api.PublicDashboardService.RotatePublicDashboardAccessToken(c.Req.Context(), c.SignedInUser, dashboardUid, uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, pd)
+}
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens dashboard_public listPublicDashboardAccessTokens
+//
+//	Get the additional access tokens of a public dashboard
+//
+// Responses:
+// 200: listPublicDashboardAccessTokensResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) ListPublicDashboardAccessTokens(c *contextmodel.ReqContext) response.Response {
+	dashboardUid, uid, err := publicDashboardParams(c, "ListPublicDashboardAccessTokens")
+	if err != nil {
+		return response.Err(err)
+	}
+
+	tokens, err := api.PublicDashboardService.FindAccessTokens(c.Req.Context(), c.SignedInUser.GetOrgID(), dashboardUid, uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens dashboard_public createPublicDashboardAccessToken
+//
+//	Create an additional access token for a public dashboard, optionally with a label and an expiration
+//
+// Produces:
+// - application/json
+//
+// Responses:
+// 200: createPublicDashboardAccessTokenResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) CreatePublicDashboardAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid, uid, err := publicDashboardParams(c, "CreatePublicDashboardAccessToken")
+	if err != nil {
+		return response.Err(err)
+	}
+
+	tokenDTO := &PublicDashboardAccessTokenDTO{}
+	if err := web.Bind(c.Req, tokenDTO); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreatePublicDashboardAccessToken: bad request data %v", err))
+	}
+
+	// Always set the orgID and userID from the session
+	dto := &SavePublicDashboardAccessTokenDTO{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		UserId:       c.UserID,
+		DashboardUid: dashboardUid,
+		Uid:          uid,
+		AccessToken:  tokenDTO,
+	}
+
+	token, err := api.PublicDashboardService.CreateAccessToken(c.Req.Context(), c.SignedInUser, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, token)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens/{tokenUid}/rotate dashboard_public rotatePublicDashboardAdditionalAccessToken
+//
+//	Revoke an additional access token of a public dashboard and replace it with a new one with the same label and expiration
+//
+// Responses:
+// 200: createPublicDashboardAccessTokenResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RotatePublicDashboardAdditionalAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid, uid, err := publicDashboardParams(c, "RotatePublicDashboardAdditionalAccessToken")
+	if err != nil {
+		return response.Err(err)
+	}
+
+	tokenUid := web.Params(c.Req)[":tokenUid"]
+	if !validation.IsValidShortUID(tokenUid) {
+		return response.Err(ErrInvalidUid.Errorf("RotatePublicDashboardAdditionalAccessToken: invalid token Uid %s", tokenUid))
+	}
+
+	token, err := api.PublicDashboardService.RotateAccessToken(c.Req.Context(), c.SignedInUser, dashboardUid, uid, tokenUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, token)
+}
+
+// swagger:route DELETE /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens/{tokenUid} dashboard_public revokePublicDashboardAccessToken
+//
+//	Revoke an additional access token of a public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RevokePublicDashboardAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid, uid, err := publicDashboardParams(c, "RevokePublicDashboardAccessToken")
+	if err != nil {
+		return response.Err(err)
+	}
+
+	tokenUid := web.Params(c.Req)[":tokenUid"]
+	if !validation.IsValidShortUID(tokenUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardAccessToken: invalid token Uid %s", tokenUid))
+	}
+
+	if err := api.PublicDashboardService.RevokeAccessToken(c.Req.Context(), c.SignedInUser, dashboardUid, uid, tokenUid); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Empty(http.StatusOK)
+}
+
+func publicDashboardParams(c *contextmodel.ReqContext, caller string) (string, string, error) {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return "", "", ErrInvalidUid.Errorf("%s: invalid dashboard Uid %s", caller, dashboardUid)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return "", "", ErrInvalidUid.Errorf("%s: invalid Uid %s", caller, uid)
+	}
+
+	return dashboardUid, uid, nil
+}
+
+// swagger:parameters rotatePublicDashboardAccessToken listPublicDashboardAccessTokens
+type PublicDashboardAccessTokensParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response rotatePublicDashboardAccessTokenResponse
+type RotatePublicDashboardAccessTokenResponse struct {
+	// in: body
+	Body PublicDashboard `json:"body"`
+}
+
+// swagger:response listPublicDashboardAccessTokensResponse
+type ListPublicDashboardAccessTokensResponse struct {
+	// in: body
+	Body []PublicDashboardAccessToken `json:"body"`
+}
+
+// swagger:parameters createPublicDashboardAccessToken
+type CreatePublicDashboardAccessTokenParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	// required:true
+	Body PublicDashboardAccessTokenDTO
+}
+
+// swagger:response createPublicDashboardAccessTokenResponse
+type CreatePublicDashboardAccessTokenResponse struct {
+	// in: body
+	Body PublicDashboardAccessToken `json:"body"`
+}
+
+// swagger:parameters rotatePublicDashboardAdditionalAccessToken revokePublicDashboardAccessToken
+type PublicDashboardAccessTokenParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:path
+	// required:true
+	TokenUid string `json:"tokenUid"`
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -28,6 +29,13 @@ type PublicDashboardStoreImpl struct {
 
 var LogPrefix = "publicdashboards.store"
 
+const (
+	// activeAccessTokenCondition matches the additional access tokens which are not revoked nor expired
+	activeAccessTokenCondition = "revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)"
+	// accessTokenCondition matches the public dashboards of an access token, either their own or an active additional one
+	accessTokenCondition = "(access_token = ? OR uid IN (SELECT public_dashboard_uid FROM dashboard_public_token WHERE access_token = ? AND " + activeAccessTokenCondition + "))"
+)
+
 // Gives us a compile time error if our database does not adhere to contract of
 // the interface
 var _ publicdashboards.Store = (*PublicDashboardStoreImpl)(nil)
@@ -127,6 +135,21 @@ func (d *PublicDashboardStoreImpl) FindByAccessToken(ctx context.Context, access
 	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		found, err = sess.Get(publicDashboard)
+		if err != nil || found {
+			return err
+		}
+
+		// the access token can also be an active additional token of the public dashboard
+		token := &PublicDashboardAccessToken{}
+		hasToken, err := sess.Where("access_token = ? AND "+activeAccessTokenCondition, accessToken, time.Now()).Get(token)
+		if err != nil || !hasToken {
+			return err
+		}
+
+		publicDashboard = &PublicDashboard{Uid: token.PublicDashboardUid}
+		found, err = sess.Get(publicDashboard)
+		// report the token used to access the public dashboard, not the one of the public dashboard
+		publicDashboard.AccessToken = accessToken
 		return err
 	})
 
@@ -191,9 +214,9 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByDashboardUid(ctx context.Conte
 func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	hasPublicDashboard := false
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT COUNT(*) FROM dashboard_public WHERE access_token=? AND is_enabled=true"
+		sql := "SELECT COUNT(*) FROM dashboard_public WHERE " + accessTokenCondition + " AND is_enabled=true"
 
-		result, err := dbSession.SQL(sql, accessToken).Count()
+		result, err := dbSession.SQL(sql, accessToken, accessToken, time.Now()).Count()
 		if err != nil {
 			return err
 		}
@@ -209,9 +232,9 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Contex
 func (d *PublicDashboardStoreImpl) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
 	var orgId int64
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT org_id FROM dashboard_public WHERE access_token=?"
+		sql := "SELECT org_id FROM dashboard_public WHERE " + accessTokenCondition
 
-		_, err := dbSession.SQL(sql, accessToken).Get(&orgId)
+		_, err := dbSession.SQL(sql, accessToken, accessToken, time.Now()).Get(&orgId)
 		if err != nil {
 			return err
 		}
@@ -247,12 +270,14 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, query_rate_limit = ?, max_concurrent_queries = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			cmd.PublicDashboard.QueryRateLimit,
+			cmd.PublicDashboard.MaxConcurrentQueries,
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
@@ -276,7 +301,16 @@ func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int6
 	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM dashboard_public_usage WHERE public_dashboard_uid = ?", uid)
+		if err != nil {
+			return err
+		}
 
+		_, err = sess.Exec("DELETE FROM dashboard_public_token WHERE public_dashboard_uid = ?", uid)
 		return err
 	})
 
@@ -905,3 +905,204 @@ func insertPublicDashboard(t *testing.T, publicdashboardStore *PublicDashboardSt
 
 	return pubdash
 }
+
+func TestIntegrationUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+	ctx := context.Background()
+
+	hour := time.Now().UTC().Truncate(time.Hour)
+	previousHour := hour.Add(-time.Hour)
+
+	err := publicdashboardStore.AddUsage(ctx, []*UsageBucket{
+		{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: hour.Unix(), Views: 1, Queries: 3},
+		{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: previousHour.Unix(), Views: 2},
+		{OrgId: 1, PublicDashboardUid: "other", Bucket: hour.Unix(), Views: 5},
+	})
+	require.NoError(t, err)
+
+	t.Run("adds the counters to existing buckets", func(t *testing.T) {
+		err := publicdashboardStore.AddUsage(ctx, []*UsageBucket{
+			{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: hour.Unix(), Queries: 2, Errors: 1, RateLimited: 4},
+		})
+		require.NoError(t, err)
+
+		buckets, err := publicdashboardStore.GetUsage(ctx, UsageQuery{OrgID: 1, PublicDashboardUid: "pubdash", From: previousHour, To: hour})
+		require.NoError(t, err)
+		require.Len(t, buckets, 2)
+		assert.Equal(t, previousHour.Unix(), buckets[0].Bucket)
+		assert.Equal(t, int64(2), buckets[0].Views)
+		assert.Equal(t, int64(1), buckets[1].Views)
+		assert.Equal(t, int64(5), buckets[1].Queries)
+		assert.Equal(t, int64(1), buckets[1].Errors)
+		assert.Equal(t, int64(4), buckets[1].RateLimited)
+	})
+
+	t.Run("only returns the buckets of the org", func(t *testing.T) {
+		buckets, err := publicdashboardStore.GetUsage(ctx, UsageQuery{OrgID: 2, PublicDashboardUid: "pubdash", From: previousHour, To: hour})
+		require.NoError(t, err)
+		require.Len(t, buckets, 0)
+	})
+
+	t.Run("deletes old buckets", func(t *testing.T) {
+		deleted, err := publicdashboardStore.DeleteUsageBefore(ctx, hour)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		buckets, err := publicdashboardStore.GetUsage(ctx, UsageQuery{OrgID: 1, PublicDashboardUid: "pubdash", From: previousHour, To: hour})
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+	})
+}
+
+func TestIntegrationAccessTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestReplDBWithCfg(t)
+	quotaService := quotatest.New(false, nil)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore.DB()), quotaService)
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore.DB(), cfg, featuremgmt.WithFeatures())
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+	pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, PublicShareType)
+	ctx := context.Background()
+
+	insertToken := func(t *testing.T, expiresAt *time.Time) *PublicDashboardAccessToken {
+		accessToken, err := service.GenerateAccessToken()
+		require.NoError(t, err)
+		token := &PublicDashboardAccessToken{
+			Uid:                util.GenerateShortUID(),
+			OrgId:              pubdash.OrgId,
+			PublicDashboardUid: pubdash.Uid,
+			AccessToken:        accessToken,
+			Label:              "partner",
+			ExpiresAt:          expiresAt,
+			CreatedBy:          1,
+			CreatedAt:          time.Now(),
+		}
+		require.NoError(t, publicdashboardStore.CreateAccessToken(ctx, token))
+		return token
+	}
+
+	t.Run("finds the public dashboard by an active token", func(t *testing.T) {
+		token := insertToken(t, nil)
+
+		found, err := publicdashboardStore.FindByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, pubdash.Uid, found.Uid)
+		assert.Equal(t, token.AccessToken, found.AccessToken)
+
+		exists, err := publicdashboardStore.ExistsEnabledByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		orgId, err := publicdashboardStore.GetOrgIdByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, pubdash.OrgId, orgId)
+	})
+
+	t.Run("does not find the public dashboard by an expired token", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		token := insertToken(t, &expiresAt)
+
+		found, err := publicdashboardStore.FindByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		exists, err := publicdashboardStore.ExistsEnabledByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("does not find the public dashboard by a revoked token", func(t *testing.T) {
+		token := insertToken(t, nil)
+
+		affectedRows, err := publicdashboardStore.RevokeAccessToken(ctx, token.OrgId, token.Uid, 2, time.Now())
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		affectedRows, err = publicdashboardStore.RevokeAccessToken(ctx, token.OrgId, token.Uid, 2, time.Now())
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, affectedRows)
+
+		found, err := publicdashboardStore.FindByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		revoked, err := publicdashboardStore.FindAccessToken(ctx, token.OrgId, token.Uid)
+		require.NoError(t, err)
+		require.NotNil(t, revoked)
+		assert.Equal(t, int64(2), revoked.RevokedBy)
+		assert.Equal(t, AccessTokenRevoked, revoked.GetStatus(time.Now()))
+	})
+
+	t.Run("replacing a token revokes it and creates its replacement", func(t *testing.T) {
+		token := insertToken(t, nil)
+		accessToken, err := service.GenerateAccessToken()
+		require.NoError(t, err)
+		replacement := &PublicDashboardAccessToken{Uid: util.GenerateShortUID(), OrgId: pubdash.OrgId, PublicDashboardUid: pubdash.Uid, AccessToken: accessToken, CreatedBy: 2, CreatedAt: time.Now()}
+
+		affectedRows, err := publicdashboardStore.ReplaceAccessToken(ctx, token.OrgId, token.Uid, 2, time.Now(), replacement)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		found, err := publicdashboardStore.FindByAccessToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = publicdashboardStore.FindByAccessToken(ctx, replacement.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, pubdash.Uid, found.Uid)
+	})
+
+	t.Run("replacing a revoked token does not create its replacement", func(t *testing.T) {
+		token := insertToken(t, nil)
+		_, err := publicdashboardStore.RevokeAccessToken(ctx, token.OrgId, token.Uid, 2, time.Now())
+		require.NoError(t, err)
+
+		accessToken, err := service.GenerateAccessToken()
+		require.NoError(t, err)
+		replacement := &PublicDashboardAccessToken{Uid: util.GenerateShortUID(), OrgId: pubdash.OrgId, PublicDashboardUid: pubdash.Uid, AccessToken: accessToken, CreatedBy: 2, CreatedAt: time.Now()}
+
+		affectedRows, err := publicdashboardStore.ReplaceAccessToken(ctx, token.OrgId, token.Uid, 2, time.Now(), replacement)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, affectedRows)
+
+		notFound, err := publicdashboardStore.FindAccessToken(ctx, replacement.OrgId, replacement.Uid)
+		require.NoError(t, err)
+		assert.Nil(t, notFound)
+	})
+
+	t.Run("rotating the access token of the public dashboard invalidates the previous one", func(t *testing.T) {
+		newAccessToken, err := service.GenerateAccessToken()
+		require.NoError(t, err)
+
+		affectedRows, err := publicdashboardStore.RotateAccessToken(ctx, pubdash.Uid, newAccessToken, 2, time.Now())
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		found, err := publicdashboardStore.FindByAccessToken(ctx, pubdash.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = publicdashboardStore.FindByAccessToken(ctx, newAccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, pubdash.Uid, found.Uid)
+	})
+
+	t.Run("deleting the public dashboard deletes its tokens", func(t *testing.T) {
+		_, err := publicdashboardStore.Delete(ctx, pubdash.Uid)
+		require.NoError(t, err)
+
+		tokens, err := publicdashboardStore.FindAccessTokens(ctx, pubdash.OrgId, pubdash.Uid)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
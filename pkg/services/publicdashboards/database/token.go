@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// CreateAccessToken adds an access token to a public dashboard
+func (d *PublicDashboardStoreImpl) CreateAccessToken(ctx context.Context, token *PublicDashboardAccessToken) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(token)
+		return err
+	})
+}
+
+// FindAccessTokens returns the additional access tokens of a public dashboard, including expired and revoked ones
+func (d *PublicDashboardStoreImpl) FindAccessTokens(ctx context.Context, orgId int64, publicDashboardUid string) ([]*PublicDashboardAccessToken, error) {
+	tokens := make([]*PublicDashboardAccessToken, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND public_dashboard_uid = ?", orgId, publicDashboardUid).Desc("created_at").Find(&tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// FindAccessToken returns an additional access token by uid or nil if not found
+func (d *PublicDashboardStoreImpl) FindAccessToken(ctx context.Context, orgId int64, uid string) (*PublicDashboardAccessToken, error) {
+	token := &PublicDashboardAccessToken{}
+	var found bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Where("org_id = ? AND uid = ?", orgId, uid).Get(token)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
+// RevokeAccessToken revokes an additional access token which is not revoked yet
+func (d *PublicDashboardStoreImpl) RevokeAccessToken(ctx context.Context, orgId int64, uid string, revokedBy int64, revokedAt time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE dashboard_public_token SET revoked_by = ?, revoked_at = ? WHERE org_id = ? AND uid = ? AND revoked_at IS NULL",
+			revokedBy, revokedAt, orgId, uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = res.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// ReplaceAccessToken revokes an additional access token which is not revoked yet and creates its replacement in the
+// same transaction, the replacement is only created when the token was revoked
+func (d *PublicDashboardStoreImpl) ReplaceAccessToken(ctx context.Context, orgId int64, uid string, revokedBy int64, revokedAt time.Time, token *PublicDashboardAccessToken) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE dashboard_public_token SET revoked_by = ?, revoked_at = ? WHERE org_id = ? AND uid = ? AND revoked_at IS NULL",
+			revokedBy, revokedAt, orgId, uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = res.RowsAffected()
+		if err != nil || affectedRows == 0 {
+			return err
+		}
+
+		_, err = sess.Insert(token)
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// RotateAccessToken replaces the access token of a public dashboard
+func (d *PublicDashboardStoreImpl) RotateAccessToken(ctx context.Context, uid string, accessToken string, updatedBy int64, updatedAt time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE dashboard_public SET access_token = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+			accessToken, updatedBy, updatedAt.UTC().Format("2006-01-02 15:04:05"), uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = res.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// AddUsage adds the counters of the buckets to the stored buckets, creating the missing ones
+func (d *PublicDashboardStoreImpl) AddUsage(ctx context.Context, buckets []*UsageBucket) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, b := range buckets {
+			res, err := sess.Exec("UPDATE dashboard_public_usage SET views = views + ?, queries = queries + ?, errors = errors + ?, rate_limited = rate_limited + ? WHERE public_dashboard_uid = ? AND bucket = ?",
+				b.Views, b.Queries, b.Errors, b.RateLimited, b.PublicDashboardUid, b.Bucket)
+			if err != nil {
+				return err
+			}
+
+			updated, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if updated > 0 {
+				continue
+			}
+
+			bucket := *b
+			bucket.ID = 0
+			if _, err := sess.Insert(&bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetUsage returns the buckets of the public dashboard in the time range ordered by time
+func (d *PublicDashboardStoreImpl) GetUsage(ctx context.Context, query UsageQuery) ([]*UsageBucket, error) {
+	buckets := make([]*UsageBucket, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND public_dashboard_uid = ? AND bucket >= ? AND bucket <= ?",
+			query.OrgID, query.PublicDashboardUid, query.From.Unix(), query.To.Unix()).
+			Asc("bucket").
+			Find(&buckets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// DeleteUsageBefore deletes the buckets older than the given time
+func (d *PublicDashboardStoreImpl) DeleteUsageBefore(ctx context.Context, before time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM dashboard_public_usage WHERE bucket < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		affectedRows, err = res.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
@@ -24,5 +24,12 @@ var (
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Dashboard Access Token already exists"))
 
+	ErrQueryRateLimited          = errutil.TooManyRequests("publicdashboards.queryRateLimited", errutil.WithPublicMessage("Too many queries for this dashboard, try again later"))
+	ErrTooManyConcurrentQueries  = errutil.TooManyRequests("publicdashboards.tooManyConcurrentQueries", errutil.WithPublicMessage("Too many queries running for this dashboard, try again later"))
+	ErrInvalidQueryRateLimit     = errutil.BadRequest("publicdashboards.invalidQueryRateLimit", errutil.WithPublicMessage("queryRateLimit and maxConcurrentQueries must not be negative, except -1 for unlimited"))
+	ErrInvalidAccessTokenLabel   = errutil.BadRequest("publicdashboards.invalidAccessTokenLabel", errutil.WithPublicMessage("Access token label is too long"))
+	ErrInvalidAccessTokenExpiry  = errutil.BadRequest("publicdashboards.invalidAccessTokenExpiry", errutil.WithPublicMessage("Access token expiration must be in the future"))
+	ErrAccessTokenNotFound       = errutil.NotFound("publicdashboards.accessTokenNotFound", errutil.WithPublicMessage("Access token not found"))
+	ErrAccessTokenRevoked        = errutil.BadRequest("publicdashboards.accessTokenRevoked", errutil.WithPublicMessage("Access token is already revoked"))
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Dashboard paused"))
 )
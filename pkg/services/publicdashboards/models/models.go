@@ -54,8 +54,17 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// QueryRateLimit is the number of queries per minute allowed for the access token, 0 uses the instance default
+	// and UnlimitedQueries removes the limit
+	QueryRateLimit int `json:"queryRateLimit" xorm:"query_rate_limit"`
+	// MaxConcurrentQueries is the number of queries allowed to run at the same time, 0 uses the instance default
+	// and UnlimitedQueries removes the limit
+	MaxConcurrentQueries int `json:"maxConcurrentQueries" xorm:"max_concurrent_queries"`
 }
 
+// UnlimitedQueries is the query limit of public dashboards without a limit, regardless of the instance default
+const UnlimitedQueries = -1
+
 type PublicDashboardDTO struct {
 	Uid                  string    `json:"uid"`
 	AccessToken          string    `json:"accessToken"`
@@ -63,6 +72,8 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	QueryRateLimit       *int      `json:"queryRateLimit"`
+	MaxConcurrentQueries *int      `json:"maxConcurrentQueries"`
 }
 
 type EmailDTO struct {
@@ -161,6 +172,126 @@ type AnnotationsQueryDTO struct {
 	To   int64
 }
 
+const (
+	AccessTokenActive  = "active"
+	AccessTokenExpired = "expired"
+	AccessTokenRevoked = "revoked"
+
+	MaxAccessTokenLabelLength = 255
+)
+
+// PublicDashboardAccessToken is an additional access token of a public dashboard. Unlike the access token of the
+// public dashboard itself, these tokens can expire and be revoked one by one. The access token of the public dashboard
+// never expires, it stays valid until it is rotated, the public dashboard is paused or deleted.
+type PublicDashboardAccessToken struct {
+	Uid                string     `json:"uid" xorm:"pk uid"`
+	OrgId              int64      `json:"-" xorm:"org_id"`
+	PublicDashboardUid string     `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	AccessToken        string     `json:"accessToken" xorm:"access_token"`
+	Label              string     `json:"label" xorm:"label"`
+	ExpiresAt          *time.Time `json:"expiresAt,omitempty" xorm:"expires_at"`
+	CreatedBy          int64      `json:"createdBy" xorm:"created_by"`
+	CreatedAt          time.Time  `json:"createdAt" xorm:"created_at"`
+	RevokedBy          int64      `json:"revokedBy,omitempty" xorm:"revoked_by"`
+	RevokedAt          *time.Time `json:"revokedAt,omitempty" xorm:"revoked_at"`
+	// Status is one of active, expired and revoked
+	Status string `json:"status" xorm:"-"`
+}
+
+func (t PublicDashboardAccessToken) TableName() string {
+	return "dashboard_public_token"
+}
+
+// GetStatus returns whether the token is active, expired or revoked at the given time
+func (t *PublicDashboardAccessToken) GetStatus(now time.Time) string {
+	switch {
+	case t.RevokedAt != nil:
+		return AccessTokenRevoked
+	case t.ExpiresAt != nil && !t.ExpiresAt.After(now):
+		return AccessTokenExpired
+	default:
+		return AccessTokenActive
+	}
+}
+
+type PublicDashboardAccessTokenDTO struct {
+	// Label describing who the token was shared with
+	Label string `json:"label"`
+	// When the token stops being valid, never if empty
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// DTO for transforming user input in the api
+type SavePublicDashboardAccessTokenDTO struct {
+	OrgID        int64
+	UserId       int64
+	DashboardUid string
+	Uid          string
+	AccessToken  *PublicDashboardAccessTokenDTO
+}
+
+type UsageKind int
+
+const (
+	UsageView UsageKind = iota
+	UsageQuery
+	UsageError
+	UsageRateLimited
+)
+
+// UsageBucket counts the requests to a public dashboard during one hour
+type UsageBucket struct {
+	ID                 int64  `json:"-" xorm:"pk autoincr 'id'"`
+	OrgId              int64  `json:"-" xorm:"org_id"`
+	PublicDashboardUid string `json:"-" xorm:"public_dashboard_uid"`
+	// Start of the bucket in unix seconds
+	Bucket      int64 `json:"time" xorm:"bucket"`
+	Views       int64 `json:"views" xorm:"views"`
+	Queries     int64 `json:"queries" xorm:"queries"`
+	Errors      int64 `json:"errors" xorm:"errors"`
+	RateLimited int64 `json:"rateLimited" xorm:"rate_limited"`
+}
+
+func (b UsageBucket) TableName() string {
+	return "dashboard_public_usage"
+}
+
+// Add increments the counter of the kind
+func (b *UsageBucket) Add(kind UsageKind, n int64) {
+	switch kind {
+	case UsageView:
+		b.Views += n
+	case UsageQuery:
+		b.Queries += n
+	case UsageError:
+		b.Errors += n
+	case UsageRateLimited:
+		b.RateLimited += n
+	}
+}
+
+type UsageQuery struct {
+	OrgID              int64
+	PublicDashboardUid string
+	From               time.Time
+	To                 time.Time
+}
+
+type UsageTotals struct {
+	Views       int64 `json:"views"`
+	Queries     int64 `json:"queries"`
+	Errors      int64 `json:"errors"`
+	RateLimited int64 `json:"rateLimited"`
+}
+
+type UsageResponse struct {
+	Uid     string         `json:"uid"`
+	From    int64          `json:"from"`
+	To      int64          `json:"to"`
+	Totals  UsageTotals    `json:"totals"`
+	Buckets []*UsageBucket `json:"buckets"`
+}
+
 //
 // COMMANDS
 //
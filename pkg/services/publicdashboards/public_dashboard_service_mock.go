@@ -49,6 +49,32 @@ func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// CreateAccessToken provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) CreateAccessToken(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardAccessTokenDTO) (*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	var r0 *models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SavePublicDashboardAccessTokenDTO) (*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, u, dto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SavePublicDashboardAccessTokenDTO) *models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.SavePublicDashboardAccessTokenDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid, dashboardUid
 func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string, dashboardUid string) error {
 	ret := _m.Called(ctx, uid, dashboardUid)
@@ -151,6 +177,32 @@ func (_m *FakePublicDashboardService) Find(ctx context.Context, uid string) (*mo
 	return r0, r1
 }
 
+// FindAccessTokens provides a mock function with given fields: ctx, orgId, dashboardUid, uid
+func (_m *FakePublicDashboardService) FindAccessTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, orgId, dashboardUid, uid)
+
+	var r0 []*models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) ([]*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, orgId, dashboardUid, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) []*models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, orgId, dashboardUid, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = rf(ctx, orgId, dashboardUid, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardService) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -499,6 +551,72 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RevokeAccessToken provides a mock function with given fields: ctx, u, dashboardUid, uid, tokenUid
+func (_m *FakePublicDashboardService) RevokeAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string, tokenUid string) error {
+	ret := _m.Called(ctx, u, dashboardUid, uid, tokenUid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, string) error); ok {
+		r0 = rf(ctx, u, dashboardUid, uid, tokenUid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateAccessToken provides a mock function with given fields: ctx, u, dashboardUid, uid, tokenUid
+func (_m *FakePublicDashboardService) RotateAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string, tokenUid string) (*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, u, dashboardUid, uid, tokenUid)
+
+	var r0 *models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, string) (*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, u, dashboardUid, uid, tokenUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, string) *models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, u, dashboardUid, uid, tokenUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string, string) error); ok {
+		r1 = rf(ctx, u, dashboardUid, uid, tokenUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotatePublicDashboardAccessToken provides a mock function with given fields: ctx, u, dashboardUid, uid
+func (_m *FakePublicDashboardService) RotatePublicDashboardAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string) (*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, u, dashboardUid, uid)
+
+	var r0 *models.PublicDashboard
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) (*models.PublicDashboard, error)); ok {
+		return rf(ctx, u, dashboardUid, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) *models.PublicDashboard); ok {
+		r0 = rf(ctx, u, dashboardUid, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboard)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string) error); ok {
+		r1 = rf(ctx, u, dashboardUid, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
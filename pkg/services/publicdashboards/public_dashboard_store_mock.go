@@ -7,6 +7,8 @@ import (
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // FakePublicDashboardStore is an autogenerated mock type for the Store type
@@ -14,6 +16,20 @@ type FakePublicDashboardStore struct {
 	mock.Mock
 }
 
+// AddUsage provides a mock function with given fields: ctx, buckets
+func (_m *FakePublicDashboardStore) AddUsage(ctx context.Context, buckets []*models.UsageBucket) error {
+	ret := _m.Called(ctx, buckets)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.UsageBucket) error); ok {
+		r0 = rf(ctx, buckets)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, cmd
 func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SavePublicDashboardCommand) (int64, error) {
 	ret := _m.Called(ctx, cmd)
@@ -38,6 +54,20 @@ func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// CreateAccessToken provides a mock function with given fields: ctx, token
+func (_m *FakePublicDashboardStore) CreateAccessToken(ctx context.Context, token *models.PublicDashboardAccessToken) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardAccessToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int64, error) {
 	ret := _m.Called(ctx, uid)
@@ -62,6 +92,30 @@ func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int
 	return r0, r1
 }
 
+// DeleteUsageBefore provides a mock function with given fields: ctx, before
+func (_m *FakePublicDashboardStore) DeleteUsageBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -136,6 +190,58 @@ func (_m *FakePublicDashboardStore) Find(ctx context.Context, uid string) (*mode
 	return r0, r1
 }
 
+// FindAccessToken provides a mock function with given fields: ctx, orgId, uid
+func (_m *FakePublicDashboardStore) FindAccessToken(ctx context.Context, orgId int64, uid string) (*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, orgId, uid)
+
+	var r0 *models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, orgId, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, orgId, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindAccessTokens provides a mock function with given fields: ctx, orgId, publicDashboardUid
+func (_m *FakePublicDashboardStore) FindAccessTokens(ctx context.Context, orgId int64, publicDashboardUid string) ([]*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, orgId, publicDashboardUid)
+
+	var r0 []*models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) ([]*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, orgId, publicDashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []*models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, orgId, publicDashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, publicDashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardStore) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -290,6 +396,104 @@ func (_m *FakePublicDashboardStore) GetOrgIdByAccessToken(ctx context.Context, a
 	return r0, r1
 }
 
+// GetUsage provides a mock function with given fields: ctx, query
+func (_m *FakePublicDashboardStore) GetUsage(ctx context.Context, query models.UsageQuery) ([]*models.UsageBucket, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []*models.UsageBucket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UsageQuery) ([]*models.UsageBucket, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.UsageQuery) []*models.UsageBucket); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.UsageBucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.UsageQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceAccessToken provides a mock function with given fields: ctx, orgId, uid, revokedBy, revokedAt, token
+func (_m *FakePublicDashboardStore) ReplaceAccessToken(ctx context.Context, orgId int64, uid string, revokedBy int64, revokedAt time.Time, token *models.PublicDashboardAccessToken) (int64, error) {
+	ret := _m.Called(ctx, orgId, uid, revokedBy, revokedAt, token)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64, time.Time, *models.PublicDashboardAccessToken) (int64, error)); ok {
+		return rf(ctx, orgId, uid, revokedBy, revokedAt, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64, time.Time, *models.PublicDashboardAccessToken) int64); ok {
+		r0 = rf(ctx, orgId, uid, revokedBy, revokedAt, token)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int64, time.Time, *models.PublicDashboardAccessToken) error); ok {
+		r1 = rf(ctx, orgId, uid, revokedBy, revokedAt, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAccessToken provides a mock function with given fields: ctx, orgId, uid, revokedBy, revokedAt
+func (_m *FakePublicDashboardStore) RevokeAccessToken(ctx context.Context, orgId int64, uid string, revokedBy int64, revokedAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, orgId, uid, revokedBy, revokedAt)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64, time.Time) (int64, error)); ok {
+		return rf(ctx, orgId, uid, revokedBy, revokedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64, time.Time) int64); ok {
+		r0 = rf(ctx, orgId, uid, revokedBy, revokedAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int64, time.Time) error); ok {
+		r1 = rf(ctx, orgId, uid, revokedBy, revokedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotateAccessToken provides a mock function with given fields: ctx, uid, accessToken, updatedBy, updatedAt
+func (_m *FakePublicDashboardStore) RotateAccessToken(ctx context.Context, uid string, accessToken string, updatedBy int64, updatedAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, uid, accessToken, updatedBy, updatedAt)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, time.Time) (int64, error)); ok {
+		return rf(ctx, uid, accessToken, updatedBy, updatedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, time.Time) int64); ok {
+		r0 = rf(ctx, uid, accessToken, updatedBy, updatedAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, time.Time) error); ok {
+		r1 = rf(ctx, uid, accessToken, updatedBy, updatedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, cmd
 func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SavePublicDashboardCommand) (int64, error) {
 	ret := _m.Called(ctx, cmd)
@@ -2,6 +2,7 @@ package publicdashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -38,6 +39,12 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	FindAccessTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*PublicDashboardAccessToken, error)
+	CreateAccessToken(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardAccessTokenDTO) (*PublicDashboardAccessToken, error)
+	RevokeAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string, tokenUid string) error
+	RotateAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string, tokenUid string) (*PublicDashboardAccessToken, error)
+	RotatePublicDashboardAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string) (*PublicDashboard, error)
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,6 +70,17 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+
+	CreateAccessToken(ctx context.Context, token *PublicDashboardAccessToken) error
+	FindAccessTokens(ctx context.Context, orgId int64, publicDashboardUid string) ([]*PublicDashboardAccessToken, error)
+	FindAccessToken(ctx context.Context, orgId int64, uid string) (*PublicDashboardAccessToken, error)
+	RevokeAccessToken(ctx context.Context, orgId int64, uid string, revokedBy int64, revokedAt time.Time) (int64, error)
+	ReplaceAccessToken(ctx context.Context, orgId int64, uid string, revokedBy int64, revokedAt time.Time, token *PublicDashboardAccessToken) (int64, error)
+	RotateAccessToken(ctx context.Context, uid string, accessToken string, updatedBy int64, updatedAt time.Time) (int64, error)
+
+	AddUsage(ctx context.Context, buckets []*UsageBucket) error
+	GetUsage(ctx context.Context, query UsageQuery) ([]*UsageBucket, error)
+	DeleteUsageBefore(ctx context.Context, before time.Time) (int64, error)
 }
 
 //go:generate mockery --name Middleware --structname FakePublicDashboardMiddleware --inpackage --filename public_dashboard_middleware_mock.go
@@ -139,16 +139,33 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, models.ErrPanelQueriesNotFound.Errorf("GetQueryDataResponse: failed to extract queries from panel")
 	}
 
+	release, err := pd.limiter.acquire(publicDashboard)
+	if err != nil {
+		pd.usage.Record(publicDashboard.OrgId, publicDashboard.Uid, models.UsageRateLimited)
+		return nil, err
+	}
+	defer release()
+
+	pd.usage.Record(publicDashboard.OrgId, publicDashboard.Uid, models.UsageQuery)
+
 	anonymousUser := buildAnonymousUser(ctx, dashboard, pd.features)
 	res, err := pd.QueryDataService.QueryData(ctx, anonymousUser, skipDSCache, metricReq)
 
 	reqDatasources := metricReq.GetUniqueDatasourceTypes()
 	if err != nil {
+		pd.usage.Record(publicDashboard.OrgId, publicDashboard.Uid, models.UsageError)
 		LogQueryFailure(reqDatasources, pd.log, err)
 		return nil, err
 	}
 	LogQuerySuccess(reqDatasources, pd.log)
 
+	for _, dr := range res.Responses {
+		if dr.Error != nil {
+			pd.usage.Record(publicDashboard.OrgId, publicDashboard.Uid, models.UsageError)
+			break
+		}
+	}
+
 	sanitizeMetadataFromQueryData(res)
 
 	return res, nil
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// limiters not used for this long are removed
+const limiterIdleTimeout = 10 * time.Minute
+
+type tokenLimiter struct {
+	rateLimit     int
+	limiter       *rate.Limiter
+	maxConcurrent int
+	running       int
+	lastUsed      time.Time
+}
+
+// queryLimiter limits the rate and the concurrency of the queries of each public dashboard access token. The limits
+// are kept in memory, so they apply per Grafana instance.
+type queryLimiter struct {
+	cfg *setting.Cfg
+	now func() time.Time
+
+	mu          sync.Mutex
+	tokens      map[string]*tokenLimiter
+	lastCleanup time.Time
+}
+
+func newQueryLimiter(cfg *setting.Cfg) *queryLimiter {
+	return &queryLimiter{
+		cfg:    cfg,
+		now:    time.Now,
+		tokens: make(map[string]*tokenLimiter),
+	}
+}
+
+// acquire reserves a query for the public dashboard. The returned function must be called once the query is done.
+// It is safe to call on a nil limiter, in that case queries are not limited.
+func (l *queryLimiter) acquire(pubdash *PublicDashboard) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	rateLimit, maxConcurrent := l.limits(pubdash)
+	if rateLimit <= 0 && maxConcurrent <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	t, ok := l.tokens[pubdash.AccessToken]
+	if !ok {
+		t = &tokenLimiter{}
+		l.tokens[pubdash.AccessToken] = t
+	}
+	// the limits of the public dashboard can change at any time
+	if t.limiter == nil || t.rateLimit != rateLimit {
+		t.rateLimit = rateLimit
+		t.limiter = nil
+		if rateLimit > 0 {
+			// allow a full minute of queries at once, dashboards query all their panels when loaded
+			t.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(rateLimit)), rateLimit)
+		}
+	}
+	t.maxConcurrent = maxConcurrent
+	t.lastUsed = now
+
+	if t.maxConcurrent > 0 && t.running >= t.maxConcurrent {
+		return nil, ErrTooManyConcurrentQueries.Errorf("acquire: %d queries already running", t.running)
+	}
+	if t.limiter != nil && !t.limiter.AllowN(now, 1) {
+		return nil, ErrQueryRateLimited.Errorf("acquire: more than %d queries per minute", t.rateLimit)
+	}
+
+	t.running++
+	released := false
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !released && t.running > 0 {
+			t.running--
+			released = true
+		}
+	}, nil
+}
+
+// limits returns the limits of the public dashboard, falling back to the instance defaults. A limit of 0 means
+// unlimited.
+func (l *queryLimiter) limits(pubdash *PublicDashboard) (int, int) {
+	rateLimit, maxConcurrent := 0, 0
+	if l.cfg != nil {
+		rateLimit, maxConcurrent = l.cfg.PublicDashboardsQueryRateLimit, l.cfg.PublicDashboardsMaxConcurrentQueries
+	}
+	return queryLimit(pubdash.QueryRateLimit, rateLimit), queryLimit(pubdash.MaxConcurrentQueries, maxConcurrent)
+}
+
+func queryLimit(limit int, defaultLimit int) int {
+	switch limit {
+	case 0:
+		return defaultLimit
+	case UnlimitedQueries:
+		return 0
+	default:
+		return limit
+	}
+}
+
+func (l *queryLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < limiterIdleTimeout {
+		return
+	}
+	l.lastCleanup = now
+
+	for token, t := range l.tokens {
+		if t.running == 0 && now.Sub(t.lastUsed) > limiterIdleTimeout {
+			delete(l.tokens, token)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newLimiter := func(rateLimit, maxConcurrent int) *queryLimiter {
+		cfg := setting.NewCfg()
+		cfg.PublicDashboardsQueryRateLimit = rateLimit
+		cfg.PublicDashboardsMaxConcurrentQueries = maxConcurrent
+		l := newQueryLimiter(cfg)
+		l.now = func() time.Time { return now }
+		return l
+	}
+
+	t.Run("does not limit without limits", func(t *testing.T) {
+		l := newLimiter(0, 0)
+		pubdash := &PublicDashboard{AccessToken: "token"}
+		for i := 0; i < 100; i++ {
+			_, err := l.acquire(pubdash)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("a nil limiter does not limit", func(t *testing.T) {
+		var l *queryLimiter
+		release, err := l.acquire(&PublicDashboard{AccessToken: "token"})
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("limits the queries per minute of each access token", func(t *testing.T) {
+		l := newLimiter(2, 0)
+		pubdash := &PublicDashboard{AccessToken: "token"}
+		for i := 0; i < 2; i++ {
+			release, err := l.acquire(pubdash)
+			require.NoError(t, err)
+			release()
+		}
+
+		_, err := l.acquire(pubdash)
+		require.ErrorIs(t, err, ErrQueryRateLimited)
+
+		// other access tokens have their own limit
+		_, err = l.acquire(&PublicDashboard{AccessToken: "other"})
+		require.NoError(t, err)
+
+		now = now.Add(30 * time.Second)
+		_, err = l.acquire(pubdash)
+		require.NoError(t, err)
+	})
+
+	t.Run("the limit of the public dashboard overrides the default", func(t *testing.T) {
+		l := newLimiter(1, 0)
+		pubdash := &PublicDashboard{AccessToken: "token", QueryRateLimit: 3}
+		for i := 0; i < 3; i++ {
+			_, err := l.acquire(pubdash)
+			require.NoError(t, err)
+		}
+		_, err := l.acquire(pubdash)
+		require.ErrorIs(t, err, ErrQueryRateLimited)
+	})
+
+	t.Run("a public dashboard can be unlimited regardless of the default", func(t *testing.T) {
+		l := newLimiter(1, 1)
+		pubdash := &PublicDashboard{AccessToken: "token", QueryRateLimit: UnlimitedQueries, MaxConcurrentQueries: UnlimitedQueries}
+		for i := 0; i < 10; i++ {
+			_, err := l.acquire(pubdash)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("limits the concurrent queries of each access token", func(t *testing.T) {
+		l := newLimiter(0, 1)
+		pubdash := &PublicDashboard{AccessToken: "token"}
+
+		release, err := l.acquire(pubdash)
+		require.NoError(t, err)
+
+		_, err = l.acquire(pubdash)
+		require.ErrorIs(t, err, ErrTooManyConcurrentQueries)
+
+		release()
+		// releasing twice must not free another slot
+		release()
+
+		release, err = l.acquire(pubdash)
+		require.NoError(t, err)
+		_, err = l.acquire(pubdash)
+		require.ErrorIs(t, err, ErrTooManyConcurrentQueries)
+		release()
+	})
+
+	t.Run("removes idle access tokens", func(t *testing.T) {
+		l := newLimiter(5, 0)
+		_, err := l.acquire(&PublicDashboard{AccessToken: "token"})
+		require.NoError(t, err)
+		assert.Len(t, l.tokens, 1)
+
+		now = now.Add(2 * limiterIdleTimeout)
+		_, err = l.acquire(&PublicDashboard{AccessToken: "other"})
+		require.NoError(t, err)
+		assert.Len(t, l.tokens, 1)
+		assert.Contains(t, l.tokens, "other")
+	})
+}
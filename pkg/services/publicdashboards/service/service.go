@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/usage"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -43,6 +44,8 @@ type PublicDashboardServiceImpl struct {
 	serviceWrapper     publicdashboards.ServiceWrapper
 	dashboardService   dashboards.DashboardService
 	license            licensing.Licensing
+	usage              *usage.Service
+	limiter            *queryLimiter
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -64,6 +67,7 @@ func ProvideService(
 	serviceWrapper publicdashboards.ServiceWrapper,
 	dashboardService dashboards.DashboardService,
 	license licensing.Licensing,
+	usageService *usage.Service,
 ) *PublicDashboardServiceImpl {
 	return &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
@@ -77,6 +81,8 @@ func ProvideService(
 		serviceWrapper:     serviceWrapper,
 		dashboardService:   dashboardService,
 		license:            license,
+		usage:              usageService,
+		limiter:            newQueryLimiter(cfg),
 	}
 }
 
@@ -89,6 +95,8 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 		return nil, err
 	}
 
+	pd.usage.Record(pubdash.OrgId, pubdash.Uid, UsageView)
+
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.PublicDashboards).Inc()
 	meta := dtos.DashboardMeta{
 		Slug:                   dash.Slug,
@@ -558,6 +566,8 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            now,
 		AccessToken:          accessToken,
+		QueryRateLimit:       returnIntOrDefault(dto.PublicDashboard.QueryRateLimit, 0),
+		MaxConcurrentQueries: returnIntOrDefault(dto.PublicDashboard.MaxConcurrentQueries, 0),
 	}, nil
 }
 
@@ -581,6 +591,8 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		Share:                share,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
+		QueryRateLimit:       returnIntOrDefault(pubdashDTO.QueryRateLimit, pd.QueryRateLimit),
+		MaxConcurrentQueries: returnIntOrDefault(pubdashDTO.MaxConcurrentQueries, pd.MaxConcurrentQueries),
 	}
 }
 
@@ -591,3 +603,11 @@ func returnValueOrDefault(value *bool, defaultValue bool) bool {
 
 	return defaultValue
 }
+
+func returnIntOrDefault(value *int, defaultValue int) int {
+	if value != nil {
+		return *value
+	}
+
+	return defaultValue
+}
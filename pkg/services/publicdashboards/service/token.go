@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FindAccessTokens returns the additional access tokens of a public dashboard
+func (pd *PublicDashboardServiceImpl) FindAccessTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*PublicDashboardAccessToken, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.FindAccessTokens")
+	defer span.End()
+
+	pubdash, err := pd.findForDashboard(ctx, orgId, dashboardUid, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := pd.store.FindAccessTokens(ctx, orgId, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindAccessTokens: failed to find access tokens: %w", err)
+	}
+
+	now := time.Now()
+	for _, t := range tokens {
+		t.Status = t.GetStatus(now)
+	}
+
+	return tokens, nil
+}
+
+// CreateAccessToken adds an access token with an optional label and expiration to a public dashboard
+func (pd *PublicDashboardServiceImpl) CreateAccessToken(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardAccessTokenDTO) (*PublicDashboardAccessToken, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.CreateAccessToken")
+	defer span.End()
+
+	now := time.Now()
+	if err := validation.ValidateAccessToken(dto.AccessToken, now); err != nil {
+		return nil, err
+	}
+
+	pubdash, err := pd.findForDashboard(ctx, dto.OrgID, dto.DashboardUid, dto.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pd.newAccessToken(ctx, pubdash, dto.AccessToken.Label, dto.AccessToken.ExpiresAt, dto.UserId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	pd.log.Info("Public dashboard access token created", "publicDashboardUid", pubdash.Uid, "tokenUid", token.Uid, "label", token.Label, "user", u.Login)
+
+	return token, nil
+}
+
+// RevokeAccessToken revokes an additional access token of a public dashboard, the public dashboard can't be accessed
+// with it anymore
+func (pd *PublicDashboardServiceImpl) RevokeAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string, tokenUid string) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RevokeAccessToken")
+	defer span.End()
+
+	token, err := pd.findAccessToken(ctx, u.OrgID, dashboardUid, uid, tokenUid)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := pd.store.RevokeAccessToken(ctx, u.OrgID, token.Uid, u.UserID, time.Now())
+	if err != nil {
+		return ErrInternalServerError.Errorf("RevokeAccessToken: failed to revoke access token: %w", err)
+	}
+	if affectedRows == 0 {
+		return ErrAccessTokenRevoked.Errorf("RevokeAccessToken: access token %s is already revoked", tokenUid)
+	}
+
+	pd.log.Info("Public dashboard access token revoked", "publicDashboardUid", uid, "tokenUid", token.Uid, "label", token.Label, "user", u.Login)
+
+	return nil
+}
+
+// RotateAccessToken revokes an additional access token of a public dashboard and replaces it with a new token with
+// the same label and expiration, both in the same transaction
+func (pd *PublicDashboardServiceImpl) RotateAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string, tokenUid string) (*PublicDashboardAccessToken, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RotateAccessToken")
+	defer span.End()
+
+	token, err := pd.findAccessToken(ctx, u.OrgID, dashboardUid, uid, tokenUid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.GetStatus(now) != AccessTokenActive {
+		return nil, ErrAccessTokenRevoked.Errorf("RotateAccessToken: access token %s is not active", tokenUid)
+	}
+
+	pubdash := &PublicDashboard{Uid: token.PublicDashboardUid, OrgId: token.OrgId}
+	newToken, err := pd.buildAccessToken(ctx, pubdash, token.Label, token.ExpiresAt, u.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	affectedRows, err := pd.store.ReplaceAccessToken(ctx, u.OrgID, token.Uid, u.UserID, now, newToken)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("RotateAccessToken: failed to replace access token: %w", err)
+	}
+	if affectedRows == 0 {
+		return nil, ErrAccessTokenRevoked.Errorf("RotateAccessToken: access token %s is already revoked", tokenUid)
+	}
+	newToken.Status = newToken.GetStatus(now)
+
+	pd.log.Info("Public dashboard access token rotated", "publicDashboardUid", uid, "tokenUid", token.Uid, "newTokenUid", newToken.Uid, "label", token.Label, "user", u.Login)
+
+	return newToken, nil
+}
+
+// RotatePublicDashboardAccessToken replaces the access token of the public dashboard itself
+func (pd *PublicDashboardServiceImpl) RotatePublicDashboardAccessToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, uid string) (*PublicDashboard, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RotatePublicDashboardAccessToken")
+	defer span.End()
+
+	pubdash, err := pd.findForDashboard(ctx, u.OrgID, dashboardUid, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	affectedRows, err := pd.store.RotateAccessToken(ctx, pubdash.Uid, accessToken, u.UserID, time.Now())
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("RotatePublicDashboardAccessToken: failed to rotate access token: %w", err)
+	}
+	if affectedRows == 0 {
+		return nil, ErrPublicDashboardNotFound.Errorf("RotatePublicDashboardAccessToken: public dashboard not found by uid: %s", uid)
+	}
+
+	newPubdash, err := pd.store.Find(ctx, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("RotatePublicDashboardAccessToken: failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+
+	pd.log.Info("Public dashboard access token rotated", "publicDashboardUid", uid, "user", u.Login)
+
+	return newPubdash, nil
+}
+
+// findForDashboard returns the public dashboard if it belongs to the dashboard
+func (pd *PublicDashboardServiceImpl) findForDashboard(ctx context.Context, orgId int64, dashboardUid string, uid string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("findForDashboard: failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+	if pubdash == nil || pubdash.OrgId != orgId {
+		return nil, ErrPublicDashboardNotFound.Errorf("findForDashboard: public dashboard not found by uid: %s", uid)
+	}
+	if pubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("findForDashboard: the public dashboard does not belong to the dashboard")
+	}
+
+	return pubdash, nil
+}
+
+// findAccessToken returns the additional access token if it belongs to the public dashboard of the dashboard
+func (pd *PublicDashboardServiceImpl) findAccessToken(ctx context.Context, orgId int64, dashboardUid string, uid string, tokenUid string) (*PublicDashboardAccessToken, error) {
+	pubdash, err := pd.findForDashboard(ctx, orgId, dashboardUid, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pd.store.FindAccessToken(ctx, orgId, tokenUid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("findAccessToken: failed to find access token by uid: %s: %w", tokenUid, err)
+	}
+	if token == nil || token.PublicDashboardUid != pubdash.Uid {
+		return nil, ErrAccessTokenNotFound.Errorf("findAccessToken: access token not found by uid: %s", tokenUid)
+	}
+
+	return token, nil
+}
+
+func (pd *PublicDashboardServiceImpl) newAccessToken(ctx context.Context, pubdash *PublicDashboard, label string, expiresAt *time.Time, userId int64, now time.Time) (*PublicDashboardAccessToken, error) {
+	token, err := pd.buildAccessToken(ctx, pubdash, label, expiresAt, userId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pd.store.CreateAccessToken(ctx, token); err != nil {
+		return nil, ErrInternalServerError.Errorf("newAccessToken: failed to create access token: %w", err)
+	}
+	token.Status = token.GetStatus(now)
+
+	return token, nil
+}
+
+// buildAccessToken generates an additional access token without storing it
+func (pd *PublicDashboardServiceImpl) buildAccessToken(ctx context.Context, pubdash *PublicDashboard, label string, expiresAt *time.Time, userId int64, now time.Time) (*PublicDashboardAccessToken, error) {
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicDashboardAccessToken{
+		Uid:                util.GenerateShortUID(),
+		OrgId:              pubdash.OrgId,
+		PublicDashboardUid: pubdash.Uid,
+		AccessToken:        accessToken,
+		Label:              label,
+		ExpiresAt:          expiresAt,
+		CreatedBy:          userId,
+		CreatedAt:          now,
+	}, nil
+}
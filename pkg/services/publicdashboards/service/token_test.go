@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestAccessTokens(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "pubdash-uid", DashboardUid: "dashboard-uid", OrgId: 1, AccessToken: "b9ab4f3ea2e34b7b8fc4b0ca32b1a8ae"}
+	u := &user.SignedInUser{UserID: 2, OrgID: 1, Login: "admin"}
+
+	newService := func(store *FakePublicDashboardStore) *PublicDashboardServiceImpl {
+		return &PublicDashboardServiceImpl{store: store, log: log.New("test.logger")}
+	}
+
+	t.Run("FindAccessTokens sets the status of the tokens", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		future := time.Now().Add(time.Hour)
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindAccessTokens", mock.Anything, int64(1), "pubdash-uid").Return([]*PublicDashboardAccessToken{
+			{Uid: "active", ExpiresAt: &future},
+			{Uid: "expired", ExpiresAt: &past},
+			{Uid: "revoked", RevokedAt: &past},
+		}, nil)
+
+		tokens, err := newService(store).FindAccessTokens(context.Background(), 1, "dashboard-uid", "pubdash-uid")
+		require.NoError(t, err)
+		require.Len(t, tokens, 3)
+		assert.Equal(t, AccessTokenActive, tokens[0].Status)
+		assert.Equal(t, AccessTokenExpired, tokens[1].Status)
+		assert.Equal(t, AccessTokenRevoked, tokens[2].Status)
+	})
+
+	t.Run("FindAccessTokens fails when the public dashboard belongs to another org", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+
+		_, err := newService(store).FindAccessTokens(context.Background(), 2, "dashboard-uid", "pubdash-uid")
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+	})
+
+	t.Run("CreateAccessToken creates a token with label and expiration", func(t *testing.T) {
+		expiresAt := time.Now().Add(24 * time.Hour)
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("CreateAccessToken", mock.Anything, mock.Anything).Return(nil)
+
+		token, err := newService(store).CreateAccessToken(context.Background(), u, &SavePublicDashboardAccessTokenDTO{
+			OrgID:        1,
+			UserId:       2,
+			DashboardUid: "dashboard-uid",
+			Uid:          "pubdash-uid",
+			AccessToken:  &PublicDashboardAccessTokenDTO{Label: "partner", ExpiresAt: &expiresAt},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, token.Uid)
+		assert.Len(t, token.AccessToken, 32)
+		assert.Equal(t, "pubdash-uid", token.PublicDashboardUid)
+		assert.Equal(t, "partner", token.Label)
+		assert.Equal(t, &expiresAt, token.ExpiresAt)
+		assert.Equal(t, int64(2), token.CreatedBy)
+		assert.Equal(t, AccessTokenActive, token.Status)
+	})
+
+	t.Run("CreateAccessToken fails with an expiration in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		store := NewFakePublicDashboardStore(t)
+
+		_, err := newService(store).CreateAccessToken(context.Background(), u, &SavePublicDashboardAccessTokenDTO{
+			OrgID:        1,
+			DashboardUid: "dashboard-uid",
+			Uid:          "pubdash-uid",
+			AccessToken:  &PublicDashboardAccessTokenDTO{ExpiresAt: &expiresAt},
+		})
+		assert.ErrorIs(t, err, ErrInvalidAccessTokenExpiry)
+	})
+
+	t.Run("RevokeAccessToken fails when the token belongs to another public dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindAccessToken", mock.Anything, int64(1), "token-uid").
+			Return(&PublicDashboardAccessToken{Uid: "token-uid", OrgId: 1, PublicDashboardUid: "other-uid"}, nil)
+
+		err := newService(store).RevokeAccessToken(context.Background(), u, "dashboard-uid", "pubdash-uid", "token-uid")
+		assert.ErrorIs(t, err, ErrAccessTokenNotFound)
+	})
+
+	t.Run("RevokeAccessToken fails when the token is already revoked", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindAccessToken", mock.Anything, int64(1), "token-uid").
+			Return(&PublicDashboardAccessToken{Uid: "token-uid", OrgId: 1, PublicDashboardUid: "pubdash-uid"}, nil)
+		store.On("RevokeAccessToken", mock.Anything, int64(1), "token-uid", int64(2), mock.Anything).Return(int64(0), nil)
+
+		err := newService(store).RevokeAccessToken(context.Background(), u, "dashboard-uid", "pubdash-uid", "token-uid")
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+	})
+
+	t.Run("RotateAccessToken revokes the token and creates a new one with the same label", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindAccessToken", mock.Anything, int64(1), "token-uid").
+			Return(&PublicDashboardAccessToken{Uid: "token-uid", OrgId: 1, PublicDashboardUid: "pubdash-uid", Label: "partner", AccessToken: "e71950f2dbce4ca6a8f5d4abd3e2c02b"}, nil)
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("ReplaceAccessToken", mock.Anything, int64(1), "token-uid", int64(2), mock.Anything, mock.Anything).Return(int64(1), nil)
+
+		token, err := newService(store).RotateAccessToken(context.Background(), u, "dashboard-uid", "pubdash-uid", "token-uid")
+		require.NoError(t, err)
+		assert.NotEqual(t, "token-uid", token.Uid)
+		assert.NotEqual(t, "e71950f2dbce4ca6a8f5d4abd3e2c02b", token.AccessToken)
+		assert.Equal(t, "partner", token.Label)
+		assert.Equal(t, AccessTokenActive, token.Status)
+	})
+
+	t.Run("RotateAccessToken fails when the token was revoked concurrently", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindAccessToken", mock.Anything, int64(1), "token-uid").
+			Return(&PublicDashboardAccessToken{Uid: "token-uid", OrgId: 1, PublicDashboardUid: "pubdash-uid"}, nil)
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("ReplaceAccessToken", mock.Anything, int64(1), "token-uid", int64(2), mock.Anything, mock.Anything).Return(int64(0), nil)
+
+		_, err := newService(store).RotateAccessToken(context.Background(), u, "dashboard-uid", "pubdash-uid", "token-uid")
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+	})
+
+	t.Run("RotatePublicDashboardAccessToken replaces the access token of the public dashboard", func(t *testing.T) {
+		rotated := *pubdash
+		rotated.AccessToken = "0c5b7e9a38b34d1e9a3a2f5e6b1d4c7f"
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil).Once()
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("RotateAccessToken", mock.Anything, "pubdash-uid", mock.Anything, int64(2), mock.Anything).Return(int64(1), nil)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(&rotated, nil).Once()
+
+		got, err := newService(store).RotatePublicDashboardAccessToken(context.Background(), u, "dashboard-uid", "pubdash-uid")
+		require.NoError(t, err)
+		assert.Equal(t, rotated.AccessToken, got.AccessToken)
+	})
+}
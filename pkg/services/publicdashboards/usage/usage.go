@@ -0,0 +1,179 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	bucketSize    = time.Hour
+	flushInterval = time.Minute
+)
+
+type bucketKey struct {
+	orgID  int64
+	uid    string
+	bucket int64
+}
+
+// Service counts the views, queries, errors and rate limited queries of public dashboards. The counters are kept in
+// memory and periodically added to the hourly buckets stored in the database.
+type Service struct {
+	store publicdashboards.Store
+	cfg   *setting.Cfg
+	log   log.Logger
+	now   func() time.Time
+
+	mu      sync.Mutex
+	pending map[bucketKey]*UsageBucket
+}
+
+func ProvideService(store publicdashboards.Store, cfg *setting.Cfg) *Service {
+	return &Service{
+		store:   store,
+		cfg:     cfg,
+		log:     log.New("publicdashboards.usage"),
+		now:     time.Now,
+		pending: make(map[bucketKey]*UsageBucket),
+	}
+}
+
+// Record counts a request to a public dashboard. It is safe to call on a nil Service.
+func (s *Service) Record(orgID int64, uid string, kind UsageKind) {
+	if s == nil {
+		return
+	}
+
+	key := bucketKey{orgID: orgID, uid: uid, bucket: s.now().Truncate(bucketSize).Unix()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.pending[key]
+	if !ok {
+		b = &UsageBucket{OrgId: orgID, PublicDashboardUid: uid, Bucket: key.bucket}
+		s.pending[key] = b
+	}
+	b.Add(kind, 1)
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			// flush the remaining counters, the context is already canceled
+			s.flush(context.Background())
+			return ctx.Err()
+		case <-ticker.C:
+			s.flush(ctx)
+
+			if s.now().Sub(lastCleanup) > 24*time.Hour {
+				s.cleanup(ctx)
+				lastCleanup = s.now()
+			}
+		}
+	}
+}
+
+// GetUsage returns the hourly usage of a public dashboard, including the counters not yet stored
+func (s *Service) GetUsage(ctx context.Context, query UsageQuery) (*UsageResponse, error) {
+	stored, err := s.store.GetUsage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[int64]*UsageBucket, len(stored))
+	for _, b := range stored {
+		buckets[b.Bucket] = b
+	}
+
+	s.mu.Lock()
+	for key, p := range s.pending {
+		if key.orgID != query.OrgID || key.uid != query.PublicDashboardUid || key.bucket < query.From.Unix() || key.bucket > query.To.Unix() {
+			continue
+		}
+		b, ok := buckets[key.bucket]
+		if !ok {
+			b = &UsageBucket{OrgId: key.orgID, PublicDashboardUid: key.uid, Bucket: key.bucket}
+			buckets[key.bucket] = b
+		}
+		b.Views += p.Views
+		b.Queries += p.Queries
+		b.Errors += p.Errors
+		b.RateLimited += p.RateLimited
+	}
+	s.mu.Unlock()
+
+	resp := &UsageResponse{
+		Uid:     query.PublicDashboardUid,
+		From:    query.From.UnixMilli(),
+		To:      query.To.UnixMilli(),
+		Buckets: make([]*UsageBucket, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		resp.Totals.Views += b.Views
+		resp.Totals.Queries += b.Queries
+		resp.Totals.Errors += b.Errors
+		resp.Totals.RateLimited += b.RateLimited
+		resp.Buckets = append(resp.Buckets, b)
+	}
+	sort.Slice(resp.Buckets, func(i, j int) bool { return resp.Buckets[i].Bucket < resp.Buckets[j].Bucket })
+
+	return resp, nil
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	if len(s.pending) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	buckets := make([]*UsageBucket, 0, len(s.pending))
+	for _, b := range s.pending {
+		buckets = append(buckets, b)
+	}
+	s.pending = make(map[bucketKey]*UsageBucket)
+	s.mu.Unlock()
+
+	if err := s.store.AddUsage(ctx, buckets); err != nil {
+		s.log.Error("Failed to store public dashboard usage", "buckets", len(buckets), "error", err)
+
+		// keep the counters for the next flush
+		s.mu.Lock()
+		for _, b := range buckets {
+			key := bucketKey{orgID: b.OrgId, uid: b.PublicDashboardUid, bucket: b.Bucket}
+			if p, ok := s.pending[key]; ok {
+				b.Views += p.Views
+				b.Queries += p.Queries
+				b.Errors += p.Errors
+				b.RateLimited += p.RateLimited
+			}
+			s.pending[key] = b
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	if s.cfg.PublicDashboardsUsageRetention <= 0 {
+		return
+	}
+
+	deleted, err := s.store.DeleteUsageBefore(ctx, s.now().Add(-s.cfg.PublicDashboardsUsageRetention))
+	if err != nil {
+		s.log.Error("Failed to delete old public dashboard usage", "error", err)
+		return
+	}
+	s.log.Debug("Deleted old public dashboard usage", "rows", deleted)
+}
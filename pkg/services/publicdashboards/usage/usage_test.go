@@ -0,0 +1,83 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestUsage(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	hour := now.Truncate(time.Hour)
+
+	t.Run("a nil service does not record", func(t *testing.T) {
+		var s *Service
+		s.Record(1, "pubdash", UsageView)
+	})
+
+	t.Run("flushes the counters per hour", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		s := ProvideService(store, setting.NewCfg())
+		s.now = func() time.Time { return now }
+
+		s.Record(1, "pubdash", UsageView)
+		s.Record(1, "pubdash", UsageQuery)
+		s.Record(1, "pubdash", UsageQuery)
+		s.Record(1, "pubdash", UsageError)
+		s.Record(1, "pubdash", UsageRateLimited)
+
+		store.On("AddUsage", mock.Anything, []*UsageBucket{
+			{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: hour.Unix(), Views: 1, Queries: 2, Errors: 1, RateLimited: 1},
+		}).Return(nil).Once()
+		s.flush(context.Background())
+
+		// nothing left to flush
+		s.flush(context.Background())
+	})
+
+	t.Run("keeps the counters when they cannot be stored", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		s := ProvideService(store, setting.NewCfg())
+		s.now = func() time.Time { return now }
+
+		s.Record(1, "pubdash", UsageView)
+		store.On("AddUsage", mock.Anything, mock.Anything).Return(assert.AnError).Once()
+		s.flush(context.Background())
+
+		s.Record(1, "pubdash", UsageView)
+		store.On("AddUsage", mock.Anything, []*UsageBucket{
+			{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: hour.Unix(), Views: 2},
+		}).Return(nil).Once()
+		s.flush(context.Background())
+	})
+
+	t.Run("merges the stored and pending counters", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		s := ProvideService(store, setting.NewCfg())
+		s.now = func() time.Time { return now }
+
+		s.Record(1, "pubdash", UsageView)
+		s.Record(1, "other", UsageView)
+
+		query := UsageQuery{OrgID: 1, PublicDashboardUid: "pubdash", From: hour.Add(-time.Hour), To: now}
+		store.On("GetUsage", mock.Anything, query).Return([]*UsageBucket{
+			{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: hour.Add(-time.Hour).Unix(), Views: 3, Queries: 1},
+			{OrgId: 1, PublicDashboardUid: "pubdash", Bucket: hour.Unix(), Views: 1},
+		}, nil)
+
+		resp, err := s.GetUsage(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, resp.Buckets, 2)
+		assert.Equal(t, hour.Add(-time.Hour).Unix(), resp.Buckets[0].Bucket)
+		assert.Equal(t, int64(2), resp.Buckets[1].Views)
+		assert.Equal(t, UsageTotals{Views: 5, Queries: 1}, resp.Totals)
+	})
+}
@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -13,9 +15,34 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if !isValidQueryLimit(dto.PublicDashboard.QueryRateLimit) || !isValidQueryLimit(dto.PublicDashboard.MaxConcurrentQueries) {
+		return ErrInvalidQueryRateLimit.Errorf("ValidateSavePublicDashboard: query limits must not be negative, except %d for unlimited", UnlimitedQueries)
+	}
+
+	return nil
+}
+
+// ValidateAccessToken validates the label and the expiration of an additional access token
+func ValidateAccessToken(dto *PublicDashboardAccessTokenDTO, now time.Time) error {
+	if dto == nil {
+		return ErrBadRequest.Errorf("ValidateAccessToken: missing access token")
+	}
+
+	if len(dto.Label) > MaxAccessTokenLabelLength {
+		return ErrInvalidAccessTokenLabel.Errorf("ValidateAccessToken: label is longer than %d characters", MaxAccessTokenLabelLength)
+	}
+
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		return ErrInvalidAccessTokenExpiry.Errorf("ValidateAccessToken: expiration is in the past")
+	}
+
 	return nil
 }
 
+func isValidQueryLimit(value *int) bool {
+	return value == nil || *value >= 0 || *value == UnlimitedQueries
+}
+
 func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *PublicDashboard) error {
 	if req.IntervalMs < 0 {
 		return ErrInvalidInterval.Errorf("ValidateQueryPublicDashboardRequest: intervalMS should be greater than 0")
@@ -22,6 +22,26 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when query limits are negative", func(t *testing.T) {
+		limit := -2
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{QueryRateLimit: &limit}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidQueryRateLimit)
+
+		dto = &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{MaxConcurrentQueries: &limit}}
+		err = ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidQueryRateLimit)
+	})
+
+	t.Run("Accepts unlimited query limits", func(t *testing.T) {
+		limit := UnlimitedQueries
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{QueryRateLimit: &limit, MaxConcurrentQueries: &limit}}
+
+		err := ValidatePublicDashboard(dto)
+		require.NoError(t, err)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
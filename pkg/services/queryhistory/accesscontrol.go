@@ -0,0 +1,49 @@
+package queryhistory
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	// ActionShare allows sharing own queries in query history with a team or the organization
+	ActionShare = "query.history:share"
+	// ActionWrite allows editing and deleting queries other users shared with the user
+	ActionWrite = "query.history:write"
+)
+
+var (
+	sharerRole = accesscontrol.RoleDTO{
+		Name:        "fixed:query.history:sharer",
+		DisplayName: "Query history sharer",
+		Description: "Share queries in query history with teams or the organization",
+		Group:       "Query history",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionShare},
+		},
+	}
+
+	curatorRole = accesscontrol.RoleDTO{
+		Name:        "fixed:query.history:curator",
+		DisplayName: "Query history curator",
+		Description: "Share queries in query history and edit or delete queries shared by other users",
+		Group:       "Query history",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionShare},
+			{Action: ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	sharer := accesscontrol.RoleRegistration{
+		Role:   sharerRole,
+		Grants: []string{string(org.RoleEditor)},
+	}
+	curator := accesscontrol.RoleRegistration{
+		Role:   curatorRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(sharer, curator)
+}
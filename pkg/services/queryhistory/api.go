@@ -1,6 +1,7 @@
 package queryhistory
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
@@ -15,6 +16,8 @@ import (
 )
 
 func (s *QueryHistoryService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
 	s.RouteRegister.Group("/api/query-history", func(entities routing.RouteRegister) {
 		entities.Post("/", middleware.ReqSignedIn, routing.Wrap(s.permissionsMiddleware(s.createHandler, "Failed to create query history")))
 		entities.Get("/", middleware.ReqSignedIn, routing.Wrap(s.permissionsMiddleware(s.searchHandler, "Failed to get query history")))
@@ -22,6 +25,8 @@ func (s *QueryHistoryService) registerAPIEndpoints() {
 		entities.Post("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.permissionsMiddleware(s.starHandler, "Failed to star query history")))
 		entities.Delete("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.permissionsMiddleware(s.unstarHandler, "Failed to unstar query history")))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(s.permissionsMiddleware(s.patchCommentHandler, "Failed to update comment of query in query history")))
+		entities.Put("/:uid/metadata", middleware.ReqSignedIn, routing.Wrap(s.permissionsMiddleware(s.updateMetadataHandler, "Failed to update query in query history")))
+		entities.Put("/:uid/share", middleware.ReqSignedIn, authorize(ac.EvalPermission(ActionShare)), routing.Wrap(s.permissionsMiddleware(s.shareHandler, "Failed to share query in query history")))
 	})
 }
 
@@ -78,14 +83,17 @@ func (s *QueryHistoryService) searchHandler(c *contextmodel.ReqContext) response
 	timeRange := gtime.NewTimeRange(c.Query("from"), c.Query("to"))
 
 	query := SearchInQueryHistoryQuery{
-		DatasourceUIDs: c.QueryStrings("datasourceUid"),
-		SearchString:   c.Query("searchString"),
-		OnlyStarred:    c.QueryBoolWithDefault("onlyStarred", false),
-		Sort:           c.Query("sort"),
-		Page:           c.QueryInt("page"),
-		Limit:          c.QueryInt("limit"),
-		From:           timeRange.GetFromAsSecondsEpoch(),
-		To:             timeRange.GetToAsSecondsEpoch(),
+		DatasourceUIDs:  c.QueryStrings("datasourceUid"),
+		DatasourceTypes: c.QueryStrings("datasourceType"),
+		Tags:            c.QueryStrings("tag"),
+		SearchString:    c.Query("searchString"),
+		OnlyStarred:     c.QueryBoolWithDefault("onlyStarred", false),
+		IncludeShared:   c.QueryBoolWithDefault("includeShared", false),
+		Sort:            c.Query("sort"),
+		Page:            c.QueryInt("page"),
+		Limit:           c.QueryInt("limit"),
+		From:            timeRange.GetFromAsSecondsEpoch(),
+		To:              timeRange.GetToAsSecondsEpoch(),
 	}
 
 	result, err := s.SearchInQueryHistory(c.Req.Context(), c.SignedInUser, query)
@@ -114,6 +122,9 @@ func (s *QueryHistoryService) deleteHandler(c *contextmodel.ReqContext) response
 
 	id, err := s.DeleteQueryFromQueryHistory(c.Req.Context(), c.SignedInUser, queryUID)
 	if err != nil {
+		if errors.Is(err, ErrQueryReadOnly) {
+			return response.Error(http.StatusForbidden, "Query in query history can only be deleted by its creator", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to delete query from query history", err)
 	}
 
@@ -147,12 +158,98 @@ func (s *QueryHistoryService) patchCommentHandler(c *contextmodel.ReqContext) re
 
 	query, err := s.PatchQueryCommentInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
 	if err != nil {
+		if errors.Is(err, ErrQueryReadOnly) {
+			return response.Error(http.StatusForbidden, "Query in query history can only be edited by its creator", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to update comment of query in query history", err)
 	}
 
 	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
 }
 
+// swagger:route PUT /query-history/{query_history_uid}/metadata query_history updateQueryMetadata
+//
+// Update title and tags of query in query history.
+//
+// Updates title and replaces tags of query in query history as specified by the UID.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) updateMetadataHandler(c *contextmodel.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := UpdateQueryMetadataInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.UpdateQueryMetadataInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrQueryNotFound):
+			return response.Error(http.StatusNotFound, "Query in query history not found", err)
+		case errors.Is(err, ErrQueryReadOnly):
+			return response.Error(http.StatusForbidden, "Query in query history can only be edited by its creator", err)
+		case errors.Is(err, ErrTitleTooLong), errors.Is(err, ErrTagTooLong):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route PUT /query-history/{query_history_uid}/share query_history shareQuery
+//
+// Share query in query history.
+//
+// Shares query in query history as specified by the UID with a team or the whole organization.
+// Shared queries are kept by the query history cleanup.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) shareHandler(c *contextmodel.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := ShareQueryInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.ShareQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrQueryNotFound):
+			return response.Error(http.StatusNotFound, "Query in query history not found", err)
+		case errors.Is(err, ErrQueryReadOnly):
+			return response.Error(http.StatusForbidden, "Query in query history can only be shared by its creator", err)
+		case errors.Is(err, ErrNotTeamMember):
+			return response.Error(http.StatusForbidden, "Query in query history can only be shared with your teams", err)
+		case errors.Is(err, ErrInvalidVisibility), errors.Is(err, ErrTeamRequired):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to share query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
 // swagger:route POST /query-history/star/{query_history_uid} query_history starQuery
 //
 // Add star to query in query history.
@@ -201,7 +298,7 @@ func (s *QueryHistoryService) unstarHandler(c *contextmodel.ReqContext) response
 	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
 }
 
-// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery
+// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery updateQueryMetadata shareQuery
 type QueryHistoryByUID struct {
 	// in:path
 	// required:true
@@ -216,7 +313,19 @@ type SearchQueriesParams struct {
 	// type: array
 	// collectionFormat: multi
 	DatasourceUid []string `json:"datasourceUid"`
-	// Text inside query or comments that is searched for
+	// List of data source types to search for
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	DatasourceType []string `json:"datasourceType"`
+	// List of tags the queries must have
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	Tag []string `json:"tag"`
+	// Text inside query, comments or title that is searched for
 	// in:query
 	// required: false
 	SearchString string `json:"searchString"`
@@ -224,6 +333,10 @@ type SearchQueriesParams struct {
 	// in:query
 	// required: false
 	OnlyStarred bool `json:"onlyStarred"`
+	// Flag indicating if queries shared with the user's teams or organization should be returned
+	// in:query
+	// required: false
+	IncludeShared bool `json:"includeShared"`
 	// Sort method
 	// in:query
 	// required: false
@@ -262,6 +375,20 @@ type PatchQueryCommentParams struct {
 	Body PatchQueryCommentInQueryHistoryCommand `json:"body"`
 }
 
+// swagger:parameters updateQueryMetadata
+type UpdateQueryMetadataParams struct {
+	// in:body
+	// required:true
+	Body UpdateQueryMetadataInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters shareQuery
+type ShareQueryParams struct {
+	// in:body
+	// required:true
+	Body ShareQueryInQueryHistoryCommand `json:"body"`
+}
+
 //swagger:response getQueryHistorySearchResponse
 type GetQueryHistorySearchResponse struct {
 	// in: body
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -29,6 +30,7 @@ func (s QueryHistoryService) createQuery(ctx context.Context, user *user.SignedI
 		CreatedBy:     user.UserID,
 		CreatedAt:     s.now().Unix(),
 		Comment:       "",
+		Visibility:    VisibilityPrivate,
 	}
 
 	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
@@ -58,17 +60,7 @@ func (s QueryHistoryService) createQuery(ctx context.Context, user *user.SignedI
 		})
 	}
 
-	dto := QueryHistoryDTO{
-		UID:           queryHistory.UID,
-		DatasourceUID: queryHistory.DatasourceUID,
-		CreatedBy:     queryHistory.CreatedBy,
-		CreatedAt:     queryHistory.CreatedAt,
-		Comment:       queryHistory.Comment,
-		Queries:       queryHistory.Queries,
-		Starred:       false,
-	}
-
-	return dto, nil
+	return newQueryHistoryDTO(queryHistory, false, []string{}), nil
 }
 
 // searchQueries searches for queries in query history based on provided parameters
@@ -100,9 +92,12 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 			query_history.created_by,
 			query_history.created_at AS created_at,
 			query_history.comment,
+			query_history.title,
+			query_history.visibility,
+			query_history.team_id,
 			query_history.queries,
 		`)
-		writeStarredSQL(query, s.store, &dtosBuilder, false)
+		writeStarredSQL(query, user, s.store, &dtosBuilder, false)
 		writeFiltersSQL(query, user, s.store, &dtosBuilder)
 		writeSortSQL(query, s.store, &dtosBuilder)
 		writeLimitSQL(query, s.store, &dtosBuilder)
@@ -113,10 +108,25 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 			return err
 		}
 
+		uids := make([]string, 0, len(dtos))
+		for _, dto := range dtos {
+			uids = append(uids, dto.UID)
+		}
+		tags, err := getTags(session, uids...)
+		if err != nil {
+			return err
+		}
+		for i := range dtos {
+			dtos[i].Tags = tags[dtos[i].UID]
+			if dtos[i].Tags == nil {
+				dtos[i].Tags = []string{}
+			}
+		}
+
 		countBuilder := db.SQLBuilder{}
 		countBuilder.Write(`SELECT
 		`)
-		writeStarredSQL(query, s.store, &countBuilder, true)
+		writeStarredSQL(query, user, s.store, &countBuilder, true)
 		writeFiltersSQL(query, user, s.store, &countBuilder)
 		_, err = session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Get(&totalCount)
 		return err
@@ -139,8 +149,13 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedInUser, UID string) (int64, error) {
 	var queryID int64
 	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
-		// Try to unstar the query first
-		_, err := session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, UID).Delete(QueryHistoryStar{})
+		queryHistory, err := s.getEditableQuery(ctx, session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		// Try to unstar the query first, shared queries may be starred by other users too
+		_, err = session.Table("query_history_star").Where("query_uid = ?", UID).Delete(QueryHistoryStar{})
 		if err != nil {
 			s.log.Error("Failed to unstar query while deleting it from query history", "query", UID, "user", user.UserID, "error", err)
 		}
@@ -151,8 +166,14 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedI
 			s.log.Error("Failed to remove the details for the query item", "query", UID, "user", user.UserID, "error", err)
 		}
 
+		// remove the tags
+		_, err = session.Table("query_history_tag").Where("query_history_item_uid = ?", UID).Delete(QueryHistoryTag{})
+		if err != nil {
+			s.log.Error("Failed to remove the tags for the query item", "query", UID, "user", user.UserID, "error", err)
+		}
+
 		// Then delete it
-		id, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Delete(QueryHistory{})
+		id, err := session.ID(queryHistory.ID).Delete(QueryHistory{})
 		if err != nil {
 			return err
 		}
@@ -171,15 +192,14 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedI
 func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error) {
 	var queryHistory QueryHistory
 	var isStarred bool
+	var tags []string
 
 	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
-		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		var err error
+		queryHistory, err = s.getEditableQuery(ctx, session, user, UID)
 		if err != nil {
 			return err
 		}
-		if !exists {
-			return ErrQueryNotFound
-		}
 
 		queryHistory.Comment = cmd.Comment
 		_, err = session.ID(queryHistory.ID).Update(queryHistory)
@@ -187,45 +207,127 @@ func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.S
 			return err
 		}
 
-		starred, err := session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, UID).Exist()
+		isStarred, tags, err = getStarAndTags(session, user, UID)
+		return err
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	return newQueryHistoryDTO(queryHistory, isStarred, tags), nil
+}
+
+// updateQueryMetadata updates title and replaces tags of query in query history
+func (s QueryHistoryService) updateQueryMetadata(ctx context.Context, user *user.SignedInUser, UID string, cmd UpdateQueryMetadataInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	var queryHistory QueryHistory
+	var isStarred bool
+	var tags []string
+
+	if err := cmd.Validate(); err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var err error
+		queryHistory, err = s.getEditableQuery(ctx, session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		queryHistory.Title = cmd.Title
+		_, err = session.ID(queryHistory.ID).Cols("title").Update(queryHistory)
 		if err != nil {
 			return err
 		}
-		isStarred = starred
-		return nil
+
+		_, err = session.Table("query_history_tag").Where("query_history_item_uid = ?", UID).Delete(QueryHistoryTag{})
+		if err != nil {
+			return err
+		}
+		for _, tag := range cmd.Tags {
+			if _, err := session.Insert(&QueryHistoryTag{QueryHistoryItemUID: UID, Term: tag}); err != nil {
+				return err
+			}
+		}
+
+		isStarred, tags, err = getStarAndTags(session, user, UID)
+		return err
 	})
 
 	if err != nil {
 		return QueryHistoryDTO{}, err
 	}
 
-	dto := QueryHistoryDTO{
-		UID:           queryHistory.UID,
-		DatasourceUID: queryHistory.DatasourceUID,
-		CreatedBy:     queryHistory.CreatedBy,
-		CreatedAt:     queryHistory.CreatedAt,
-		Comment:       queryHistory.Comment,
-		Queries:       queryHistory.Queries,
-		Starred:       isStarred,
+	return newQueryHistoryDTO(queryHistory, isStarred, tags), nil
+}
+
+// shareQuery changes who, besides its creator, can see query in query history
+func (s QueryHistoryService) shareQuery(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	var queryHistory QueryHistory
+	var isStarred bool
+	var tags []string
+
+	if err := cmd.Validate(); err != nil {
+		return QueryHistoryDTO{}, err
 	}
 
-	return dto, nil
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		var err error
+		queryHistory, err = s.getEditableQuery(ctx, session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		teamID := int64(0)
+		if cmd.Visibility == VisibilityTeam {
+			teamID = cmd.TeamID
+			canWrite, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(ActionWrite))
+			if err != nil {
+				return err
+			}
+			if !canWrite {
+				isMember, err := session.Table("team_member").Where("org_id = ? AND team_id = ? AND user_id = ?", user.OrgID, teamID, user.UserID).Exist()
+				if err != nil {
+					return err
+				}
+				if !isMember {
+					return ErrNotTeamMember
+				}
+			}
+		}
+
+		queryHistory.Visibility = cmd.Visibility
+		queryHistory.TeamID = teamID
+		_, err = session.ID(queryHistory.ID).Cols("visibility", "team_id").Update(queryHistory)
+		if err != nil {
+			return err
+		}
+
+		isStarred, tags, err = getStarAndTags(session, user, UID)
+		return err
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	return newQueryHistoryDTO(queryHistory, isStarred, tags), nil
 }
 
 // starQuery adds query into query_history_star table together with user_id and org_id
 func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error) {
 	var queryHistory QueryHistory
 	var isStarred bool
+	var tags []string
 
 	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
-		// Check if query exists as we want to star only existing queries
-		exists, err := session.Table("query_history").Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		// Check if query exists as we want to star only existing queries the user can see
+		var err error
+		queryHistory, err = getVisibleQuery(session, user, UID)
 		if err != nil {
 			return err
 		}
-		if !exists {
-			return ErrQueryNotFound
-		}
 
 		// If query exists then star it
 		queryHistoryStar := QueryHistoryStar{
@@ -242,39 +344,29 @@ func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInU
 		}
 
 		isStarred = true
-		return nil
+		tags, err = getTagsOfQuery(session, UID)
+		return err
 	})
 
 	if err != nil {
 		return QueryHistoryDTO{}, err
 	}
 
-	dto := QueryHistoryDTO{
-		UID:           queryHistory.UID,
-		DatasourceUID: queryHistory.DatasourceUID,
-		CreatedBy:     queryHistory.CreatedBy,
-		CreatedAt:     queryHistory.CreatedAt,
-		Comment:       queryHistory.Comment,
-		Queries:       queryHistory.Queries,
-		Starred:       isStarred,
-	}
-
-	return dto, nil
+	return newQueryHistoryDTO(queryHistory, isStarred, tags), nil
 }
 
 // unstarQuery deletes query with with user_id and org_id from query_history_star table
 func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error) {
 	var queryHistory QueryHistory
 	var isStarred bool
+	var tags []string
 
 	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
-		exists, err := session.Table("query_history").Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		var err error
+		queryHistory, err = getVisibleQuery(session, user, UID)
 		if err != nil {
 			return err
 		}
-		if !exists {
-			return ErrQueryNotFound
-		}
 
 		id, err := session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, UID).Delete(QueryHistoryStar{})
 		if id == 0 {
@@ -285,35 +377,28 @@ func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedI
 		}
 
 		isStarred = false
-		return nil
+		tags, err = getTagsOfQuery(session, UID)
+		return err
 	})
 
 	if err != nil {
 		return QueryHistoryDTO{}, err
 	}
 
-	dto := QueryHistoryDTO{
-		UID:           queryHistory.UID,
-		DatasourceUID: queryHistory.DatasourceUID,
-		CreatedBy:     queryHistory.CreatedBy,
-		CreatedAt:     queryHistory.CreatedAt,
-		Comment:       queryHistory.Comment,
-		Queries:       queryHistory.Queries,
-		Starred:       isStarred,
-	}
-
-	return dto, nil
+	return newQueryHistoryDTO(queryHistory, isStarred, tags), nil
 }
 
 func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan int64) (int, error) {
 	var rowsCount int64
 
 	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		// starred and shared queries are never stale
 		uids_sql := `SELECT uid FROM (
 			SELECT uid FROM query_history
 			LEFT JOIN query_history_star
 			ON query_history_star.query_uid = query_history.uid
 			WHERE query_history_star.query_uid IS NULL
+			AND query_history.visibility = ?
 			AND query_history.created_at <= ?
 			ORDER BY query_history.id ASC
 			LIMIT 10000
@@ -323,16 +408,25 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 			FROM query_history_details
 			WHERE query_history_item_uid IN (` + uids_sql + `)`
 
+		tags_sql := `DELETE
+			FROM query_history_tag
+			WHERE query_history_item_uid IN (` + uids_sql + `)`
+
 		sql := `DELETE
 			FROM query_history
 			WHERE uid IN (` + uids_sql + `)`
 
-		_, err := session.Exec(details_sql, strconv.FormatInt(olderThan, 10))
+		_, err := session.Exec(details_sql, VisibilityPrivate, strconv.FormatInt(olderThan, 10))
+		if err != nil {
+			return err
+		}
+
+		_, err = session.Exec(tags_sql, VisibilityPrivate, strconv.FormatInt(olderThan, 10))
 		if err != nil {
 			return err
 		}
 
-		res, err := session.Exec(sql, strconv.FormatInt(olderThan, 10))
+		res, err := session.Exec(sql, VisibilityPrivate, strconv.FormatInt(olderThan, 10))
 		if err != nil {
 			return err
 		}
@@ -372,6 +466,7 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 		countRowsToDelete := rowsCount - int64(limit)
 		if countRowsToDelete > 0 {
 			var sql string
+			params := []any{}
 			if starredQueries {
 				sql = `DELETE FROM query_history_star
 					WHERE id IN (
@@ -390,10 +485,13 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 							LEFT JOIN query_history_star
 							ON query_history_star.query_uid = query_history.uid
 							WHERE query_history_star.query_uid IS NULL
+							AND query_history.visibility = ?
 							ORDER BY query_history.id ASC
 							LIMIT ?
 						) AS q
 					)`
+				// shared queries are kept, same as starred ones
+				params = append(params, VisibilityPrivate)
 			}
 
 			sqlLimit := countRowsToDelete
@@ -401,7 +499,8 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 				sqlLimit = 10000
 			}
 
-			res, err := session.Exec(sql, strconv.FormatInt(sqlLimit, 10))
+			params = append(params, strconv.FormatInt(sqlLimit, 10))
+			res, err := session.Exec(append([]any{sql}, params...)...)
 			if err != nil {
 				return err
 			}
@@ -419,3 +518,100 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 
 	return int(deletedRowsCount), nil
 }
+
+// getVisibleQuery returns query in query history created by the user or shared with the user
+func getVisibleQuery(session *db.Session, user *user.SignedInUser, UID string) (QueryHistory, error) {
+	var queryHistory QueryHistory
+
+	visibleSQL, params := visibleToUserSQL(user)
+	exists, err := session.Table("query_history").Where("query_history.org_id = ? AND query_history.uid = ?", user.OrgID, UID).And(visibleSQL, params...).Get(&queryHistory)
+	if err != nil {
+		return QueryHistory{}, err
+	}
+	if !exists {
+		return QueryHistory{}, ErrQueryNotFound
+	}
+	return queryHistory, nil
+}
+
+// getEditableQuery returns query in query history created by the user. Queries shared with the user are returned
+// only when the user is allowed to edit queries of other users.
+func (s QueryHistoryService) getEditableQuery(ctx context.Context, session *db.Session, user *user.SignedInUser, UID string) (QueryHistory, error) {
+	queryHistory, err := getVisibleQuery(session, user, UID)
+	if err != nil {
+		return QueryHistory{}, err
+	}
+	if queryHistory.CreatedBy == user.UserID {
+		return queryHistory, nil
+	}
+
+	canWrite, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(ActionWrite))
+	if err != nil {
+		return QueryHistory{}, err
+	}
+	if !canWrite {
+		return QueryHistory{}, ErrQueryReadOnly
+	}
+	return queryHistory, nil
+}
+
+// getTags returns the tags of the given queries in query history indexed by query UID
+func getTags(session *db.Session, UIDs ...string) (map[string][]string, error) {
+	result := make(map[string][]string, len(UIDs))
+	if len(UIDs) == 0 {
+		return result, nil
+	}
+
+	var tags []QueryHistoryTag
+	if err := session.Table("query_history_tag").In("query_history_item_uid", UIDs).Asc("term").Find(&tags); err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		result[tag.QueryHistoryItemUID] = append(result[tag.QueryHistoryItemUID], tag.Term)
+	}
+	return result, nil
+}
+
+func getTagsOfQuery(session *db.Session, UID string) ([]string, error) {
+	tags, err := getTags(session, UID)
+	if err != nil {
+		return nil, err
+	}
+	return tags[UID], nil
+}
+
+func getStarAndTags(session *db.Session, user *user.SignedInUser, UID string) (bool, []string, error) {
+	starred, err := session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, UID).Exist()
+	if err != nil {
+		return false, nil, err
+	}
+	tags, err := getTagsOfQuery(session, UID)
+	if err != nil {
+		return false, nil, err
+	}
+	return starred, tags, nil
+}
+
+func newQueryHistoryDTO(queryHistory QueryHistory, starred bool, tags []string) QueryHistoryDTO {
+	if tags == nil {
+		tags = []string{}
+	}
+	visibility := queryHistory.Visibility
+	if visibility == "" {
+		visibility = VisibilityPrivate
+	}
+
+	return QueryHistoryDTO{
+		UID:           queryHistory.UID,
+		DatasourceUID: queryHistory.DatasourceUID,
+		CreatedBy:     queryHistory.CreatedBy,
+		CreatedAt:     queryHistory.CreatedAt,
+		Comment:       queryHistory.Comment,
+		Title:         queryHistory.Title,
+		Tags:          tags,
+		Visibility:    visibility,
+		TeamID:        queryHistory.TeamID,
+		Queries:       queryHistory.Queries,
+		Starred:       starred,
+	}
+}
@@ -2,6 +2,8 @@ package queryhistory
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
@@ -10,8 +12,39 @@ var (
 	ErrQueryNotFound        = errors.New("query in query history not found")
 	ErrStarredQueryNotFound = errors.New("starred query not found")
 	ErrQueryAlreadyStarred  = errors.New("query was already starred")
+	ErrQueryReadOnly        = errors.New("query in query history can only be edited by its creator")
+	ErrInvalidVisibility    = errors.New("invalid query history visibility")
+	ErrTeamRequired         = errors.New("queries shared with a team require a teamId")
+	ErrNotTeamMember        = errors.New("queries can only be shared with teams the user is a member of")
+	ErrTitleTooLong         = fmt.Errorf("title must not be longer than %d characters", maxTitleLength)
+	ErrTagTooLong           = fmt.Errorf("tags must not be longer than %d characters", maxTagLength)
 )
 
+const (
+	maxTitleLength = 255
+	maxTagLength   = 50
+)
+
+// Visibility defines who, besides its creator, can see a query in query history
+type Visibility string
+
+const (
+	// VisibilityPrivate queries are only visible to the user who created them
+	VisibilityPrivate Visibility = "private"
+	// VisibilityTeam queries are visible to the members of the team they are shared with
+	VisibilityTeam Visibility = "team"
+	// VisibilityOrg queries are visible to every user of the organization
+	VisibilityOrg Visibility = "org"
+)
+
+func (v Visibility) Validate() error {
+	switch v {
+	case VisibilityPrivate, VisibilityTeam, VisibilityOrg:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidVisibility, v)
+}
+
 // QueryHistory is the model for query history definitions
 type QueryHistory struct {
 	ID            int64  `xorm:"pk autoincr 'id'"`
@@ -21,9 +54,19 @@ type QueryHistory struct {
 	CreatedBy     int64
 	CreatedAt     int64
 	Comment       string
+	Title         string
+	Visibility    Visibility `xorm:"visibility"`
+	TeamID        int64      `xorm:"team_id"`
 	Queries       *simplejson.Json
 }
 
+// QueryHistoryTag is the model for query history tags
+type QueryHistoryTag struct {
+	ID                  int64  `xorm:"pk autoincr 'id'"`
+	QueryHistoryItemUID string `xorm:"query_history_item_uid"`
+	Term                string `xorm:"term"`
+}
+
 // QueryHistory is the model for query history star definitions
 type QueryHistoryStar struct {
 	ID       int64  `xorm:"pk autoincr 'id'"`
@@ -32,14 +75,18 @@ type QueryHistoryStar struct {
 }
 
 type SearchInQueryHistoryQuery struct {
-	DatasourceUIDs []string `json:"datasourceUids"`
-	SearchString   string   `json:"searchString"`
-	OnlyStarred    bool     `json:"onlyStarred"`
-	Sort           string   `json:"sort"`
-	Page           int      `json:"page"`
-	Limit          int      `json:"limit"`
-	From           int64    `json:"from"`
-	To             int64    `json:"to"`
+	DatasourceUIDs  []string `json:"datasourceUids"`
+	DatasourceTypes []string `json:"datasourceTypes"`
+	Tags            []string `json:"tags"`
+	SearchString    string   `json:"searchString"`
+	OnlyStarred     bool     `json:"onlyStarred"`
+	// IncludeShared includes queries other users shared with the user's teams or organization
+	IncludeShared bool   `json:"includeShared"`
+	Sort          string `json:"sort"`
+	Page          int    `json:"page"`
+	Limit         int    `json:"limit"`
+	From          int64  `json:"from"`
+	To            int64  `json:"to"`
 }
 
 type QueryHistoryDTO struct {
@@ -48,6 +95,10 @@ type QueryHistoryDTO struct {
 	CreatedBy     int64            `json:"createdBy"`
 	CreatedAt     int64            `json:"createdAt"`
 	Comment       string           `json:"comment"`
+	Title         string           `json:"title"`
+	Tags          []string         `json:"tags" xorm:"-"`
+	Visibility    Visibility       `json:"visibility" xorm:"visibility"`
+	TeamID        int64            `json:"teamId,omitempty" xorm:"team_id"`
 	Queries       *simplejson.Json `json:"queries"`
 	Starred       bool             `json:"starred"`
 }
@@ -91,3 +142,58 @@ type PatchQueryCommentInQueryHistoryCommand struct {
 	// Updated comment
 	Comment string `json:"comment"`
 }
+
+// UpdateQueryMetadataInQueryHistoryCommand is the command for updating title and tags of query in query history
+// swagger:model
+type UpdateQueryMetadataInQueryHistoryCommand struct {
+	// Title of the query
+	// example: Error rate by service
+	Title string `json:"title"`
+	// Tags of the query. Existing tags are replaced.
+	// example: ["on-call","errors"]
+	Tags []string `json:"tags"`
+}
+
+func (cmd *UpdateQueryMetadataInQueryHistoryCommand) Validate() error {
+	cmd.Title = strings.TrimSpace(cmd.Title)
+	if len(cmd.Title) > maxTitleLength {
+		return ErrTitleTooLong
+	}
+
+	tags := make([]string, 0, len(cmd.Tags))
+	seen := make(map[string]bool, len(cmd.Tags))
+	for _, tag := range cmd.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return ErrTagTooLong
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	cmd.Tags = tags
+	return nil
+}
+
+// ShareQueryInQueryHistoryCommand is the command for sharing query in query history with a team or the organization
+// swagger:model
+type ShareQueryInQueryHistoryCommand struct {
+	// Who can see the query. Use "private" to stop sharing.
+	// required: true
+	// Enum: private,team,org
+	Visibility Visibility `json:"visibility"`
+	// ID of the team the query is shared with. Required when visibility is "team".
+	TeamID int64 `json:"teamId"`
+}
+
+func (cmd ShareQueryInQueryHistoryCommand) Validate() error {
+	if err := cmd.Visibility.Validate(); err != nil {
+		return err
+	}
+	if cmd.Visibility == VisibilityTeam && cmd.TeamID <= 0 {
+		return ErrTeamRequired
+	}
+	return nil
+}
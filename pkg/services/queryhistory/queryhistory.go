@@ -12,7 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl) *QueryHistoryService {
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl, accessControlService ac.Service) (*QueryHistoryService, error) {
 	s := &QueryHistoryService{
 		store:         sqlStore,
 		Cfg:           cfg,
@@ -22,12 +22,16 @@ func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.Rout
 		accessControl: accessControl,
 	}
 
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+
 	// Register routes only when query history is enabled
 	if s.Cfg.QueryHistoryEnabled {
 		s.registerAPIEndpoints()
 	}
 
-	return s
+	return s, nil
 }
 
 type Service interface {
@@ -35,6 +39,8 @@ type Service interface {
 	SearchInQueryHistory(ctx context.Context, user *user.SignedInUser, query SearchInQueryHistoryQuery) (QueryHistorySearchResult, error)
 	DeleteQueryFromQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (int64, error)
 	PatchQueryCommentInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error)
+	UpdateQueryMetadataInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd UpdateQueryMetadataInQueryHistoryCommand) (QueryHistoryDTO, error)
+	ShareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error)
 	StarQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error)
 	UnstarQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error)
 	DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error)
@@ -66,6 +72,14 @@ func (s QueryHistoryService) PatchQueryCommentInQueryHistory(ctx context.Context
 	return s.patchQueryComment(ctx, user, UID, cmd)
 }
 
+func (s QueryHistoryService) UpdateQueryMetadataInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd UpdateQueryMetadataInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.updateQueryMetadata(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) ShareQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd ShareQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.shareQuery(ctx, user, UID, cmd)
+}
+
 func (s QueryHistoryService) StarQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error) {
 	return s.starQuery(ctx, user, UID)
 }
@@ -0,0 +1,95 @@
+package queryhistory
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationUpdateQueryMetadataInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update metadata of query in query history that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Title: "test title"})
+			resp := sc.service.updateMetadataHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update metadata of query in query history that exists, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			cmd := UpdateQueryMetadataInQueryHistoryCommand{Title: " Error rate ", Tags: []string{"on-call", "errors", "on-call", " "}}
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(cmd)
+			resp := sc.service.updateMetadataHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, "Error rate", result.Result.Title)
+			require.Equal(t, []string{"errors", "on-call"}, result.Result.Tags)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update query in query history with a too long title, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Title: strings.Repeat("a", maxTitleLength+1)})
+			resp := sc.service.updateMetadataHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to update query in query history with a too long tag, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Tags: []string{strings.Repeat("a", maxTagLength+1)}})
+			resp := sc.service.updateMetadataHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user replaces tags of query in query history, old tags should be removed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Tags: []string{"old"}})
+			validateAndUnMarshalResponse(t, sc.service.updateMetadataHandler(sc.reqContext))
+
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Tags: []string{"new"}})
+			result := validateAndUnMarshalResponse(t, sc.service.updateMetadataHandler(sc.reqContext))
+			require.Equal(t, []string{"new"}, result.Result.Tags)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When users tries to get queries with tag, it should return only tagged queries",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Title: "Runbook query", Tags: []string{"on-call", "errors"}})
+			validateAndUnMarshalResponse(t, sc.service.updateMetadataHandler(sc.reqContext))
+
+			sc.reqContext.Req.Form.Add("tag", "on-call")
+			sc.reqContext.Req.Form.Add("tag", "errors")
+			resp := sc.service.searchHandler(sc.reqContext)
+			var response QueryHistorySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, sc.initialResult.Result.UID, response.Result.QueryHistory[0].UID)
+			require.Equal(t, "Runbook query", response.Result.QueryHistory[0].Title)
+			require.Equal(t, []string{"errors", "on-call"}, response.Result.QueryHistory[0].Tags)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When users tries to get queries by title, it should return matching queries",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(UpdateQueryMetadataInQueryHistoryCommand{Title: "Runbook query"})
+			validateAndUnMarshalResponse(t, sc.service.updateMetadataHandler(sc.reqContext))
+
+			sc.reqContext.Req.Form.Add("searchString", "Runbook")
+			resp := sc.service.searchHandler(sc.reqContext)
+			var response QueryHistorySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 1, response.Result.TotalCount)
+		})
+}
@@ -0,0 +1,111 @@
+package queryhistory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationShareQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user tries to share query in query history with the organization, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{Visibility: VisibilityOrg})
+			resp := sc.service.shareHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, VisibilityOrg, result.Result.Visibility)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to share query in query history with unknown visibility, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{Visibility: "everyone"})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to share query in query history with a team without a team id, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{Visibility: VisibilityTeam})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to share query in query history with a team they are not member of, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{Visibility: VisibilityTeam, TeamID: 42})
+			resp := sc.service.shareHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When query in query history is shared with the organization, other users can find it",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{Visibility: VisibilityOrg})
+			validateAndUnMarshalResponse(t, sc.service.shareHandler(sc.reqContext))
+
+			sc.reqContext.SignedInUser = &user.SignedInUser{UserID: testUserID + 1, OrgID: testOrgID, OrgRole: org.RoleEditor}
+
+			resp := sc.service.searchHandler(sc.reqContext)
+			var response QueryHistorySearchResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &response))
+			require.Equal(t, 0, response.Result.TotalCount)
+
+			sc.reqContext.Req.Form.Add("includeShared", "true")
+			resp = sc.service.searchHandler(sc.reqContext)
+			require.NoError(t, json.Unmarshal(resp.Body(), &response))
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, VisibilityOrg, response.Result.QueryHistory[0].Visibility)
+			require.False(t, response.Result.QueryHistory[0].Starred)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When query in query history is shared, other users can star it but not edit it",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(ShareQueryInQueryHistoryCommand{Visibility: VisibilityOrg})
+			validateAndUnMarshalResponse(t, sc.service.shareHandler(sc.reqContext))
+
+			sc.reqContext.SignedInUser = &user.SignedInUser{UserID: testUserID + 1, OrgID: testOrgID, OrgRole: org.RoleEditor}
+
+			result := validateAndUnMarshalResponse(t, sc.service.starHandler(sc.reqContext))
+			require.True(t, result.Result.Starred)
+
+			sc.reqContext.Req.Body = mockRequestBody(PatchQueryCommentInQueryHistoryCommand{Comment: "not mine"})
+			resp := sc.service.patchCommentHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+
+			resp = sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When query in query history is private, other users can not star it",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.SignedInUser = &user.SignedInUser{UserID: testUserID + 1, OrgID: testOrgID, OrgRole: org.RoleEditor}
+
+			resp := sc.service.starHandler(sc.reqContext)
+			require.Equal(t, 500, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "Stale shared query history can not be deleted",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.ShareQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID, ShareQueryInQueryHistoryCommand{Visibility: VisibilityOrg})
+			require.NoError(t, err)
+
+			olderThan := sc.service.now().Unix() + 60
+			rowsDeleted, err := sc.service.DeleteStaleQueriesInQueryHistory(context.Background(), olderThan)
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+		})
+}
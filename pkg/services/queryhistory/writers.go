@@ -8,7 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
-func writeStarredSQL(query SearchInQueryHistoryQuery, sqlStore db.DB, builder *db.SQLBuilder, isCount bool) {
+func writeStarredSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, sqlStore db.DB, builder *db.SQLBuilder, isCount bool) {
 	var sql bytes.Buffer
 	if isCount {
 		sql.WriteString(`COUNT(`)
@@ -29,14 +29,28 @@ func writeStarredSQL(query SearchInQueryHistoryQuery, sqlStore db.DB, builder *d
 		sql.WriteString(`LEFT`)
 	}
 
-	sql.WriteString(` JOIN query_history_star ON query_history_star.query_uid = query_history.uid `)
-	builder.Write(sql.String())
+	// Shared queries can be starred by many users, only the stars of the current user are relevant
+	sql.WriteString(` JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ? `)
+	builder.Write(sql.String(), user.UserID)
 }
 
 func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, sqlStore db.DB, builder *db.SQLBuilder) {
-	params := []any{user.OrgID, user.UserID, query.From, query.To, "%" + query.SearchString + "%", "%" + query.SearchString + "%"}
+	params := []any{user.OrgID}
 	var sql bytes.Buffer
-	sql.WriteString(" WHERE query_history.org_id = ? AND query_history.created_by = ? AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.GetDialect().LikeStr() + " ? OR query_history.comment " + sqlStore.GetDialect().LikeStr() + " ?) ")
+	sql.WriteString(" WHERE query_history.org_id = ? AND ")
+
+	if query.IncludeShared {
+		visibleSQL, visibleParams := visibleToUserSQL(user)
+		sql.WriteString(visibleSQL)
+		params = append(params, visibleParams...)
+	} else {
+		sql.WriteString("query_history.created_by = ?")
+		params = append(params, user.UserID)
+	}
+
+	searchString := "%" + query.SearchString + "%"
+	params = append(params, query.From, query.To, searchString, searchString, searchString)
+	sql.WriteString(" AND query_history.created_at >= ? AND query_history.created_at <= ? AND (query_history.queries " + sqlStore.GetDialect().LikeStr() + " ? OR query_history.comment " + sqlStore.GetDialect().LikeStr() + " ? OR query_history.title " + sqlStore.GetDialect().LikeStr() + " ?) ")
 
 	if len(query.DatasourceUIDs) > 0 {
 		q := "?" + strings.Repeat(",?", len(query.DatasourceUIDs)-1)
@@ -52,9 +66,38 @@ func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, s
 		sql.WriteString("(query_history.uid IN (SELECT i.query_history_item_uid from query_history_details i WHERE i.datasource_uid IN (" + q + ")))")
 		sql.WriteString(")")
 	}
+
+	if len(query.DatasourceTypes) > 0 {
+		q := "?" + strings.Repeat(",?", len(query.DatasourceTypes)-1)
+		params = append(params, user.OrgID)
+		for _, dsType := range query.DatasourceTypes {
+			params = append(params, dsType)
+		}
+		params = append(params, user.OrgID)
+		for _, dsType := range query.DatasourceTypes {
+			params = append(params, dsType)
+		}
+		sql.WriteString(" AND (")
+		sql.WriteString("(query_history.datasource_uid IN (SELECT ds.uid FROM data_source ds WHERE ds.org_id = ? AND ds.type IN (" + q + ")))")
+		sql.WriteString(" OR ")
+		sql.WriteString("(query_history.uid IN (SELECT i.query_history_item_uid FROM query_history_details i INNER JOIN data_source ds ON ds.uid = i.datasource_uid WHERE ds.org_id = ? AND ds.type IN (" + q + ")))")
+		sql.WriteString(")")
+	}
+
+	// queries have to match all the given tags
+	for _, tag := range query.Tags {
+		params = append(params, tag)
+		sql.WriteString(" AND query_history.uid IN (SELECT t.query_history_item_uid FROM query_history_tag t WHERE t.term = ?)")
+	}
 	builder.Write(sql.String(), params...)
 }
 
+// visibleToUserSQL returns the condition matching queries created by the user or shared with the user's organization or teams
+func visibleToUserSQL(user *user.SignedInUser) (string, []any) {
+	sql := "(query_history.created_by = ? OR query_history.visibility = ? OR (query_history.visibility = ? AND query_history.team_id IN (SELECT tm.team_id FROM team_member tm WHERE tm.org_id = ? AND tm.user_id = ?)))"
+	return sql, []any{user.UserID, VisibilityOrg, VisibilityTeam, user.OrgID, user.UserID}
+}
+
 func writeSortSQL(query SearchInQueryHistoryQuery, sqlStore db.DB, builder *db.SQLBuilder) {
 	if query.Sort == "time-asc" {
 		builder.Write(" ORDER BY created_at ASC ")
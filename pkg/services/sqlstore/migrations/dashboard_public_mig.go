@@ -96,4 +96,62 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add query_rate_limit column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "query_rate_limit",
+		Type:     DB_Int,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add max_concurrent_queries column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "max_concurrent_queries",
+		Type:     DB_Int,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	dashboardPublicUsageV1 := Table{
+		Name: "dashboard_public_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "bucket", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "queries", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "errors", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "rate_limited", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "bucket"}, Type: UniqueIndex},
+			{Cols: []string{"bucket"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public usage table v1", NewAddTableMigration(dashboardPublicUsageV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicUsageV1)
+
+	dashboardPublicTokenV1 := Table{
+		Name: "dashboard_public_token",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "label", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''"},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+			{Name: "revoked_by", Type: DB_BigInt, Nullable: true},
+			{Name: "revoked_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "public_dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public token table v1", NewAddTableMigration(dashboardPublicTokenV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicTokenV1)
 }
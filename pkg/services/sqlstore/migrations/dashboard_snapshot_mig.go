@@ -71,4 +71,41 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add column schedule_uid to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "schedule_uid", Type: DB_NVarchar, Length: 40, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("Add index dashboard_snapshot.org_id-schedule_uid", NewAddIndexMigration(snapshotV5, &Index{
+		Cols: []string{"org_id", "schedule_uid"},
+	}))
+
+	snapshotScheduleV1 := Table{
+		Name: "dashboard_snapshot_schedule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "cron", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "keep_last", Type: DB_Int, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "last_run", Type: DB_DateTime, Nullable: true},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+			{Cols: []string{"enabled"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_snapshot_schedule table v1", NewAddTableMigration(snapshotScheduleV1))
+	addTableIndicesMigrations(mg, "v1", snapshotScheduleV1)
 }
@@ -351,6 +351,8 @@ type Cfg struct {
 
 	// Only used in https://snapshots.raintank.io/
 	SnapshotPublicMode bool
+	// ScheduledSnapshotsEnabled enables the creation of snapshots on a cron schedule
+	ScheduledSnapshotsEnabled bool
 
 	ErrTemplateName string
 
@@ -493,6 +495,12 @@ type Cfg struct {
 
 	// Public dashboards
 	PublicDashboardsEnabled bool
+	// Default number of queries per minute allowed for each public dashboard access token, 0 means unlimited
+	PublicDashboardsQueryRateLimit int
+	// Default number of queries allowed to run at the same time for each public dashboard access token, 0 means unlimited
+	PublicDashboardsMaxConcurrentQueries int
+	// How long the usage of public dashboards is kept
+	PublicDashboardsUsageRetention time.Duration
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
@@ -1873,6 +1881,7 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 
 	cfg.ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.ScheduledSnapshotsEnabled = snapshots.Key("scheduled_enabled").MustBool(false)
 
 	return nil
 }
@@ -2050,4 +2059,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsQueryRateLimit = publicDashboards.Key("query_rate_limit").MustInt(0)
+	cfg.PublicDashboardsMaxConcurrentQueries = publicDashboards.Key("max_concurrent_queries").MustInt(0)
+	cfg.PublicDashboardsUsageRetention = publicDashboards.Key("usage_retention").MustDuration(90 * 24 * time.Hour)
 }
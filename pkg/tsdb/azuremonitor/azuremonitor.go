@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/azmoncredentials"
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/costmanagement"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/loganalytics"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/metrics"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/resourcegraph"
@@ -33,11 +34,12 @@ func ProvideService(httpClientProvider *httpclient.Provider) *Service {
 		logger: logger,
 	}
 	executors := map[string]azDatasourceExecutor{
-		azureMonitor:       &metrics.AzureMonitorDatasource{Proxy: proxy, Logger: logger},
-		azureLogAnalytics:  &loganalytics.AzureLogAnalyticsDatasource{Proxy: proxy, Logger: logger},
-		azureResourceGraph: &resourcegraph.AzureResourceGraphDatasource{Proxy: proxy, Logger: logger},
-		azureTraces:        &loganalytics.AzureLogAnalyticsDatasource{Proxy: proxy, Logger: logger},
-		traceExemplar:      &loganalytics.AzureLogAnalyticsDatasource{Proxy: proxy, Logger: logger},
+		azureMonitor:        &metrics.AzureMonitorDatasource{Proxy: proxy, Logger: logger},
+		azureLogAnalytics:   &loganalytics.AzureLogAnalyticsDatasource{Proxy: proxy, Logger: logger},
+		azureResourceGraph:  &resourcegraph.AzureResourceGraphDatasource{Proxy: proxy, Logger: logger},
+		azureTraces:         &loganalytics.AzureLogAnalyticsDatasource{Proxy: proxy, Logger: logger},
+		traceExemplar:       &loganalytics.AzureLogAnalyticsDatasource{Proxy: proxy, Logger: logger},
+		azureCostManagement: &costmanagement.AzureCostManagementDatasource{Proxy: proxy, Logger: logger},
 	}
 
 	im := datasource.NewInstanceManager(NewInstanceSettings(httpClientProvider, executors, logger))
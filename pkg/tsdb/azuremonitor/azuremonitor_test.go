@@ -52,6 +52,11 @@ var testRoutes = map[string]types.AzRoute{
 	azurePortal: {
 		URL: "https://portal.azure.com",
 	},
+	azureCostManagement: {
+		URL:     "https://management.azure.com",
+		Scopes:  []string{"https://management.azure.com/.default"},
+		Headers: map[string]string{"x-ms-app": "Grafana"},
+	},
 }
 
 func TestNewInstanceSettings(t *testing.T) {
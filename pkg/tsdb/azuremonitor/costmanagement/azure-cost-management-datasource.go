@@ -0,0 +1,345 @@
+package costmanagement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/types"
+)
+
+const CostManagementAPIVersion = "2023-03-01"
+const costManagementQueryProviderName = "/providers/Microsoft.CostManagement/query"
+
+// maxResultPages limits the pages of results read for a single query
+const maxResultPages = 50
+
+// AzureCostManagementDatasource calls the Azure Cost Management query API
+type AzureCostManagementDatasource struct {
+	Proxy  types.ServiceProxy
+	Logger log.Logger
+}
+
+// costManagementJSONQuery is the model of the cost queries, for example:
+//
+//	{"azureCostManagement": {"scope": "/subscriptions/<id>", "granularity": "Daily", "groupBy": {"type": "Dimension", "name": "ServiceName"}}}
+type costManagementJSONQuery struct {
+	AzureCostManagement struct {
+		// The scope of the costs, the default subscription of the data source when empty
+		Scope string `json:"scope"`
+		// ActualCost by default, or AmortizedCost or Usage
+		CostType string `json:"costType"`
+		// Daily by default, or Monthly
+		Granularity string `json:"granularity"`
+		// Groups the costs by a Dimension, such as ServiceName, or by a TagKey. The costs are totaled when empty.
+		GroupBy *costManagementGrouping `json:"groupBy"`
+	} `json:"azureCostManagement"`
+}
+
+type costManagementGrouping struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// AzureCostManagementResponse is the json response object from the Azure Cost Management query API
+type AzureCostManagementResponse struct {
+	Properties struct {
+		NextLink *string `json:"nextLink"`
+		Columns  []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"columns"`
+		Rows [][]any `json:"rows"`
+	} `json:"properties"`
+}
+
+func (e *AzureCostManagementDatasource) ResourceRequest(rw http.ResponseWriter, req *http.Request, cli *http.Client) (http.ResponseWriter, error) {
+	return e.Proxy.Do(rw, req, cli)
+}
+
+// ExecuteTimeSeriesQuery queries the costs of each query and returns a cost over time frame for each group of costs
+func (e *AzureCostManagementDatasource) ExecuteTimeSeriesQuery(ctx context.Context, originalQueries []backend.DataQuery, dsInfo types.DatasourceInfo, client *http.Client, url string, fromAlert bool) (*backend.QueryDataResponse, error) {
+	result := backend.NewQueryDataResponse()
+
+	for _, query := range originalQueries {
+		frames, err := e.executeQuery(ctx, query, dsInfo, client, url)
+		if err != nil {
+			errorsource.AddErrorToResponse(query.RefID, result, err)
+			continue
+		}
+		result.Responses[query.RefID] = backend.DataResponse{Frames: frames}
+	}
+
+	return result, nil
+}
+
+func (e *AzureCostManagementDatasource) executeQuery(ctx context.Context, query backend.DataQuery, dsInfo types.DatasourceInfo, client *http.Client, dsURL string) (data.Frames, error) {
+	model := costManagementJSONQuery{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return nil, fmt.Errorf("failed to decode the Azure Cost Management query object from JSON: %w", err)
+	}
+	costQuery := model.AzureCostManagement
+
+	scope := costQuery.Scope
+	if scope == "" {
+		if dsInfo.Settings.SubscriptionId == "" {
+			return nil, errorsource.DownstreamError(fmt.Errorf("a scope or a default subscription is required to query costs"), false)
+		}
+		scope = "/subscriptions/" + dsInfo.Settings.SubscriptionId
+	}
+	if costQuery.CostType == "" {
+		costQuery.CostType = "ActualCost"
+	}
+	if costQuery.Granularity == "" {
+		costQuery.Granularity = "Daily"
+	}
+
+	dataset := map[string]any{
+		"granularity": costQuery.Granularity,
+		"aggregation": map[string]any{
+			"totalCost": map[string]string{"name": "Cost", "function": "Sum"},
+		},
+	}
+	if costQuery.GroupBy != nil && costQuery.GroupBy.Name != "" {
+		dataset["grouping"] = []costManagementGrouping{*costQuery.GroupBy}
+	}
+	reqBody, err := json.Marshal(map[string]any{
+		"type":      costQuery.CostType,
+		"timeframe": "Custom",
+		"timePeriod": map[string]string{
+			"from": query.TimeRange.From.UTC().Format(time.RFC3339),
+			"to":   query.TimeRange.To.UTC().Format(time.RFC3339),
+		},
+		"dataset": dataset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "failed to create request", err)
+	}
+	req.URL.Path = path.Join("/", scope, costManagementQueryProviderName)
+	req.URL.RawQuery = "api-version=" + CostManagementAPIVersion
+	queryURL := req.URL.String()
+
+	_, span := tracing.DefaultTracer().Start(ctx, "azure cost management query", trace.WithAttributes(
+		attribute.String("scope", scope),
+		attribute.Int64("from", query.TimeRange.From.UnixNano()/int64(time.Millisecond)),
+		attribute.Int64("until", query.TimeRange.To.UnixNano()/int64(time.Millisecond)),
+		attribute.Int64("datasource_id", dsInfo.DatasourceID),
+		attribute.Int64("org_id", dsInfo.OrgID),
+	))
+	defer span.End()
+
+	series := newCostSeries()
+	for pages := 0; queryURL != ""; pages++ {
+		if pages == maxResultPages {
+			return nil, errorsource.DownstreamError(fmt.Errorf("the costs have more than %d pages, narrow down the time range or the grouping", maxResultPages), false)
+		}
+		costResponse, err := e.postQuery(ctx, client, queryURL, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		if err := series.addRows(costResponse, costQuery.GroupBy); err != nil {
+			return nil, err
+		}
+		queryURL = ""
+		if costResponse.Properties.NextLink != nil {
+			queryURL = *costResponse.Properties.NextLink
+		}
+	}
+
+	return series.frames(), nil
+}
+
+func (e *AzureCostManagementDatasource) postQuery(ctx context.Context, client *http.Client, queryURL string, reqBody []byte) (AzureCostManagementResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, queryURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return AzureCostManagementResponse{}, fmt.Errorf("%v: %w", "failed to create request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return AzureCostManagementResponse{}, errorsource.DownstreamError(err, false)
+	}
+	return e.unmarshalResponse(res)
+}
+
+func (e *AzureCostManagementDatasource) unmarshalResponse(res *http.Response) (AzureCostManagementResponse, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return AzureCostManagementResponse{}, err
+	}
+
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			e.Logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		return AzureCostManagementResponse{}, errorsource.SourceError(backend.ErrorSourceFromHTTPStatus(res.StatusCode), fmt.Errorf("%s. Azure Cost Management error: %s", res.Status, string(body)), false)
+	}
+
+	var data AzureCostManagementResponse
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&data); err != nil {
+		return AzureCostManagementResponse{}, err
+	}
+
+	return data, nil
+}
+
+// costSeries collects the costs of each group over time
+type costSeries struct {
+	groups map[string]*costGroup
+}
+
+type costGroup struct {
+	labels   data.Labels
+	currency string
+	times    []time.Time
+	costs    []float64
+}
+
+func newCostSeries() *costSeries {
+	return &costSeries{groups: map[string]*costGroup{}}
+}
+
+// addRows adds the rows of a response, the columns are the cost, the date, the grouping and the currency
+func (s *costSeries) addRows(response AzureCostManagementResponse, grouping *costManagementGrouping) error {
+	columns := map[string]int{}
+	for i, column := range response.Properties.Columns {
+		columns[column.Name] = i
+	}
+	costColumn, ok := columns["Cost"]
+	if !ok {
+		costColumn, ok = columns["PreTaxCost"]
+	}
+	if !ok {
+		return fmt.Errorf("the costs response has no cost column")
+	}
+
+	for _, row := range response.Properties.Rows {
+		t, err := rowTime(row, columns)
+		if err != nil {
+			return err
+		}
+		cost, err := rowFloat(row, costColumn)
+		if err != nil {
+			return err
+		}
+		labels := rowLabels(row, columns, grouping)
+
+		// costs in different currencies are separate series, they cannot be added up
+		currency := ""
+		if i, ok := columns["Currency"]; ok && i < len(row) {
+			currency, _ = row[i].(string)
+		}
+		key := labels.String() + " " + currency
+		group, ok := s.groups[key]
+		if !ok {
+			group = &costGroup{labels: labels, currency: currency}
+			s.groups[key] = group
+		}
+		group.times = append(group.times, t)
+		group.costs = append(group.costs, cost)
+	}
+	return nil
+}
+
+// rowTime returns the date of a row, daily costs have a UsageDate number such as 20240101 and monthly costs a
+// BillingMonth timestamp
+func rowTime(row []any, columns map[string]int) (time.Time, error) {
+	if i, ok := columns["UsageDate"]; ok && i < len(row) {
+		return time.Parse("20060102", fmt.Sprint(row[i]))
+	}
+	if i, ok := columns["BillingMonth"]; ok && i < len(row) {
+		value := fmt.Sprint(row[i])
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02T15:04:05", value)
+	}
+	return time.Time{}, fmt.Errorf("the costs response has no date column")
+}
+
+func rowFloat(row []any, i int) (float64, error) {
+	if i >= len(row) {
+		return 0, fmt.Errorf("the costs response has a row without a cost")
+	}
+	switch v := row[i].(type) {
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	default:
+		return strconv.ParseFloat(fmt.Sprint(v), 64)
+	}
+}
+
+// rowLabels returns the labels of a row, costs grouped by ServiceName are labeled with service and costs grouped by
+// tag with the tag key
+func rowLabels(row []any, columns map[string]int, grouping *costManagementGrouping) data.Labels {
+	labels := data.Labels{}
+	if grouping == nil || grouping.Name == "" {
+		return labels
+	}
+	value := func(column string) string {
+		if i, ok := columns[column]; ok && i < len(row) && row[i] != nil {
+			return fmt.Sprint(row[i])
+		}
+		return ""
+	}
+	switch {
+	case strings.EqualFold(grouping.Type, "TagKey"):
+		labels[grouping.Name] = value("TagValue")
+	case grouping.Name == "ServiceName":
+		labels["service"] = value(grouping.Name)
+	default:
+		labels[grouping.Name] = value(grouping.Name)
+	}
+	return labels
+}
+
+// frames returns a cost over time frame for each group, sorted by their labels
+func (s *costSeries) frames() data.Frames {
+	keys := make([]string, 0, len(s.groups))
+	for key := range s.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		group := s.groups[key]
+		costField := data.NewField("cost", group.labels, group.costs)
+		if group.currency != "" {
+			costField.Config = &data.FieldConfig{Unit: "currency" + group.currency}
+		}
+		frame := data.NewFrame("", data.NewField(data.TimeSeriesTimeFieldName, nil, group.times), costField)
+		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti}
+		frames = append(frames, frame)
+	}
+	return frames
+}
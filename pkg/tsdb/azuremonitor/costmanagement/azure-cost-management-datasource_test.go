@@ -0,0 +1,133 @@
+package costmanagement
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/types"
+)
+
+func TestExecuteTimeSeriesQuery(t *testing.T) {
+	ds := &AzureCostManagementDatasource{Logger: log.NewNullLogger()}
+	dsInfo := types.DatasourceInfo{
+		Settings: types.AzureMonitorSettings{SubscriptionId: "sub-1"},
+	}
+	query := func(model string) []backend.DataQuery {
+		return []backend.DataQuery{{
+			RefID: "A",
+			TimeRange: backend.TimeRange{
+				From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			JSON: []byte(model),
+		}}
+	}
+
+	t.Run("returns the costs grouped by service over time", func(t *testing.T) {
+		var bodies []map[string]any
+		var svr *httptest.Server
+		svr = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/subscriptions/sub-1/providers/Microsoft.CostManagement/query", r.URL.Path)
+			require.Equal(t, CostManagementAPIVersion, r.URL.Query().Get("api-version"))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			model := map[string]any{}
+			require.NoError(t, json.Unmarshal(body, &model))
+			bodies = append(bodies, model)
+
+			columns := `[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"ServiceName","type":"String"},{"name":"Currency","type":"String"}]`
+			if r.URL.Query().Get("page") == "" {
+				_, _ = w.Write([]byte(`{"properties":{"nextLink":"` + svr.URL + r.URL.Path + `?api-version=` + CostManagementAPIVersion + `&page=2","columns":` + columns + `,
+					"rows":[[1.5,20240101,"Storage","USD"],[0.5,20240101,"Functions","USD"]]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"properties":{"nextLink":null,"columns":` + columns + `,"rows":[[2,20240102,"Storage","USD"]]}}`))
+		}))
+		defer svr.Close()
+
+		res, err := ds.ExecuteTimeSeriesQuery(context.Background(), query(`{
+			"queryType": "Azure Cost Management",
+			"azureCostManagement": {"groupBy": {"type": "Dimension", "name": "ServiceName"}}
+		}`), dsInfo, svr.Client(), svr.URL, false)
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["A"].Error)
+
+		require.Len(t, bodies, 2)
+		assert.Equal(t, "ActualCost", bodies[0]["type"])
+		assert.Equal(t, map[string]any{"from": "2024-01-01T00:00:00Z", "to": "2024-01-03T00:00:00Z"}, bodies[0]["timePeriod"])
+		assert.Equal(t, "Daily", bodies[0]["dataset"].(map[string]any)["granularity"])
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		assert.Equal(t, data.Labels{"service": "Functions"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, data.Labels{"service": "Storage"}, frames[1].Fields[1].Labels)
+		assert.Equal(t, "currencyUSD", frames[1].Fields[1].Config.Unit)
+		require.Equal(t, 2, frames[1].Rows())
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), frames[1].Fields[0].At(1))
+		assert.Equal(t, 2.0, frames[1].Fields[1].At(1))
+	})
+
+	t.Run("labels the costs grouped by tag with the tag key", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/subscriptions/sub-2/providers/Microsoft.CostManagement/query", r.URL.Path)
+			_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"BillingMonth","type":"Datetime"},{"name":"TagKey","type":"String"},{"name":"TagValue","type":"String"},{"name":"Currency","type":"String"}],
+				"rows":[[3,"2024-01-01T00:00:00","team","payments","EUR"]]}}`))
+		}))
+		defer svr.Close()
+
+		res, err := ds.ExecuteTimeSeriesQuery(context.Background(), query(`{
+			"queryType": "Azure Cost Management",
+			"azureCostManagement": {"scope": "/subscriptions/sub-2", "granularity": "Monthly", "groupBy": {"type": "TagKey", "name": "team"}}
+		}`), dsInfo, svr.Client(), svr.URL, false)
+		require.NoError(t, err)
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		assert.Equal(t, data.Labels{"team": "payments"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, "currencyEUR", frames[0].Fields[1].Config.Unit)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), frames[0].Fields[0].At(0))
+		assert.Equal(t, 3.0, frames[0].Fields[1].At(0))
+	})
+
+	t.Run("returns the costs in each currency as separate series", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"ServiceName","type":"String"},{"name":"Currency","type":"String"}],
+				"rows":[[1.5,20240101,"Storage","USD"],[2,20240101,"Storage","EUR"]]}}`))
+		}))
+		defer svr.Close()
+
+		res, err := ds.ExecuteTimeSeriesQuery(context.Background(), query(`{
+			"queryType": "Azure Cost Management",
+			"azureCostManagement": {"groupBy": {"type": "Dimension", "name": "ServiceName"}}
+		}`), dsInfo, svr.Client(), svr.URL, false)
+		require.NoError(t, err)
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		assert.Equal(t, "currencyEUR", frames[0].Fields[1].Config.Unit)
+		assert.Equal(t, 2.0, frames[0].Fields[1].At(0))
+		assert.Equal(t, "currencyUSD", frames[1].Fields[1].Config.Unit)
+		assert.Equal(t, 1.5, frames[1].Fields[1].At(0))
+	})
+
+	t.Run("returns the API errors", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer svr.Close()
+
+		res, err := ds.ExecuteTimeSeriesQuery(context.Background(), query(`{"azureCostManagement": {}}`), dsInfo, svr.Client(), svr.URL, false)
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Responses["A"].Error, "Azure Cost Management error")
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+	})
+}
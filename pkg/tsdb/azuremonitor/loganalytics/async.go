@@ -0,0 +1,245 @@
+package loganalytics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/types"
+)
+
+const (
+	defaultAsyncTimeout      = 10 * time.Minute
+	defaultAsyncPollInterval = 2 * time.Second
+	cancelOperationTimeout   = 10 * time.Second
+	// maxResultPages limits the pages of results read for a single query
+	maxResultPages = 100
+)
+
+// asyncSettings configure the async query mode of the data source
+type asyncSettings struct {
+	enabled bool
+	// timeout for a query to complete, including the pages of its results
+	timeout time.Duration
+}
+
+func getAsyncSettings(dsInfo types.DatasourceInfo) asyncSettings {
+	settings := asyncSettings{timeout: defaultAsyncTimeout}
+	if enabled, ok := dsInfo.JSONData["logAnalyticsAsyncQueries"].(bool); ok {
+		settings.enabled = enabled
+	}
+	if timeout, ok := dsInfo.JSONData["logAnalyticsAsyncTimeout"].(string); ok && timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil && d > 0 {
+			settings.timeout = d
+		}
+	}
+	return settings
+}
+
+// executeAsyncRequest sends a query that the API may run asynchronously. When the API accepts the query the operation
+// in the Location header is polled until it completes, and it is cancelled when the query is. Results larger than a
+// response are read from the nextLink of each page. When the results cannot be read fully the rows read so far are
+// returned with a warning notice.
+func (e *AzureLogAnalyticsDatasource) executeAsyncRequest(ctx context.Context, client *http.Client, req *http.Request, settings asyncSettings) (AzureLogAnalyticsResponse, []data.Notice, error) {
+	deadline := time.Now().Add(settings.timeout)
+	req.Header.Set("Prefer", fmt.Sprintf("respond-async, wait=%d", int(settings.timeout.Seconds())))
+
+	reqCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	res, err := client.Do(req.WithContext(reqCtx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return AzureLogAnalyticsResponse{}, nil, ctx.Err()
+		}
+		if reqCtx.Err() == context.DeadlineExceeded {
+			return AzureLogAnalyticsResponse{}, nil, errorsource.DownstreamError(fmt.Errorf("the query did not complete within %s", settings.timeout), false)
+		}
+		return AzureLogAnalyticsResponse{}, nil, errorsource.DownstreamError(err, false)
+	}
+	if res.StatusCode == http.StatusAccepted {
+		res, err = e.pollOperation(ctx, client, req.URL, res, deadline, settings.timeout)
+		if err != nil {
+			return AzureLogAnalyticsResponse{}, nil, err
+		}
+	}
+
+	logResponse, err := e.unmarshalResponse(res)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, nil, err
+	}
+
+	notices := []data.Notice{}
+	for pages := 1; logResponse.NextLink != nil && *logResponse.NextLink != ""; pages++ {
+		if pages >= maxResultPages {
+			notices = append(notices, partialResultsNotice(fmt.Sprintf("the results have more than %d pages", maxResultPages)))
+			break
+		}
+		page, err := e.getResultPage(ctx, client, req.URL, *logResponse.NextLink, deadline)
+		if err != nil {
+			if ctx.Err() != nil {
+				return AzureLogAnalyticsResponse{}, nil, ctx.Err()
+			}
+			notices = append(notices, partialResultsNotice(err.Error()))
+			break
+		}
+		appendResultPage(&logResponse, page)
+	}
+
+	return logResponse, notices, nil
+}
+
+// pollOperation polls the operation of an accepted query until it completes and returns its response
+func (e *AzureLogAnalyticsDatasource) pollOperation(ctx context.Context, client *http.Client, queryURL *url.URL, accepted *http.Response, deadline time.Time, timeout time.Duration) (*http.Response, error) {
+	location := accepted.Header.Get("Location")
+	if location == "" {
+		location = accepted.Header.Get("Azure-AsyncOperation")
+	}
+	interval := retryAfter(accepted.Header)
+	e.closeBody(accepted)
+	if location == "" {
+		return nil, errorsource.DownstreamError(fmt.Errorf("the query was accepted without an operation to poll"), false)
+	}
+	operationURL, err := queryURL.Parse(location)
+	if err != nil {
+		return nil, errorsource.DownstreamError(fmt.Errorf("invalid operation location %q: %w", location, err), false)
+	}
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			e.cancelOperation(ctx, client, operationURL)
+			return nil, errorsource.DownstreamError(fmt.Errorf("the query did not complete within %s", timeout), false)
+		}
+
+		timer := time.NewTimer(min(interval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			e.cancelOperation(ctx, client, operationURL)
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, operationURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				e.cancelOperation(ctx, client, operationURL)
+				return nil, ctx.Err()
+			}
+			return nil, errorsource.DownstreamError(err, false)
+		}
+		switch {
+		case res.StatusCode == http.StatusAccepted:
+			interval = retryAfter(res.Header)
+			e.closeBody(res)
+		case res.StatusCode/100 == 2:
+			return res, nil
+		default:
+			return nil, e.operationError(res)
+		}
+	}
+}
+
+// operationError returns the error of an operation or result page that failed. The API deletes the operation and its
+// results some time after the query completes and then answers with 404 Not Found.
+func (e *AzureLogAnalyticsDatasource) operationError(res *http.Response) error {
+	defer e.closeBody(res)
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone {
+		return errorsource.DownstreamError(fmt.Errorf("the query operation expired, status: %s", res.Status), false)
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	return errorsource.SourceError(backend.ErrorSourceFromHTTPStatus(res.StatusCode), fmt.Errorf("request failed, status: %s, body: %s", res.Status, string(body)), false)
+}
+
+// cancelOperation deletes the operation of a query that is no longer waited for. It is best effort, the operation
+// expires on its own otherwise.
+func (e *AzureLogAnalyticsDatasource) cancelOperation(ctx context.Context, client *http.Client, operationURL *url.URL) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelOperationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, operationURL.String(), nil)
+	if err != nil {
+		e.Logger.Warn("Failed to cancel the Log Analytics query", "err", err)
+		return
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		e.Logger.Warn("Failed to cancel the Log Analytics query", "err", err)
+		return
+	}
+	e.closeBody(res)
+}
+
+// getResultPage reads the next page of the results of a query
+func (e *AzureLogAnalyticsDatasource) getResultPage(ctx context.Context, client *http.Client, queryURL *url.URL, nextLink string, deadline time.Time) (AzureLogAnalyticsResponse, error) {
+	pageURL, err := queryURL.Parse(nextLink)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, fmt.Errorf("invalid next link %q: %w", nextLink, err)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return AzureLogAnalyticsResponse{}, fmt.Errorf("the query did not complete within the timeout")
+		}
+		return AzureLogAnalyticsResponse{}, err
+	}
+	if res.StatusCode/100 != 2 {
+		return AzureLogAnalyticsResponse{}, e.operationError(res)
+	}
+	return e.unmarshalResponse(res)
+}
+
+// appendResultPage appends the rows of a page to the tables of the same name in the response
+func appendResultPage(response *AzureLogAnalyticsResponse, page AzureLogAnalyticsResponse) {
+	for _, pageTable := range page.Tables {
+		for i := range response.Tables {
+			if response.Tables[i].Name == pageTable.Name {
+				response.Tables[i].Rows = append(response.Tables[i].Rows, pageTable.Rows...)
+			}
+		}
+	}
+	if response.Error == nil {
+		response.Error = page.Error
+	}
+	response.NextLink = page.NextLink
+}
+
+func partialResultsNotice(reason string) data.Notice {
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "The results are partial, " + reason,
+	}
+}
+
+// retryAfter returns the interval in the Retry-After header, in seconds, or the default poll interval
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultAsyncPollInterval
+}
+
+func (e *AzureLogAnalyticsDatasource) closeBody(res *http.Response) {
+	if err := res.Body.Close(); err != nil {
+		e.Logger.Warn("Failed to close response body", "err", err)
+	}
+}
@@ -0,0 +1,219 @@
+package loganalytics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/kinds/dataquery"
+	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/types"
+)
+
+func resultPage(rows string, nextLink string) string {
+	link := ""
+	if nextLink != "" {
+		link = fmt.Sprintf(`,"nextLink":%q`, nextLink)
+	}
+	return `{"tables":[{"name":"PrimaryResult","columns":[{"name":"Computer","type":"string"}],"rows":[` + rows + `]}]` + link + `}`
+}
+
+func TestExecuteAsyncQuery(t *testing.T) {
+	ds := AzureLogAnalyticsDatasource{Logger: log.NewNullLogger()}
+	query := &AzureLogAnalyticsQuery{
+		RefID:        "A",
+		ResultFormat: dataquery.ResultFormatTable,
+		URL:          "v1/workspaces/aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/query",
+		JSON:         []byte(`{}`),
+		Query:        "Heartbeat",
+		TimeRange:    backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)},
+	}
+	dsInfo := func(url string, timeout string) types.DatasourceInfo {
+		return types.DatasourceInfo{
+			Services: map[string]types.DatasourceService{
+				"Azure Log Analytics": {URL: url},
+			},
+			Routes: map[string]types.AzRoute{
+				"Azure Portal": {URL: "http://portal"},
+			},
+			JSONData: map[string]any{
+				"logAnalyticsAsyncQueries": true,
+				"logAnalyticsAsyncTimeout": timeout,
+			},
+		}
+	}
+
+	t.Run("polls accepted queries and reads the pages of their results", func(t *testing.T) {
+		var polls atomic.Int32
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost:
+				require.Equal(t, "respond-async, wait=60", r.Header.Get("Prefer"))
+				w.Header().Set("Location", "/operations/1")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusAccepted)
+			case r.URL.Path == "/operations/1" && polls.Add(1) < 3:
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusAccepted)
+			case r.URL.Path == "/operations/1":
+				_, _ = w.Write([]byte(resultPage(`["a"],["b"]`, "/operations/1/pages/2")))
+			case r.URL.Path == "/operations/1/pages/2":
+				_, _ = w.Write([]byte(resultPage(`["c"]`, "")))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer svr.Close()
+
+		info := dsInfo(svr.URL, "1m")
+		res, err := ds.executeQuery(context.Background(), query, info, svr.Client(), svr.URL)
+		require.NoError(t, err)
+		require.Equal(t, int32(3), polls.Load())
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 3, res.Frames[0].Rows())
+		require.Empty(t, res.Frames[0].Meta.Notices)
+	})
+
+	t.Run("returns partial results with a warning when a page fails", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/" + query.URL:
+				_, _ = w.Write([]byte(resultPage(`["a"]`, "/pages/2")))
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer svr.Close()
+
+		res, err := ds.executeQuery(context.Background(), query, dsInfo(svr.URL, "1m"), svr.Client(), svr.URL)
+		require.NoError(t, err)
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 1, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, res.Frames[0].Meta.Notices[0].Severity)
+		require.Contains(t, res.Frames[0].Meta.Notices[0].Text, "The results are partial")
+	})
+
+	t.Run("stops reading pages after the maximum number of pages", func(t *testing.T) {
+		var pages atomic.Int32
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page := pages.Add(1)
+			_, _ = w.Write([]byte(resultPage(`["a"]`, fmt.Sprintf("/pages/%d", page+1))))
+		}))
+		defer svr.Close()
+
+		res, err := ds.executeQuery(context.Background(), query, dsInfo(svr.URL, "1m"), svr.Client(), svr.URL)
+		require.NoError(t, err)
+		require.Equal(t, int32(maxResultPages), pages.Load())
+		require.Equal(t, maxResultPages, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("fails queries that do not complete within the timeout", func(t *testing.T) {
+		var cancelled atomic.Bool
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				cancelled.Store(true)
+				return
+			}
+			w.Header().Set("Location", "/operations/1")
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer svr.Close()
+
+		_, err := ds.executeQuery(context.Background(), query, dsInfo(svr.URL, "100ms"), svr.Client(), svr.URL)
+		require.ErrorContains(t, err, "the query did not complete within 100ms")
+		require.True(t, cancelled.Load())
+	})
+
+	t.Run("fails queries whose initial request does not complete within the timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer svr.Close()
+		defer close(done)
+
+		_, err := ds.executeQuery(context.Background(), query, dsInfo(svr.URL, "100ms"), svr.Client(), svr.URL)
+		require.ErrorContains(t, err, "the query did not complete within 100ms")
+	})
+
+	t.Run("fails queries whose operation expired", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.Header().Set("Location", "/operations/1")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer svr.Close()
+
+		_, err := ds.executeQuery(context.Background(), query, dsInfo(svr.URL, "1m"), svr.Client(), svr.URL)
+		require.ErrorContains(t, err, "the query operation expired")
+	})
+
+	t.Run("returns partial results with a warning when the results expired", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/" + query.URL:
+				_, _ = w.Write([]byte(resultPage(`["a"]`, "/pages/2")))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer svr.Close()
+
+		res, err := ds.executeQuery(context.Background(), query, dsInfo(svr.URL, "1m"), svr.Client(), svr.URL)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		require.Contains(t, res.Frames[0].Meta.Notices[0].Text, "the query operation expired")
+	})
+
+	t.Run("cancels the operation when the query is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var cancelled atomic.Bool
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete:
+				cancelled.Store(true)
+			case http.MethodPost:
+				w.Header().Set("Location", "/operations/1")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusAccepted)
+			default:
+				cancel()
+				w.WriteHeader(http.StatusAccepted)
+			}
+		}))
+		defer svr.Close()
+
+		_, err := ds.executeQuery(ctx, query, dsInfo(svr.URL, "1m"), svr.Client(), svr.URL)
+		require.ErrorIs(t, err, context.Canceled)
+		require.True(t, cancelled.Load())
+	})
+}
+
+func TestGetAsyncSettings(t *testing.T) {
+	settings := getAsyncSettings(types.DatasourceInfo{JSONData: map[string]any{}})
+	require.Equal(t, asyncSettings{timeout: defaultAsyncTimeout}, settings)
+
+	settings = getAsyncSettings(types.DatasourceInfo{JSONData: map[string]any{
+		"logAnalyticsAsyncQueries": true,
+		"logAnalyticsAsyncTimeout": "5m",
+	}})
+	require.Equal(t, asyncSettings{enabled: true, timeout: 5 * time.Minute}, settings)
+}
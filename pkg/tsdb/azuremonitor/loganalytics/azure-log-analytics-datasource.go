@@ -307,18 +307,7 @@ func (e *AzureLogAnalyticsDatasource) executeQuery(ctx context.Context, query *A
 	))
 	defer span.End()
 
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, errorsource.DownstreamError(err, false)
-	}
-
-	defer func() {
-		if err := res.Body.Close(); err != nil {
-			e.Logger.Warn("Failed to close response body", "err", err)
-		}
-	}()
-
-	logResponse, err := e.unmarshalResponse(res)
+	logResponse, notices, err := e.sendQueryRequest(ctx, client, req, getAsyncSettings(dsInfo))
 	if err != nil {
 		return nil, err
 	}
@@ -337,6 +326,9 @@ func (e *AzureLogAnalyticsDatasource) executeQuery(ctx context.Context, query *A
 		dataResponse := backend.DataResponse{}
 		return &dataResponse, nil
 	}
+	if len(notices) > 0 {
+		frame.AppendNotices(notices...)
+	}
 
 	queryUrl, err := getQueryUrl(query.Query, query.Resources, dsInfo.Routes["Azure Portal"].URL, query.TimeRange)
 	if err != nil {
@@ -381,6 +373,21 @@ func (e *AzureLogAnalyticsDatasource) executeQuery(ctx context.Context, query *A
 	return &dataResponse, nil
 }
 
+// sendQueryRequest sends the request of a query and returns its response, with the notices of partial results
+// in the async mode
+func (e *AzureLogAnalyticsDatasource) sendQueryRequest(ctx context.Context, client *http.Client, req *http.Request, async asyncSettings) (AzureLogAnalyticsResponse, []data.Notice, error) {
+	if async.enabled {
+		return e.executeAsyncRequest(ctx, client, req, async)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, nil, errorsource.DownstreamError(err, false)
+	}
+	logResponse, err := e.unmarshalResponse(res)
+	return logResponse, nil, err
+}
+
 func addDataLinksToFields(query *AzureLogAnalyticsQuery, azurePortalBaseUrl string, frame *data.Frame, dsInfo types.DatasourceInfo, queryUrl string) error {
 	if query.QueryType == dataquery.AzureQueryTypeAzureTraces {
 		err := addTraceDataLinksToFields(query, azurePortalBaseUrl, frame, dsInfo)
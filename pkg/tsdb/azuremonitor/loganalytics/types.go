@@ -60,6 +60,8 @@ type AzureLogAnalyticsInnerError struct {
 type AzureLogAnalyticsResponse struct {
 	Tables []types.AzureResponseTable `json:"tables"`
 	Error  *AzureLogAnalyticsAPIError `json:"error,omitempty"`
+	// NextLink is set on the pages of results of async queries that are followed by more rows
+	NextLink *string `json:"nextLink,omitempty"`
 }
 
 type AzureCorrelationAPIResponse struct {
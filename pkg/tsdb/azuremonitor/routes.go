@@ -14,12 +14,13 @@ import (
 
 // Azure cloud query types
 const (
-	azureMonitor       = "Azure Monitor"
-	azureLogAnalytics  = "Azure Log Analytics"
-	azureResourceGraph = "Azure Resource Graph"
-	azureTraces        = "Azure Traces"
-	azurePortal        = "Azure Portal"
-	traceExemplar      = "traceql"
+	azureMonitor        = "Azure Monitor"
+	azureLogAnalytics   = "Azure Log Analytics"
+	azureResourceGraph  = "Azure Resource Graph"
+	azureTraces         = "Azure Traces"
+	azurePortal         = "Azure Portal"
+	traceExemplar       = "traceql"
+	azureCostManagement = "Azure Cost Management"
 )
 
 func getAzureMonitorRoutes(settings *azsettings.AzureSettings, credentials azcredentials.AzureCredentials, jsonData json.RawMessage) (map[string]types.AzRoute, error) {
@@ -33,6 +34,13 @@ func getAzureMonitorRoutes(settings *azsettings.AzureSettings, credentials azcre
 		if err != nil {
 			return nil, err
 		}
+		// Cost Management is served by the Resource Manager, customized clouds configured before it was supported do
+		// not have a route for it
+		if resourceManagerRoute, ok := routes[azureMonitor]; ok {
+			if _, ok := routes[azureCostManagement]; !ok {
+				routes[azureCostManagement] = resourceManagerRoute
+			}
+		}
 		return routes, nil
 	}
 
@@ -79,12 +87,13 @@ func getAzureMonitorRoutes(settings *azsettings.AzureSettings, credentials azcre
 	}
 
 	routes := map[string]types.AzRoute{
-		azureMonitor:       resourceManagerRoute,
-		azureLogAnalytics:  logAnalyticsRoute,
-		azureResourceGraph: resourceManagerRoute,
-		azureTraces:        logAnalyticsRoute,
-		traceExemplar:      logAnalyticsRoute,
-		azurePortal:        portalRoute,
+		azureMonitor:        resourceManagerRoute,
+		azureLogAnalytics:   logAnalyticsRoute,
+		azureResourceGraph:  resourceManagerRoute,
+		azureTraces:         logAnalyticsRoute,
+		traceExemplar:       logAnalyticsRoute,
+		azurePortal:         portalRoute,
+		azureCostManagement: resourceManagerRoute,
 	}
 
 	return routes, nil
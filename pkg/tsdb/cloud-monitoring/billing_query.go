@@ -0,0 +1,324 @@
+package cloudmonitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+const (
+	// bigQueryTimeoutMs is how long a request waits for the billing query to complete before polling for its results
+	bigQueryTimeoutMs = 30000
+	// bigQueryPollTimeout limits how long the results of a billing query are polled for until it completes
+	bigQueryPollTimeout = 5 * time.Minute
+	// maxBillingPages limits the pages of results read for a single query
+	maxBillingPages = 50
+)
+
+// billingTablePattern matches the fully qualified name of a billing export table, such as
+// my-project.billing.gcp_billing_export_v1_XXXXXX
+var billingTablePattern = regexp.MustCompile(`^[A-Za-z0-9_:.-]+$`)
+
+// billingGroupColumns are the billing export columns of the groups of costs, labels are grouped by their value
+var billingGroupColumns = map[string]string{
+	"service": "service.description",
+	"project": "project.id",
+	"sku":     "sku.description",
+}
+
+var billingGranularities = map[string]string{
+	"HOUR":  "HOUR",
+	"DAY":   "DAY",
+	"MONTH": "MONTH",
+}
+
+func (bq *cloudMonitoringBilling) run(ctx context.Context, req *backend.QueryDataRequest,
+	s *Service, dsInfo datasourceInfo, logger log.Logger) (*backend.DataResponse, any, string, error) {
+	dr := &backend.DataResponse{}
+	projectName, err := s.ensureProject(ctx, dsInfo, bq.parameters.ProjectName)
+	if err != nil {
+		return dr, bigQueryResults{}, "", err
+	}
+
+	sql, params, err := bq.buildSQL()
+	if err != nil {
+		return dr, bigQueryResults{}, "", errorsource.DownstreamError(err, false)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, dsInfo.services[bigQuery].url, nil)
+	if err != nil {
+		return dr, bigQueryResults{}, "", err
+	}
+	r.URL.Path = path.Join("/bigquery/v2/projects", projectName, "queries")
+	span := traceReq(ctx, req, dsInfo, r, sql)
+	defer span.End()
+
+	results, err := bq.runQuery(r, dsInfo, map[string]any{
+		"query":           sql,
+		"useLegacySql":    false,
+		"parameterMode":   "NAMED",
+		"queryParameters": params,
+		"timeoutMs":       bigQueryTimeoutMs,
+	}, logger)
+	if err != nil {
+		return dr, bigQueryResults{}, sql, err
+	}
+	return dr, results, sql, nil
+}
+
+// buildSQL returns the query of the costs over time in each group, with the credits deducted
+func (bq *cloudMonitoringBilling) buildSQL() (string, []map[string]any, error) {
+	p := bq.parameters
+	if !billingTablePattern.MatchString(p.Table) {
+		return "", nil, fmt.Errorf("invalid billing export table %q", p.Table)
+	}
+	granularity := "DAY"
+	if p.Granularity != "" {
+		g, ok := billingGranularities[p.Granularity]
+		if !ok {
+			return "", nil, fmt.Errorf("invalid granularity %q, expected HOUR, DAY or MONTH", p.Granularity)
+		}
+		granularity = g
+	}
+
+	params := []map[string]any{
+		bigQueryParameter("from", "TIMESTAMP", bq.timeRange.From.UTC().Format(time.RFC3339)),
+		bigQueryParameter("to", "TIMESTAMP", bq.timeRange.To.UTC().Format(time.RFC3339)),
+	}
+	group := "''"
+	if p.GroupBy != nil {
+		switch {
+		case p.GroupBy.Type == "label":
+			if p.GroupBy.Key == "" {
+				return "", nil, fmt.Errorf("a label key is required to group the costs by label")
+			}
+			group = "(SELECT l.value FROM UNNEST(labels) l WHERE l.key = @labelKey)"
+			params = append(params, bigQueryParameter("labelKey", "STRING", p.GroupBy.Key))
+		case billingGroupColumns[p.GroupBy.Type] != "":
+			group = billingGroupColumns[p.GroupBy.Type]
+		default:
+			return "", nil, fmt.Errorf("invalid grouping %q, expected service, project, sku or label", p.GroupBy.Type)
+		}
+	}
+
+	sql := fmt.Sprintf("SELECT TIMESTAMP_TRUNC(usage_start_time, %s) AS time, %s AS grp, currency, "+
+		"SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) AS cost "+
+		"FROM `%s` WHERE usage_start_time >= @from AND usage_start_time < @to "+
+		"GROUP BY time, grp, currency ORDER BY time", granularity, group, p.Table)
+	return sql, params, nil
+}
+
+func bigQueryParameter(name string, parameterType string, value string) map[string]any {
+	return map[string]any{
+		"name":           name,
+		"parameterType":  map[string]string{"type": parameterType},
+		"parameterValue": map[string]string{"value": value},
+	}
+}
+
+type bigQueryResponse struct {
+	JobComplete  bool `json:"jobComplete"`
+	JobReference struct {
+		ProjectID string `json:"projectId"`
+		JobID     string `json:"jobId"`
+		Location  string `json:"location"`
+	} `json:"jobReference"`
+	Rows []struct {
+		F []struct {
+			V any `json:"v"`
+		} `json:"f"`
+	} `json:"rows"`
+	PageToken string `json:"pageToken"`
+}
+
+// bigQueryResults are the rows of a billing query, with the time, group, currency and cost columns
+type bigQueryResults struct {
+	rows [][]any
+}
+
+// runQuery runs the query and reads its results, polling for them until the query completes or the poll timeout
+func (bq *cloudMonitoringBilling) runQuery(r *http.Request, dsInfo datasourceInfo, body map[string]any, logger log.Logger) (bigQueryResults, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return bigQueryResults{}, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Body = io.NopCloser(bytes.NewBuffer(buf))
+
+	res, err := doBigQueryRequest(r, dsInfo, logger)
+	if err != nil {
+		return bigQueryResults{}, err
+	}
+
+	results := bigQueryResults{}
+	deadline := time.Now().Add(bigQueryPollTimeout)
+	pages := 0
+	for {
+		if res.JobComplete {
+			pages++
+			if pages > maxBillingPages {
+				return bigQueryResults{}, errorsource.DownstreamError(fmt.Errorf("the costs have more than %d pages, narrow down the time range or the grouping", maxBillingPages), false)
+			}
+			for _, row := range res.Rows {
+				values := make([]any, 0, len(row.F))
+				for _, f := range row.F {
+					values = append(values, f.V)
+				}
+				results.rows = append(results.rows, values)
+			}
+			if res.PageToken == "" {
+				return results, nil
+			}
+		} else if time.Now().After(deadline) {
+			return bigQueryResults{}, errorsource.DownstreamError(fmt.Errorf("the billing query did not complete within %s", bigQueryPollTimeout), false)
+		}
+		if err := r.Context().Err(); err != nil {
+			return bigQueryResults{}, err
+		}
+
+		params := url.Values{}
+		params.Set("timeoutMs", strconv.Itoa(bigQueryTimeoutMs))
+		if res.JobReference.Location != "" {
+			params.Set("location", res.JobReference.Location)
+		}
+		if res.JobComplete {
+			params.Set("pageToken", res.PageToken)
+		}
+		next, err := http.NewRequestWithContext(r.Context(), http.MethodGet, dsInfo.services[bigQuery].url, nil)
+		if err != nil {
+			return bigQueryResults{}, err
+		}
+		next.URL.Path = path.Join("/bigquery/v2/projects", res.JobReference.ProjectID, "queries", res.JobReference.JobID)
+		next.URL.RawQuery = params.Encode()
+		res, err = doBigQueryRequest(next, dsInfo, logger)
+		if err != nil {
+			return bigQueryResults{}, err
+		}
+	}
+}
+
+func doBigQueryRequest(r *http.Request, dsInfo datasourceInfo, logger log.Logger) (bigQueryResponse, error) {
+	res, err := dsInfo.services[bigQuery].client.Do(r)
+	if err != nil {
+		return bigQueryResponse{}, errorsource.DownstreamError(err, false)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return bigQueryResponse{}, err
+	}
+	if res.StatusCode/100 != 2 {
+		return bigQueryResponse{}, errorsource.SourceError(backend.ErrorSourceFromHTTPStatus(res.StatusCode), fmt.Errorf("billing query failed: %s", string(body)), false)
+	}
+
+	var response bigQueryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return bigQueryResponse{}, fmt.Errorf("failed to unmarshal billing query response: %w", err)
+	}
+	return response, nil
+}
+
+// parseResponse returns a cost over time frame for each group of costs
+func (bq *cloudMonitoringBilling) parseResponse(queryRes *backend.DataResponse,
+	response any, executedQueryString string, logger log.Logger) error {
+	results := response.(bigQueryResults)
+
+	type costGroup struct {
+		labels   data.Labels
+		currency string
+		times    []time.Time
+		costs    []float64
+	}
+	groups := map[string]*costGroup{}
+	for _, row := range results.rows {
+		if len(row) != 4 {
+			return fmt.Errorf("unexpected billing query row with %d columns", len(row))
+		}
+		seconds, err := strconv.ParseFloat(fmt.Sprint(row[0]), 64)
+		if err != nil {
+			return fmt.Errorf("invalid billing query time %v: %w", row[0], err)
+		}
+		cost, err := strconv.ParseFloat(fmt.Sprint(row[3]), 64)
+		if err != nil {
+			return fmt.Errorf("invalid billing query cost %v: %w", row[3], err)
+		}
+
+		labels := data.Labels{}
+		if bq.parameters.GroupBy != nil {
+			name := bq.parameters.GroupBy.Type
+			if name == "label" {
+				name = bq.parameters.GroupBy.Key
+			}
+			value, _ := row[1].(string)
+			labels[name] = value
+		}
+		// costs in different currencies are separate series, they cannot be added up
+		currency, _ := row[2].(string)
+		key := labels.String() + " " + currency
+		group, ok := groups[key]
+		if !ok {
+			group = &costGroup{labels: labels, currency: currency}
+			groups[key] = group
+		}
+		group.times = append(group.times, time.Unix(0, int64(seconds*1e9)).UTC())
+		group.costs = append(group.costs, cost)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		costField := data.NewField("cost", group.labels, group.costs)
+		if group.currency != "" {
+			costField.Config = &data.FieldConfig{Unit: "currency" + group.currency}
+		}
+		frame := data.NewFrame("", data.NewField(data.TimeSeriesTimeFieldName, nil, group.times), costField)
+		frame.Meta = &data.FrameMeta{
+			Type:                data.FrameTypeTimeSeriesMulti,
+			ExecutedQueryString: executedQueryString,
+		}
+		frames = append(frames, frame)
+	}
+	queryRes.Frames = frames
+	return nil
+}
+
+func (bq *cloudMonitoringBilling) buildDeepLink() string {
+	return ""
+}
+
+func (bq *cloudMonitoringBilling) getRefID() string {
+	return bq.refID
+}
+
+func (bq *cloudMonitoringBilling) getAliasBy() string {
+	return bq.aliasBy
+}
+
+func (bq *cloudMonitoringBilling) getParameter(i string) string {
+	return ""
+}
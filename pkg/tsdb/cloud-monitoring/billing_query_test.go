@@ -0,0 +1,164 @@
+package cloudmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBillingQuery(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+	req := &backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A", TimeRange: timeRange}}}
+
+	t.Run("builds the query of the costs grouped by label", func(t *testing.T) {
+		query := &cloudMonitoringBilling{
+			parameters: &billingQuery{
+				Table:       "my-project.billing.gcp_billing_export_v1_0000",
+				Granularity: "MONTH",
+				GroupBy:     &billingGroupBy{Type: "label", Key: "team"},
+			},
+			timeRange: timeRange,
+		}
+		sql, params, err := query.buildSQL()
+		require.NoError(t, err)
+		assert.Contains(t, sql, "TIMESTAMP_TRUNC(usage_start_time, MONTH)")
+		assert.Contains(t, sql, "FROM `my-project.billing.gcp_billing_export_v1_0000`")
+		assert.Contains(t, sql, "l.key = @labelKey")
+		require.Len(t, params, 3)
+		assert.Equal(t, map[string]string{"value": "team"}, params[2]["parameterValue"])
+	})
+
+	t.Run("rejects invalid tables and groupings", func(t *testing.T) {
+		for _, parameters := range []*billingQuery{
+			{Table: "billing` WHERE 1=1 --"},
+			{Table: "p.d.t", Granularity: "WEEK"},
+			{Table: "p.d.t", GroupBy: &billingGroupBy{Type: "region"}},
+			{Table: "p.d.t", GroupBy: &billingGroupBy{Type: "label"}},
+		} {
+			query := &cloudMonitoringBilling{parameters: parameters, timeRange: timeRange}
+			_, _, err := query.buildSQL()
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("polls for the results and returns the costs by service", func(t *testing.T) {
+		var requests []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+			job := `"jobReference":{"projectId":"my-project","jobId":"job-1","location":"US"}`
+			switch {
+			case r.Method == http.MethodPost:
+				body := map[string]any{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				require.Equal(t, false, body["useLegacySql"])
+				_, _ = w.Write([]byte(`{"jobComplete":false,` + job + `}`))
+			case r.URL.Query().Get("pageToken") == "":
+				_, _ = w.Write([]byte(`{"jobComplete":true,` + job + `,"pageToken":"2","rows":[
+					{"f":[{"v":"1.7040672E9"},{"v":"Compute Engine"},{"v":"USD"},{"v":"1.5"}]},
+					{"f":[{"v":"1.7040672E9"},{"v":"BigQuery"},{"v":"USD"},{"v":"0.5"}]}
+				]}`))
+			default:
+				_, _ = w.Write([]byte(`{"jobComplete":true,` + job + `,"rows":[
+					{"f":[{"v":"1.7041536E9"},{"v":"Compute Engine"},{"v":"USD"},{"v":"2"}]}
+				]}`))
+			}
+		}))
+		defer srv.Close()
+
+		dsInfo := datasourceInfo{
+			defaultProject: "my-project",
+			services: map[string]datasourceService{
+				bigQuery: {url: srv.URL, client: srv.Client()},
+			},
+		}
+		query := &cloudMonitoringBilling{
+			refID: "A",
+			parameters: &billingQuery{
+				Table:   "my-project.billing.gcp_billing_export_v1_0000",
+				GroupBy: &billingGroupBy{Type: "service"},
+			},
+			timeRange: timeRange,
+		}
+		s := &Service{}
+		dr, results, executedQueryString, err := query.run(context.Background(), req, s, dsInfo, log.NewNullLogger())
+		require.NoError(t, err)
+		require.NoError(t, query.parseResponse(dr, results, executedQueryString, log.NewNullLogger()))
+
+		assert.Equal(t, []string{
+			"POST /bigquery/v2/projects/my-project/queries?",
+			"GET /bigquery/v2/projects/my-project/queries/job-1?location=US&timeoutMs=30000",
+			"GET /bigquery/v2/projects/my-project/queries/job-1?location=US&pageToken=2&timeoutMs=30000",
+		}, requests)
+
+		require.Len(t, dr.Frames, 2)
+		assert.Equal(t, data.Labels{"service": "BigQuery"}, dr.Frames[0].Fields[1].Labels)
+		assert.Equal(t, data.Labels{"service": "Compute Engine"}, dr.Frames[1].Fields[1].Labels)
+		assert.Equal(t, "currencyUSD", dr.Frames[1].Fields[1].Config.Unit)
+		require.Equal(t, 2, dr.Frames[1].Rows())
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), dr.Frames[1].Fields[0].At(1))
+		assert.Equal(t, 2.0, dr.Frames[1].Fields[1].At(1))
+	})
+
+	t.Run("does not count the polls of an incomplete query as pages", func(t *testing.T) {
+		polls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			job := `"jobReference":{"projectId":"my-project","jobId":"job-1"}`
+			if r.Method == http.MethodGet {
+				polls++
+			}
+			if polls <= maxBillingPages {
+				_, _ = w.Write([]byte(`{"jobComplete":false,` + job + `}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"jobComplete":true,` + job + `,"rows":[
+				{"f":[{"v":"1.7040672E9"},{"v":""},{"v":"USD"},{"v":"1.5"}]}
+			]}`))
+		}))
+		defer srv.Close()
+
+		dsInfo := datasourceInfo{
+			defaultProject: "my-project",
+			services: map[string]datasourceService{
+				bigQuery: {url: srv.URL, client: srv.Client()},
+			},
+		}
+		query := &cloudMonitoringBilling{
+			refID:      "A",
+			parameters: &billingQuery{Table: "my-project.billing.gcp_billing_export_v1_0000"},
+			timeRange:  timeRange,
+		}
+		_, results, _, err := query.run(context.Background(), req, &Service{}, dsInfo, log.NewNullLogger())
+		require.NoError(t, err)
+		require.Len(t, results.(bigQueryResults).rows, 1)
+	})
+
+	t.Run("returns the costs in each currency as separate series", func(t *testing.T) {
+		query := &cloudMonitoringBilling{
+			parameters: &billingQuery{GroupBy: &billingGroupBy{Type: "service"}},
+		}
+		results := bigQueryResults{rows: [][]any{
+			{"1.7040672E9", "Compute Engine", "USD", "1.5"},
+			{"1.7040672E9", "Compute Engine", "EUR", "2"},
+		}}
+		dr := &backend.DataResponse{}
+		require.NoError(t, query.parseResponse(dr, results, "", log.NewNullLogger()))
+
+		require.Len(t, dr.Frames, 2)
+		assert.Equal(t, "currencyEUR", dr.Frames[0].Fields[1].Config.Unit)
+		assert.Equal(t, 2.0, dr.Frames[0].Fields[1].At(0))
+		assert.Equal(t, "currencyUSD", dr.Frames[1].Fields[1].Config.Unit)
+		assert.Equal(t, 1.5, dr.Frames[1].Fields[1].At(0))
+	})
+}
@@ -57,6 +57,7 @@ const (
 	timeSeriesQueryQueryType  = dataquery.QueryTypeTimeSeriesQuery
 	sloQueryType              = dataquery.QueryTypeSlo
 	promQLQueryType           = dataquery.QueryTypePromQL
+	billingQueryType          = "billing"
 	crossSeriesReducerDefault = "REDUCE_NONE"
 	perSeriesAlignerDefault   = "ALIGN_MEAN"
 )
@@ -441,6 +442,16 @@ func (s *Service) buildQueryExecutors(logger log.Logger, req *backend.QueryDataR
 				logger:     logger,
 			}
 			queryInterface = cmp
+		case billingQueryType:
+			if q.BillingQuery == nil {
+				return nil, fmt.Errorf("billing query %q has no billingQuery", query.RefID)
+			}
+			queryInterface = &cloudMonitoringBilling{
+				refID:      query.RefID,
+				aliasBy:    q.AliasBy,
+				parameters: q.BillingQuery,
+				timeRange:  query.TimeRange,
+			}
 		default:
 			return nil, fmt.Errorf("unrecognized query type %q", query.QueryType)
 		}
@@ -10,8 +10,10 @@ import (
 const (
 	cloudMonitor         = "cloudmonitoring"
 	resourceManager      = "cloudresourcemanager"
+	bigQuery             = "bigquery"
 	cloudMonitorScope    = "https://www.googleapis.com/auth/monitoring.read"
 	resourceManagerScope = "https://www.googleapis.com/auth/cloudplatformprojects.readonly"
+	bigQueryScope        = "https://www.googleapis.com/auth/bigquery.readonly"
 )
 
 type routeInfo struct {
@@ -31,6 +33,11 @@ var routes = map[string]routeInfo{
 		url:    "https://cloudresourcemanager.googleapis.com",
 		scopes: []string{resourceManagerScope},
 	},
+	bigQuery: {
+		method: "POST",
+		url:    "https://bigquery.googleapis.com",
+		scopes: []string{bigQueryScope},
+	},
 }
 
 func getMiddleware(model *datasourceInfo, routePath string) (httpclient.Middleware, error) {
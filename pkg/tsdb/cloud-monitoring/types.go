@@ -35,6 +35,24 @@ type (
 		TimeSeriesQuery *dataquery.TimeSeriesQuery `json:"timeSeriesQuery,omitempty"`
 		SloQuery        *dataquery.SLOQuery        `json:"sloQuery,omitempty"`
 		PromQLQuery     *dataquery.PromQLQuery     `json:"promQLQuery,omitempty"`
+		BillingQuery    *billingQuery              `json:"billingQuery,omitempty"`
+	}
+
+	// billingQuery queries the costs in a Cloud Billing export to BigQuery
+	billingQuery struct {
+		// The project that runs the BigQuery jobs, the default project when empty
+		ProjectName string `json:"projectName"`
+		// The billing export table, such as my-project.billing.gcp_billing_export_v1_XXXXXX
+		Table string `json:"table"`
+		// DAY by default, or HOUR or MONTH
+		Granularity string `json:"granularity"`
+		// Groups the costs by service, project, sku or by the value of a label. The costs are totaled when empty.
+		GroupBy *billingGroupBy `json:"groupBy,omitempty"`
+	}
+
+	billingGroupBy struct {
+		Type string `json:"type"`
+		Key  string `json:"key,omitempty"`
 	}
 
 	cloudMonitoringTimeSeriesList struct {
@@ -63,6 +81,14 @@ type (
 		IntervalMS int64
 	}
 
+	// cloudMonitoringBilling is used to query the costs in a billing export
+	cloudMonitoringBilling struct {
+		refID      string
+		aliasBy    string
+		parameters *billingQuery
+		timeRange  backend.TimeRange
+	}
+
 	// cloudMonitoringTimeSeriesQuery is used to build MQL queries
 	cloudMonitoringTimeSeriesQuery struct {
 		refID      string
@@ -7,6 +7,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/costexplorer/costexploreriface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/oam"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
@@ -49,6 +51,13 @@ var NewCWLogsClient = func(sess *session.Session) cloudwatchlogsiface.CloudWatch
 	return cloudwatchlogs.New(sess)
 }
 
+// NewCostExplorerClient is a Cost Explorer client factory.
+//
+// Stubbable by tests.
+var NewCostExplorerClient = func(sess *session.Session) costexploreriface.CostExplorerAPI {
+	return costexplorer.New(sess)
+}
+
 // NewEC2Client is a client factory.
 //
 // Stubbable by tests.
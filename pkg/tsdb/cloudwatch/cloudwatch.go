@@ -59,6 +59,7 @@ const (
 	annotationQuery = "annotationQuery"
 	logAction       = "logAction"
 	timeSeriesQuery = "timeSeriesQuery"
+	costQuery       = "costExplorerQuery"
 )
 
 func ProvideService(httpClientProvider *httpclient.Provider) *CloudWatchService {
@@ -226,6 +227,8 @@ func (e *cloudWatchExecutor) QueryData(ctx context.Context, req *backend.QueryDa
 		result, err = e.executeAnnotationQuery(ctx, req.PluginContext, model, q)
 	case logAction:
 		result, err = e.executeLogActions(ctx, req)
+	case costQuery:
+		result, err = e.executeCostExplorerQuery(ctx, req)
 	case timeSeriesQuery:
 		fallthrough
 	default:
@@ -0,0 +1,92 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+// queryCost is the usage of the CloudWatch APIs by a query. AWS bills GetMetricData by the number of metrics
+// requested and Logs Insights queries by the number of bytes scanned, which the logs frames already report.
+type queryCost struct {
+	apiCalls         int64
+	metricsRequested int64
+}
+
+func (c queryCost) stats() []data.QueryStat {
+	stats := []data.QueryStat{{
+		FieldConfig: data.FieldConfig{DisplayName: "API calls"},
+		Value:       float64(c.apiCalls),
+	}}
+	if c.metricsRequested > 0 {
+		stats = append(stats, data.QueryStat{
+			FieldConfig: data.FieldConfig{DisplayName: "Metrics requested"},
+			Value:       float64(c.metricsRequested),
+		})
+	}
+	return stats
+}
+
+// addCostStats adds the cost of a query to the stats of its frames
+func addCostStats(frames data.Frames, cost queryCost) {
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.Stats = append(frame.Meta.Stats, cost.stats()...)
+	}
+}
+
+// metricsRequestedByID counts the metrics of the GetMetricData results by query ID. Search expressions and Metrics
+// Insights queries request a metric for each time series they return.
+func metricsRequestedByID(metricDataOutputs []*cloudwatch.GetMetricDataOutput) map[string]int64 {
+	labelsByID := make(map[string]map[string]bool)
+	for _, output := range metricDataOutputs {
+		for _, r := range output.MetricDataResults {
+			if r.Id == nil || r.Label == nil {
+				continue
+			}
+			if _, ok := labelsByID[*r.Id]; !ok {
+				labelsByID[*r.Id] = make(map[string]bool)
+			}
+			labelsByID[*r.Id][*r.Label] = true
+		}
+	}
+
+	metrics := make(map[string]int64, len(labelsByID))
+	for id, labels := range labelsByID {
+		metrics[id] = int64(len(labels))
+	}
+	return metrics
+}
+
+// checkBudget returns an error when the usage is over the limit of a cost budget, a zero limit means no limit
+func checkBudget(usage string, used int64, limit int64) error {
+	if limit > 0 && used > limit {
+		return errorsource.PluginError(fmt.Errorf("%w: %d %s, the limit is %d", models.ErrBudgetExceeded, used, usage, limit), false)
+	}
+	return nil
+}
+
+// checkLogBytesScannedBudget stops a running logs query once it scanned more bytes than the budget
+func (e *cloudWatchExecutor) checkLogBytesScannedBudget(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
+	logsQuery models.LogsQuery, res *cloudwatchlogs.GetQueryResultsOutput, maxBytesScanned int64) error {
+	if res.Statistics == nil || res.Statistics.BytesScanned == nil {
+		return nil
+	}
+
+	budgetErr := checkBudget("bytes scanned", int64(*res.Statistics.BytesScanned), maxBytesScanned)
+	if budgetErr == nil {
+		return nil
+	}
+	if _, err := e.executeStopQuery(ctx, logsClient, logsQuery); err != nil {
+		e.logger.FromContext(ctx).Warn("Failed to stop logs query over the cost budget", "error", err)
+	}
+	return budgetErr
+}
@@ -0,0 +1,202 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/costexplorer/costexploreriface"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+// Cost Explorer is a global service, its API is only served from us-east-1
+const costExplorerRegion = "us-east-1"
+
+const costExplorerDateFormat = "2006-01-02"
+
+// costExplorerQueryJson is the model of the cost queries, for example:
+//
+//	{"type": "costExplorerQuery", "metric": "UnblendedCost", "granularity": "DAILY", "groupBy": {"type": "DIMENSION", "key": "SERVICE"}}
+type costExplorerQueryJson struct {
+	// The cost metric, UnblendedCost by default
+	Metric string `json:"metric,omitempty"`
+	// DAILY by default, or HOURLY or MONTHLY
+	Granularity string `json:"granularity,omitempty"`
+	// Groups the costs by a dimension, such as SERVICE, or by a cost allocation tag. The costs are totaled when empty.
+	GroupBy *costExplorerGroupBy `json:"groupBy,omitempty"`
+}
+
+type costExplorerGroupBy struct {
+	// DIMENSION or TAG
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+func (e *cloudWatchExecutor) executeCostExplorerQuery(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	sess, err := e.newSessionFromContext(ctx, req.PluginContext, costExplorerRegion)
+	if err != nil {
+		return nil, err
+	}
+	client := NewCostExplorerClient(sess)
+
+	for _, q := range req.Queries {
+		frames, err := queryCostAndUsage(ctx, client, q)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.ErrorResponseWithErrorSource(err)
+			continue
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+	return resp, nil
+}
+
+func queryCostAndUsage(ctx context.Context, client costexploreriface.CostExplorerAPI, q backend.DataQuery) (data.Frames, error) {
+	var model costExplorerQueryJson
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return nil, errorsource.DownstreamError(fmt.Errorf("failed to parse the cost query: %w", err), false)
+	}
+	if model.Metric == "" {
+		model.Metric = costexplorer.MetricUnblendedCost
+	}
+	if model.Granularity == "" {
+		model.Granularity = costexplorer.GranularityDaily
+	}
+
+	// the end of the time period is exclusive, and the hourly granularity takes timestamps instead of dates
+	dateFormat, step := costExplorerDateFormat, 24*time.Hour
+	if model.Granularity == costexplorer.GranularityHourly {
+		dateFormat, step = time.RFC3339, time.Hour
+	}
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: aws.String(model.Granularity),
+		Metrics:     []*string{aws.String(model.Metric)},
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(q.TimeRange.From.UTC().Truncate(step).Format(dateFormat)),
+			End:   aws.String(q.TimeRange.To.UTC().Truncate(step).Add(step).Format(dateFormat)),
+		},
+	}
+	if model.GroupBy != nil && model.GroupBy.Key != "" {
+		input.GroupBy = []*costexplorer.GroupDefinition{{
+			Type: aws.String(model.GroupBy.Type),
+			Key:  aws.String(model.GroupBy.Key),
+		}}
+	}
+
+	series := newCostSeries()
+	for {
+		output, err := client.GetCostAndUsageWithContext(ctx, input)
+		if err != nil {
+			return nil, errorsource.DownstreamError(fmt.Errorf("failed to get the costs: %w", err), false)
+		}
+		for _, result := range output.ResultsByTime {
+			if result.TimePeriod == nil || result.TimePeriod.Start == nil {
+				continue
+			}
+			start, err := parseCostExplorerTime(*result.TimePeriod.Start)
+			if err != nil {
+				return nil, err
+			}
+			if len(input.GroupBy) == 0 {
+				series.add(data.Labels{}, start, result.Total[model.Metric])
+				continue
+			}
+			for _, group := range result.Groups {
+				series.add(costGroupLabels(model.GroupBy, group.Keys), start, group.Metrics[model.Metric])
+			}
+		}
+		if output.NextPageToken == nil || *output.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+	return series.frames(), nil
+}
+
+func parseCostExplorerTime(value string) (time.Time, error) {
+	if t, err := time.Parse(costExplorerDateFormat, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// costGroupLabels returns the labels of a group of costs, tag groups are keyed by "<tag>$<value>"
+func costGroupLabels(groupBy *costExplorerGroupBy, keys []*string) data.Labels {
+	if len(keys) == 0 || keys[0] == nil {
+		return data.Labels{}
+	}
+	key := *keys[0]
+	if groupBy.Type == costexplorer.GroupDefinitionTypeTag {
+		tag, value, _ := strings.Cut(key, "$")
+		return data.Labels{tag: value}
+	}
+	name := strings.ToLower(groupBy.Key)
+	return data.Labels{name: key}
+}
+
+// costSeries collects the costs of each group over time
+type costSeries struct {
+	groups map[string]*costGroup
+}
+
+type costGroup struct {
+	labels data.Labels
+	unit   string
+	times  []time.Time
+	costs  []float64
+}
+
+func newCostSeries() *costSeries {
+	return &costSeries{groups: map[string]*costGroup{}}
+}
+
+func (s *costSeries) add(labels data.Labels, t time.Time, value *costexplorer.MetricValue) {
+	if value == nil || value.Amount == nil {
+		return
+	}
+	amount, err := strconv.ParseFloat(*value.Amount, 64)
+	if err != nil {
+		return
+	}
+	// costs in different currencies are separate series, they cannot be added up
+	unit := aws.StringValue(value.Unit)
+	key := labels.String() + " " + unit
+	group, ok := s.groups[key]
+	if !ok {
+		group = &costGroup{labels: labels, unit: unit}
+		s.groups[key] = group
+	}
+	group.times = append(group.times, t)
+	group.costs = append(group.costs, amount)
+}
+
+// frames returns a cost over time frame for each group, sorted by their labels
+func (s *costSeries) frames() data.Frames {
+	keys := make([]string, 0, len(s.groups))
+	for key := range s.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		group := s.groups[key]
+		costField := data.NewField("cost", group.labels, group.costs)
+		if group.unit != "" {
+			costField.Config = &data.FieldConfig{Unit: "currency" + group.unit}
+		}
+		frame := data.NewFrame("", data.NewField(data.TimeSeriesTimeFieldName, nil, group.times), costField)
+		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti}
+		frames = append(frames, frame)
+	}
+	return frames
+}
@@ -0,0 +1,148 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/costexplorer/costexploreriface"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+type mockCostExplorerClient struct {
+	costexploreriface.CostExplorerAPI
+
+	mock.Mock
+}
+
+func (m *mockCostExplorerClient) GetCostAndUsageWithContext(ctx context.Context, input *costexplorer.GetCostAndUsageInput, option ...request.Option) (*costexplorer.GetCostAndUsageOutput, error) {
+	args := m.Called(ctx, input, option)
+	return args.Get(0).(*costexplorer.GetCostAndUsageOutput), args.Error(1)
+}
+
+func TestCostExplorerQuery(t *testing.T) {
+	origNewCostExplorerClient := NewCostExplorerClient
+	t.Cleanup(func() {
+		NewCostExplorerClient = origNewCostExplorerClient
+	})
+	var cli *mockCostExplorerClient
+	NewCostExplorerClient = func(sess *session.Session) costexploreriface.CostExplorerAPI {
+		return cli
+	}
+
+	costQuery := func(model string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries: []backend.DataQuery{{
+				RefID: "A",
+				TimeRange: backend.TimeRange{
+					From: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+					To:   time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+				},
+				JSON: json.RawMessage(model),
+			}},
+		}
+	}
+	cost := func(amount string) *costexplorer.MetricValue {
+		return &costexplorer.MetricValue{Amount: aws.String(amount), Unit: aws.String("USD")}
+	}
+	day := func(date string) *costexplorer.DateInterval {
+		return &costexplorer.DateInterval{Start: aws.String(date)}
+	}
+
+	t.Run("returns the costs grouped by service over time", func(t *testing.T) {
+		cli = &mockCostExplorerClient{}
+		cli.On("GetCostAndUsageWithContext", mock.Anything, mock.MatchedBy(func(input *costexplorer.GetCostAndUsageInput) bool {
+			return input.NextPageToken == nil
+		}), mock.Anything).Return(&costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: []*costexplorer.ResultByTime{{
+				TimePeriod: day("2024-01-01"),
+				Groups: []*costexplorer.Group{
+					{Keys: []*string{aws.String("Amazon S3")}, Metrics: map[string]*costexplorer.MetricValue{"UnblendedCost": cost("1.5")}},
+					{Keys: []*string{aws.String("AWS Lambda")}, Metrics: map[string]*costexplorer.MetricValue{"UnblendedCost": cost("0.25")}},
+				},
+			}},
+			NextPageToken: aws.String("next"),
+		}, nil).Once()
+		cli.On("GetCostAndUsageWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: []*costexplorer.ResultByTime{{
+				TimePeriod: day("2024-01-02"),
+				Groups: []*costexplorer.Group{
+					{Keys: []*string{aws.String("Amazon S3")}, Metrics: map[string]*costexplorer.MetricValue{"UnblendedCost": cost("2")}},
+				},
+			}},
+		}, nil).Once()
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), costQuery(`{
+			"type": "costExplorerQuery",
+			"groupBy": {"type": "DIMENSION", "key": "SERVICE"}
+		}`))
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+
+		input := cli.Calls[0].Arguments.Get(1).(*costexplorer.GetCostAndUsageInput)
+		assert.Equal(t, "2024-01-01", *input.TimePeriod.Start)
+		assert.Equal(t, "2024-01-03", *input.TimePeriod.End)
+		assert.Equal(t, "DAILY", *input.Granularity)
+
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		assert.Equal(t, data.Labels{"service": "AWS Lambda"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, data.Labels{"service": "Amazon S3"}, frames[1].Fields[1].Labels)
+		assert.Equal(t, "currencyUSD", frames[1].Fields[1].Config.Unit)
+		require.Equal(t, 2, frames[1].Rows())
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), frames[1].Fields[0].At(1))
+		assert.Equal(t, 2.0, frames[1].Fields[1].At(1))
+	})
+
+	t.Run("labels the costs grouped by tag with the tag key", func(t *testing.T) {
+		cli = &mockCostExplorerClient{}
+		cli.On("GetCostAndUsageWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: []*costexplorer.ResultByTime{{
+				TimePeriod: day("2024-01-01"),
+				Groups: []*costexplorer.Group{
+					{Keys: []*string{aws.String("team$payments")}, Metrics: map[string]*costexplorer.MetricValue{"BlendedCost": cost("3")}},
+				},
+			}},
+		}, nil)
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), costQuery(`{
+			"type": "costExplorerQuery",
+			"metric": "BlendedCost",
+			"groupBy": {"type": "TAG", "key": "team"}
+		}`))
+		require.NoError(t, err)
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		assert.Equal(t, data.Labels{"team": "payments"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, 3.0, frames[0].Fields[1].At(0))
+	})
+}
+
+func TestCostSeries_currencies(t *testing.T) {
+	series := newCostSeries()
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series.add(data.Labels{"service": "Amazon S3"}, t0, &costexplorer.MetricValue{Amount: aws.String("1.5"), Unit: aws.String("USD")})
+	series.add(data.Labels{"service": "Amazon S3"}, t0, &costexplorer.MetricValue{Amount: aws.String("2"), Unit: aws.String("EUR")})
+
+	frames := series.frames()
+	require.Len(t, frames, 2)
+	assert.Equal(t, "currencyEUR", frames[0].Fields[1].Config.Unit)
+	assert.Equal(t, 2.0, frames[0].Fields[1].At(0))
+	assert.Equal(t, "currencyUSD", frames[1].Fields[1].Config.Unit)
+	assert.Equal(t, 1.5, frames[1].Fields[1].At(0))
+}
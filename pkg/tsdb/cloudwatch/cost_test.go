@@ -0,0 +1,224 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+func budgetTestInstanceManager(budget models.CostBudget) instancemgmt.InstanceManager {
+	return datasource.NewInstanceManager(func(ctx context.Context, s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return DataSource{Settings: models.CloudWatchSettings{CostBudget: budget}, sessions: &fakeSessionCache{}}, nil
+	})
+}
+
+func metricStatQuery(refID string, id string) backend.DataQuery {
+	now := time.Now()
+	return backend.DataQuery{
+		RefID: refID,
+		TimeRange: backend.TimeRange{
+			From: now.Add(time.Hour * -2),
+			To:   now.Add(time.Hour * -1),
+		},
+		JSON: json.RawMessage(`{
+			"type":       "timeSeriesQuery",
+			"namespace":  "AWS/EC2",
+			"metricName": "NetworkOut",
+			"dimensions": {
+				"InstanceId": "i-00645d91ed77d87ac"
+			},
+			"region":     "us-east-2",
+			"id":         "` + id + `",
+			"statistic":  "Maximum",
+			"period":     "300",
+			"matchExact": true,
+			"refId":      "` + refID + `"
+		}`),
+	}
+}
+
+func TestTimeSeriesQuery_cost(t *testing.T) {
+	origNewCWClient := NewCWClient
+	t.Cleanup(func() {
+		NewCWClient = origNewCWClient
+	})
+	var api mocks.MetricsAPI
+	NewCWClient = func(sess *session.Session) cloudwatchiface.CloudWatchAPI {
+		return &api
+	}
+	now := time.Now()
+
+	t.Run("adds the API calls and metrics requested to the frame stats", func(t *testing.T) {
+		api = mocks.MetricsAPI{}
+		api.On("GetMetricDataWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []*cloudwatch.MetricDataResult{
+				{StatusCode: aws.String("Complete"), Id: aws.String("a"), Label: aws.String("i-1"), Values: []*float64{aws.Float64(1.0)}, Timestamps: []*time.Time{&now}},
+				{StatusCode: aws.String("Complete"), Id: aws.String("a"), Label: aws.String("i-2"), Values: []*float64{aws.Float64(2.0)}, Timestamps: []*time.Time{&now}},
+			},
+			NextToken: aws.String("next"),
+		}, nil).Once()
+		api.On("GetMetricDataWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []*cloudwatch.MetricDataResult{
+				{StatusCode: aws.String("Complete"), Id: aws.String("a"), Label: aws.String("i-2"), Values: []*float64{aws.Float64(3.0)}, Timestamps: []*time.Time{&now}},
+			},
+		}, nil).Once()
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{metricStatQuery("A", "a")},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.NotEmpty(t, resp.Responses["A"].Frames)
+		for _, frame := range resp.Responses["A"].Frames {
+			assert.Equal(t, []data.QueryStat{
+				{FieldConfig: data.FieldConfig{DisplayName: "API calls"}, Value: 2},
+				{FieldConfig: data.FieldConfig{DisplayName: "Metrics requested"}, Value: 2},
+			}, frame.Meta.Stats)
+		}
+	})
+
+	t.Run("rejects queries over the metrics requested budget", func(t *testing.T) {
+		api = mocks.MetricsAPI{}
+		api.On("GetMetricDataWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{}, nil)
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{MaxMetricsRequested: 1}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{metricStatQuery("A", "a"), metricStatQuery("B", "b")},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses[""].Error, models.ErrBudgetExceeded.Error())
+		require.Equal(t, backend.ErrorSourcePlugin, resp.Responses[""].ErrorSource)
+		api.AssertNotCalled(t, "GetMetricDataWithContext", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects search expressions that return more metrics than the budget", func(t *testing.T) {
+		api = mocks.MetricsAPI{}
+		api.On("GetMetricDataWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []*cloudwatch.MetricDataResult{
+				{StatusCode: aws.String("Complete"), Id: aws.String("a"), Label: aws.String("i-1"), Values: []*float64{aws.Float64(1.0)}, Timestamps: []*time.Time{&now}},
+				{StatusCode: aws.String("Complete"), Id: aws.String("a"), Label: aws.String("i-2"), Values: []*float64{aws.Float64(2.0)}, Timestamps: []*time.Time{&now}},
+			},
+		}, nil)
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{MaxMetricsRequested: 1}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{metricStatQuery("A", "a")},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses[""].Error, "2 metrics requested, the limit is 1")
+		require.Equal(t, backend.ErrorSourcePlugin, resp.Responses[""].ErrorSource)
+		api.AssertNumberOfCalls(t, "GetMetricDataWithContext", 1)
+	})
+
+	t.Run("stops paging over the API calls budget", func(t *testing.T) {
+		api = mocks.MetricsAPI{}
+		api.On("GetMetricDataWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []*cloudwatch.MetricDataResult{
+				{StatusCode: aws.String("PartialData"), Id: aws.String("a"), Label: aws.String("i-1"), Values: []*float64{aws.Float64(1.0)}, Timestamps: []*time.Time{&now}},
+			},
+			NextToken: aws.String("next"),
+		}, nil)
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{MaxAPICalls: 1}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{metricStatQuery("A", "a")},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses[""].Error, "2 GetMetricData calls, the limit is 1")
+		api.AssertNumberOfCalls(t, "GetMetricDataWithContext", 1)
+	})
+}
+
+func TestSyncLogQuery_cost(t *testing.T) {
+	origNewCWLogsClient := NewCWLogsClient
+	t.Cleanup(func() {
+		NewCWLogsClient = origNewCWLogsClient
+	})
+	var cli *mockLogsSyncClient
+	NewCWLogsClient = func(sess *session.Session) cloudwatchlogsiface.CloudWatchLogsAPI {
+		return cli
+	}
+
+	query := backend.DataQuery{
+		RefID:     "A",
+		TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(1, 0)},
+		JSON: json.RawMessage(`{
+			"queryMode":  "Logs",
+			"expression": "fields @message"
+		}`),
+	}
+
+	t.Run("adds the API calls to the frame stats", func(t *testing.T) {
+		cli = &mockLogsSyncClient{}
+		cli.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{
+			QueryId: aws.String("abcd-efgh-ijkl-mnop"),
+		}, nil)
+		cli.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{
+			Statistics: &cloudwatchlogs.QueryStatistics{BytesScanned: aws.Float64(512)},
+			Status:     aws.String("Complete"),
+		}, nil)
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{MaxLogBytesScanned: 1024}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			Headers:       map[string]string{headerFromAlert: "some value"},
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{query},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		assert.Equal(t, []data.QueryStat{
+			{FieldConfig: data.FieldConfig{DisplayName: "Bytes scanned"}, Value: 512},
+			{FieldConfig: data.FieldConfig{DisplayName: "API calls"}, Value: 2},
+		}, resp.Responses["A"].Frames[0].Meta.Stats)
+	})
+
+	t.Run("stops queries over the bytes scanned budget", func(t *testing.T) {
+		cli = &mockLogsSyncClient{}
+		cli.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{
+			QueryId: aws.String("abcd-efgh-ijkl-mnop"),
+		}, nil)
+		cli.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{
+			Statistics: &cloudwatchlogs.QueryStatistics{BytesScanned: aws.Float64(2048)},
+			Status:     aws.String("Running"),
+		}, nil)
+		cli.On("StopQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StopQueryOutput{
+			Success: aws.Bool(true),
+		}, nil)
+
+		executor := newExecutor(budgetTestInstanceManager(models.CostBudget{MaxLogBytesScanned: 1024}), log.NewNullLogger())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			Headers:       map[string]string{headerFromAlert: "some value"},
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{query},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses["A"].Error, "2048 bytes scanned, the limit is 1024")
+		cli.AssertNumberOfCalls(t, "GetQueryResultsWithContext", 1)
+		cli.AssertNumberOfCalls(t, "StopQueryWithContext", 1)
+	})
+}
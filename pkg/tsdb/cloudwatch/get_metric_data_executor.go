@@ -9,18 +9,29 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/features"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/utils"
 )
 
+// executeRequest calls GetMetricData until all the pages are fetched. Requests that would go over the API calls
+// budget are rejected before they are made. The metrics requested are checked before the first call, and again after
+// each page since search expressions and Metrics Insights queries return a metric for each time series they match.
 func (e *cloudWatchExecutor) executeRequest(ctx context.Context, client cloudwatchiface.CloudWatchAPI,
-	metricDataInput *cloudwatch.GetMetricDataInput) ([]*cloudwatch.GetMetricDataOutput, error) {
+	metricDataInput *cloudwatch.GetMetricDataInput, budget models.CostBudget) ([]*cloudwatch.GetMetricDataOutput, error) {
 	mdo := make([]*cloudwatch.GetMetricDataOutput, 0)
 
+	if err := checkBudget("metrics requested", int64(len(metricDataInput.MetricDataQueries)), budget.MaxMetricsRequested); err != nil {
+		return mdo, err
+	}
+
 	nextToken := ""
 	for {
 		if nextToken != "" {
 			metricDataInput.NextToken = aws.String(nextToken)
 		}
+		if err := checkBudget("GetMetricData calls", int64(len(mdo)+1), budget.MaxAPICalls); err != nil {
+			return mdo, err
+		}
 		// GetMetricData EndTime is exclusive, so we round up to the next minute to get the last data point
 		if features.IsEnabled(ctx, features.FlagCloudWatchRoundUpEndTime) {
 			*metricDataInput.EndTime = metricDataInput.EndTime.Truncate(time.Minute).Add(time.Minute)
@@ -33,6 +44,15 @@ func (e *cloudWatchExecutor) executeRequest(ctx context.Context, client cloudwat
 
 		mdo = append(mdo, resp)
 		utils.QueriesTotalCounter.WithLabelValues(utils.GetMetricDataLabel).Add(float64(len(metricDataInput.MetricDataQueries)))
+
+		metricsRequested := int64(0)
+		for _, metrics := range metricsRequestedByID(mdo) {
+			metricsRequested += metrics
+		}
+		if err := checkBudget("metrics requested", metricsRequested, budget.MaxMetricsRequested); err != nil {
+			return mdo, err
+		}
+
 		if resp.NextToken == nil || *resp.NextToken == "" {
 			break
 		}
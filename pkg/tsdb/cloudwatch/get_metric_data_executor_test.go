@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/features"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -24,7 +25,7 @@ func TestGetMetricDataExecutorTestRequest(t *testing.T) {
 			&cloudwatch.GetMetricDataOutput{
 				MetricDataResults: []*cloudwatch.MetricDataResult{{Values: []*float64{}}},
 			}, nil).Once()
-		_, err := executor.executeRequest(contextWithFeaturesEnabled(features.FlagCloudWatchRoundUpEndTime), mockMetricClient, inputs)
+		_, err := executor.executeRequest(contextWithFeaturesEnabled(features.FlagCloudWatchRoundUpEndTime), mockMetricClient, inputs, models.CostBudget{})
 		require.NoError(t, err)
 		expectedTime, _ := time.Parse("2006-01-02T15:04:05Z07:00", "2024-05-01T01:46:00Z")
 		expectedInput := &cloudwatch.GetMetricDataInput{EndTime: &expectedTime, MetricDataQueries: []*cloudwatch.MetricDataQuery{}}
@@ -45,7 +46,7 @@ func TestGetMetricDataExecutorTestResponse(t *testing.T) {
 		&cloudwatch.GetMetricDataOutput{
 			MetricDataResults: []*cloudwatch.MetricDataResult{{Values: []*float64{aws.Float64(100)}}},
 		}, nil).Once()
-	res, err := executor.executeRequest(context.Background(), mockMetricClient, inputs)
+	res, err := executor.executeRequest(context.Background(), mockMetricClient, inputs, models.CostBudget{})
 	require.NoError(t, err)
 	require.Len(t, res, 2)
 	require.Len(t, res[0].MetricDataResults[0].Values, 2)
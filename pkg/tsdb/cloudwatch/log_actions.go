@@ -117,7 +117,7 @@ func (e *cloudWatchExecutor) executeLogAction(ctx context.Context, logsQuery mod
 	case "StopQuery":
 		data, err = e.handleStopQuery(ctx, logsClient, logsQuery)
 	case "GetQueryResults":
-		data, err = e.handleGetQueryResults(ctx, logsClient, logsQuery, query.RefID)
+		data, err = e.handleGetQueryResults(ctx, logsClient, logsQuery, query.RefID, instance.Settings.MaxLogBytesScanned)
 	case "GetLogEvents":
 		data, err = e.handleGetLogEvents(ctx, logsClient, logsQuery)
 	}
@@ -319,11 +319,16 @@ func (e *cloudWatchExecutor) executeGetQueryResults(ctx context.Context, logsCli
 }
 
 func (e *cloudWatchExecutor) handleGetQueryResults(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
-	logsQuery models.LogsQuery, refID string) (*data.Frame, error) {
+	logsQuery models.LogsQuery, refID string, maxBytesScanned int64) (*data.Frame, error) {
 	getQueryResultsOutput, err := e.executeGetQueryResults(ctx, logsClient, logsQuery)
 	if err != nil {
 		return nil, err
 	}
+	if getQueryResultsOutput.Status != nil && !isTerminated(*getQueryResultsOutput.Status) {
+		if err := e.checkLogBytesScannedBudget(ctx, logsClient, logsQuery, getQueryResultsOutput, maxBytesScanned); err != nil {
+			return nil, err
+		}
+	}
 
 	dataFrame, err := logsResultsToDataframes(getQueryResultsOutput)
 	if err != nil {
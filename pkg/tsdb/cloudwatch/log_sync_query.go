@@ -54,7 +54,7 @@ var executeSyncLogQuery = func(ctx context.Context, e *cloudWatchExecutor, req *
 			refId = q.RefID
 		}
 
-		getQueryResultsOutput, err := e.syncQuery(ctx, logsClient, q, logsQuery, instance.Settings.LogsTimeout.Duration)
+		getQueryResultsOutput, cost, err := e.syncQuery(ctx, logsClient, q, logsQuery, instance.Settings.LogsTimeout.Duration, instance.Settings.MaxLogBytesScanned)
 		var sourceError errorsource.Error
 		if errors.As(err, &sourceError) {
 			errorsource.AddErrorToResponse(refId, resp, sourceError)
@@ -78,6 +78,7 @@ var executeSyncLogQuery = func(ctx context.Context, e *cloudWatchExecutor, req *
 		} else {
 			frames = data.Frames{dataframe}
 		}
+		addCostStats(frames, cost)
 
 		respD := resp.Responses[refId]
 		respD.Frames = frames
@@ -87,11 +88,13 @@ var executeSyncLogQuery = func(ctx context.Context, e *cloudWatchExecutor, req *
 	return resp, nil
 }
 
+// syncQuery runs a logs query until it completes. Queries that scan more bytes than the budget are stopped.
 func (e *cloudWatchExecutor) syncQuery(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
-	queryContext backend.DataQuery, logsQuery models.LogsQuery, logsTimeout time.Duration) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	queryContext backend.DataQuery, logsQuery models.LogsQuery, logsTimeout time.Duration, maxBytesScanned int64) (*cloudwatchlogs.GetQueryResultsOutput, queryCost, error) {
+	cost := queryCost{apiCalls: 1}
 	startQueryOutput, err := e.executeStartQuery(ctx, logsClient, logsQuery, queryContext.TimeRange)
 	if err != nil {
-		return nil, err
+		return nil, cost, err
 	}
 
 	requestParams := models.LogsQuery{
@@ -114,19 +117,23 @@ func (e *cloudWatchExecutor) syncQuery(ctx context.Context, logsClient cloudwatc
 
 	attemptCount := 1
 	for range ticker.C {
+		cost.apiCalls++
 		res, err := e.executeGetQueryResults(ctx, logsClient, requestParams)
 		if err != nil {
-			return nil, err
+			return nil, cost, err
 		}
 		if isTerminated(*res.Status) {
-			return res, err
+			return res, cost, err
+		}
+		if err := e.checkLogBytesScannedBudget(ctx, logsClient, requestParams, res, maxBytesScanned); err != nil {
+			return res, cost, err
 		}
 		if time.Duration(attemptCount)*time.Second >= logsTimeout {
-			return res, fmt.Errorf("time to fetch query results exceeded logs timeout")
+			return res, cost, fmt.Errorf("time to fetch query results exceeded logs timeout")
 		}
 
 		attemptCount++
 	}
 
-	return nil, nil
+	return nil, cost, nil
 }
@@ -5,3 +5,5 @@ import "fmt"
 // put misc expected user errors here
 
 var ErrMissingRegion = fmt.Errorf("missing default region")
+
+var ErrBudgetExceeded = fmt.Errorf("query exceeds the cost budget of the data source")
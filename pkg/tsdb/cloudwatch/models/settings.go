@@ -18,11 +18,22 @@ type CloudWatchSettings struct {
 	Namespace               string   `json:"customMetricsNamespaces"`
 	SecureSocksProxyEnabled bool     `json:"enableSecureSocksProxy"` // this can be removed when https://github.com/grafana/grafana/issues/39089 is implemented
 	LogsTimeout             Duration `json:"logsTimeout"`
+	CostBudget
 
 	// GrafanaSettings are fetched from the GrafanaCfg in the context
 	GrafanaSettings awsds.AuthSettings `json:"-"`
 }
 
+// CostBudget limits the usage of the CloudWatch APIs by the queries of the data source, a zero limit means no limit
+type CostBudget struct {
+	// GetMetricData calls for a batch of metric queries
+	MaxAPICalls int64 `json:"maxApiCalls"`
+	// Metrics requested by a batch of metric queries
+	MaxMetricsRequested int64 `json:"maxMetricsRequested"`
+	// Bytes scanned by a logs query
+	MaxLogBytesScanned int64 `json:"maxLogBytesScanned"`
+}
+
 func LoadCloudWatchSettings(ctx context.Context, config backend.DataSourceInstanceSettings) (CloudWatchSettings, error) {
 	instance := CloudWatchSettings{}
 
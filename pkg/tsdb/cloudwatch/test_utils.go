@@ -75,6 +75,10 @@ func (m *mockLogsSyncClient) StartQueryWithContext(ctx context.Context, input *c
 	args := m.Called(ctx, input, option)
 	return args.Get(0).(*cloudwatchlogs.StartQueryOutput), args.Error(1)
 }
+func (m *mockLogsSyncClient) StopQueryWithContext(ctx context.Context, input *cloudwatchlogs.StopQueryInput, option ...request.Option) (*cloudwatchlogs.StopQueryOutput, error) {
+	args := m.Called(ctx, input, option)
+	return args.Get(0).(*cloudwatchlogs.StopQueryOutput), args.Error(1)
+}
 
 func (m *fakeCWLogsClient) DescribeLogGroupsWithContext(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, option ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
 	m.calls.describeLogGroups = append(m.calls.describeLogGroups, input)
@@ -92,7 +92,7 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 					return err
 				}
 
-				mdo, err := e.executeRequest(ectx, client, metricDataInput)
+				mdo, err := e.executeRequest(ectx, client, metricDataInput, instance.Settings.CostBudget)
 				if err != nil {
 					return err
 				}
@@ -118,7 +118,17 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 					return err
 				}
 
+				// the API calls are shared by the queries of the batch
+				metricsByID := metricsRequestedByID(mdo)
+				metricsByRefID := make(map[string]int64, len(requestQueries))
+				for _, query := range requestQueries {
+					metricsByRefID[query.RefId] += metricsByID[query.Id]
+				}
 				for _, responseWrapper := range res {
+					addCostStats(responseWrapper.DataResponse.Frames, queryCost{
+						apiCalls:         int64(len(mdo)),
+						metricsRequested: metricsByRefID[responseWrapper.RefId],
+					})
 					resultChan <- responseWrapper
 				}
 
@@ -51,6 +51,10 @@ type Client interface {
 	GetConfiguredFields() ConfiguredFields
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	ExecuteEsql(r *EsqlRequest) (*ColumnarResponse, error)
+	ExecuteSQL(r *SQLRequest) (*ColumnarResponse, error)
+	CloseSQLCursor(cursor string) error
+	ExecutePaginatedSearch(r *SearchRequest, limit int) (*SearchResponse, error)
 }
 
 // NewClient creates a new elasticsearch client
@@ -116,11 +120,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 			return nil, err
 		}
 
-		body := string(reqBody)
-		body = strings.ReplaceAll(body, "$__interval_ms", strconv.FormatInt(r.interval.Milliseconds(), 10))
-		body = strings.ReplaceAll(body, "$__interval", r.interval.String())
-
-		payload.WriteString(body + "\n")
+		payload.WriteString(replaceIntervalVariables(string(reqBody), r.interval) + "\n")
 	}
 
 	elapsed := time.Since(start)
@@ -129,20 +129,22 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
+func replaceIntervalVariables(body string, interval time.Duration) string {
+	body = strings.ReplaceAll(body, "$__interval_ms", strconv.FormatInt(interval.Milliseconds(), 10))
+	return strings.ReplaceAll(body, "$__interval", interval.String())
+}
+
 func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
-	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
-	u, err := url.Parse(c.ds.URL)
+	u, err := c.requestURL(uriPath, uriQuery)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = path.Join(u.Path, uriPath)
-	u.RawQuery = uriQuery
 
 	var req *http.Request
 	if method == http.MethodPost {
-		req, err = http.NewRequestWithContext(c.ctx, http.MethodPost, u.String(), bytes.NewBuffer(body))
+		req, err = http.NewRequestWithContext(c.ctx, http.MethodPost, u, bytes.NewBuffer(body))
 	} else {
-		req, err = http.NewRequestWithContext(c.ctx, http.MethodGet, u.String(), nil)
+		req, err = http.NewRequestWithContext(c.ctx, http.MethodGet, u, nil)
 	}
 	if err != nil {
 		return nil, err
@@ -158,6 +160,17 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 	return resp, nil
 }
 
+func (c *baseClientImpl) requestURL(uriPath, uriQuery string) (string, error) {
+	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
+	u, err := url.Parse(c.ds.URL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, uriPath)
+	u.RawQuery = uriQuery
+	return u.String(), nil
+}
+
 func (c *baseClientImpl) ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error) {
 	var err error
 	multiRequests := c.createMultiSearchRequests(r.Requests)
@@ -3,9 +3,12 @@ package es
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -286,6 +289,164 @@ func TestClient_Index(t *testing.T) {
 	}
 }
 
+func TestClient_ExecuteColumnar(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(buf))
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, err = rw.Write([]byte(`{
+			"columns": [{ "name": "count", "type": "long" }],
+			"values": [[ 9007199254740993 ]],
+			"rows": [[ 1 ]],
+			"cursor": "abc"
+		}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:        ts.URL,
+		HTTPClient: ts.Client(),
+		Database:   "metrics",
+	}
+	c, err := NewClient(context.Background(), &ds, log.New())
+	require.NoError(t, err)
+
+	t.Run("ExecuteEsql", func(t *testing.T) {
+		requests, bodies = nil, nil
+		res, err := c.ExecuteEsql(&EsqlRequest{Query: "FROM metrics | STATS count = COUNT(*)"})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Equal(t, "/_query", requests[0].URL.Path)
+		assert.Equal(t, "format=json", requests[0].URL.RawQuery)
+		assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"query":"FROM metrics | STATS count = COUNT(*)"}`, bodies[0])
+
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, []ColumnarColumn{{Name: "count", Type: "long"}}, res.Columns)
+		assert.Equal(t, "9007199254740993", res.Records()[0][0].(fmt.Stringer).String())
+	})
+
+	t.Run("ExecuteSQL", func(t *testing.T) {
+		requests, bodies = nil, nil
+		res, err := c.ExecuteSQL(&SQLRequest{Cursor: "abc"})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, "/_sql", requests[0].URL.Path)
+		assert.JSONEq(t, `{"cursor":"abc"}`, bodies[0])
+		assert.Equal(t, "abc", res.Cursor)
+	})
+
+	t.Run("CloseSQLCursor", func(t *testing.T) {
+		requests, bodies = nil, nil
+		err := c.CloseSQLCursor("abc")
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, "/_sql/close", requests[0].URL.Path)
+		assert.JSONEq(t, `{"cursor":"abc"}`, bodies[0])
+	})
+}
+
+func TestClient_ExecutePaginatedSearch(t *testing.T) {
+	var paths []string
+	var bodies []*simplejson.Json
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var res string
+		switch r.URL.Path {
+		case "/metrics/_pit":
+			res = `{"id": "pit-1"}`
+		case "/_pit":
+			res = `{"succeeded": true}`
+		default:
+			body, err := simplejson.NewJson(buf)
+			require.NoError(t, err)
+			bodies = append(bodies, body)
+			// two pages of two hits and a last page with a single hit
+			pages := [][]string{
+				{`{"_id": "1", "sort": [3, 10]}`, `{"_id": "2", "sort": [2, 11]}`},
+				{`{"_id": "3", "sort": [2, 12]}`, `{"_id": "4", "sort": [1, 13]}`},
+				{`{"_id": "5", "sort": [1, 14]}`},
+			}
+			hits := pages[min(len(bodies), len(pages))-1]
+			hits = hits[:min(len(hits), body.Get("size").MustInt())]
+			res = `{"pit_id": "pit-2", "hits": {"hits": [` + strings.Join(hits, ",") + `]}}`
+		}
+		_, err = rw.Write([]byte(res))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:        ts.URL,
+		HTTPClient: ts.Client(),
+		Database:   "metrics",
+	}
+	c, err := NewClient(context.Background(), &ds, log.New())
+	require.NoError(t, err)
+
+	searchPageSize = 2
+	t.Cleanup(func() {
+		searchPageSize = MaxSearchPageSize
+	})
+
+	sr := &SearchRequest{
+		Size: 10,
+		Sort: map[string]any{
+			"@timestamp": map[string]string{"order": "desc"},
+			"_doc":       map[string]string{"order": "desc"},
+		},
+		CustomProps: map[string]any{},
+		Aggs:        AggArray{{Key: "1", Aggregation: &aggContainer{Type: "date_histogram", Aggregation: map[string]any{}}}},
+	}
+
+	t.Run("Reads pages until the limit is reached", func(t *testing.T) {
+		paths, bodies = nil, nil
+		res, err := c.ExecutePaginatedSearch(sr, 3)
+		require.NoError(t, err)
+
+		require.Len(t, res.Hits.Hits, 3)
+		assert.Empty(t, res.PitID)
+		assert.Equal(t, []string{"POST /metrics/_pit", "POST /_search", "POST /_search", "DELETE /_pit"}, paths)
+
+		// the first page has the aggregations, following pages continue after the last hit
+		assert.Equal(t, int64(2), bodies[0].Get("size").MustInt64())
+		assert.Equal(t, "pit-1", bodies[0].GetPath("pit", "id").MustString())
+		assert.NotNil(t, bodies[0].Get("aggs").Interface())
+		_, hasDocSort := bodies[0].Get("sort").CheckGet("_doc")
+		assert.False(t, hasDocSort)
+
+		assert.Equal(t, int64(1), bodies[1].Get("size").MustInt64())
+		assert.Equal(t, "pit-2", bodies[1].GetPath("pit", "id").MustString())
+		assert.Nil(t, bodies[1].Get("aggs").Interface())
+		assert.Equal(t, []any{json.Number("2"), json.Number("11")}, bodies[1].Get("search_after").MustArray())
+
+		// the request of the caller is not modified
+		assert.Contains(t, sr.Sort, "_doc")
+		assert.NotContains(t, sr.CustomProps, "pit")
+	})
+
+	t.Run("Stops at the last page", func(t *testing.T) {
+		paths, bodies = nil, nil
+		res, err := c.ExecutePaginatedSearch(sr, 100)
+		require.NoError(t, err)
+		require.Len(t, res.Hits.Hits, 5)
+		require.Len(t, bodies, 3)
+	})
+}
+
 func createMultisearchForTest(t *testing.T, c Client, timeRange backend.TimeRange) (*MultiSearchRequest, error) {
 	t.Helper()
 
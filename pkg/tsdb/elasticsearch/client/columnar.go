@@ -0,0 +1,160 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	exp "github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+// EsqlRequest represents a request to the ES|QL `_query` API
+type EsqlRequest struct {
+	Query  string `json:"query"`
+	Filter *Query `json:"filter,omitempty"`
+}
+
+// SQLRequest represents a request to the `_sql` API. Either Query or Cursor is set,
+// follow-up pages of a result are requested with the cursor of the previous page only.
+type SQLRequest struct {
+	Query     string `json:"query,omitempty"`
+	Filter    *Query `json:"filter,omitempty"`
+	FetchSize int    `json:"fetch_size,omitempty"`
+	TimeZone  string `json:"time_zone,omitempty"`
+	Cursor    string `json:"cursor,omitempty"`
+}
+
+// ColumnarColumn represents a column of an ES|QL or SQL response
+type ColumnarColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ColumnarResponse represents an ES|QL or SQL response. Columns are only sent with the first page of a SQL result.
+type ColumnarResponse struct {
+	Status  int              `json:"status,omitempty"`
+	Error   map[string]any   `json:"error"`
+	Columns []ColumnarColumn `json:"columns"`
+	// Values holds the rows of an ES|QL response
+	Values [][]any `json:"values"`
+	// Rows holds the rows of a SQL response
+	Rows [][]any `json:"rows"`
+	// Cursor is set when a SQL result has more pages
+	Cursor    string `json:"cursor"`
+	IsPartial bool   `json:"is_partial"`
+}
+
+// Records returns the rows of the response regardless of the API it was returned from
+func (r *ColumnarResponse) Records() [][]any {
+	if r.Values != nil {
+		return r.Values
+	}
+	return r.Rows
+}
+
+func (c *baseClientImpl) ExecuteEsql(r *EsqlRequest) (*ColumnarResponse, error) {
+	return c.executeColumnarRequest("_query", "format=json", r)
+}
+
+func (c *baseClientImpl) ExecuteSQL(r *SQLRequest) (*ColumnarResponse, error) {
+	return c.executeColumnarRequest("_sql", "format=json", r)
+}
+
+func (c *baseClientImpl) CloseSQLCursor(cursor string) error {
+	body, err := json.Marshal(SQLRequest{Cursor: cursor})
+	if err != nil {
+		return err
+	}
+	res, err := c.executeJSONRequest(http.MethodPost, "_sql/close", "", body)
+	if err != nil {
+		return err
+	}
+	if err := res.Body.Close(); err != nil {
+		c.logger.Warn("Failed to close response body", "error", err)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to close sql cursor, status code: %d", res.StatusCode)
+	}
+	return nil
+}
+
+func (c *baseClientImpl) executeColumnarRequest(uriPath, uriQuery string, r any) (*ColumnarResponse, error) {
+	var err error
+	_, span := tracing.DefaultTracer().Start(c.ctx, "datasource.elasticsearch.queryData.executeColumnar", trace.WithAttributes(
+		attribute.String("path", uriPath),
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeJSONRequest(http.MethodPost, uriPath, uriQuery, body)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		lp := []any{"error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest, "path", uriPath}
+		sourceErr := exp.Error{}
+		if errors.As(err, &sourceErr) {
+			lp = append(lp, "statusSource", sourceErr.Source())
+		}
+		c.logger.Error("Error received from Elasticsearch", lp...)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "status", "ok", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest, "path", uriPath)
+
+	var cr ColumnarResponse
+	dec := json.NewDecoder(res.Body)
+	// keep the precision of long values, they are converted when the frame is built
+	dec.UseNumber()
+	err = dec.Decode(&cr)
+	if err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	cr.Status = res.StatusCode
+
+	return &cr, nil
+}
+
+func (c *baseClientImpl) executeJSONRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
+	u, err := c.requestURL(uriPath, uriQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, u, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	//nolint:bodyclose
+	return c.ds.HTTPClient.Do(req)
+}
@@ -54,6 +54,8 @@ type SearchResponse struct {
 	Error        map[string]interface{} `json:"error"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 	Hits         *SearchResponseHits    `json:"hits"`
+	// PitID is the id of the point in time of a paginated search, it can change between pages
+	PitID string `json:"pit_id,omitempty"`
 }
 
 // MultiSearchRequest represents a multi search request
@@ -0,0 +1,183 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+)
+
+const (
+	// MaxSearchPageSize is the default `index.max_result_window`, larger results have to be paginated
+	MaxSearchPageSize = 10000
+	// pointInTimeKeepAlive only needs to cover the time between two pages
+	pointInTimeKeepAlive = "1m"
+)
+
+var searchPageSize = MaxSearchPageSize
+
+// ExecutePaginatedSearch returns up to limit hits of the search request. The hits are read in pages from a point in time,
+// so that documents indexed while paginating don't shift the pages. Aggregations are only computed for the first page.
+func (c *baseClientImpl) ExecutePaginatedSearch(r *SearchRequest, limit int) (*SearchResponse, error) {
+	var err error
+	_, span := tracing.DefaultTracer().Start(c.ctx, "datasource.elasticsearch.queryData.executePaginatedSearch", trace.WithAttributes(
+		attribute.Int("limit", limit),
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	indices, err := c.indexPattern.GetIndices(r.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+	pitID, err := c.openPointInTime(indices)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := c.closePointInTime(pitID); err != nil {
+			c.logger.Warn("Failed to close point in time", "error", err)
+		}
+	}()
+
+	// the point in time adds an implicit `_shard_doc` tiebreaker, sorting on `_doc` is not stable across shards
+	sort := make(map[string]any, len(r.Sort))
+	for field, props := range r.Sort {
+		if field != "_doc" {
+			sort[field] = props
+		}
+	}
+	page := *r
+	page.Sort = sort
+	page.CustomProps = make(map[string]any, len(r.CustomProps)+1)
+	for key, value := range r.CustomProps {
+		page.CustomProps[key] = value
+	}
+
+	var result *SearchResponse
+	for {
+		page.CustomProps["pit"] = map[string]any{"id": pitID, "keep_alive": pointInTimeKeepAlive}
+		remaining := limit
+		if result != nil {
+			remaining -= len(result.Hits.Hits)
+		}
+		page.Size = min(remaining, searchPageSize)
+
+		var res *SearchResponse
+		res, err = c.search(&page)
+		if err != nil {
+			return nil, err
+		}
+		if res.Error != nil {
+			return res, nil
+		}
+		if res.PitID != "" {
+			pitID = res.PitID
+		}
+		if res.Hits == nil {
+			res.Hits = &SearchResponseHits{}
+		}
+
+		if result == nil {
+			result = res
+		} else {
+			result.Hits.Hits = append(result.Hits.Hits, res.Hits.Hits...)
+		}
+		if len(res.Hits.Hits) < page.Size || len(result.Hits.Hits) >= limit {
+			break
+		}
+
+		last := res.Hits.Hits[len(res.Hits.Hits)-1]
+		searchAfter, ok := last["sort"].([]any)
+		if !ok {
+			break
+		}
+		page.CustomProps["search_after"] = searchAfter
+		page.Aggs = nil
+	}
+	result.PitID = ""
+
+	c.logger.Debug("Completed paginated search", "hitsLength", len(result.Hits.Hits), "limit", limit)
+	return result, nil
+}
+
+func (c *baseClientImpl) search(r *SearchRequest) (*SearchResponse, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeJSONRequest(http.MethodPost, "_search", "", []byte(replaceIntervalVariables(string(body), r.Interval)))
+	if err != nil {
+		c.logger.Error("Error received from Elasticsearch", "error", err, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	c.logger.Debug("Response received from Elasticsearch", "statusCode", res.StatusCode, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	var sr SearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
+
+func (c *baseClientImpl) openPointInTime(indices []string) (string, error) {
+	uriQuery := "keep_alive=" + pointInTimeKeepAlive + "&ignore_unavailable=true"
+	res, err := c.executeJSONRequest(http.MethodPost, strings.Join(indices, ",")+"/_pit", uriQuery, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	var pit struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		return "", err
+	}
+	if pit.ID == "" {
+		return "", fmt.Errorf("failed to open point in time, status code: %d", res.StatusCode)
+	}
+	return pit.ID, nil
+}
+
+func (c *baseClientImpl) closePointInTime(id string) error {
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+	res, err := c.executeJSONRequest(http.MethodDelete, "_pit", "", body)
+	if err != nil {
+		return err
+	}
+	if err := res.Body.Close(); err != nil {
+		c.logger.Warn("Failed to close response body", "error", err)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to close point in time, status code: %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// Query types sending the query text to the ES|QL and SQL APIs instead of building a search request
+	esqlQueryType = "esql"
+	sqlQueryType  = "sql"
+
+	// sqlFetchSize is the page size of SQL results, further pages are requested with the returned cursor
+	sqlFetchSize = 1000
+	// sqlRowLimit is the maximum number of rows read from a SQL result, the cursor is closed when it is reached
+	sqlRowLimit = 10000
+)
+
+func isColumnarQuery(query *Query) bool {
+	return query.QueryType == esqlQueryType || query.QueryType == sqlQueryType
+}
+
+// executeColumnarQuery runs an ES|QL or SQL query and adds its result to the response
+func (e *elasticsearchDataQuery) executeColumnarQuery(q *Query, response *backend.QueryDataResponse) {
+	start := time.Now()
+	if strings.TrimSpace(q.RawQuery) == "" {
+		errorsource.AddPluginErrorToResponse(q.RefID, response, fmt.Errorf("invalid query, missing %s query", q.QueryType))
+		return
+	}
+
+	from := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
+	to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	filter := &es.Query{
+		Bool: &es.BoolQuery{
+			Filters: []es.Filter{&es.RangeFilter{
+				Key:    e.client.GetConfiguredFields().TimeField,
+				Lte:    to,
+				Gte:    from,
+				Format: es.DateFormatEpochMS,
+			}},
+		},
+	}
+	query := strings.ReplaceAll(q.RawQuery, "$__interval_ms", fmt.Sprintf("%d", q.Interval.Milliseconds()))
+	query = strings.ReplaceAll(query, "$__interval", formatColumnarInterval(q.Interval, q.QueryType))
+
+	var res *es.ColumnarResponse
+	var err error
+	truncated := false
+	if q.QueryType == esqlQueryType {
+		res, err = e.client.ExecuteEsql(&es.EsqlRequest{Query: query, Filter: filter})
+	} else {
+		res, truncated, err = e.executeSQL(query, filter)
+	}
+	if err != nil {
+		// We are returning error containing the source that was added trough errorsource.Middleware
+		errorsource.AddErrorToResponse(q.RefID, response, err)
+		return
+	}
+	if res.Error != nil {
+		me, _ := json.Marshal(res.Error)
+		e.logger.Error("Processing error response from Elasticsearch", "error", string(me), "queryType", q.QueryType)
+		errResult := getErrorFromElasticResponse(&es.SearchResponse{Error: res.Error})
+		response.Responses[q.RefID] = errorsource.Response(errorsource.PluginError(errors.New(errResult), false))
+		return
+	}
+
+	queryRes := backend.DataResponse{}
+	if err := processColumnarResponse(res, q, query, e.client.GetConfiguredFields(), &queryRes); err != nil {
+		e.logger.Error("Error processing columnar response", "error", err, "queryType", q.QueryType, "stage", es.StageParseResponse)
+		errorsource.AddPluginErrorToResponse(q.RefID, response, err)
+		return
+	}
+	if truncated {
+		addNotice(queryRes.Frames, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("The result was limited to %d rows", sqlRowLimit),
+		})
+	}
+	if res.IsPartial {
+		addNotice(queryRes.Frames, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     "Elasticsearch returned partial results",
+		})
+	}
+	response.Responses[q.RefID] = queryRes
+	e.logger.Info("Finished processing of columnar response", "duration", time.Since(start), "queryType", q.QueryType, "stage", es.StageParseResponse)
+}
+
+// executeSQL reads the pages of a SQL result until the cursor is exhausted or sqlRowLimit rows were read.
+// It reports whether the result was truncated.
+func (e *elasticsearchDataQuery) executeSQL(query string, filter *es.Query) (*es.ColumnarResponse, bool, error) {
+	res, err := e.client.ExecuteSQL(&es.SQLRequest{
+		Query:     query,
+		Filter:    filter,
+		FetchSize: sqlFetchSize,
+		TimeZone:  "UTC",
+	})
+	if err != nil || res.Error != nil {
+		return res, false, err
+	}
+
+	for res.Cursor != "" {
+		if len(res.Rows) >= sqlRowLimit {
+			if err := e.client.CloseSQLCursor(res.Cursor); err != nil {
+				e.logger.Warn("Failed to close sql cursor", "error", err)
+			}
+			res.Rows = res.Rows[:sqlRowLimit]
+			return res, true, nil
+		}
+
+		page, err := e.client.ExecuteSQL(&es.SQLRequest{Cursor: res.Cursor})
+		if err != nil || page.Error != nil {
+			return page, false, err
+		}
+		res.Rows = append(res.Rows, page.Rows...)
+		res.Cursor = page.Cursor
+	}
+	return res, false, nil
+}
+
+// intervalUnits are the units of the intervals of ES|QL and SQL queries, from the largest to the smallest
+var intervalUnits = []struct {
+	duration time.Duration
+	name     string
+}{
+	{24 * time.Hour, "day"},
+	{time.Hour, "hour"},
+	{time.Minute, "minute"},
+	{time.Second, "second"},
+	{time.Millisecond, "millisecond"},
+}
+
+// formatColumnarInterval formats the interval in the largest unit it is a multiple of, as a time span of
+// ES|QL, such as "1 minute", or as an interval literal of SQL, such as "INTERVAL 1 MINUTE". SQL has no
+// millisecond unit, intervals with milliseconds are formatted as fractional seconds.
+func formatColumnarInterval(interval time.Duration, queryType string) string {
+	interval = max(interval.Truncate(time.Millisecond), time.Millisecond)
+	for _, unit := range intervalUnits {
+		if interval%unit.duration != 0 {
+			continue
+		}
+		if unit.duration == time.Millisecond && queryType == sqlQueryType {
+			break
+		}
+		count := int64(interval / unit.duration)
+		name := unit.name
+		if count != 1 {
+			name += "s"
+		}
+		if queryType == sqlQueryType {
+			return fmt.Sprintf("INTERVAL %d %s", count, strings.ToUpper(name))
+		}
+		return fmt.Sprintf("%d %s", count, name)
+	}
+	return fmt.Sprintf("INTERVAL '%s' SECONDS", strconv.FormatFloat(interval.Seconds(), 'f', -1, 64))
+}
+
+func addNotice(frames data.Frames, notice data.Notice) {
+	if len(frames) == 0 {
+		return
+	}
+	if frames[0].Meta == nil {
+		frames[0].Meta = &data.FrameMeta{}
+	}
+	frames[0].Meta.Notices = append(frames[0].Meta.Notices, notice)
+}
+
+// processColumnarResponse converts the columns and rows of an ES|QL or SQL response into typed fields.
+// Results with a single time column and numeric columns are returned as time series, one per numeric
+// column and combination of the values of the string columns, everything else is returned as a table.
+func processColumnarResponse(res *es.ColumnarResponse, target *Query, executedQuery string, configuredFields es.ConfiguredFields, queryRes *backend.DataResponse) error {
+	records := res.Records()
+	fields := make([]*data.Field, len(res.Columns))
+	for i, column := range res.Columns {
+		field, err := newColumnarField(column, records, i)
+		if err != nil {
+			return err
+		}
+		fields[i] = field
+	}
+
+	if frames, ok := columnarTimeSeries(res.Columns, fields, configuredFields); ok {
+		for _, frame := range frames {
+			frame.Meta.ExecutedQueryString = executedQuery
+		}
+		queryRes.Frames = frames
+		return nil
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = target.RefID
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: executedQuery}
+	setPreferredVisType(frame, data.VisTypeTable)
+	queryRes.Frames = data.Frames{frame}
+	return nil
+}
+
+func columnarFieldType(esType string) data.FieldType {
+	switch esType {
+	case "date", "datetime", "date_nanos":
+		return data.FieldTypeNullableTime
+	case "long", "integer", "short", "byte", "counter_long", "counter_integer":
+		return data.FieldTypeNullableInt64
+	case "double", "float", "half_float", "scaled_float", "unsigned_long", "counter_double":
+		return data.FieldTypeNullableFloat64
+	case "boolean":
+		return data.FieldTypeNullableBool
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+func newColumnarField(column es.ColumnarColumn, records [][]any, index int) (*data.Field, error) {
+	field := data.NewFieldFromFieldType(columnarFieldType(column.Type), len(records))
+	field.Name = column.Name
+
+	for row, record := range records {
+		if index >= len(record) || record[index] == nil {
+			continue
+		}
+		value := record[index]
+
+		switch field.Type() {
+		case data.FieldTypeNullableTime:
+			t, err := parseColumnarTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", column.Name, err)
+			}
+			field.Set(row, &t)
+		case data.FieldTypeNullableInt64:
+			n, ok := value.(json.Number)
+			if !ok {
+				// multi-valued fields are returned as arrays, they can't be represented as a number
+				continue
+			}
+			i, err := n.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", column.Name, err)
+			}
+			field.Set(row, &i)
+		case data.FieldTypeNullableFloat64:
+			n, ok := value.(json.Number)
+			if !ok {
+				continue
+			}
+			f, err := n.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", column.Name, err)
+			}
+			field.Set(row, &f)
+		case data.FieldTypeNullableBool:
+			b, ok := value.(bool)
+			if !ok {
+				continue
+			}
+			field.Set(row, &b)
+		default:
+			s, ok := value.(string)
+			if !ok {
+				b, err := json.Marshal(value)
+				if err != nil {
+					return nil, fmt.Errorf("column %q: %w", column.Name, err)
+				}
+				s = string(b)
+			}
+			field.Set(row, &s)
+		}
+	}
+	return field, nil
+}
+
+func parseColumnarTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.UTC(), nil
+	case json.Number:
+		ms, err := v.Int64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(ms).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unexpected time value %v", value)
+	}
+}
+
+type columnarSeries struct {
+	name   string
+	labels data.Labels
+	times  []time.Time
+	values []*float64
+}
+
+// columnarTimeSeries groups the rows of a columnar result into time series. The configured time field is
+// used as time column when it is part of the result, otherwise the result needs exactly one time column.
+func columnarTimeSeries(columns []es.ColumnarColumn, fields []*data.Field, configuredFields es.ConfiguredFields) (data.Frames, bool) {
+	timeIndex := -1
+	timeColumns := 0
+	var valueIndices, labelIndices []int
+	for i, field := range fields {
+		switch field.Type() {
+		case data.FieldTypeNullableTime:
+			timeColumns++
+			if timeIndex == -1 || columns[i].Name == configuredFields.TimeField {
+				timeIndex = i
+			}
+		case data.FieldTypeNullableInt64, data.FieldTypeNullableFloat64:
+			valueIndices = append(valueIndices, i)
+		case data.FieldTypeNullableString:
+			labelIndices = append(labelIndices, i)
+		default:
+			return nil, false
+		}
+	}
+	if timeIndex == -1 || len(valueIndices) == 0 {
+		return nil, false
+	}
+	if timeColumns > 1 && columns[timeIndex].Name != configuredFields.TimeField {
+		return nil, false
+	}
+
+	timeField := fields[timeIndex]
+	rows := make([]int, 0, timeField.Len())
+	for row := 0; row < timeField.Len(); row++ {
+		if timeField.At(row).(*time.Time) != nil {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, false
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return timeField.At(rows[i]).(*time.Time).Before(*timeField.At(rows[j]).(*time.Time))
+	})
+
+	var keys []string
+	series := make(map[string]*columnarSeries)
+	for _, row := range rows {
+		labels := data.Labels{}
+		for _, i := range labelIndices {
+			if v := fields[i].At(row).(*string); v != nil {
+				labels[columns[i].Name] = *v
+			}
+		}
+		for _, i := range valueIndices {
+			key := columns[i].Name + "\x00" + labels.String()
+			s, ok := series[key]
+			if !ok {
+				s = &columnarSeries{name: columns[i].Name, labels: labels}
+				series[key] = s
+				keys = append(keys, key)
+			}
+			s.times = append(s.times, *timeField.At(row).(*time.Time))
+			s.values = append(s.values, columnarFloatAt(fields[i], row))
+		}
+	}
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		s := series[key]
+		frame := newTimeSeriesFrame(s.times, s.labels, s.values)
+		frame.Fields[1].Name = s.name
+		frames = append(frames, frame)
+	}
+	return frames, true
+}
+
+func columnarFloatAt(field *data.Field, row int) *float64 {
+	switch v := field.At(row).(type) {
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		f := float64(*v)
+		return &f
+	case *float64:
+		return v
+	}
+	return nil
+}
@@ -55,11 +55,53 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	searchQueries := make([]*Query, 0, len(queries))
+	columnarQueries := make([]*Query, 0)
+	for _, q := range queries {
+		if isColumnarQuery(q) {
+			columnarQueries = append(columnarQueries, q)
+		} else {
+			searchQueries = append(searchQueries, q)
+		}
+	}
+	if len(columnarQueries) == 0 {
+		return e.executeMultisearch(queries, start)
+	}
+
+	// ES|QL and SQL queries are sent one by one, the remaining queries are still sent in a single multisearch request
+	if len(searchQueries) > 0 {
+		res, err := e.executeMultisearch(searchQueries, start)
+		if err != nil {
+			return res, err
+		}
+		response = res
+	}
+	for _, q := range columnarQueries {
+		e.executeColumnarQuery(q, response)
+	}
+	return response, nil
+}
+
+func (e *elasticsearchDataQuery) executeMultisearch(queries []*Query, start time.Time) (*backend.QueryDataResponse, error) {
+	response := backend.NewQueryDataResponse()
 	ms := e.client.MultiSearch()
+	// logs queries with a limit above the maximum page size are not part of the multisearch request,
+	// they are paginated separately and their responses are put back in the order of the queries
+	paginated := make(map[int]*es.SearchRequest)
 
-	for _, q := range queries {
+	for i, q := range queries {
 		from := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 		to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+		if isPaginatedLogsQuery(q) {
+			sr, err := e.buildSearchRequest(q, from, to)
+			if err != nil {
+				mq, _ := json.Marshal(q)
+				e.logger.Error("Failed to build paginated search request", "error", err, "query", string(mq), "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
+				return errorsource.AddPluginErrorToResponse(q.RefID, response, err), nil
+			}
+			paginated[i] = sr
+			continue
+		}
 		if err := e.processQuery(q, ms, from, to); err != nil {
 			mq, _ := json.Marshal(q)
 			e.logger.Error("Failed to process query to multisearch request builder", "error", err, "query", string(mq), "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
@@ -69,19 +111,76 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 
 	req, err := ms.Build()
 	if err != nil {
-		mqs, _ := json.Marshal(e.dataQueries)
+		mqs, _ := json.Marshal(queries)
 		e.logger.Error("Failed to build multisearch request", "error", err, "queriesLength", len(queries), "queries", string(mqs), "duration", time.Since(start), "stage", es.StagePrepareRequest)
-		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+		for _, q := range queries {
+			errorsource.AddPluginErrorToResponse(q.RefID, response, err)
+		}
+		return response, nil
 	}
 
 	e.logger.Info("Prepared request", "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
-	res, err := e.client.ExecuteMultisearch(req)
-	if err != nil {
-		// We are returning error containing the source that was added trough errorsource.Middleware
-		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+	if len(paginated) == 0 {
+		res, err := e.client.ExecuteMultisearch(req)
+		if err != nil {
+			// We are returning error containing the source that was added trough errorsource.Middleware
+			return addMultisearchError(queries, response, err), nil
+		}
+
+		return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger)
+	}
+
+	msResponses := []*es.SearchResponse{}
+	if len(req.Requests) > 0 {
+		res, err := e.client.ExecuteMultisearch(req)
+		if err != nil {
+			return addMultisearchError(queries, response, err), nil
+		}
+		msResponses = res.Responses
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger)
+	responses := make([]*es.SearchResponse, 0, len(queries))
+	for i, q := range queries {
+		sr, ok := paginated[i]
+		if !ok {
+			if len(msResponses) == 0 {
+				break
+			}
+			responses = append(responses, msResponses[0])
+			msResponses = msResponses[1:]
+			continue
+		}
+
+		res, err := e.client.ExecutePaginatedSearch(sr, logsLimit(q))
+		if err != nil {
+			return errorsource.AddErrorToResponse(q.RefID, response, err), nil
+		}
+		responses = append(responses, res)
+	}
+
+	return parseResponse(e.ctx, responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger)
+}
+
+// addMultisearchError records the error of a failed multisearch request under each query of the request, the other
+// queries of the data query have their own responses
+func addMultisearchError(queries []*Query, response *backend.QueryDataResponse, err error) *backend.QueryDataResponse {
+	for _, q := range queries {
+		errorsource.AddErrorToResponse(q.RefID, response, err)
+	}
+	return response
+}
+
+// buildSearchRequest builds the search request of a single query
+func (e *elasticsearchDataQuery) buildSearchRequest(q *Query, from, to int64) (*es.SearchRequest, error) {
+	ms := es.NewMultiSearchRequestBuilder()
+	if err := e.processQuery(q, ms, from, to); err != nil {
+		return nil, err
+	}
+	req, err := ms.Build()
+	if err != nil {
+		return nil, err
+	}
+	return req.Requests[0], nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -336,6 +435,19 @@ func isLogsQuery(query *Query) bool {
 	return query.Metrics[0].Type == logsType
 }
 
+// isPaginatedLogsQuery returns true for logs queries requesting more hits than fit in a single page.
+// Log context queries already continue from a log line and are never paginated.
+func isPaginatedLogsQuery(query *Query) bool {
+	if len(query.Metrics) == 0 || !isLogsQuery(query) {
+		return false
+	}
+	return logsLimit(query) > es.MaxSearchPageSize && len(query.Metrics[0].Settings.Get("searchAfter").MustArray()) == 0
+}
+
+func logsLimit(query *Query) int {
+	return stringToIntWithDefaultValue(query.Metrics[0].Settings.Get("limit").MustString(), defaultSize)
+}
+
 func isDocumentQuery(query *Query) bool {
 	return isRawDataQuery(query) || isRawDocumentQuery(query)
 }
@@ -361,7 +473,7 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 	// We need to add timeField as field with standardized time format to not receive
 	// invalid formats that elasticsearch can parse, but our frontend can't (e.g. yyyy_MM_dd_HH_mm_ss)
 	b.AddTimeFieldWithStandardizedFormat(defaultTimeField)
-	b.Size(stringToIntWithDefaultValue(metric.Settings.Get("limit").MustString(), defaultSize))
+	b.Size(min(logsLimit(q), es.MaxSearchPageSize))
 	b.AddHighlight()
 
 	// This is currently used only for log context query to get
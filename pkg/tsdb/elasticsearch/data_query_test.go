@@ -3,11 +3,13 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -1425,6 +1427,41 @@ func TestExecuteElasticsearchDataQuery(t *testing.T) {
 			require.Equal(t, secondSearchAfter, "2")
 		})
 
+		t.Run("With log query above the maximum page size should paginate", func(t *testing.T) {
+			c := newFakeClient()
+			c.multiSearchResponse = &es.MultiSearchResponse{Responses: []*es.SearchResponse{{Hits: &es.SearchResponseHits{}}}}
+			c.paginatedResponse = &es.SearchResponse{Hits: &es.SearchResponseHits{Hits: []map[string]any{
+				{"_id": "1", "_index": "logs", "_source": map[string]any{"line": "hello"}},
+			}}}
+			res, err := executeElasticsearchDataQueries(c, []string{`{
+				"refId": "A",
+				"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "25000" }}]
+			}`, `{
+				"refId": "B",
+				"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "100" }}]
+			}`}, from, to)
+			require.NoError(t, err)
+
+			require.Len(t, c.paginatedRequests, 1)
+			require.Equal(t, []int{25000}, c.paginatedLimits)
+			require.Len(t, c.multisearchRequests, 1)
+			require.Len(t, c.multisearchRequests[0].Requests, 1)
+			require.Equal(t, 100, c.multisearchRequests[0].Requests[0].Size)
+
+			requireFrameLength(t, res.Responses["A"].Frames[0], 1)
+			require.Len(t, res.Responses["B"].Frames, 1)
+		})
+
+		t.Run("With log context query above the maximum page size should not paginate", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeElasticsearchDataQuery(c, `{
+				"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "25000", "searchAfter": [1, "2"] }}]
+			}`, from, to)
+			require.NoError(t, err)
+			require.Len(t, c.paginatedRequests, 0)
+			require.Equal(t, es.MaxSearchPageSize, c.multisearchRequests[0].Requests[0].Size)
+		})
+
 		t.Run("With invalid query should return error", (func(t *testing.T) {
 			c := newFakeClient()
 			res, err := executeElasticsearchDataQuery(c, `{
@@ -1437,6 +1474,206 @@ func TestExecuteElasticsearchDataQuery(t *testing.T) {
 	})
 }
 
+func TestExecuteColumnarQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+	fromMs := from.UnixNano() / int64(time.Millisecond)
+	toMs := to.UnixNano() / int64(time.Millisecond)
+
+	t.Run("ES|QL query is sent with a time range filter", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ColumnarResponse{
+			Columns: []es.ColumnarColumn{{Name: "host", Type: "keyword"}, {Name: "count", Type: "long"}},
+			Values:  [][]any{{"a", json.Number("1")}, {"b", json.Number("2")}},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "esql",
+			"query": "FROM logs | STATS count = COUNT(*) BY host"
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 0)
+		require.Len(t, c.esqlRequests, 1)
+
+		r := c.esqlRequests[0]
+		require.Equal(t, "FROM logs | STATS count = COUNT(*) BY host", r.Query)
+		rangeFilter := r.Filter.Bool.Filters[0].(*es.RangeFilter)
+		require.Equal(t, c.configuredFields.TimeField, rangeFilter.Key)
+		require.Equal(t, toMs, rangeFilter.Lte)
+		require.Equal(t, fromMs, rangeFilter.Gte)
+		require.Equal(t, es.DateFormatEpochMS, rangeFilter.Format)
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 2)
+		require.Equal(t, data.VisTypeTable, frames[0].Meta.PreferredVisualization)
+	})
+
+	t.Run("SQL query follows the cursor", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponses = []*es.ColumnarResponse{
+			{
+				Columns: []es.ColumnarColumn{{Name: "host", Type: "keyword"}},
+				Rows:    [][]any{{"a"}},
+				Cursor:  "c1",
+			},
+			{Rows: [][]any{{"b"}}},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "sql",
+			"query": "SELECT host FROM logs"
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.sqlRequests, 2)
+		require.Equal(t, "SELECT host FROM logs", c.sqlRequests[0].Query)
+		require.Equal(t, sqlFetchSize, c.sqlRequests[0].FetchSize)
+		require.Equal(t, "c1", c.sqlRequests[1].Cursor)
+		require.Empty(t, c.sqlRequests[1].Query)
+		require.Empty(t, c.closedCursors)
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		requireFrameLength(t, frames[0], 2)
+		requireStringAt(t, "b", frames[0].Fields[0], 1)
+	})
+
+	t.Run("SQL result is limited and the cursor closed", func(t *testing.T) {
+		c := newFakeClient()
+		rows := make([][]any, sqlRowLimit)
+		for i := range rows {
+			rows[i] = []any{"a"}
+		}
+		c.sqlResponses = []*es.ColumnarResponse{
+			{
+				Columns: []es.ColumnarColumn{{Name: "host", Type: "keyword"}},
+				Rows:    rows,
+				Cursor:  "c1",
+			},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "sql",
+			"query": "SELECT host FROM logs"
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.sqlRequests, 1)
+		require.Equal(t, []string{"c1"}, c.closedCursors)
+
+		frames := res.Responses["A"].Frames
+		requireFrameLength(t, frames[0], sqlRowLimit)
+		require.Len(t, frames[0].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frames[0].Meta.Notices[0].Severity)
+	})
+
+	t.Run("Error in the response is returned for the query", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ColumnarResponse{
+			Status: 400,
+			Error: map[string]any{
+				"type":   "verification_exception",
+				"reason": "Found 1 problem\nline 1:6: Unknown index [missing]",
+			},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "esql",
+			"query": "FROM missing"
+		}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "Found 1 problem\nline 1:6: Unknown index [missing]")
+	})
+
+	t.Run("Empty query returns an error", func(t *testing.T) {
+		c := newFakeClient()
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "sql",
+			"query": ""
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.sqlRequests, 0)
+		require.Equal(t, backend.ErrorSourcePlugin, res.Responses["A"].ErrorSource)
+	})
+
+	t.Run("Interval variables are formatted for the query language", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponses = []*es.ColumnarResponse{{Columns: []es.ColumnarColumn{{Name: "t", Type: "datetime"}}}}
+		dataRequest := backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					TimeRange: backend.TimeRange{From: from, To: to},
+					Interval:  time.Minute,
+					JSON:      json.RawMessage(`{"queryType": "esql", "query": "FROM logs | STATS c = COUNT(*) BY BUCKET(@timestamp, $__interval) | EVAL ms = $__interval_ms"}`),
+				},
+				{
+					RefID:     "B",
+					TimeRange: backend.TimeRange{From: from, To: to},
+					Interval:  time.Hour,
+					JSON:      json.RawMessage(`{"queryType": "sql", "query": "SELECT HISTOGRAM(\"@timestamp\", $__interval) AS t FROM logs GROUP BY t"}`),
+				},
+			},
+		}
+		_, err := newElasticsearchDataQuery(context.Background(), c, &dataRequest, log.New()).execute()
+		require.NoError(t, err)
+		require.Len(t, c.esqlRequests, 1)
+		require.Equal(t, "FROM logs | STATS c = COUNT(*) BY BUCKET(@timestamp, 1 minute) | EVAL ms = 60000", c.esqlRequests[0].Query)
+		require.Len(t, c.sqlRequests, 1)
+		require.Equal(t, `SELECT HISTOGRAM("@timestamp", INTERVAL 1 HOUR) AS t FROM logs GROUP BY t`, c.sqlRequests[0].Query)
+	})
+
+	t.Run("Multisearch errors of a mixed request are returned for the search queries", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchError = errors.New("connection refused")
+		c.esqlResponse = &es.ColumnarResponse{
+			Columns: []es.ColumnarColumn{{Name: "count", Type: "long"}},
+			Values:  [][]any{{json.Number("1")}},
+		}
+		dataRequest := backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					TimeRange: backend.TimeRange{From: from, To: to},
+					JSON:      json.RawMessage(`{"queryType": "esql", "query": "FROM logs | STATS count = COUNT(*)"}`),
+				},
+				{
+					RefID:     "B",
+					TimeRange: backend.TimeRange{From: from, To: to},
+					JSON:      json.RawMessage(`{"metrics": [{"type": "count", "id": "1"}], "bucketAggs": [{"type": "date_histogram", "field": "@timestamp", "id": "2"}]}`),
+				},
+				{
+					RefID:     "C",
+					TimeRange: backend.TimeRange{From: from, To: to},
+					JSON:      json.RawMessage(`{"metrics": [{"type": "avg", "field": "value", "id": "1"}], "bucketAggs": [{"type": "date_histogram", "field": "@timestamp", "id": "2"}]}`),
+				},
+			},
+		}
+		res, err := newElasticsearchDataQuery(context.Background(), c, &dataRequest, log.New()).execute()
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 1)
+
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		require.EqualError(t, res.Responses["B"].Error, "connection refused")
+		require.EqualError(t, res.Responses["C"].Error, "connection refused")
+	})
+}
+
+func TestFormatColumnarInterval(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		esql     string
+		sql      string
+	}{
+		{time.Minute, "1 minute", "INTERVAL 1 MINUTE"},
+		{90 * time.Second, "90 seconds", "INTERVAL 90 SECONDS"},
+		{time.Hour, "1 hour", "INTERVAL 1 HOUR"},
+		{48 * time.Hour, "2 days", "INTERVAL 2 DAYS"},
+		{1500 * time.Millisecond, "1500 milliseconds", "INTERVAL '1.5' SECONDS"},
+		{500 * time.Microsecond, "1 millisecond", "INTERVAL '0.001' SECONDS"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.esql, formatColumnarInterval(tt.interval, esqlQueryType), tt.interval.String())
+		require.Equal(t, tt.sql, formatColumnarInterval(tt.interval, sqlQueryType), tt.interval.String())
+	}
+}
+
 func TestSettingsCasting(t *testing.T) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1805,6 +2042,14 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	esqlRequests        []*es.EsqlRequest
+	esqlResponse        *es.ColumnarResponse
+	sqlRequests         []*es.SQLRequest
+	sqlResponses        []*es.ColumnarResponse
+	closedCursors       []string
+	paginatedRequests   []*es.SearchRequest
+	paginatedLimits     []int
+	paginatedResponse   *es.SearchResponse
 }
 
 func newFakeClient() *fakeClient {
@@ -1818,6 +2063,7 @@ func newFakeClient() *fakeClient {
 		configuredFields:    configuredFields,
 		multisearchRequests: make([]*es.MultiSearchRequest, 0),
 		multiSearchResponse: &es.MultiSearchResponse{},
+		esqlResponse:        &es.ColumnarResponse{},
 	}
 }
 
@@ -1835,6 +2081,31 @@ func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	return c.builder
 }
 
+func (c *fakeClient) ExecuteEsql(r *es.EsqlRequest) (*es.ColumnarResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.esqlResponse, nil
+}
+
+// ExecuteSQL returns the configured responses one page after the other
+func (c *fakeClient) ExecuteSQL(r *es.SQLRequest) (*es.ColumnarResponse, error) {
+	c.sqlRequests = append(c.sqlRequests, r)
+	if len(c.sqlRequests) > len(c.sqlResponses) {
+		return &es.ColumnarResponse{}, nil
+	}
+	return c.sqlResponses[len(c.sqlRequests)-1], nil
+}
+
+func (c *fakeClient) ExecutePaginatedSearch(r *es.SearchRequest, limit int) (*es.SearchResponse, error) {
+	c.paginatedRequests = append(c.paginatedRequests, r)
+	c.paginatedLimits = append(c.paginatedLimits, limit)
+	return c.paginatedResponse, nil
+}
+
+func (c *fakeClient) CloseSQLCursor(cursor string) error {
+	c.closedCursors = append(c.closedCursors, cursor)
+	return nil
+}
+
 func newDataQuery(body string) (backend.QueryDataRequest, error) {
 	return backend.QueryDataRequest{
 		Queries: []backend.DataQuery{
@@ -1864,3 +2135,22 @@ func executeElasticsearchDataQuery(c es.Client, body string, from, to time.Time)
 	query := newElasticsearchDataQuery(context.Background(), c, &dataRequest, log.New())
 	return query.execute()
 }
+
+func executeElasticsearchDataQueries(c es.Client, bodies []string, from, to time.Time) (*backend.QueryDataResponse, error) {
+	dataRequest := backend.QueryDataRequest{}
+	for _, body := range bodies {
+		var q struct {
+			RefID string `json:"refId"`
+		}
+		if err := json.Unmarshal([]byte(body), &q); err != nil {
+			return nil, err
+		}
+		dataRequest.Queries = append(dataRequest.Queries, backend.DataQuery{
+			JSON:      json.RawMessage(body),
+			TimeRange: backend.TimeRange{From: from, To: to},
+			RefID:     q.RefID,
+		})
+	}
+	query := newElasticsearchDataQuery(context.Background(), c, &dataRequest, log.New())
+	return query.execute()
+}
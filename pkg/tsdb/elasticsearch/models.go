@@ -9,6 +9,9 @@ import (
 
 // Query represents the time series query model of the datasource
 type Query struct {
+	// QueryType selects how RawQuery is interpreted: a Lucene query string by default,
+	// or the text of an ES|QL or SQL query
+	QueryType     string       `json:"queryType"`
 	RawQuery      string       `json:"query"`
 	BucketAggs    []*BucketAgg `json:"bucketAggs"`
 	Metrics       []*MetricAgg `json:"metrics"`
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -3646,6 +3647,115 @@ func TestTrimEdges(t *testing.T) {
 	requireFrameLength(t, frames[0], 1)
 }
 
+func TestProcessColumnarResponse(t *testing.T) {
+	t.Run("ES|QL response with a time column is returned as time series", func(t *testing.T) {
+		query := `{"queryType": "esql", "query": "FROM logs | STATS c = COUNT(*) BY host, @timestamp = BUCKET(@timestamp, 1 minute)"}`
+		response := `{
+			"columns": [
+				{ "name": "c", "type": "long" },
+				{ "name": "host", "type": "keyword" },
+				{ "name": "@timestamp", "type": "date" }
+			],
+			"values": [
+				[ 2, "a", "2018-05-15T17:51:00.000Z" ],
+				[ 1, "a", "2018-05-15T17:50:00.000Z" ],
+				[ 3, "b", "2018-05-15T17:50:00.000Z" ],
+				[ null, "b", "2018-05-15T17:51:00.000Z" ]
+			]
+		}`
+		result, err := parseColumnarTestResponse(query, response)
+		require.NoError(t, err)
+
+		frames := result.Frames
+		require.Len(t, frames, 2)
+
+		frame := frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		require.Equal(t, "c", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		requireFrameLength(t, frame, 2)
+		requireTimeValue(t, 1526406600000, frame, 0)
+		requireNumberValue(t, 1, frame, 0)
+		requireTimeValue(t, 1526406660000, frame, 1)
+		requireNumberValue(t, 2, frame, 1)
+
+		frame = frames[1]
+		require.Equal(t, data.Labels{"host": "b"}, frame.Fields[1].Labels)
+		requireFrameLength(t, frame, 2)
+		requireNumberValue(t, 3, frame, 0)
+		require.Nil(t, frame.Fields[1].At(1))
+	})
+
+	t.Run("SQL response without a time column is returned as a table", func(t *testing.T) {
+		query := `{"queryType": "sql", "query": "SELECT host, avg(duration) AS duration, count(*) AS count, max(ok) AS ok FROM logs GROUP BY host"}`
+		response := `{
+			"columns": [
+				{ "name": "host", "type": "keyword" },
+				{ "name": "duration", "type": "double" },
+				{ "name": "count", "type": "long" },
+				{ "name": "ok", "type": "boolean" }
+			],
+			"rows": [
+				[ "a", 1.5, 9007199254740993, true ],
+				[ "b", null, 1, false ]
+			]
+		}`
+		result, err := parseColumnarTestResponse(query, response)
+		require.NoError(t, err)
+		require.Len(t, result.Frames, 1)
+
+		frame := result.Frames[0]
+		require.Equal(t, data.VisTypeTable, frame.Meta.PreferredVisualization)
+		requireFrameLength(t, frame, 2)
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[2].Type())
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[3].Type())
+
+		requireStringAt(t, "a", frame.Fields[0], 0)
+		requireFloatAt(t, 1.5, frame.Fields[1], 0)
+		require.Nil(t, frame.Fields[1].At(1))
+		require.Equal(t, int64(9007199254740993), *frame.Fields[2].At(0).(*int64))
+		require.True(t, *frame.Fields[3].At(0).(*bool))
+	})
+
+	t.Run("Configured time field is used when there are several time columns", func(t *testing.T) {
+		query := `{"queryType": "esql", "query": "FROM logs | KEEP created, @timestamp, bytes"}`
+		response := `{
+			"columns": [
+				{ "name": "created", "type": "date" },
+				{ "name": "@timestamp", "type": "date" },
+				{ "name": "bytes", "type": "double" }
+			],
+			"values": [
+				[ "2018-01-01T00:00:00Z", "2018-05-15T17:50:00.000Z", 10 ]
+			]
+		}`
+		result, err := parseColumnarTestResponse(query, response)
+		require.NoError(t, err)
+		require.Len(t, result.Frames, 1)
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, result.Frames[0].Meta.Type)
+		requireTimeValue(t, 1526406600000, result.Frames[0], 0)
+		requireNumberValue(t, 10, result.Frames[0], 0)
+	})
+
+	t.Run("Empty result is returned as a table", func(t *testing.T) {
+		query := `{"queryType": "esql", "query": "FROM logs | STATS c = COUNT(*) BY @timestamp = BUCKET(@timestamp, 1 minute)"}`
+		response := `{
+			"columns": [
+				{ "name": "c", "type": "long" },
+				{ "name": "@timestamp", "type": "date" }
+			],
+			"values": []
+		}`
+		result, err := parseColumnarTestResponse(query, response)
+		require.NoError(t, err)
+		require.Len(t, result.Frames, 1)
+		requireFrameLength(t, result.Frames[0], 0)
+	})
+}
+
 func parseTestResponse(tsdbQueries map[string]string, responseBody string, keepLabelsInResponse bool) (*backend.QueryDataResponse, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -3684,6 +3794,30 @@ func parseTestResponse(tsdbQueries map[string]string, responseBody string, keepL
 	return parseResponse(context.Background(), response.Responses, queries, configuredFields, keepLabelsInResponse, log.New())
 }
 
+func parseColumnarTestResponse(tsdbQuery string, responseBody string) (*backend.DataResponse, error) {
+	configuredFields := es.ConfiguredFields{
+		TimeField:       "@timestamp",
+		LogMessageField: "line",
+		LogLevelField:   "lvl",
+	}
+
+	var response es.ColumnarResponse
+	dec := json.NewDecoder(strings.NewReader(responseBody))
+	dec.UseNumber()
+	if err := dec.Decode(&response); err != nil {
+		return nil, err
+	}
+
+	queries, err := parseQuery([]backend.DataQuery{{RefID: "A", JSON: json.RawMessage(tsdbQuery)}}, log.New())
+	if err != nil {
+		return nil, err
+	}
+
+	queryRes := backend.DataResponse{}
+	err = processColumnarResponse(&response, queries[0], queries[0].RawQuery, configuredFields, &queryRes)
+	return &queryRes, err
+}
+
 func requireTimeValue(t *testing.T, expected int64, frame *data.Frame, index int) {
 	getField := func() *data.Field {
 		for _, field := range frame.Fields {
@@ -0,0 +1,217 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// tailPollInterval is the time between two searches for new documents of a tailed logs query
+	tailPollInterval = 2 * time.Second
+	// tailBatchSize is the maximum number of documents sent per poll, the next poll continues with the remaining documents
+	tailBatchSize = 500
+)
+
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	_, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	// Expect tail/${key}
+	if !strings.HasPrefix(req.Path, "tail/") {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("expected tail in channel path")
+	}
+
+	if _, err := parseTailQuery(req.Data, s.logger); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusOK,
+	}, nil
+}
+
+// Single instance for each channel (results are shared with all listeners)
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	logger := s.logger.FromContext(ctx)
+	query, err := parseTailQuery(req.Data, logger)
+	if err != nil {
+		return err
+	}
+
+	client, err := es.NewClient(ctx, dsInfo, logger)
+	if err != nil {
+		return err
+	}
+
+	tail := newLogsTail(query, client, time.Now(), logger)
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stop streaming (context canceled)")
+			return nil
+		case t := <-ticker.C:
+			frame, err := tail.poll(t)
+			if err != nil {
+				logger.Error("Failed to search for new documents", "error", err)
+				continue
+			}
+			if frame == nil {
+				continue
+			}
+			// the fields of log documents differ between polls, so the schema is always sent
+			if err := sender.SendFrame(frame, data.IncludeAll); err != nil {
+				logger.Error("Failed to send frame", "error", err)
+				return err
+			}
+		}
+	}
+}
+
+func (s *Service) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+func parseTailQuery(raw json.RawMessage, logger log.Logger) (*Query, error) {
+	queries, err := parseQuery([]backend.DataQuery{{RefID: "A", JSON: raw}}, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == 0 || len(queries[0].Metrics) == 0 || !isLogsQuery(queries[0]) {
+		return nil, fmt.Errorf("only logs queries can be streamed")
+	}
+	return queries[0], nil
+}
+
+// logsTail polls for documents of a logs query newer than the ones already sent
+type logsTail struct {
+	query  *Query
+	client es.Client
+	logger log.Logger
+	// from is the high-water mark, the time of the newest document sent so far
+	from time.Time
+	// sent holds the ids of the documents at the high-water mark, they match the next search again
+	sent map[string]bool
+}
+
+func newLogsTail(query *Query, client es.Client, from time.Time, logger log.Logger) *logsTail {
+	return &logsTail{
+		query:  query,
+		client: client,
+		logger: logger,
+		from:   from,
+		sent:   map[string]bool{},
+	}
+}
+
+// poll returns a logs frame with the documents between the high-water mark and now, or nil when there are none
+func (t *logsTail) poll(now time.Time) (*data.Frame, error) {
+	configuredFields := t.client.GetConfiguredFields()
+	timeField := configuredFields.TimeField
+	timeRange := backend.TimeRange{From: t.from, To: now}
+
+	ms := t.client.MultiSearch()
+	b := ms.Search(t.query.Interval, timeRange)
+	b.Size(tailBatchSize)
+	filters := b.Query().Bool().Filter()
+	filters.AddDateRangeFilter(timeField, now.UnixMilli(), t.from.UnixMilli(), es.DateFormatEpochMS)
+	filters.AddQueryStringFilter(t.query.RawQuery, true)
+	b.Sort(es.SortOrderAsc, timeField, "boolean")
+	b.Sort(es.SortOrderAsc, "_doc", "")
+	b.AddDocValueField(timeField)
+	b.AddTimeFieldWithStandardizedFormat(timeField)
+
+	req, err := ms.Build()
+	if err != nil {
+		return nil, err
+	}
+	res, err := t.client.ExecuteMultisearch(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Responses) == 0 {
+		return nil, nil
+	}
+	sr := res.Responses[0]
+	if sr.Error != nil {
+		return nil, errors.New(getErrorFromElasticResponse(sr))
+	}
+	if sr.Hits == nil {
+		return nil, nil
+	}
+
+	hits := make([]map[string]any, 0, len(sr.Hits.Hits))
+	for _, hit := range sr.Hits.Hits {
+		id := fmt.Sprintf("%v#%v", hit["_index"], hit["_id"])
+		if t.sent[id] {
+			continue
+		}
+
+		ts, ok := hitSortTime(hit)
+		if !ok {
+			continue
+		}
+		if ts.After(t.from) {
+			t.from = ts
+			t.sent = map[string]bool{}
+		}
+		t.sent[id] = true
+		hits = append(hits, hit)
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	queryRes := backend.DataResponse{}
+	err = processLogsResponse(&es.SearchResponse{Hits: &es.SearchResponseHits{Hits: hits}}, t.query, configuredFields, &queryRes, t.logger)
+	if err != nil {
+		return nil, err
+	}
+	return queryRes.Frames[0], nil
+}
+
+// hitSortTime returns the time of a document from its first sort value, the time field in epoch milliseconds
+func hitSortTime(hit map[string]any) (time.Time, bool) {
+	sort, ok := hit["sort"].([]any)
+	if !ok || len(sort) == 0 {
+		return time.Time{}, false
+	}
+	switch v := sort[0].(type) {
+	case float64:
+		return time.UnixMilli(int64(v)), true
+	case json.Number:
+		ms, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.UnixMilli(ms), true
+	}
+	return time.Time{}, false
+}
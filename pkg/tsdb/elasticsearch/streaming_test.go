@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestParseTailQuery(t *testing.T) {
+	t.Run("Logs query can be streamed", func(t *testing.T) {
+		q, err := parseTailQuery(json.RawMessage(`{"query": "level:error", "metrics": [{ "type": "logs", "id": "1" }]}`), log.New())
+		require.NoError(t, err)
+		require.Equal(t, "level:error", q.RawQuery)
+	})
+
+	t.Run("Metric query can't be streamed", func(t *testing.T) {
+		_, err := parseTailQuery(json.RawMessage(`{
+			"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+			"metrics": [{ "type": "count", "id": "1" }]
+		}`), log.New())
+		require.Error(t, err)
+	})
+}
+
+func TestLogsTail(t *testing.T) {
+	start := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	hit := func(id string, ts time.Time) map[string]any {
+		return map[string]any{
+			"_id":     id,
+			"_index":  "logs",
+			"_source": map[string]any{"line": "log line " + id},
+			"sort":    []any{float64(ts.UnixMilli()), float64(1)},
+		}
+	}
+	respond := func(c *fakeClient, hits ...map[string]any) {
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Hits: &es.SearchResponseHits{Hits: hits}}},
+		}
+	}
+
+	c := newFakeClient()
+	q, err := parseTailQuery(json.RawMessage(`{"query": "level:error", "metrics": [{ "type": "logs", "id": "1" }]}`), log.New())
+	require.NoError(t, err)
+	tail := newLogsTail(q, c, start, log.New())
+
+	t.Run("Sends new documents in ascending order", func(t *testing.T) {
+		respond(c, hit("1", start.Add(time.Second)), hit("2", start.Add(2*time.Second)))
+		frame, err := tail.poll(start.Add(3 * time.Second))
+		require.NoError(t, err)
+		require.NotNil(t, frame)
+		requireFrameLength(t, frame, 2)
+
+		sr := c.multisearchRequests[0].Requests[0]
+		require.Equal(t, tailBatchSize, sr.Size)
+		require.Equal(t, map[string]string{"order": "asc", "unmapped_type": "boolean"}, sr.Sort[c.configuredFields.TimeField])
+		rangeFilter := sr.Query.Bool.Filters[0].(*es.RangeFilter)
+		require.Equal(t, start.UnixMilli(), rangeFilter.Gte)
+		require.Equal(t, start.Add(3*time.Second).UnixMilli(), rangeFilter.Lte)
+	})
+
+	t.Run("Continues from the newest document without sending it again", func(t *testing.T) {
+		respond(c, hit("2", start.Add(2*time.Second)), hit("3", start.Add(2*time.Second)))
+		frame, err := tail.poll(start.Add(4 * time.Second))
+		require.NoError(t, err)
+		require.NotNil(t, frame)
+		requireFrameLength(t, frame, 1)
+
+		rangeFilter := c.multisearchRequests[1].Requests[0].Query.Bool.Filters[0].(*es.RangeFilter)
+		require.Equal(t, start.Add(2*time.Second).UnixMilli(), rangeFilter.Gte)
+	})
+
+	t.Run("Returns no frame without new documents", func(t *testing.T) {
+		respond(c, hit("2", start.Add(2*time.Second)), hit("3", start.Add(2*time.Second)))
+		frame, err := tail.poll(start.Add(5 * time.Second))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+	})
+
+	t.Run("Returns the error of the search", func(t *testing.T) {
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Error: map[string]any{"reason": "index not found"}}},
+		}
+		_, err := tail.poll(start.Add(6 * time.Second))
+		require.EqualError(t, err, "index not found")
+	})
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+)
+
+// queryCanceled is the SQLSTATE of statements canceled by the statement_timeout
+const queryCanceled = "57014"
+
+// postgresQueryGuard estimates queries with EXPLAIN (FORMAT JSON) and limits them with statement_timeout.
+type postgresQueryGuard struct{}
+
+func (g *postgresQueryGuard) Explain(ctx context.Context, conn *sql.Conn, query string, args ...any) (sqleng.QueryEstimate, error) {
+	var plan []byte
+	if err := conn.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&plan); err != nil {
+		return sqleng.QueryEstimate{}, err
+	}
+	return parseExplainPlan(plan)
+}
+
+func (g *postgresQueryGuard) SetStatementTimeout(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	query := "RESET statement_timeout"
+	if timeout > 0 {
+		query = fmt.Sprintf("SET statement_timeout = %d", timeout.Milliseconds())
+	}
+	_, err := conn.ExecContext(ctx, query)
+	return err
+}
+
+func (g *postgresQueryGuard) IsStatementTimeout(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == queryCanceled
+}
+
+// parseExplainPlan returns the estimate of the root node of the plan
+func parseExplainPlan(plan []byte) (sqleng.QueryEstimate, error) {
+	var explain []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+			PlanRows  float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return sqleng.QueryEstimate{}, err
+	}
+	if len(explain) == 0 {
+		return sqleng.QueryEstimate{}, fmt.Errorf("unexpected explain plan")
+	}
+	return sqleng.QueryEstimate{Rows: explain[0].Plan.PlanRows, Cost: explain[0].Plan.TotalCost}, nil
+}
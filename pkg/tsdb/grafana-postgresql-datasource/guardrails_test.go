@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+)
+
+func TestParseExplainPlan(t *testing.T) {
+	plan := `[{
+		"Plan": {
+			"Node Type": "Hash Join",
+			"Total Cost": 1510.75,
+			"Plan Rows": 5000,
+			"Plans": [{ "Node Type": "Seq Scan", "Total Cost": 1200, "Plan Rows": 100000 }]
+		}
+	}]`
+	estimate, err := parseExplainPlan([]byte(plan))
+	require.NoError(t, err)
+	require.Equal(t, sqleng.QueryEstimate{Rows: 5000, Cost: 1510.75}, estimate)
+
+	_, err = parseExplainPlan([]byte(`[]`))
+	require.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package postgres
+
+// postgresSchemaProvider provides the catalog queries of the schema resources. A connection is bound to
+// a single database, so the database parameter is ignored and the current database is described.
+type postgresSchemaProvider struct{}
+
+func (p *postgresSchemaProvider) DatabasesQuery() string {
+	return `SELECT datname FROM pg_database
+		WHERE NOT datistemplate AND has_database_privilege(datname, 'CONNECT')
+		ORDER BY datname`
+}
+
+func (p *postgresSchemaProvider) SchemasQuery(_ string) (string, []any) {
+	return `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'pg_catalog')
+			AND schema_name NOT LIKE 'pg_toast%' AND schema_name NOT LIKE 'pg_temp%'
+		ORDER BY schema_name`, nil
+}
+
+func (p *postgresSchemaProvider) TablesQuery(_, schema string) (string, []any) {
+	return `SELECT table_name, table_type FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema())
+		ORDER BY table_name`, []any{schema}
+}
+
+func (p *postgresSchemaProvider) ColumnsQuery(_, schema, table string) (string, []any) {
+	return `SELECT column_name, data_type, is_nullable FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+		ORDER BY ordinal_position`, []any{schema, table}
+}
+
+func (p *postgresSchemaProvider) IndexesQuery(_, schema, table string) (string, []any) {
+	return `SELECT i.relname, a.attname, ix.indisunique FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2
+		ORDER BY i.relname, k.ord`, []any{schema, table}
+}
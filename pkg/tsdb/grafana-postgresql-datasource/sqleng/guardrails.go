@@ -0,0 +1,186 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// CostLimitActionReject rejects queries exceeding the cost limits, it is the default action
+	CostLimitActionReject = "reject"
+	// CostLimitActionWarn executes queries exceeding the cost limits with a warning
+	CostLimitActionWarn = "warn"
+
+	// statementTimeoutGrace is the time given to the server to abort a statement before the query context is canceled
+	statementTimeoutGrace = time.Second
+)
+
+// QueryEstimate is the estimate of the query planner for a query
+type QueryEstimate struct {
+	Rows float64
+	Cost float64
+}
+
+// QueryGuard is implemented by the dialects to enforce the query guardrails configured for a data source.
+type QueryGuard interface {
+	// Explain returns the estimate of the query planner for the query and its arguments without executing it
+	Explain(ctx context.Context, conn *sql.Conn, query string, args ...any) (QueryEstimate, error)
+	// SetStatementTimeout limits the execution time of the following statements of the connection on the server,
+	// a zero timeout restores the default of the server
+	SetStatementTimeout(ctx context.Context, conn *sql.Conn, timeout time.Duration) error
+	// IsStatementTimeout reports whether the error was returned by the server for a statement exceeding the timeout
+	IsStatementTimeout(err error) bool
+}
+
+// GuardrailError is returned for queries that were rejected or aborted by the guardrails of the data source
+type GuardrailError struct {
+	msg string
+}
+
+func (e *GuardrailError) Error() string {
+	return e.msg
+}
+
+type guardrails struct {
+	maxRows          int64
+	maxCost          float64
+	action           string
+	statementTimeout time.Duration
+}
+
+func newGuardrails(jsonData JsonData) guardrails {
+	g := guardrails{
+		maxRows:          jsonData.MaxEstimatedRows,
+		maxCost:          jsonData.MaxEstimatedCost,
+		action:           jsonData.CostLimitAction,
+		statementTimeout: time.Duration(jsonData.StatementTimeout) * time.Second,
+	}
+	if g.action != CostLimitActionWarn {
+		g.action = CostLimitActionReject
+	}
+	return g
+}
+
+func (g guardrails) hasCostLimits() bool {
+	return g.maxRows > 0 || g.maxCost > 0
+}
+
+func (g guardrails) enabled() bool {
+	return g.hasCostLimits() || g.statementTimeout > 0
+}
+
+// check returns a description of the limits exceeded by the estimate, or an empty string
+func (g guardrails) check(estimate QueryEstimate) string {
+	if g.maxRows > 0 && estimate.Rows > float64(g.maxRows) {
+		return fmt.Sprintf("the estimated number of rows %s exceeds the limit of %d", formatEstimate(estimate.Rows), g.maxRows)
+	}
+	if g.maxCost > 0 && estimate.Cost > g.maxCost {
+		return fmt.Sprintf("the estimated cost %s exceeds the limit of %s", formatEstimate(estimate.Cost), formatEstimate(g.maxCost))
+	}
+	return ""
+}
+
+func formatEstimate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// guardedQuery executes the query within the guardrails of the data source. Guarded queries run on a dedicated
+// connection, so that the statement timeout only applies to them. The returned release function has to be called
+// once the rows are closed. Warnings of the guardrails are returned as notices, also along with errors.
+func (e *DataSourceHandler) guardedQuery(ctx context.Context, logger log.Logger, query string, args ...any) (*sql.Rows, func(), []data.Notice, error) {
+	g := newGuardrails(e.dsInfo.JsonData)
+	if e.queryGuard == nil || !g.enabled() {
+		rows, err := e.db.QueryContext(ctx, query, args...)
+		return rows, func() {}, nil, err
+	}
+
+	cancel := func() {}
+	if g.statementTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, g.statementTimeout+statementTimeoutGrace)
+	}
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	release := func() {
+		if g.statementTimeout > 0 {
+			// the connection returns to the pool, it must not keep the timeout of the query
+			if err := e.queryGuard.SetStatementTimeout(context.Background(), conn, 0); err != nil {
+				logger.Warn("Failed to reset statement timeout", "error", err)
+			}
+		}
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to close connection", "error", err)
+		}
+		cancel()
+	}
+
+	var notices []data.Notice
+	if g.hasCostLimits() {
+		estimate, err := e.queryGuard.Explain(ctx, conn, query, args...)
+		if err != nil {
+			// not every statement can be explained, those are executed without the cost limits
+			logger.Warn("Failed to estimate query cost", "error", err)
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     "The cost of the query could not be estimated, the cost limits of the data source were not applied",
+			})
+		} else if exceeded := g.check(estimate); exceeded != "" {
+			logger.Debug("Query exceeds cost limits", "rows", estimate.Rows, "cost", estimate.Cost, "action", g.action)
+			if g.action == CostLimitActionReject {
+				release()
+				err := &GuardrailError{msg: fmt.Sprintf("query rejected, %s of the data source", exceeded)}
+				return nil, nil, guardrailNotices(err), err
+			}
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("The query is expensive, %s of the data source", exceeded),
+			})
+		}
+	}
+
+	if g.statementTimeout > 0 {
+		if err := e.queryGuard.SetStatementTimeout(ctx, conn, g.statementTimeout); err != nil {
+			release()
+			return nil, nil, notices, err
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = e.statementTimeoutError(ctx, err)
+		release()
+		return nil, nil, append(notices, guardrailNotices(err)...), err
+	}
+	return rows, release, notices, nil
+}
+
+// statementTimeoutError converts the errors of statements aborted by the statement timeout, either on the
+// server or by the deadline of the context, other errors are returned unchanged
+func (e *DataSourceHandler) statementTimeoutError(ctx context.Context, err error) error {
+	timeout := e.dsInfo.JsonData.StatementTimeout
+	if e.queryGuard == nil || timeout <= 0 {
+		return err
+	}
+	if e.queryGuard.IsStatementTimeout(err) || errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &GuardrailError{msg: fmt.Sprintf("query aborted, the execution time exceeds the statement timeout of %ds of the data source", timeout)}
+	}
+	return err
+}
+
+// guardrailNotices returns the error notice for errors of the guardrails
+func guardrailNotices(err error) []data.Notice {
+	var guardErr *GuardrailError
+	if !errors.As(err, &guardErr) {
+		return nil
+	}
+	return []data.Notice{{Severity: data.NoticeSeverityError, Text: guardErr.Error()}}
+}
@@ -0,0 +1,401 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+const (
+	defaultStreamInterval = 5 * time.Second
+	minStreamInterval     = time.Second
+	defaultStreamLookback = time.Hour
+)
+
+// StreamQueryBuilder builds the queries of the streaming mode for a dialect
+type StreamQueryBuilder interface {
+	// InitialQuery returns the query ordered by the column, so that the rows beyond the row limit of the first query
+	// are the newest ones, which are returned by the following incremental queries
+	InitialQuery(query, column string) string
+	// IncrementalQuery returns the query selecting the rows of the query with a value of the column greater than
+	// the high-water mark, which is passed as the only argument, ordered by the column
+	IncrementalQuery(query, column string) string
+}
+
+// streamQuery is the query model of a stream, streams are started from a query with a stream column
+type streamQuery struct {
+	RawSql string `json:"rawSql"`
+	// StreamColumn is the time or monotonically increasing id column the new rows are selected by
+	StreamColumn string `json:"streamColumn"`
+	// StreamIntervalMs is the time between two incremental queries
+	StreamIntervalMs int64 `json:"streamIntervalMs"`
+	// StreamLookback is the time range of the time macros, ending at the time of each query
+	StreamLookback string `json:"streamLookback"`
+
+	interval time.Duration
+	lookback time.Duration
+}
+
+func parseStreamQuery(raw json.RawMessage) (*streamQuery, error) {
+	q := &streamQuery{}
+	if err := json.Unmarshal(raw, q); err != nil {
+		return nil, fmt.Errorf("error unmarshal stream query json: %w", err)
+	}
+	if q.RawSql == "" {
+		return nil, errors.New("missing rawSql in stream query")
+	}
+	if q.StreamColumn == "" {
+		return nil, errors.New("missing streamColumn in stream query")
+	}
+
+	q.interval = defaultStreamInterval
+	if q.StreamIntervalMs > 0 {
+		q.interval = max(time.Duration(q.StreamIntervalMs)*time.Millisecond, minStreamInterval)
+	}
+	q.lookback = defaultStreamLookback
+	if q.StreamLookback != "" {
+		lookback, err := gtime.ParseDuration(q.StreamLookback)
+		if err != nil {
+			return nil, fmt.Errorf("invalid streamLookback: %w", err)
+		}
+		q.lookback = lookback
+	}
+	return q, nil
+}
+
+func (e *DataSourceHandler) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	// Expect tail/${key}
+	if !strings.HasPrefix(req.Path, "tail/") {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("expected tail in channel path")
+	}
+	if e.streamQueryBuilder == nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("streaming is not supported by the data source")
+	}
+	if _, err := parseStreamQuery(req.Data); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	e.streamsMu.RLock()
+	defer e.streamsMu.RUnlock()
+
+	// new subscribers receive the rows of the last query of a running stream
+	if cache, ok := e.streams[req.Path]; ok {
+		msg, err := backend.NewInitialData(cache.Bytes(data.IncludeAll))
+		return &backend.SubscribeStreamResponse{
+			Status:      backend.SubscribeStreamStatusOK,
+			InitialData: msg,
+		}, err
+	}
+
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusOK,
+	}, nil
+}
+
+// Single instance for each channel (results are shared with all listeners)
+func (e *DataSourceHandler) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	if e.streamQueryBuilder == nil {
+		return fmt.Errorf("streaming is not supported by the data source")
+	}
+	query, err := parseStreamQuery(req.Data)
+	if err != nil {
+		return err
+	}
+
+	logger := e.log.FromContext(ctx)
+	defer func() {
+		e.streamsMu.Lock()
+		delete(e.streams, req.Path)
+		e.streamsMu.Unlock()
+	}()
+
+	tail := &sqlTail{handler: e, query: query, logger: logger}
+	prev := data.FrameJSONCache{}
+	ticker := time.NewTicker(query.interval)
+	defer ticker.Stop()
+
+	poll := func(now time.Time) error {
+		frame, err := tail.poll(ctx, now)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.As(err, new(*transientPollError)) {
+				// the query is retried with the same high-water mark on the next tick
+				logger.Warn("Failed to query new rows", "error", err)
+				return nil
+			}
+			// the other errors fail on every poll, the subscribers are sent the error and the stream stops
+			logger.Error("Failed to query new rows, stopping the stream", "error", err)
+			errFrame := data.NewFrame("")
+			errFrame.Meta = &data.FrameMeta{Notices: []data.Notice{{Severity: data.NoticeSeverityError, Text: err.Error()}}}
+			if err := sender.SendFrame(errFrame, data.IncludeAll); err != nil {
+				logger.Error("Failed to send frame", "error", err)
+			}
+			return errStreamStopped
+		}
+		if frame == nil {
+			return nil
+		}
+
+		next, err := data.FrameToJSONCache(frame)
+		if err != nil {
+			return err
+		}
+		if next.SameSchema(&prev) {
+			err = sender.SendBytes(next.Bytes(data.IncludeDataOnly))
+		} else {
+			err = sender.SendFrame(frame, data.IncludeAll)
+		}
+		if err != nil {
+			logger.Error("Failed to send frame", "error", err)
+			return err
+		}
+		prev = next
+
+		e.streamsMu.Lock()
+		e.streams[req.Path] = prev
+		e.streamsMu.Unlock()
+		return nil
+	}
+
+	if err := poll(time.Now()); err != nil {
+		return streamResult(err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stop streaming (context canceled)")
+			return nil
+		case t := <-ticker.C:
+			if err := poll(t); err != nil {
+				return streamResult(err)
+			}
+		}
+	}
+}
+
+// errStreamStopped stops a stream after a poll failed with an error that is not transient
+var errStreamStopped = errors.New("stream stopped")
+
+// streamResult returns the error of RunStream. A stream that stopped returns no error, otherwise it would be
+// restarted and fail again.
+func streamResult(err error) error {
+	if errors.Is(err, errStreamStopped) {
+		return nil
+	}
+	return err
+}
+
+func (e *DataSourceHandler) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// sqlTail queries the rows of a stream newer than the ones already sent
+type sqlTail struct {
+	handler *DataSourceHandler
+	query   *streamQuery
+	logger  log.Logger
+	// highWaterMark is the largest value of the stream column sent so far, nil before the first query
+	highWaterMark any
+}
+
+// poll returns a frame with the new rows of the stream, or nil when there are none. The first poll returns the
+// rows of the query itself, the following polls only the rows with a stream column above the high-water mark.
+// The queries are ordered by the stream column, rows beyond the row limit are returned by the next poll. A batch
+// cut by the row limit ends before the rows of its last value, which the next poll returns together.
+// Polls run within the guardrails of the data source, the same as the other queries.
+func (t *sqlTail) poll(ctx context.Context, now time.Time) (*data.Frame, error) {
+	e := t.handler
+	dataQuery := backend.DataQuery{
+		RefID:     "A",
+		JSON:      json.RawMessage("{}"),
+		TimeRange: backend.TimeRange{From: now.Add(-t.query.lookback), To: now},
+		Interval:  t.query.interval,
+	}
+
+	interpolatedQuery := Interpolate(dataQuery, dataQuery.TimeRange, e.dsInfo.JsonData.TimeInterval, t.query.RawSql)
+	interpolatedQuery, err := e.macroEngine.Interpolate(&dataQuery, dataQuery.TimeRange, interpolatedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []any
+	if t.highWaterMark != nil {
+		interpolatedQuery = e.streamQueryBuilder.IncrementalQuery(interpolatedQuery, t.query.StreamColumn)
+		args = append(args, t.highWaterMark)
+	} else {
+		interpolatedQuery = e.streamQueryBuilder.InitialQuery(interpolatedQuery, t.query.StreamColumn)
+	}
+
+	rows, release, notices, err := e.guardedQuery(ctx, t.logger, interpolatedQuery, args...)
+	if err != nil {
+		if errors.As(err, new(*GuardrailError)) {
+			return nil, transientPollErr(err, err)
+		}
+		return nil, transientPollErr(err, e.TransformQueryError(t.logger, err))
+	}
+	defer release()
+	defer func() {
+		if err := rows.Close(); err != nil {
+			t.logger.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	stringConverters := e.queryResultTransformer.GetConverterList()
+	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	if err != nil {
+		err = e.statementTimeoutError(ctx, err)
+		return nil, transientPollErr(err, err)
+	}
+	if frame.Rows() == 0 {
+		return nil, nil
+	}
+	truncated := e.rowLimit > 0 && int64(frame.Rows()) >= e.rowLimit
+
+	field, _ := frame.FieldByName(t.query.StreamColumn)
+	if field == nil {
+		return nil, fmt.Errorf("stream column %q not found in the query result", t.query.StreamColumn)
+	}
+	values := make([]any, field.Len())
+	for i := range values {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		values[i] = normalizeStreamValue(v)
+		if values[i] == nil {
+			return nil, fmt.Errorf("stream column %q has unsupported type %s", t.query.StreamColumn, field.Type())
+		}
+	}
+
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	if truncated {
+		// the rows sharing the last value may continue beyond the row limit, they are left to the next poll so
+		// that none of them is skipped by the high-water mark
+		last := len(values)
+		for last > 0 && values[last-1] != nil && streamValueEqual(values[last-1], values[len(values)-1]) {
+			last--
+		}
+		if last > 0 {
+			for i := frame.Rows() - 1; i >= last; i-- {
+				frame.DeleteRow(i)
+			}
+			values = values[:last]
+		} else {
+			frame.Meta.Notices = append(frame.Meta.Notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("More rows than the row limit have the same value of the stream column %q, the rows beyond the limit are skipped", t.query.StreamColumn),
+			})
+		}
+	}
+	for _, v := range values {
+		if v != nil && streamValueAfter(v, t.highWaterMark) {
+			t.highWaterMark = v
+		}
+	}
+
+	frame.Meta.ExecutedQueryString = interpolatedQuery
+	frame.Meta.Notices = append(frame.Meta.Notices, notices...)
+	return frame, nil
+}
+
+// transientPollError is the error of a poll that is retried on the next tick
+type transientPollError struct {
+	err error
+}
+
+func (e *transientPollError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientPollError) Unwrap() error {
+	return e.err
+}
+
+// transientPollErr returns the error of a poll, marked as transient when its cause is a guardrail, a lost
+// connection or a timeout. Other errors, such as an unknown column, fail on every poll.
+func transientPollErr(cause error, err error) error {
+	var netErr net.Error
+	if errors.As(cause, new(*GuardrailError)) || errors.As(cause, &netErr) || errors.Is(cause, driver.ErrBadConn) ||
+		errors.Is(cause, io.EOF) || errors.Is(cause, io.ErrUnexpectedEOF) || errors.Is(cause, context.DeadlineExceeded) {
+		return &transientPollError{err: err}
+	}
+	return err
+}
+
+// normalizeStreamValue converts a value of the stream column to a time, an int64, a float64 or a string,
+// it returns nil for other types
+func normalizeStreamValue(v any) any {
+	switch v := v.(type) {
+	case time.Time, int64, float64, string:
+		return v
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return nil
+}
+
+// streamValueEqual reports whether two values of the stream column are equal
+func streamValueEqual(a, b any) bool {
+	if t, ok := a.(time.Time); ok {
+		other, ok := b.(time.Time)
+		return ok && t.Equal(other)
+	}
+	return a == b
+}
+
+// streamValueAfter reports whether the value is greater than the high-water mark
+func streamValueAfter(v, highWaterMark any) bool {
+	switch v := v.(type) {
+	case time.Time:
+		hwm, ok := highWaterMark.(time.Time)
+		return !ok || v.After(hwm)
+	case int64:
+		hwm, ok := highWaterMark.(int64)
+		return !ok || v > hwm
+	case float64:
+		hwm, ok := highWaterMark.(float64)
+		return !ok || v > hwm
+	case string:
+		hwm, ok := highWaterMark.(string)
+		return !ok || v > hwm
+	}
+	return false
+}
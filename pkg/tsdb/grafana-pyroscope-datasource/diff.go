@@ -0,0 +1,174 @@
+package pyroscope
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/grafana-pyroscope-datasource/kinds/dataquery"
+	"golang.org/x/sync/errgroup"
+)
+
+// DiffTree is a node of the merged tree of the baseline (left) and the comparison (right) profiles of a diff query
+type DiffTree struct {
+	Level      int
+	Name       string
+	Value      int64
+	Self       int64
+	ValueRight int64
+	SelfRight  int64
+	Nodes      []*DiffTree
+
+	nodesByName map[string]*DiffTree
+}
+
+func (dt *DiffTree) child(name string) *DiffTree {
+	if node, ok := dt.nodesByName[name]; ok {
+		return node
+	}
+	node := &DiffTree{Level: dt.Level + 1, Name: name}
+	if dt.nodesByName == nil {
+		dt.nodesByName = map[string]*DiffTree{}
+	}
+	dt.nodesByName[name] = node
+	dt.Nodes = append(dt.Nodes, node)
+	return node
+}
+
+// mergeDiffTrees merges the trees of the baseline and the comparison profiles. Nodes with the same path of names
+// in both trees are merged into a single node with the values of both profiles, either tree can be nil.
+func mergeDiffTrees(left, right *ProfileTree) *DiffTree {
+	if left == nil && right == nil {
+		return nil
+	}
+
+	var root *DiffTree
+	add := func(tree *ProfileTree, isRight bool) {
+		if tree == nil {
+			return
+		}
+		if root == nil {
+			root = &DiffTree{Level: tree.Level, Name: tree.Name}
+		}
+		// path holds the merged nodes of the current branch, indexed by level
+		path := []*DiffTree{root}
+		walkTree(tree, func(tree *ProfileTree) {
+			node := root
+			if tree.Level > 0 {
+				if tree.Level > len(path) {
+					logger.Error("Profile tree skips a level", "level", tree.Level, "function", logEntrypoint())
+					return
+				}
+				node = path[tree.Level-1].child(tree.Name)
+				path = append(path[:tree.Level], node)
+			}
+			if isRight {
+				node.ValueRight += tree.Value
+				node.SelfRight += tree.Self
+			} else {
+				node.Value += tree.Value
+				node.Self += tree.Self
+			}
+		})
+	}
+	add(left, false)
+	add(right, true)
+	return root
+}
+
+// profileSelection returns the label selector and the time range in milliseconds of the baseline or the comparison
+// profile of a diff query, the label selector and the time range of the query are used for the values not set
+func profileSelection(selection *dataquery.ProfileSelection, labelSelector string, timeRange backend.TimeRange) (string, int64, int64) {
+	start, end := timeRange.From.UnixMilli(), timeRange.To.UnixMilli()
+	if selection == nil {
+		return labelSelector, start, end
+	}
+	if selection.LabelSelector != nil && *selection.LabelSelector != "" {
+		labelSelector = *selection.LabelSelector
+	}
+	if selection.From != nil {
+		start = *selection.From
+	}
+	if selection.To != nil {
+		end = *selection.To
+	}
+	return labelSelector, start, end
+}
+
+// queryDiff queries the baseline and the comparison profiles concurrently and returns a single flame graph frame
+// with the values of both profiles
+func (d *PyroscopeDatasource) queryDiff(ctx context.Context, qm queryModel, query backend.DataQuery) (*data.Frame, error) {
+	profileTypeId := depointerizer(qm.ProfileTypeId)
+	labelSelector := depointerizer(qm.LabelSelector)
+
+	var left, right *ProfileResponse
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		selector, start, end := profileSelection(qm.Baseline, labelSelector, query.TimeRange)
+		logger.Debug("Calling GetProfile for the baseline", "labelSelector", selector, "start", start, "end", end, "function", logEntrypoint())
+		prof, err := d.client.GetProfile(gCtx, profileTypeId, selector, start, end, qm.MaxNodes)
+		left = prof
+		return err
+	})
+	g.Go(func() error {
+		selector, start, end := profileSelection(qm.Comparison, labelSelector, query.TimeRange)
+		logger.Debug("Calling GetProfile for the comparison", "labelSelector", selector, "start", start, "end", end, "function", logEntrypoint())
+		prof, err := d.client.GetProfile(gCtx, profileTypeId, selector, start, end, qm.MaxNodes)
+		right = prof
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		logger.Error("Error GetProfile()", "err", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	var leftTree, rightTree *ProfileTree
+	unit := ""
+	if left != nil {
+		leftTree = levelsToTree(left.Flamebearer.Levels, left.Flamebearer.Names)
+		unit = left.Units
+	}
+	if right != nil {
+		rightTree = levelsToTree(right.Flamebearer.Levels, right.Flamebearer.Names)
+		unit = right.Units
+	}
+	return diffTreeToNestedSetDataFrame(mergeDiffTrees(leftTree, rightTree), unit), nil
+}
+
+// diffTreeToNestedSetDataFrame walks the tree depth first like treeToNestedSetDataFrame. The value and self fields
+// hold the values of the baseline profile and the valueRight and selfRight fields the values of the comparison
+// profile, which is the format of diff flame graphs.
+func diffTreeToNestedSetDataFrame(tree *DiffTree, unit string) *data.Frame {
+	frame := data.NewFrame("response")
+	frame.Meta = &data.FrameMeta{PreferredVisualization: "flamegraph"}
+
+	levelField := data.NewField("level", nil, []int64{})
+	valueField := data.NewField("value", nil, []int64{})
+	selfField := data.NewField("self", nil, []int64{})
+	valueRightField := data.NewField("valueRight", nil, []int64{})
+	selfRightField := data.NewField("selfRight", nil, []int64{})
+	for _, field := range []*data.Field{valueField, selfField, valueRightField, selfRightField} {
+		field.Config = &data.FieldConfig{Unit: unit}
+	}
+
+	labelField := NewEnumField("label", nil)
+
+	// Tree can be nil if both profiles were empty, we can still send empty frame in that case
+	if tree != nil {
+		stack := []*DiffTree{tree}
+		for len(stack) > 0 {
+			node := stack[0]
+			stack = append(append([]*DiffTree{}, node.Nodes...), stack[1:]...)
+
+			levelField.Append(int64(node.Level))
+			valueField.Append(node.Value)
+			selfField.Append(node.Self)
+			valueRightField.Append(node.ValueRight)
+			selfRightField.Append(node.SelfRight)
+			labelField.Append(node.Name)
+		}
+	}
+
+	frame.Fields = data.Fields{levelField, valueField, selfField, labelField.GetField(), valueRightField, selfRightField}
+	return frame
+}
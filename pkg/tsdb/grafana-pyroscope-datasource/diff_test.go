@@ -0,0 +1,87 @@
+package pyroscope
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-pyroscope-datasource/kinds/dataquery"
+)
+
+func Test_queryDiff(t *testing.T) {
+	ds := &PyroscopeDatasource{
+		client: &FakeClient{},
+	}
+
+	dataQuery := makeDataQuery()
+	dataQuery.QueryType = queryTypeDiff
+	resp := ds.query(context.Background(), backend.PluginContext{}, *dataQuery)
+	require.Nil(t, resp.Error)
+	require.Equal(t, 1, len(resp.Frames))
+
+	frame := resp.Frames[0]
+	require.Equal(t, data.NewField("level", nil, []int64{0, 1, 2}), frame.Fields[0])
+	require.Equal(t, []int64{10, 9, 8}, fieldValues[int64](frame.Fields[1]))
+	require.Equal(t, "valueRight", frame.Fields[4].Name)
+	require.Equal(t, []int64{10, 9, 8}, fieldValues[int64](frame.Fields[4]))
+	require.Equal(t, "selfRight", frame.Fields[5].Name)
+	require.Equal(t, []int64{0, 0, 8}, fieldValues[int64](frame.Fields[5]))
+}
+
+func Test_profileSelection(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.UnixMilli(10000), To: time.UnixMilli(20000)}
+
+	selector, start, end := profileSelection(nil, "{}", timeRange)
+	require.Equal(t, "{}", selector)
+	require.Equal(t, int64(10000), start)
+	require.Equal(t, int64(20000), end)
+
+	labelSelector := `{app="foo"}`
+	from := int64(5000)
+	selector, start, end = profileSelection(&dataquery.ProfileSelection{LabelSelector: &labelSelector, From: &from}, "{}", timeRange)
+	require.Equal(t, labelSelector, selector)
+	require.Equal(t, int64(5000), start)
+	require.Equal(t, int64(20000), end)
+}
+
+func Test_diffTreeToNestedSetDataFrame(t *testing.T) {
+	left := &ProfileTree{
+		Value: 100, Level: 0, Self: 0, Name: "total", Nodes: []*ProfileTree{
+			{Value: 60, Level: 1, Self: 60, Name: "func1"},
+			{Value: 40, Level: 1, Self: 10, Name: "func2", Nodes: []*ProfileTree{
+				{Value: 30, Level: 2, Self: 30, Name: "func3"},
+			}},
+		},
+	}
+	right := &ProfileTree{
+		Value: 80, Level: 0, Self: 0, Name: "total", Nodes: []*ProfileTree{
+			{Value: 50, Level: 1, Self: 20, Name: "func2", Nodes: []*ProfileTree{
+				{Value: 30, Level: 2, Self: 30, Name: "func4"},
+			}},
+			{Value: 30, Level: 1, Self: 30, Name: "func5"},
+		},
+	}
+
+	frame := diffTreeToNestedSetDataFrame(mergeDiffTrees(left, right), "short")
+
+	labelConfig := &data.FieldConfig{
+		TypeConfig: &data.FieldTypeConfig{
+			Enum: &data.EnumFieldConfig{
+				Text: []string{"total", "func1", "func2", "func3", "func4", "func5"},
+			},
+		},
+	}
+	require.Equal(t,
+		[]*data.Field{
+			data.NewField("level", nil, []int64{0, 1, 1, 2, 2, 1}),
+			data.NewField("value", nil, []int64{100, 60, 40, 30, 0, 0}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("self", nil, []int64{0, 60, 10, 30, 0, 0}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("label", nil, []data.EnumItemIndex{0, 1, 2, 3, 4, 5}).SetConfig(labelConfig),
+			data.NewField("valueRight", nil, []int64{80, 0, 50, 0, 30, 30}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("selfRight", nil, []int64{0, 0, 20, 0, 30, 30}).SetConfig(&data.FieldConfig{Unit: "short"}),
+		}, frame.Fields)
+}
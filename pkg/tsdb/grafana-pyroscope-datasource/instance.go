@@ -79,6 +79,9 @@ func (d *PyroscopeDatasource) CallResource(ctx context.Context, req *backend.Cal
 	if req.Path == "labelValues" {
 		return d.labelValues(ctx, req, sender)
 	}
+	if req.Path == "spanProfiles" {
+		return d.spanProfiles(ctx, req, sender)
+	}
 	return sender.Send(&backend.CallResourceResponse{
 		Status: 404,
 	})
@@ -12,6 +12,7 @@ package dataquery
 // Defines values for PyroscopeQueryType.
 const (
 	PyroscopeQueryTypeBoth    PyroscopeQueryType = "both"
+	PyroscopeQueryTypeDiff    PyroscopeQueryType = "diff"
 	PyroscopeQueryTypeMetrics PyroscopeQueryType = "metrics"
 	PyroscopeQueryTypeProfile PyroscopeQueryType = "profile"
 )
@@ -41,6 +42,12 @@ type DataQuery struct {
 
 // GrafanaPyroscopeDataQuery defines model for GrafanaPyroscopeDataQuery.
 type GrafanaPyroscopeDataQuery struct {
+	// Selects the baseline profile of diff queries, the label selector and the time range of the query are used when not set.
+	Baseline *ProfileSelection `json:"baseline,omitempty"`
+
+	// Selects the comparison profile of diff queries, the label selector and the time range of the query are used when not set.
+	Comparison *ProfileSelection `json:"comparison,omitempty"`
+
 	// For mixed data sources the selected datasource is on the query level.
 	// For non mixed scenarios this is undefined.
 	// TODO find a better way to do this ^ that's friendly to schema
@@ -73,6 +80,21 @@ type GrafanaPyroscopeDataQuery struct {
 
 	// Specifies the query span selectors.
 	SpanSelector []string `json:"spanSelector,omitempty"`
+
+	// Specifies sets of span IDs, like the matching span sets of a trace search. The profile of the spans of all sets is queried.
+	SpanSets [][]string `json:"spanSets,omitempty"`
+}
+
+// ProfileSelection Selects a profile by its label selector and time range.
+type ProfileSelection struct {
+	// Start of the time range of the profile in milliseconds since epoch.
+	From *int64 `json:"from,omitempty"`
+
+	// Specifies the label selectors of the profile.
+	LabelSelector *string `json:"labelSelector,omitempty"`
+
+	// End of the time range of the profile in milliseconds since epoch.
+	To *int64 `json:"to,omitempty"`
 }
 
 // PyroscopeQueryType defines model for PyroscopeQueryType.
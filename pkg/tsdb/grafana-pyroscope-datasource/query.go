@@ -34,6 +34,7 @@ const (
 	queryTypeProfile = string(dataquery.PyroscopeQueryTypeProfile)
 	queryTypeMetrics = string(dataquery.PyroscopeQueryTypeMetrics)
 	queryTypeBoth    = string(dataquery.PyroscopeQueryTypeBoth)
+	queryTypeDiff    = string(dataquery.PyroscopeQueryTypeDiff)
 )
 
 // query processes single Pyroscope query transforming the response to data.Frame packaged in DataResponse
@@ -54,6 +55,13 @@ func (d *PyroscopeDatasource) query(ctx context.Context, pCtx backend.PluginCont
 
 	profileTypeId := depointerizer(qm.ProfileTypeId)
 	labelSelector := depointerizer(qm.LabelSelector)
+	spanSelector, err := spanIDs(qm)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		response.Error = err
+		return response
+	}
 
 	responseMutex := sync.Mutex{}
 	g, gCtx := errgroup.WithContext(ctx)
@@ -102,9 +110,9 @@ func (d *PyroscopeDatasource) query(ctx context.Context, pCtx backend.PluginCont
 	if query.QueryType == queryTypeProfile || query.QueryType == queryTypeBoth {
 		g.Go(func() error {
 			var profileResp *ProfileResponse
-			if len(qm.SpanSelector) > 0 {
+			if len(spanSelector) > 0 {
 				logger.Debug("Calling GetSpanProfile", "queryModel", qm, "function", logEntrypoint())
-				prof, err := d.client.GetSpanProfile(gCtx, profileTypeId, labelSelector, qm.SpanSelector, query.TimeRange.From.UnixMilli(), query.TimeRange.To.UnixMilli(), qm.MaxNodes)
+				prof, err := d.client.GetSpanProfile(gCtx, profileTypeId, labelSelector, spanSelector, query.TimeRange.From.UnixMilli(), query.TimeRange.To.UnixMilli(), qm.MaxNodes)
 				if err != nil {
 					span.RecordError(err)
 					span.SetStatus(codes.Error, err.Error())
@@ -151,6 +159,21 @@ func (d *PyroscopeDatasource) query(ctx context.Context, pCtx backend.PluginCont
 		})
 	}
 
+	if query.QueryType == queryTypeDiff {
+		g.Go(func() error {
+			frame, err := d.queryDiff(gCtx, qm, query)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return err
+			}
+			responseMutex.Lock()
+			response.Frames = append(response.Frames, frame)
+			responseMutex.Unlock()
+			return nil
+		})
+	}
+
 	if err := g.Wait(); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
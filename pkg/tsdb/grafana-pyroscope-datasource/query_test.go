@@ -293,7 +293,11 @@ func (f *FakeClient) LabelValues(ctx context.Context, label string, labelSelecto
 }
 
 func (f *FakeClient) LabelNames(ctx context.Context, labelSelector string, start int64, end int64) ([]string, error) {
-	panic("implement me")
+	f.Args = []any{labelSelector, start, end}
+	if labelSelector == `{service_name="with-spans"}` {
+		return []string{"service_name", "span_name"}, nil
+	}
+	return []string{"service_name"}, nil
 }
 
 func (f *FakeClient) GetProfile(ctx context.Context, profileTypeID, labelSelector string, start, end int64, maxNodes *int64) (*ProfileResponse, error) {
@@ -313,6 +317,7 @@ func (f *FakeClient) GetProfile(ctx context.Context, profileTypeID, labelSelecto
 }
 
 func (f *FakeClient) GetSpanProfile(ctx context.Context, profileTypeID, labelSelector string, spanSelector []string, start, end int64, maxNodes *int64) (*ProfileResponse, error) {
+	f.Args = []any{profileTypeID, labelSelector, spanSelector, start, end}
 	return &ProfileResponse{
 		Flamebearer: &Flamebearer{
 			Names: []string{"foo", "bar", "baz"},
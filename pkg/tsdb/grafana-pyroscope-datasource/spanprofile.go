@@ -0,0 +1,106 @@
+package pyroscope
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// spanIDLength is the length of the hex encoded 8 byte span IDs Pyroscope annotates samples with
+const spanIDLength = 16
+
+// spanNameLabel is the label the span profiles instrumentation adds to the profiles of spans
+const spanNameLabel = "span_name"
+
+// spanIDs returns the span IDs of the span selector and of the span sets of the query, without duplicates.
+// Span IDs are normalized to the format of Pyroscope, span IDs of traces can be shorter when their leading zeros
+// are dropped.
+func spanIDs(qm queryModel) ([]string, error) {
+	ids := make([]string, 0, len(qm.SpanSelector))
+	add := func(id string) error {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			return nil
+		}
+		if len(id) < spanIDLength {
+			id = strings.Repeat("0", spanIDLength-len(id)) + id
+		}
+		if _, err := hex.DecodeString(id); err != nil || len(id) != spanIDLength {
+			return fmt.Errorf("invalid span ID %q", id)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+		return nil
+	}
+
+	for _, id := range qm.SpanSelector {
+		if err := add(id); err != nil {
+			return nil, err
+		}
+	}
+	for _, set := range qm.SpanSets {
+		for _, id := range set {
+			if err := add(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+type spanProfilesResponse struct {
+	Available bool `json:"available"`
+}
+
+// spanProfiles tells whether there are span profiles for the label selector or the service in the time range, the
+// UI uses it to decide whether to link spans and exemplars to their profiles
+func (d *PyroscopeDatasource) spanProfiles(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	ctxLogger := logger.FromContext(ctx)
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		ctxLogger.Error("Failed to parse URL", "error", err, "function", logEntrypoint())
+		return err
+	}
+	query := u.Query()
+
+	start, _ := strconv.ParseInt(query.Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(query.Get("end"), 10, 64)
+	labelSelector := query.Get("query")
+	if labelSelector == "" {
+		service := query.Get("service")
+		if service == "" {
+			return sender.Send(&backend.CallResourceResponse{
+				Status: 400,
+				Body:   []byte("either query or service is required"),
+			})
+		}
+		labelSelector = fmt.Sprintf("{service_name=%q}", service)
+	}
+
+	labelNames, err := d.client.LabelNames(ctx, labelSelector, start, end)
+	if err != nil {
+		ctxLogger.Error("Received error from client", "error", err, "function", logEntrypoint())
+		return fmt.Errorf("error calling LabelNames: %v", err)
+	}
+
+	data, err := json.Marshal(spanProfilesResponse{Available: slices.Contains(labelNames, spanNameLabel)})
+	if err != nil {
+		ctxLogger.Error("Failed to marshal response", "error", err, "function", logEntrypoint())
+		return err
+	}
+
+	err = sender.Send(&backend.CallResourceResponse{Body: data, Headers: req.Headers, Status: 200})
+	if err != nil {
+		ctxLogger.Error("Failed to send response", "error", err, "function", logEntrypoint())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package pyroscope
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_spanIDs(t *testing.T) {
+	t.Run("span selector and span sets are merged", func(t *testing.T) {
+		qm := queryModel{}
+		qm.SpanSelector = []string{"9a2fc7B0f1e4d3c2", " 1a2b3c "}
+		qm.SpanSets = [][]string{{"9a2fc7b0f1e4d3c2", "0000000000abcdef"}, {"abcdef"}}
+		ids, err := spanIDs(qm)
+		require.NoError(t, err)
+		require.Equal(t, []string{"9a2fc7b0f1e4d3c2", "00000000001a2b3c", "0000000000abcdef"}, ids)
+	})
+
+	t.Run("invalid span IDs are an error", func(t *testing.T) {
+		qm := queryModel{}
+		qm.SpanSets = [][]string{{"not-a-span-id"}}
+		_, err := spanIDs(qm)
+		require.Error(t, err)
+
+		qm.SpanSets = [][]string{{"9a2fc7b0f1e4d3c2ff"}}
+		_, err = spanIDs(qm)
+		require.Error(t, err)
+	})
+}
+
+func Test_querySpanSets(t *testing.T) {
+	client := &FakeClient{}
+	ds := &PyroscopeDatasource{
+		client: client,
+	}
+
+	dataQuery := makeDataQuery()
+	dataQuery.QueryType = queryTypeProfile
+	dataQuery.JSON = []byte(`{"profileTypeId":"memory:alloc_objects:count:space:bytes","labelSelector":"{}","spanSets":[["9a2fc7b0f1e4d3c2"],["abcdef"]]}`)
+	resp := ds.query(context.Background(), backend.PluginContext{}, *dataQuery)
+	require.Nil(t, resp.Error)
+	require.Equal(t, 1, len(resp.Frames))
+	require.Equal(t, []string{"9a2fc7b0f1e4d3c2", "0000000000abcdef"}, client.Args[2])
+}
+
+func Test_spanProfilesResource(t *testing.T) {
+	ds := &PyroscopeDatasource{
+		client: &FakeClient{},
+	}
+
+	callResource := func(url string) *backend.CallResourceResponse {
+		sender := &FakeSender{}
+		err := ds.CallResource(context.Background(), &backend.CallResourceRequest{
+			Path:   "spanProfiles",
+			Method: "GET",
+			URL:    url,
+		}, sender)
+		require.NoError(t, err)
+		return sender.Resp
+	}
+
+	resp := callResource("spanProfiles?service=with-spans&start=10000&end=20000")
+	require.Equal(t, 200, resp.Status)
+	require.Equal(t, `{"available":true}`, string(resp.Body))
+
+	resp = callResource("spanProfiles?query=%7Bservice_name%3D%22other%22%7D&start=10000&end=20000")
+	require.Equal(t, 200, resp.Status)
+	require.Equal(t, `{"available":false}`, string(resp.Body))
+
+	resp = callResource("spanProfiles")
+	require.Equal(t, 400, resp.Status)
+}
@@ -55,6 +55,7 @@ const (
 	TestDataQueryTypeGrafanaApi                   TestDataQueryType = "grafana_api"
 	TestDataQueryTypeLinearHeatmapBucketData      TestDataQueryType = "linear_heatmap_bucket_data"
 	TestDataQueryTypeLive                         TestDataQueryType = "live"
+	TestDataQueryTypeLoad                         TestDataQueryType = "load"
 	TestDataQueryTypeLogs                         TestDataQueryType = "logs"
 	TestDataQueryTypeManualEntry                  TestDataQueryType = "manual_entry"
 	TestDataQueryTypeNoDataPoints                 TestDataQueryType = "no_data_points"
@@ -69,6 +70,8 @@ const (
 	TestDataQueryTypeSimulation                   TestDataQueryType = "simulation"
 	TestDataQueryTypeSlowQuery                    TestDataQueryType = "slow_query"
 	TestDataQueryTypeStreamingClient              TestDataQueryType = "streaming_client"
+	TestDataQueryTypeSyntheticFlameGraph          TestDataQueryType = "synthetic_flame_graph"
+	TestDataQueryTypeSyntheticTraces              TestDataQueryType = "synthetic_traces"
 	TestDataQueryTypeTableStatic                  TestDataQueryType = "table_static"
 	TestDataQueryTypeTrace                        TestDataQueryType = "trace"
 	TestDataQueryTypeUsa                          TestDataQueryType = "usa"
@@ -108,11 +111,14 @@ type TestDataQuery struct {
 	SeriesCount     int       `json:"seriesCount,omitempty"`
 	SpanCount       int       `json:"spanCount,omitempty"`
 
-	Nodes     *NodesQuery      `json:"nodes,omitempty"`
-	PulseWave *PulseWaveQuery  `json:"pulseWave,omitempty"`
-	Sim       *SimulationQuery `json:"sim,omitempty"`
-	Stream    *StreamingQuery  `json:"stream,omitempty"`
-	Usa       *USAQuery        `json:"usa,omitempty"`
+	FlameGraph *FlameGraphQuery `json:"flameGraph,omitempty"`
+	Load       *LoadQuery       `json:"load,omitempty"`
+	Nodes      *NodesQuery      `json:"nodes,omitempty"`
+	PulseWave  *PulseWaveQuery  `json:"pulseWave,omitempty"`
+	Sim        *SimulationQuery `json:"sim,omitempty"`
+	Stream     *StreamingQuery  `json:"stream,omitempty"`
+	Traces     *TracesQuery     `json:"traces,omitempty"`
+	Usa        *USAQuery        `json:"usa,omitempty"`
 }
 
 // CSVWave defines model for CSVWave.
@@ -123,6 +129,28 @@ type CSVWave struct {
 	Name      string `json:"name,omitempty"`
 }
 
+// FlameGraphQuery defines model for FlameGraphQuery.
+type FlameGraphQuery struct {
+	// Depth of the generated call tree
+	Depth int64 `json:"depth,omitempty"`
+	// Number of callees of each function
+	Fanout int64 `json:"fanout,omitempty"`
+	Seed   int64 `json:"seed,omitempty"`
+}
+
+// LoadQuery defines model for LoadQuery.
+type LoadQuery struct {
+	// Share of the series, between 0 and 1, replaced by new series every churn interval
+	Churn float64 `json:"churn,omitempty"`
+	// Interval at which series are replaced, for example 5m
+	ChurnInterval string `json:"churnInterval,omitempty"`
+	// Number of labels of each series
+	LabelCount int64 `json:"labelCount,omitempty"`
+	Seed       int64 `json:"seed,omitempty"`
+	// Number of series
+	SeriesCount int64 `json:"seriesCount,omitempty"`
+}
+
 // NodesQuery defines model for NodesQuery.
 type NodesQuery struct {
 	Count int64          `json:"count,omitempty"`
@@ -149,6 +177,8 @@ type SimulationQuery struct {
 	} `json:"key"`
 	Last   bool `json:"last,omitempty"`
 	Stream bool `json:"stream,omitempty"`
+	// YAML timeline of incidents (spike, flatline, gap or nan) to inject into the simulation
+	Timeline string `json:"timeline,omitempty"`
 }
 
 // StreamingQuery defines model for StreamingQuery.
@@ -161,6 +191,21 @@ type StreamingQuery struct {
 	Url    string             `json:"url,omitempty"`
 }
 
+// TracesQuery defines model for TracesQuery.
+type TracesQuery struct {
+	// Number of generated traces
+	Count int64 `json:"count,omitempty"`
+	// Depth of the span tree of each trace
+	Depth int64 `json:"depth,omitempty"`
+	// Number of child spans of each span
+	Fanout int64 `json:"fanout,omitempty"`
+	Seed   int64 `json:"seed,omitempty"`
+	// Adds the service graph of the traces as node graph frames
+	ServiceGraph bool `json:"serviceGraph,omitempty"`
+	// Number of services the spans are spread across
+	Services int64 `json:"services,omitempty"`
+}
+
 // USAQuery defines model for USAQuery.
 type USAQuery struct {
 	Fields []string `json:"fields,omitempty"`
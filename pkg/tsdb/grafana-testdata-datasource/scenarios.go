@@ -198,6 +198,29 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		Name: "Trace",
 	})
 
+	s.registerScenario(&Scenario{
+		ID:          kinds.TestDataQueryTypeSyntheticTraces,
+		Name:        "Synthetic Traces",
+		handler:     s.handleSyntheticTracesScenario,
+		Description: "Generates traces with a span tree of the configured depth and fanout, and optionally their service graph",
+	})
+
+	s.registerScenario(&Scenario{
+		ID:          kinds.TestDataQueryTypeSyntheticFlameGraph,
+		Name:        "Synthetic Flame Graph",
+		handler:     s.handleSyntheticFlameGraphScenario,
+		Description: "Generates a flame graph of a call tree of the configured depth and fanout",
+	})
+
+	s.registerScenario(&Scenario{
+		ID:      kinds.TestDataQueryTypeLoad,
+		Name:    "High Cardinality Load",
+		handler: s.handleLoadScenario,
+		Description: `Returns the configured number of series with the configured number of labels, for load tests.
+Every churn interval the churn share of the series is replaced by new series.
+The series and their values are deterministic for the same seed and time range.`,
+	})
+
 	s.queryMux.HandleFunc("", s.handleFallbackScenario)
 }
 
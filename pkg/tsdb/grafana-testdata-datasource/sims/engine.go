@@ -110,10 +110,42 @@ func (s *SimulationEngine) Lookup(info simulationState) (Simulation, error) {
 	return v, err
 }
 
+// replayable simulations depend on their state and not only on the time, reset restarts them at a point in time
+type replayable interface {
+	reset(t time.Time)
+}
+
+// replay creates a new instance of the simulation that is not shared with the running simulations, replayable
+// simulations are reset at start so they return the same values for the same times.
+func (s *SimulationEngine) replay(info simulationState, start time.Time) (Simulation, error) {
+	if info.Key.Type == "" {
+		return nil, fmt.Errorf("missing simulation type")
+	}
+
+	s.mutex.Lock()
+	t, ok := s.registry[info.Key.Type]
+	s.mutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown simulation type")
+	}
+
+	v, err := t.create(info)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := v.(replayable); ok {
+		r.reset(start)
+	}
+	return v, nil
+}
+
 type simulationQuery struct {
 	simulationState
 	Last   bool `json:"last"`
 	Stream bool `json:"stream"`
+
+	// YAML timeline of incidents to inject into the simulation
+	Timeline string `json:"timeline,omitempty"`
 }
 
 type dumbQueryQrapper struct {
@@ -134,9 +166,39 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 			sq.Key.TickHZ = 10
 		}
 
-		sim, err := s.Lookup(sq.simulationState)
-		if err != nil {
-			return nil, fmt.Errorf("error fetching simulation: %v", err)
+		var sim Simulation
+		getValues := func(t time.Time) map[string]any {
+			return sim.GetValues(t)
+		}
+		if sq.Timeline != "" {
+			tl, err := parseTimeline(sq.Timeline)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing timeline: %v", err)
+			}
+			start := q.TimeRange.From
+			if tl.Start != nil {
+				start = *tl.Start
+			}
+			sim, err = s.replay(sq.simulationState, start)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching simulation: %v", err)
+			}
+			valuesAt := func(t time.Time) map[string]any {
+				// use another instance to not move replayable simulations forward
+				v, err := s.replay(sq.simulationState, start)
+				if err != nil {
+					return nil
+				}
+				return v.GetValues(t)
+			}
+			getValues = func(t time.Time) map[string]any {
+				return tl.apply(start, t, sim.GetValues(t), valuesAt)
+			}
+		} else {
+			sim, err = s.Lookup(sq.simulationState)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching simulation: %v", err)
+			}
 		}
 
 		if sim == nil {
@@ -145,7 +207,7 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 
 		frame := sim.NewFrame(0)
 		if sq.Last {
-			v := sim.GetValues(q.TimeRange.To)
+			v := getValues(q.TimeRange.To)
 			appendFrameRow(frame, v)
 		} else {
 			timeWalkerMs := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
@@ -155,15 +217,16 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 			maxPoints := q.MaxDataPoints * 2
 			for i := int64(0); i < maxPoints && timeWalkerMs < to; i++ {
 				t := time.UnixMilli(timeWalkerMs).UTC()
-				vals := sim.GetValues(t)
-				if vals != nil { // nil is returned when you ask for an invalid time
+				vals := getValues(t)
+				if vals != nil { // nil is returned when you ask for an invalid time, or a gap in the timeline
 					appendFrameRow(frame, vals)
 				}
 				timeWalkerMs += stepMillis
 			}
 		}
 
-		if sq.Stream && req.PluginContext.DataSourceInstanceSettings != nil {
+		// the live stream does not replay the timeline
+		if sq.Stream && sq.Timeline == "" && req.PluginContext.DataSourceInstanceSettings != nil {
 			uid := req.PluginContext.DataSourceInstanceSettings.UID
 			frame.Meta = &data.FrameMeta{
 				Channel: fmt.Sprintf("ds/%s/sim/%s", uid, sim.GetState().Key.String()),
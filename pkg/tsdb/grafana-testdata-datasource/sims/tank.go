@@ -14,6 +14,7 @@ type tankSim struct {
 
 var (
 	_ Simulation = (*tankSim)(nil)
+	_ replayable = (*tankSim)(nil)
 )
 
 type tankConfig struct {
@@ -103,6 +104,11 @@ func (s *tankSim) GetValues(t time.Time) map[string]any {
 	}
 }
 
+// reset starts filling or draining the tank at t, the fill level stays at the level of the last values
+func (s *tankSim) reset(t time.Time) {
+	s.state.Time = t
+}
+
 func (s *tankSim) Close() error {
 	return nil
 }
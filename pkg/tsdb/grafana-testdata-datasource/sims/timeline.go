@@ -0,0 +1,155 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type incidentType string
+
+const (
+	incidentSpike    incidentType = "spike"    // adds value to the fields
+	incidentFlatline incidentType = "flatline" // holds the fields at value, or at their value when the incident starts
+	incidentGap      incidentType = "gap"      // drops the rows
+	incidentNaN      incidentType = "nan"      // sets the fields to NaN
+)
+
+// timeline scripts incidents on top of a simulation, for example:
+//
+//	start: 2024-01-01T00:00:00Z
+//	repeat: 1h
+//	incidents:
+//	  - type: spike
+//	    offset: 10m
+//	    duration: 1m
+//	    value: 50
+//	  - type: gap
+//	    offset: 30m
+//	    duration: 5m
+type timeline struct {
+	// The incident offsets are relative to start, which defaults to the start of the query time range.
+	// Set it to replay the same incidents for any time range.
+	Start *time.Time `yaml:"start"`
+	// Replay the incidents every repeat duration after start
+	Repeat    time.Duration `yaml:"repeat"`
+	Incidents []incident    `yaml:"incidents"`
+
+	// values of the flatline incidents without a value, by the time they start in nanoseconds
+	frozen map[int64]map[string]any
+}
+
+type incident struct {
+	Type     incidentType  `yaml:"type"`
+	Offset   time.Duration `yaml:"offset"`
+	Duration time.Duration `yaml:"duration"`
+	Value    *float64      `yaml:"value"`
+	// The affected fields, all the numeric fields when empty
+	Fields []string `yaml:"fields"`
+}
+
+func parseTimeline(text string) (*timeline, error) {
+	tl := &timeline{}
+	if err := yaml.Unmarshal([]byte(text), tl); err != nil {
+		return nil, err
+	}
+	if tl.Repeat < 0 {
+		return nil, fmt.Errorf("repeat must not be negative")
+	}
+	for i, inc := range tl.Incidents {
+		switch inc.Type {
+		case incidentSpike:
+			if inc.Value == nil {
+				return nil, fmt.Errorf("incident %d: spike requires a value", i)
+			}
+		case incidentFlatline, incidentGap, incidentNaN:
+		default:
+			return nil, fmt.Errorf("incident %d: unknown type %q", i, inc.Type)
+		}
+		if inc.Offset < 0 {
+			return nil, fmt.Errorf("incident %d: offset must not be negative", i)
+		}
+		if inc.Duration <= 0 {
+			return nil, fmt.Errorf("incident %d: duration must be positive", i)
+		}
+		if tl.Repeat > 0 && inc.Offset+inc.Duration > tl.Repeat {
+			return nil, fmt.Errorf("incident %d: must end within the repeat duration", i)
+		}
+	}
+	tl.frozen = make(map[int64]map[string]any)
+	return tl, nil
+}
+
+// apply returns the values of the simulation at t with the active incidents applied, or nil when t is in a gap.
+// valuesAt returns the values of the simulation at any time, flatlines use it to find the value they hold.
+func (tl *timeline) apply(start time.Time, t time.Time, values map[string]any, valuesAt func(time.Time) map[string]any) map[string]any {
+	if values == nil {
+		return nil
+	}
+	elapsed := t.Sub(start)
+	if elapsed < 0 {
+		return values
+	}
+	if tl.Repeat > 0 {
+		elapsed %= tl.Repeat
+	}
+
+	for _, inc := range tl.Incidents {
+		if elapsed < inc.Offset || elapsed >= inc.Offset+inc.Duration {
+			continue
+		}
+
+		switch inc.Type {
+		case incidentGap:
+			return nil
+		case incidentSpike:
+			values = updateNumericValues(values, inc.Fields, func(name string, v float64) float64 {
+				return v + *inc.Value
+			})
+		case incidentNaN:
+			values = updateNumericValues(values, inc.Fields, func(name string, v float64) float64 {
+				return math.NaN()
+			})
+		case incidentFlatline:
+			if inc.Value != nil {
+				values = updateNumericValues(values, inc.Fields, func(name string, v float64) float64 {
+					return *inc.Value
+				})
+				continue
+			}
+			from := t.Add(inc.Offset - elapsed)
+			held, ok := tl.frozen[from.UnixNano()]
+			if !ok {
+				held = valuesAt(from)
+				tl.frozen[from.UnixNano()] = held
+			}
+			values = updateNumericValues(values, inc.Fields, func(name string, v float64) float64 {
+				if h, ok := held[name].(float64); ok {
+					return h
+				}
+				return v
+			})
+		}
+	}
+	return values
+}
+
+// updateNumericValues returns a copy of the values with the float64 values of the fields updated
+func updateNumericValues(values map[string]any, fields []string, update func(name string, v float64) float64) map[string]any {
+	next := make(map[string]any, len(values))
+	for name, v := range values {
+		next[name] = v
+		f, ok := v.(float64)
+		if !ok {
+			continue
+		}
+		if len(fields) > 0 && !slices.Contains(fields, name) {
+			continue
+		}
+		next[name] = update(name, f)
+	}
+	return next
+}
@@ -0,0 +1,161 @@
+package sims
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimelineQuery(t *testing.T) {
+	s, err := NewSimulationEngine()
+	require.NoError(t, err)
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	query := func(simType string, config map[string]any, from time.Time, timeline string) *data.Frame {
+		sq := &simulationQuery{}
+		sq.Key = simulationKey{
+			Type:   simType,
+			TickHZ: 1,
+		}
+		sq.Config = config
+		sq.Timeline = timeline
+		sb, err := json.Marshal(map[string]any{
+			"sim": sq,
+		})
+		require.NoError(t, err)
+
+		rsp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID: "A",
+					TimeRange: backend.TimeRange{
+						From: from,
+						To:   from.Add(10 * time.Minute),
+					},
+					Interval:      time.Minute,
+					MaxDataPoints: 100,
+					JSON:          sb,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, rsp.Responses["A"].Frames, 1)
+		return rsp.Responses["A"].Frames[0]
+	}
+
+	sine := map[string]any{"period": 7}
+	t.Run("incidents are injected at their offsets", func(t *testing.T) {
+		base := query("sine", sine, start, "")
+		require.Equal(t, 10, base.Rows())
+
+		frame := query("sine", sine, start, `
+incidents:
+  - type: spike
+    offset: 2m
+    duration: 1m
+    value: 100
+  - type: gap
+    offset: 4m
+    duration: 2m
+  - type: nan
+    offset: 7m
+    duration: 1m
+  - type: flatline
+    offset: 8m
+    duration: 2m
+`)
+		require.Equal(t, 8, frame.Rows())
+		times := []time.Time{}
+		for i := 0; i < frame.Rows(); i++ {
+			times = append(times, frame.Fields[0].At(i).(time.Time))
+		}
+		require.Equal(t, start.Add(3*time.Minute), times[3])
+		require.Equal(t, start.Add(6*time.Minute), times[4])
+
+		value := func(frame *data.Frame, i int) float64 {
+			return frame.Fields[1].At(i).(float64)
+		}
+		require.Equal(t, value(base, 0), value(frame, 0))
+		require.Equal(t, value(base, 2)+100, value(frame, 2))
+		require.True(t, math.IsNaN(value(frame, 5)))
+		require.Equal(t, value(base, 8), value(frame, 6))
+		require.Equal(t, value(base, 8), value(frame, 7))
+	})
+
+	t.Run("timelines with a start replay for any time range", func(t *testing.T) {
+		timeline := `
+start: 2024-01-01T00:00:00Z
+repeat: 5m
+incidents:
+  - type: flatline
+    offset: 1m
+    duration: 3m
+    value: 42
+`
+		frame := query("sine", sine, start, timeline)
+		shifted := query("sine", sine, start.Add(5*time.Minute), timeline)
+		require.Equal(t, 10, frame.Rows())
+		for i := 0; i < 5; i++ {
+			require.Equal(t, frame.Fields[1].At(i+5), shifted.Fields[1].At(i))
+		}
+		require.Equal(t, 42.0, frame.Fields[1].At(1))
+		require.Equal(t, 42.0, frame.Fields[1].At(8))
+		require.NotEqual(t, 42.0, frame.Fields[1].At(4))
+	})
+
+	t.Run("stateful simulations are replayed", func(t *testing.T) {
+		timeline := `
+incidents:
+  - type: flatline
+    offset: 5m
+    duration: 2m
+    fields: [percentFull]
+`
+		// the tank does not fill up in the time range
+		tank := map[string]any{"tankCapacity": 10000}
+		frame := query("tank", tank, start, timeline)
+		again := query("tank", tank, start, timeline)
+		require.Equal(t, 10, frame.Rows())
+		require.Equal(t, frame, again)
+		require.Equal(t, frame.Fields[1].At(5), frame.Fields[1].At(6))
+		require.NotEqual(t, frame.Fields[2].At(5), frame.Fields[2].At(6))
+	})
+}
+
+func TestParseTimeline(t *testing.T) {
+	tl, err := parseTimeline(`
+repeat: 1h
+incidents:
+  - type: spike
+    offset: 10m
+    duration: 30s
+    value: 5
+    fields: [value]
+`)
+	require.NoError(t, err)
+	require.Nil(t, tl.Start)
+	require.Equal(t, time.Hour, tl.Repeat)
+	require.Len(t, tl.Incidents, 1)
+	require.Equal(t, 10*time.Minute, tl.Incidents[0].Offset)
+	require.Equal(t, 30*time.Second, tl.Incidents[0].Duration)
+	require.Equal(t, []string{"value"}, tl.Incidents[0].Fields)
+
+	invalid := []string{
+		"incidents: [{type: outage, offset: 1m, duration: 1m}]",
+		"incidents: [{type: spike, offset: 1m, duration: 1m}]",
+		"incidents: [{type: gap, offset: 1m}]",
+		"incidents: [{type: gap, offset: -1m, duration: 1m}]",
+		"{repeat: 5m, incidents: [{type: gap, offset: 4m, duration: 2m}]}",
+		"incidents: [{type: nan, offset: soon, duration: 1m}]",
+	}
+	for _, text := range invalid {
+		_, err := parseTimeline(text)
+		require.Error(t, err, text)
+	}
+}
@@ -0,0 +1,445 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
+)
+
+const (
+	// maxSyntheticSpans is the maximum number of spans of all the traces of a query
+	maxSyntheticSpans = 10000
+	// maxSyntheticFlameGraphNodes is the maximum number of nodes of a flame graph
+	maxSyntheticFlameGraphNodes = 10000
+	// maxLoadPoints is the maximum number of points of all the series of a load query
+	maxLoadPoints = 5000000
+)
+
+func (s *Service) handleSyntheticTracesScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			continue
+		}
+
+		respD := resp.Responses[q.RefID]
+		respD.Frames = append(respD.Frames, syntheticTraces(q, model)...)
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+func (s *Service) handleSyntheticFlameGraphScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			continue
+		}
+
+		respD := resp.Responses[q.RefID]
+		respD.Frames = append(respD.Frames, syntheticFlameGraph(model))
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+func (s *Service) handleLoadScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			continue
+		}
+
+		respD := resp.Responses[q.RefID]
+		frames, err := loadSeries(q, model)
+		if err != nil {
+			respD.Error = err
+		} else {
+			respD.Frames = append(respD.Frames, frames...)
+		}
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+type syntheticSpan struct {
+	traceID      string
+	spanID       string
+	parentSpanID string
+	operation    string
+	service      string
+	kind         string
+	statusCode   int64
+	start        float64
+	duration     float64
+}
+
+// syntheticTraces returns a trace frame for each generated trace, and the service graph of the traces as node
+// graph frames when it is enabled. Each span has fanout child spans, up to the depth of the span tree. The traces
+// are the same for the same seed and time range.
+func syntheticTraces(query backend.DataQuery, model kinds.TestDataQuery) data.Frames {
+	options := kinds.TracesQuery{}
+	if model.Traces != nil {
+		options = *model.Traces
+	}
+	count := valueOrDefault(options.Count, 1)
+	depth := valueOrDefault(options.Depth, 3)
+	fanout := valueOrDefault(options.Fanout, 2)
+	services := valueOrDefault(options.Services, 5)
+
+	rng := rand.New(rand.NewSource(options.Seed))
+	from := float64(query.TimeRange.From.UnixMilli())
+	rangeMs := float64(query.TimeRange.To.Sub(query.TimeRange.From).Milliseconds())
+
+	frames := data.Frames{}
+	spans := 0
+	var all []syntheticSpan
+	for i := int64(0); i < count && spans < maxSyntheticSpans; i++ {
+		traceID := fmt.Sprintf("%016x%016x", rng.Uint64(), rng.Uint64())
+		root := syntheticSpan{
+			traceID:   traceID,
+			spanID:    fmt.Sprintf("%016x", rng.Uint64()),
+			operation: "GET /api/operation-0",
+			service:   "service-0",
+			kind:      "server",
+			start:     from + rng.Float64()*rangeMs*0.9,
+			duration:  100 + rng.Float64()*900,
+		}
+		trace := []syntheticSpan{root}
+		spans++
+
+		// the spans of a level are the parents of the spans of the next level
+		parents := []syntheticSpan{root}
+		for level := int64(1); level < depth; level++ {
+			var children []syntheticSpan
+			for _, parent := range parents {
+				offset := parent.start
+				for c := int64(0); c < fanout && spans < maxSyntheticSpans; c++ {
+					duration := parent.duration / float64(fanout) * (0.5 + rng.Float64()*0.5)
+					child := syntheticSpan{
+						traceID:      traceID,
+						spanID:       fmt.Sprintf("%016x", rng.Uint64()),
+						parentSpanID: parent.spanID,
+						operation:    fmt.Sprintf("operation-%d", rng.Intn(10)),
+						service:      fmt.Sprintf("service-%d", rng.Int63n(services)),
+						kind:         "internal",
+						start:        offset,
+						duration:     duration,
+					}
+					if child.service != parent.service {
+						child.kind = "client"
+					}
+					// unset, or error for a few spans
+					if rng.Float64() < 0.05 {
+						child.statusCode = 2
+					}
+					offset += duration
+					children = append(children, child)
+					spans++
+				}
+			}
+			trace = append(trace, children...)
+			parents = children
+		}
+
+		frames = append(frames, traceFrame(trace))
+		all = append(all, trace...)
+	}
+
+	if options.ServiceGraph {
+		frames = append(frames, serviceGraphFrames(all)...)
+	}
+	return frames
+}
+
+// traceFrame returns a frame of the spans of a trace in the format of the trace visualization
+func traceFrame(spans []syntheticSpan) *data.Frame {
+	frame := data.NewFrame("Trace",
+		data.NewField("traceID", nil, []string{}),
+		data.NewField("spanID", nil, []string{}),
+		data.NewField("parentSpanID", nil, []string{}),
+		data.NewField("operationName", nil, []string{}),
+		data.NewField("serviceName", nil, []string{}),
+		data.NewField("kind", nil, []string{}),
+		data.NewField("statusCode", nil, []int64{}),
+		data.NewField("serviceTags", nil, []json.RawMessage{}),
+		data.NewField("startTime", nil, []float64{}),
+		data.NewField("duration", nil, []float64{}),
+		data.NewField("tags", nil, []json.RawMessage{}),
+	).SetMeta(&data.FrameMeta{
+		PreferredVisualization: "trace",
+	})
+
+	for _, span := range spans {
+		serviceTags := json.RawMessage(fmt.Sprintf(`[{"key":"service.name","value":%q}]`, span.service))
+		status := 200
+		if span.statusCode == 2 {
+			status = 500
+		}
+		tags := json.RawMessage(fmt.Sprintf(`[{"key":"http.status_code","value":%d}]`, status))
+		frame.AppendRow(span.traceID, span.spanID, span.parentSpanID, span.operation, span.service, span.kind,
+			span.statusCode, serviceTags, span.start, span.duration, tags)
+	}
+	return frame
+}
+
+// serviceGraphFrames returns the nodes and edges frames of the calls between the services of the spans
+func serviceGraphFrames(spans []syntheticSpan) data.Frames {
+	spanByID := make(map[string]syntheticSpan, len(spans))
+	for _, span := range spans {
+		spanByID[span.spanID] = span
+	}
+
+	type stats struct {
+		requests float64
+		errors   float64
+	}
+	var services, edges []string
+	serviceStats := map[string]*stats{}
+	edgeStats := map[string]*stats{}
+	edgeEnds := map[string][2]string{}
+	for _, span := range spans {
+		if _, ok := serviceStats[span.service]; !ok {
+			serviceStats[span.service] = &stats{}
+			services = append(services, span.service)
+		}
+		serviceStats[span.service].requests++
+		if span.statusCode == 2 {
+			serviceStats[span.service].errors++
+		}
+
+		parent, ok := spanByID[span.parentSpanID]
+		if !ok || parent.service == span.service {
+			continue
+		}
+		id := parent.service + "_" + span.service
+		if _, ok := edgeStats[id]; !ok {
+			edgeStats[id] = &stats{}
+			edgeEnds[id] = [2]string{parent.service, span.service}
+			edges = append(edges, id)
+		}
+		edgeStats[id].requests++
+	}
+
+	nodes := data.NewFrame("nodes",
+		data.NewField("id", nil, []string{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("mainstat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests"}),
+		data.NewField("secondarystat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Errors"}),
+	).SetMeta(&data.FrameMeta{PreferredVisualization: "nodeGraph"})
+	for _, service := range services {
+		nodes.AppendRow(service, service, serviceStats[service].requests, serviceStats[service].errors)
+	}
+
+	edgesFrame := data.NewFrame("edges",
+		data.NewField("id", nil, []string{}),
+		data.NewField("source", nil, []string{}),
+		data.NewField("target", nil, []string{}),
+		data.NewField("mainstat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Requests"}),
+	).SetMeta(&data.FrameMeta{PreferredVisualization: "nodeGraph"})
+	for _, id := range edges {
+		edgesFrame.AppendRow(id, edgeEnds[id][0], edgeEnds[id][1], edgeStats[id].requests)
+	}
+
+	return data.Frames{nodes, edgesFrame}
+}
+
+type syntheticFunction struct {
+	level      int64
+	label      string
+	self       int64
+	value      int64
+	selfRight  int64
+	valueRight int64
+	children   []*syntheticFunction
+}
+
+// syntheticFlameGraph returns a flame graph frame of a generated call tree, with the values of a second profile
+// when flamegraphDiff is set. The call tree is the same for the same seed.
+func syntheticFlameGraph(model kinds.TestDataQuery) *data.Frame {
+	options := kinds.FlameGraphQuery{}
+	if model.FlameGraph != nil {
+		options = *model.FlameGraph
+	}
+	depth := valueOrDefault(options.Depth, 5)
+	fanout := valueOrDefault(options.Fanout, 3)
+	rng := rand.New(rand.NewSource(options.Seed))
+
+	nodes := 1
+	root := &syntheticFunction{label: "total"}
+	parents := []*syntheticFunction{root}
+	for level := int64(1); level < depth; level++ {
+		var children []*syntheticFunction
+		for _, parent := range parents {
+			for c := int64(0); c < fanout && nodes < maxSyntheticFlameGraphNodes; c++ {
+				child := &syntheticFunction{
+					level: level,
+					label: fmt.Sprintf("pkg%d.function%d", rng.Intn(20), len(parent.children)),
+				}
+				parent.children = append(parent.children, child)
+				children = append(children, child)
+				nodes++
+			}
+		}
+		parents = children
+	}
+
+	var fill func(fn *syntheticFunction)
+	fill = func(fn *syntheticFunction) {
+		fn.self = rng.Int63n(100)
+		if len(fn.children) == 0 {
+			fn.self += 1
+		}
+		// the second profile differs by up to 50% in both directions
+		fn.selfRight = int64(float64(fn.self) * (0.5 + rng.Float64()))
+		fn.value, fn.valueRight = fn.self, fn.selfRight
+		for _, child := range fn.children {
+			fill(child)
+			fn.value += child.value
+			fn.valueRight += child.valueRight
+		}
+	}
+	fill(root)
+
+	frame := data.NewFrame("response",
+		data.NewField("level", nil, []int64{}),
+		data.NewField("value", nil, []int64{}).SetConfig(&data.FieldConfig{Unit: "short"}),
+		data.NewField("self", nil, []int64{}).SetConfig(&data.FieldConfig{Unit: "short"}),
+		data.NewField("label", nil, []string{}),
+	).SetMeta(&data.FrameMeta{PreferredVisualization: "flamegraph"})
+	if model.FlamegraphDiff {
+		frame.Fields = append(frame.Fields,
+			data.NewField("valueRight", nil, []int64{}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("selfRight", nil, []int64{}).SetConfig(&data.FieldConfig{Unit: "short"}),
+		)
+	}
+
+	// depth first, the nested set format of flame graph frames
+	var walk func(fn *syntheticFunction)
+	walk = func(fn *syntheticFunction) {
+		if model.FlamegraphDiff {
+			frame.AppendRow(fn.level, fn.value, fn.self, fn.label, fn.valueRight, fn.selfRight)
+		} else {
+			frame.AppendRow(fn.level, fn.value, fn.self, fn.label)
+		}
+		for _, child := range fn.children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return frame
+}
+
+// loadSeries returns series of deterministic values for load tests. Each of the series slots holds a series with
+// the configured number of labels, every churn interval the given share of the slots is replaced by new series.
+// The series and their values are the same for the same seed and time range.
+func loadSeries(query backend.DataQuery, model kinds.TestDataQuery) (data.Frames, error) {
+	options := kinds.LoadQuery{}
+	if model.Load != nil {
+		options = *model.Load
+	}
+	seriesCount := valueOrDefault(options.SeriesCount, 100)
+	labelCount := valueOrDefault(options.LabelCount, 5)
+	if options.Churn < 0 || options.Churn > 1 {
+		return nil, fmt.Errorf("churn must be between 0 and 1")
+	}
+
+	var churnInterval time.Duration
+	if options.ChurnInterval != "" {
+		var err error
+		churnInterval, err = gtime.ParseDuration(options.ChurnInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid churn interval: %w", err)
+		}
+	}
+
+	step := query.Interval
+	if step <= 0 {
+		step = time.Minute
+	}
+	from := query.TimeRange.From.Truncate(step)
+	to := query.TimeRange.To
+	points := int64(to.Sub(from)/step) + 1
+	if points*seriesCount > maxLoadPoints {
+		return nil, fmt.Errorf("%d series of %d points exceed the maximum of %d points, reduce the series count or the time range", seriesCount, points, maxLoadPoints)
+	}
+
+	type seriesKey struct {
+		slot    int64
+		version int64
+	}
+	frames := data.Frames{}
+	frameByKey := map[seriesKey]*data.Frame{}
+	for t := from; !t.After(to); t = t.Add(step) {
+		generation := int64(0)
+		if churnInterval > 0 {
+			generation = t.UnixNano() / churnInterval.Nanoseconds()
+		}
+
+		for slot := int64(0); slot < seriesCount; slot++ {
+			// a slot gets a new version once its share of churn adds up to one, the slots are offset from each
+			// other so that the given share of the slots is replaced every churn interval
+			offset := float64(mix(options.Seed, slot)%1000) / 1000
+			key := seriesKey{slot: slot, version: int64(math.Floor(float64(generation)*options.Churn + offset))}
+
+			frame, ok := frameByKey[key]
+			if !ok {
+				labels := data.Labels{"series": fmt.Sprintf("series-%d-%d", key.slot, key.version)}
+				for l := int64(0); l < labelCount; l++ {
+					labels[fmt.Sprintf("label_%d", l)] = fmt.Sprintf("value_%d", mix(options.Seed, key.slot, key.version, l)%1000)
+				}
+				frame = data.NewFrame("",
+					data.NewField(data.TimeSeriesTimeFieldName, nil, []time.Time{}),
+					data.NewField(data.TimeSeriesValueFieldName, labels, []float64{}),
+				)
+				frameByKey[key] = frame
+				frames = append(frames, frame)
+			}
+
+			// a sine wave per series with a period of an hour, so thresholds are crossed at predictable times
+			phase := float64(mix(options.Seed, key.slot, key.version)%360) * math.Pi / 180
+			value := 50 + 50*math.Sin(2*math.Pi*float64(t.UnixMilli())/float64(time.Hour.Milliseconds())+phase)
+			frame.AppendRow(t, value)
+		}
+	}
+	return frames, nil
+}
+
+// mix hashes the values into a deterministic pseudo random number, using the finalizer of splitmix64
+func mix(values ...int64) uint64 {
+	h := uint64(0x9e3779b97f4a7c15)
+	for _, v := range values {
+		h ^= uint64(v)
+		h += 0x9e3779b97f4a7c15
+		h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+		h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+		h ^= h >> 31
+	}
+	return h
+}
+
+func valueOrDefault(v, def int64) int64 {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+package testdatasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyntheticScenarios(t *testing.T) {
+	s := &Service{}
+	timeRange := backend.TimeRange{
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+	}
+	query := func(json string) backend.DataQuery {
+		return backend.DataQuery{
+			RefID:     "A",
+			TimeRange: timeRange,
+			Interval:  time.Minute,
+			JSON:      []byte(json),
+		}
+	}
+
+	t.Run("synthetic traces", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query(`{"traces":{"count":2,"depth":3,"fanout":2,"services":3,"serviceGraph":true,"seed":1}}`)},
+		}
+		resp, err := s.handleSyntheticTracesScenario(context.Background(), req)
+		require.NoError(t, err)
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 4)
+
+		for _, frame := range frames[:2] {
+			require.Equal(t, "trace", string(frame.Meta.PreferredVisualization))
+			// 1 root span, 2 children and 4 grandchildren
+			require.Equal(t, 7, frame.Rows())
+			require.Equal(t, "", frame.Fields[2].At(0))
+			require.Equal(t, frame.Fields[1].At(0), frame.Fields[2].At(1))
+		}
+		require.Equal(t, "nodes", frames[2].Name)
+		require.Equal(t, "edges", frames[3].Name)
+
+		// the same seed generates the same traces
+		again, err := s.handleSyntheticTracesScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, frames, again.Responses["A"].Frames)
+	})
+
+	t.Run("synthetic flame graph", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query(`{"flameGraph":{"depth":3,"fanout":2},"flamegraphDiff":true}`)},
+		}
+		resp, err := s.handleSyntheticFlameGraphScenario(context.Background(), req)
+		require.NoError(t, err)
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		require.Equal(t, 7, frame.Rows())
+		require.Equal(t, []string{"level", "value", "self", "label", "valueRight", "selfRight"}, fieldNames(frame))
+		require.Equal(t, []int64{0, 1, 2, 2, 1, 2, 2}, fieldInt64s(frame.Fields[0]))
+
+		// the value of a function is its self value and the values of its callees
+		values := fieldInt64s(frame.Fields[1])
+		selfs := fieldInt64s(frame.Fields[2])
+		require.Equal(t, selfs[1]+values[2]+values[3], values[1])
+		require.Equal(t, selfs[0]+values[1]+values[4], values[0])
+		valuesRight := fieldInt64s(frame.Fields[4])
+		selfsRight := fieldInt64s(frame.Fields[5])
+		require.Equal(t, selfsRight[0]+valuesRight[1]+valuesRight[4], valuesRight[0])
+	})
+
+	t.Run("load series without churn", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query(`{"load":{"seriesCount":10,"labelCount":3}}`)},
+		}
+		resp, err := s.handleLoadScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 10)
+		for _, frame := range frames {
+			require.Equal(t, 61, frame.Rows())
+			require.Len(t, frame.Fields[1].Labels, 4)
+		}
+	})
+
+	t.Run("load series with churn", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query(`{"load":{"seriesCount":10,"labelCount":3,"churn":0.5,"churnInterval":"10m","seed":2}}`)},
+		}
+		resp, err := s.handleLoadScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		frames := resp.Responses["A"].Frames
+
+		// half of the series are replaced every 10 minutes
+		require.Greater(t, len(frames), 10)
+		rows := 0
+		for _, frame := range frames {
+			rows += frame.Rows()
+		}
+		require.Equal(t, 10*61, rows)
+
+		again, err := s.handleLoadScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, frames, again.Responses["A"].Frames)
+	})
+
+	t.Run("load series with a churn interval below a millisecond", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query(`{"load":{"seriesCount":2,"churn":1,"churnInterval":"500us"}}`)},
+		}
+		resp, err := s.handleLoadScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.NotEmpty(t, resp.Responses["A"].Frames)
+	})
+
+	t.Run("load series with too many points", func(t *testing.T) {
+		req := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{query(`{"load":{"seriesCount":1000000}}`)},
+		}
+		resp, err := s.handleLoadScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+	})
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func fieldInt64s(field *data.Field) []int64 {
+	values := make([]int64, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		values = append(values, field.At(i).(int64))
+	}
+	return values
+}
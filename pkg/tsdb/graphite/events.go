@@ -0,0 +1,91 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type eventQuery struct {
+	query backend.DataQuery
+	tags  []string
+}
+
+// splitEventQueries separates the annotation queries for Graphite events, which have tags but no target,
+// from the queries for series
+func splitEventQueries(queries []backend.DataQuery) ([]eventQuery, []backend.DataQuery, error) {
+	eventQueries := make([]eventQuery, 0)
+	targetQueries := make([]backend.DataQuery, 0, len(queries))
+	for _, q := range queries {
+		var model struct {
+			Target     string   `json:"target"`
+			TargetFull string   `json:"targetFull"`
+			Tags       []string `json:"tags"`
+		}
+		if err := json.Unmarshal(q.JSON, &model); err != nil {
+			return nil, nil, err
+		}
+
+		if model.Target == "" && model.TargetFull == "" && len(model.Tags) > 0 {
+			eventQueries = append(eventQueries, eventQuery{query: q, tags: model.Tags})
+			continue
+		}
+		targetQueries = append(targetQueries, q)
+	}
+	return eventQueries, targetQueries, nil
+}
+
+// queryEvents returns the Graphite events matching the tags of the query as annotation frame
+func (s *Service) queryEvents(ctx context.Context, dsInfo *datasourceInfo, q eventQuery) backend.DataResponse {
+	from, until := epochMStoGraphiteTime(q.query.TimeRange)
+	params := url.Values{
+		"from":  []string{from},
+		"until": []string{until},
+		"tags":  []string{strings.Join(q.tags, " ")},
+	}
+
+	body, status, err := s.doResourceRequest(ctx, dsInfo, "events/get_data", params)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusBadGateway, err.Error())
+	}
+	if status/100 != 2 {
+		return backend.ErrDataResponse(backend.Status(status), fmt.Sprintf("request failed, status: %d", status))
+	}
+
+	events, err := parseEvents(body)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("failed to parse graphite events: %v", err))
+	}
+
+	return backend.DataResponse{Frames: data.Frames{eventsToFrame(q.query.RefID, events)}}
+}
+
+func eventsToFrame(refID string, events []Event) *data.Frame {
+	times := make([]time.Time, 0, len(events))
+	titles := make([]string, 0, len(events))
+	texts := make([]string, 0, len(events))
+	tags := make([]json.RawMessage, 0, len(events))
+	for _, e := range events {
+		times = append(times, time.UnixMilli(int64(e.When*1000)).UTC())
+		titles = append(titles, e.What)
+		texts = append(texts, e.Data)
+		encoded, _ := json.Marshal(e.Tags)
+		tags = append(tags, encoded)
+	}
+
+	frame := data.NewFrame(refID,
+		data.NewField("time", nil, times),
+		data.NewField("title", nil, titles),
+		data.NewField("text", nil, texts),
+		data.NewField("tags", nil, tags),
+	)
+	frame.RefID = refID
+	frame.Meta = &data.FrameMeta{DataTopic: data.DataTopicAnnotations}
+	return frame
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/patrickmn/go-cache"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
@@ -31,8 +33,9 @@ import (
 var logger = log.New("tsdb.graphite")
 
 type Service struct {
-	im     instancemgmt.InstanceManager
-	tracer tracing.Tracer
+	im              instancemgmt.InstanceManager
+	tracer          tracing.Tracer
+	resourceHandler backend.CallResourceHandler
 }
 
 const (
@@ -41,16 +44,20 @@ const (
 )
 
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
-	return &Service{
+	s := &Service{
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 		tracer: tracer,
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+	// resourceCache caches the responses of the resource handlers
+	resourceCache *cache.Cache
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -66,9 +73,10 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 		}
 
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:    client,
+			URL:           settings.URL,
+			Id:            settings.ID,
+			resourceCache: newResourceCache(),
 		}
 
 		return model, nil
@@ -97,8 +105,35 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	eventQueries, queries, err := splitEventQueries(req.Queries)
+	if err != nil {
+		return nil, err
+	}
+	if len(eventQueries) == 0 {
+		return s.queryTargets(ctx, logger, dsInfo, req.PluginContext, queries)
+	}
+
+	result := &backend.QueryDataResponse{Responses: make(backend.Responses)}
+	if len(queries) > 0 {
+		result, err = s.queryTargets(ctx, logger, dsInfo, req.PluginContext, queries)
+		if err != nil {
+			return result, err
+		}
+		if result.Responses == nil {
+			result.Responses = make(backend.Responses)
+		}
+	}
+	for _, q := range eventQueries {
+		result.Responses[q.query.RefID] = s.queryEvents(ctx, dsInfo, q)
+	}
+
+	return result, nil
+}
+
+// queryTargets renders the targets of the queries with a single request
+func (s *Service) queryTargets(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, pluginCtx backend.PluginContext, queries []backend.DataQuery) (*backend.QueryDataResponse, error) {
 	// take the first query in the request list, since all query should share the same timerange
-	q := req.Queries[0]
+	q := queries[0]
 
 	/*
 		graphite doc about from and until, with sdk we are getting absolute instead of relative time
@@ -114,7 +149,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	// Convert datasource query to graphite target request
-	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, req.Queries)
+	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, queries)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +158,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	if len(emptyQueries) != 0 {
 		logger.Warn("Found query models without targets", "models without targets", strings.Join(emptyQueries, "\n"))
 		// If no queries had a valid target, return an error; otherwise, attempt with the targets we have
-		if len(emptyQueries) == len(req.Queries) {
+		if len(emptyQueries) == len(queries) {
 			return &result, errors.New("no query target found for the alert rule")
 		}
 	}
@@ -147,7 +182,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		attribute.String("from", from),
 		attribute.String("until", until),
 		attribute.Int64("datasource_id", dsInfo.Id),
-		attribute.Int64("org_id", req.PluginContext.OrgID),
+		attribute.Int64("org_id", pluginCtx.OrgID),
 	)
 	s.tracer.Inject(ctx, graphiteReq.Header, span)
 
@@ -0,0 +1,282 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	// metric names and tags change slowly, a short cache saves the many requests of the query editor
+	metricsCacheTTL = time.Minute
+	// functions only change when Graphite is upgraded
+	functionsCacheTTL = time.Hour
+)
+
+// Graphite 1.1.7 returns invalid JSON for function parameters defaulting to infinity,
+// see https://github.com/graphite-project/graphite-web/issues/2609
+var infinityDefaultRegexp = regexp.MustCompile(`"default": ?Infinity`)
+
+var tagsSeparatorRegexp = regexp.MustCompile(`[ ,]+`)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleMetricsFind)
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleTagsAutoComplete("tags/autoComplete/tags"))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleTagsAutoComplete("tags/autoComplete/values"))
+	mux.HandleFunc("/functions", s.handleFunctions)
+	mux.HandleFunc("/events", s.handleEvents)
+	return mux
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
+// MetricsFindResponse is a node of the metric tree returned by /metrics/find
+type MetricsFindResponse struct {
+	Text       string `json:"text"`
+	Id         string `json:"id"`
+	Expandable bool   `json:"expandable"`
+	Leaf       bool   `json:"leaf"`
+}
+
+type FunctionParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Multiple    bool   `json:"multiple,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Options     any    `json:"options,omitempty"`
+	Suggestions any    `json:"suggestions,omitempty"`
+}
+
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Module      string          `json:"module"`
+	Group       string          `json:"group"`
+	Params      []FunctionParam `json:"params"`
+}
+
+// Event is a Graphite event, usually used as annotation
+type Event struct {
+	When float64  `json:"when"`
+	What string   `json:"what"`
+	Tags []string `json:"tags"`
+	Data string   `json:"data"`
+}
+
+func (s *Service) handleMetricsFind(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	// the query editor sends the pattern in a form body
+	if err := req.ParseForm(); err == nil && req.PostForm.Get("query") != "" {
+		query.Set("query", req.PostForm.Get("query"))
+	}
+	if query.Get("query") == "" {
+		writeResourceError(rw, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	s.serveResource(rw, req, "metrics/find", query, metricsCacheTTL, func(body []byte) (any, error) {
+		var nodes []struct {
+			Text       string `json:"text"`
+			Id         string `json:"id"`
+			Expandable int    `json:"expandable"`
+			Leaf       int    `json:"leaf"`
+		}
+		if err := json.Unmarshal(body, &nodes); err != nil {
+			return nil, err
+		}
+		result := make([]MetricsFindResponse, 0, len(nodes))
+		for _, n := range nodes {
+			result = append(result, MetricsFindResponse{Text: n.Text, Id: n.Id, Expandable: n.Expandable == 1, Leaf: n.Leaf == 1})
+		}
+		return result, nil
+	})
+}
+
+func (s *Service) handleTagsAutoComplete(endpoint string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		s.serveResource(rw, req, endpoint, req.URL.Query(), metricsCacheTTL, func(body []byte) (any, error) {
+			var tags []string
+			if err := json.Unmarshal(body, &tags); err != nil {
+				return nil, err
+			}
+			return tags, nil
+		})
+	}
+}
+
+func (s *Service) handleFunctions(rw http.ResponseWriter, req *http.Request) {
+	s.serveResource(rw, req, "functions", url.Values{}, functionsCacheTTL, func(body []byte) (any, error) {
+		body = infinityDefaultRegexp.ReplaceAll(body, []byte(`"default": "Infinity"`))
+		var functions map[string]FunctionDefinition
+		if err := json.Unmarshal(body, &functions); err != nil {
+			return nil, err
+		}
+		return functions, nil
+	})
+}
+
+func (s *Service) handleEvents(rw http.ResponseWriter, req *http.Request) {
+	query := url.Values{}
+	for _, param := range []string{"from", "until", "tags"} {
+		if v := req.URL.Query().Get(param); v != "" {
+			query.Set(param, v)
+		}
+	}
+
+	// events are not cached, new annotations should show up right away
+	s.serveResource(rw, req, "events/get_data", query, 0, func(body []byte) (any, error) {
+		return parseEvents(body)
+	})
+}
+
+// serveResource requests the endpoint of the Graphite API and writes the typed response. Successful responses are
+// cached per datasource for the given duration.
+func (s *Service) serveResource(rw http.ResponseWriter, req *http.Request, endpoint string, query url.Values, ttl time.Duration, parse func(body []byte) (any, error)) {
+	ctx := req.Context()
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, backend.PluginConfigFromContext(ctx))
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to get datasource: %v", err))
+		return
+	}
+
+	cacheKey := endpoint + "?" + query.Encode()
+	if ttl > 0 && dsInfo.resourceCache != nil {
+		if cached, found := dsInfo.resourceCache.Get(cacheKey); found {
+			writeResourceResponse(rw, http.StatusOK, cached.([]byte))
+			return
+		}
+	}
+
+	body, status, err := s.doResourceRequest(ctx, dsInfo, endpoint, query)
+	if err != nil {
+		logger.Warn("Graphite resource request failed", "endpoint", endpoint, "error", err)
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+	if status/100 != 2 {
+		logger.Info("Graphite resource request failed", "endpoint", endpoint, "status", status, "body", string(body))
+		writeResourceError(rw, status, fmt.Sprintf("request failed, status: %d", status))
+		return
+	}
+
+	result, err := parse(body)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to parse graphite response: %v", err))
+		return
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+		return
+	}
+
+	if ttl > 0 && dsInfo.resourceCache != nil {
+		dsInfo.resourceCache.Set(cacheKey, encoded, ttl)
+	}
+	writeResourceResponse(rw, http.StatusOK, encoded)
+}
+
+// doResourceRequest sends a GET request to the Graphite API with the client of the datasource, so the configured
+// authentication and headers are used.
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, endpoint string, query url.Values) ([]byte, int, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, 0, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = query.Encode()
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, res.Body); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), res.StatusCode, nil
+}
+
+// parseEvents parses the response of /events/get_data. Older Graphite versions return the tags as a single string.
+func parseEvents(body []byte) ([]Event, error) {
+	var raw []struct {
+		When float64 `json:"when"`
+		What string  `json:"what"`
+		Tags any     `json:"tags"`
+		Data string  `json:"data"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(raw))
+	for _, e := range raw {
+		event := Event{When: e.When, What: e.What, Data: e.Data, Tags: []string{}}
+		switch tags := e.Tags.(type) {
+		case string:
+			for _, tag := range tagsSeparatorRegexp.Split(strings.TrimSpace(tags), -1) {
+				if tag != "" {
+					event.Tags = append(event.Tags, tag)
+				}
+			}
+		case []any:
+			for _, tag := range tags {
+				if tag, ok := tag.(string); ok {
+					event.Tags = append(event.Tags, tag)
+				}
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func newResourceCache() *cache.Cache {
+	return cache.New(metricsCacheTTL, 10*time.Minute)
+}
+
+func writeResourceResponse(rw http.ResponseWriter, code int, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, code int, msg string) {
+	body, _ := json.Marshal(map[string]string{"error": msg})
+	writeResourceResponse(rw, code, body)
+}
@@ -0,0 +1,145 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type resourceInstanceManager struct {
+	dsInfo datasourceInfo
+}
+
+func (f resourceInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.dsInfo, nil
+}
+
+func (f resourceInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func newResourceTestService(t *testing.T, handler http.HandlerFunc) (*Service, *int) {
+	t.Helper()
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &Service{
+		im: resourceInstanceManager{dsInfo: datasourceInfo{
+			HTTPClient:    srv.Client(),
+			URL:           srv.URL,
+			resourceCache: newResourceCache(),
+		}},
+		tracer: tracing.InitializeTracerForTest(),
+	}, &requests
+}
+
+func TestResourceHandler(t *testing.T) {
+	t.Run("metrics/find returns typed nodes and caches them", func(t *testing.T) {
+		s, requests := newResourceTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/metrics/find", r.URL.Path)
+			assert.Equal(t, "prod.*", r.URL.Query().Get("query"))
+			_, _ = w.Write([]byte(`[{"text":"servers","id":"prod.servers","leaf":0,"expandable":1,"allowChildren":1},{"text":"up","id":"prod.up","leaf":1,"expandable":0,"allowChildren":0}]`))
+		})
+
+		for i := 0; i < 2; i++ {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/metrics/find", strings.NewReader("query=prod.*"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			s.handleMetricsFind(rw, req)
+
+			require.Equal(t, http.StatusOK, rw.Code)
+			var nodes []MetricsFindResponse
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &nodes))
+			assert.Equal(t, []MetricsFindResponse{
+				{Text: "servers", Id: "prod.servers", Expandable: true},
+				{Text: "up", Id: "prod.up", Leaf: true},
+			}, nodes)
+		}
+		assert.Equal(t, 1, *requests)
+	})
+
+	t.Run("functions fixes infinite defaults", func(t *testing.T) {
+		s, _ := newResourceTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"removeAboveValue":{"name":"removeAboveValue","group":"Filter Data","params":[{"name":"n","type":"float","default": Infinity}]}}`))
+		})
+
+		rw := httptest.NewRecorder()
+		s.handleFunctions(rw, httptest.NewRequest(http.MethodGet, "/functions", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		var functions map[string]FunctionDefinition
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &functions))
+		assert.Equal(t, "Infinity", functions["removeAboveValue"].Params[0].Default)
+	})
+
+	t.Run("events normalizes string tags", func(t *testing.T) {
+		s, _ := newResourceTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/events/get_data", r.URL.Path)
+			assert.Equal(t, "deploy", r.URL.Query().Get("tags"))
+			_, _ = w.Write([]byte(`[{"when":1700000000,"what":"deploy","tags":"deploy, prod","data":"v1.2"},{"when":1700000060,"what":"rollback","tags":["deploy"],"data":""}]`))
+		})
+
+		rw := httptest.NewRecorder()
+		s.handleEvents(rw, httptest.NewRequest(http.MethodGet, "/events?from=1&until=2&tags=deploy", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		var events []Event
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &events))
+		require.Len(t, events, 2)
+		assert.Equal(t, []string{"deploy", "prod"}, events[0].Tags)
+		assert.Equal(t, []string{"deploy"}, events[1].Tags)
+	})
+
+	t.Run("returns the status of failed requests", func(t *testing.T) {
+		s, _ := newResourceTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		rw := httptest.NewRecorder()
+		s.handleTagsAutoComplete("tags/autoComplete/tags")(rw, httptest.NewRequest(http.MethodGet, "/tags/autoComplete/tags", nil))
+		assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	})
+}
+
+func TestQueryDataEvents(t *testing.T) {
+	s, _ := newResourceTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/events/get_data", r.URL.Path)
+		assert.Equal(t, "deploy prod", r.URL.Query().Get("tags"))
+		_, _ = w.Write([]byte(`[{"when":1700000000,"what":"deploy","tags":["deploy","prod"],"data":"v1.2"}]`))
+	})
+
+	res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     "Anno",
+			JSON:      []byte(`{"tags":["deploy","prod"],"fromAnnotations":true}`),
+			TimeRange: backend.TimeRange{From: time.Unix(1699990000, 0), To: time.Unix(1700010000, 0)},
+		}},
+	})
+	require.NoError(t, err)
+
+	resp := res.Responses["Anno"]
+	require.NoError(t, resp.Error)
+	require.Len(t, resp.Frames, 1)
+	frame := resp.Frames[0]
+	assert.Equal(t, data.DataTopicAnnotations, frame.Meta.DataTopic)
+	assert.Equal(t, 1, frame.Rows())
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), frame.Fields[0].At(0))
+	assert.Equal(t, "deploy", frame.Fields[1].At(0))
+	assert.Equal(t, "v1.2", frame.Fields[2].At(0))
+}
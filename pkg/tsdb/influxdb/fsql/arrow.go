@@ -59,7 +59,15 @@ func newQueryDataResponse(reader recordReader, query sqlutil.Query, headers meta
 
 		if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
 			var err error
-			frame, err = data.LongToWide(frame, nil)
+			frame, err = data.LongToWide(frame, query.FillMissing)
+			if err != nil {
+				resp.Error = err
+				return resp
+			}
+		}
+		if query.FillMissing != nil && query.Interval > 0 {
+			var err error
+			frame, err = resampleFrame(frame, query)
 			if err != nil {
 				resp.Error = err
 				return resp
@@ -78,7 +86,21 @@ func newQueryDataResponse(reader recordReader, query sqlutil.Query, headers meta
 	return resp
 }
 
-// frameForRecords creates a [data.Frame] from a stream of [arrow.Record]s.
+// resampleFrame fills the missing intervals of a wide frame, the buckets start at the first interval of the time range
+func resampleFrame(frame *data.Frame, query sqlutil.Query) (*data.Frame, error) {
+	interval := int64(query.Interval.Seconds())
+	if interval <= 0 {
+		return frame, nil
+	}
+	timeRange := backend.TimeRange{
+		From: time.Unix(query.TimeRange.From.Unix()/interval*interval, 0),
+		To:   query.TimeRange.To,
+	}
+	return sqlutil.ResampleWideFrame(frame, query.FillMissing, timeRange, query.Interval)
+}
+
+// frameForRecords creates a [data.Frame] from a stream of [arrow.Record]s. The records are copied into the frame
+// one at a time and no further records are read once the row limit is reached.
 func frameForRecords(reader recordReader) (*data.Frame, error) {
 	var (
 		frame = newFrame(reader.Schema())
@@ -86,26 +108,44 @@ func frameForRecords(reader recordReader) (*data.Frame, error) {
 	)
 	for reader.Next() {
 		record := reader.Record()
-		for i, col := range record.Columns() {
-			if err := copyData(frame.Fields[i], col); err != nil {
-				return frame, err
-			}
+		limit := record.NumRows()
+		if rows+limit > rowLimit {
+			limit = rowLimit - rows
+			frame.AppendNotices(rowLimitNotice())
+		}
+		if err := copyRecord(frame.Fields, record, limit); err != nil {
+			return frame, err
 		}
 
-		rows += record.NumRows()
-		if rows > rowLimit {
-			frame.AppendNotices(data.Notice{
-				Severity: data.NoticeSeverityWarning,
-				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
-			})
+		rows += limit
+		if rows >= rowLimit {
 			return frame, nil
 		}
+	}
+	if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return frame, err
+	}
+	return frame, nil
+}
 
-		if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
-			return frame, err
+// copyRecord appends the first rows of the columns of the record to the fields
+func copyRecord(fields []*data.Field, record arrow.Record, rows int64) error {
+	for i, col := range record.Columns() {
+		sliced := array.NewSlice(col, 0, rows)
+		err := copyData(fields[i], sliced)
+		sliced.Release()
+		if err != nil {
+			return err
 		}
 	}
-	return frame, nil
+	return nil
+}
+
+func rowLimitNotice() data.Notice {
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+	}
 }
 
 // newFrame builds a new Data Frame from an Arrow Schema.
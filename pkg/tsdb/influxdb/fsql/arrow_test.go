@@ -265,6 +265,51 @@ func TestNewQueryDataResponse_WideTable(t *testing.T) {
 	assert.Equal(t, []int64{1, 0, 0}, extractFieldValues[int64](t, frame.Fields[3]))
 }
 
+func TestNewQueryDataResponse_RowLimit(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "value", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	builder := array.NewInt64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues(make([]int64, 300_000), nil)
+	values := builder.NewArray()
+	defer values.Release()
+	record := array.NewRecord(schema, []arrow.Array{values}, -1)
+	defer record.Release()
+
+	reader := &endlessReader{schema: schema, record: record}
+	resp := newQueryDataResponse(reader, sqlutil.Query{Format: sqlutil.FormatOptionTable}, metadata.MD{})
+	assert.NoError(t, resp.Error)
+	if assert.Len(t, resp.Frames, 1) {
+		assert.Equal(t, rowLimit, resp.Frames[0].Rows())
+		assert.Len(t, resp.Frames[0].Meta.Notices, 1)
+	}
+	// the fourth record reaches the row limit, no further records are read
+	assert.Equal(t, 4, reader.reads)
+}
+
+// endlessReader returns the same record forever and counts the records read
+type endlessReader struct {
+	schema *arrow.Schema
+	record arrow.Record
+	reads  int
+}
+
+func (r *endlessReader) Next() bool {
+	r.reads++
+	return true
+}
+
+func (r *endlessReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *endlessReader) Record() arrow.Record {
+	return r.record
+}
+
+func (r *endlessReader) Err() error {
+	return nil
+}
+
 func extractFieldValues[T any](t *testing.T, field *data.Field) []T {
 	t.Helper()
 
@@ -5,13 +5,16 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/apache/arrow/go/v15/arrow/flight"
 	"github.com/apache/arrow/go/v15/arrow/flight/flightsql"
 	"github.com/apache/arrow/go/v15/arrow/flight/flightsql/example"
 	"github.com/apache/arrow/go/v15/arrow/memory"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
@@ -102,6 +105,129 @@ func (suite *FSQLTestSuite) TestIntegration_QueryData() {
 	})
 }
 
+func (suite *FSQLTestSuite) TestIntegration_CallResource() {
+	dsInfo := &models.DatasourceInfo{
+		URL:          "http://" + suite.addr,
+		InsecureGrpc: true,
+	}
+	call := func(path, query string) *backend.CallResourceResponse {
+		var res *backend.CallResourceResponse
+		err := CallResource(context.Background(), dsInfo, &backend.CallResourceRequest{Method: "GET", Path: path, URL: path + "?" + query},
+			backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+				res = r
+				return nil
+			}))
+		require.NoError(suite.T(), err)
+		return res
+	}
+
+	suite.Run("should list the tables", func() {
+		res := call("tables", "")
+		require.Equal(suite.T(), 200, res.Status)
+
+		var tables []Table
+		require.NoError(suite.T(), json.Unmarshal(res.Body, &tables))
+		names := make([]string, 0, len(tables))
+		for _, t := range tables {
+			names = append(names, t.Name)
+		}
+		require.Contains(suite.T(), names, "intTable")
+		require.Contains(suite.T(), names, "foreignTable")
+	})
+
+	suite.Run("should list the columns of a table", func() {
+		res := call("columns", "table=intTable")
+		require.Equal(suite.T(), 200, res.Status)
+
+		var columns []Column
+		require.NoError(suite.T(), json.Unmarshal(res.Body, &columns))
+		require.Len(suite.T(), columns, 4)
+		require.Equal(suite.T(), "id", columns[0].Name)
+		require.Equal(suite.T(), columnKindField, columns[0].Kind)
+	})
+
+	suite.Run("should require a table", func() {
+		res := call("columns", "")
+		require.Equal(suite.T(), 400, res.Status)
+	})
+}
+
+func (suite *FSQLTestSuite) TestIntegration_StreamQuery() {
+	suite.Run("should send the record batches as frames", func() {
+		qm, err := parseStreamQuery(mustQueryJSON(suite.T(), "A", "select * from intTable"), time.Now())
+		require.NoError(suite.T(), err)
+
+		var rows int
+		err = streamQuery(context.Background(), &models.DatasourceInfo{
+			URL:          "http://" + suite.addr,
+			InsecureGrpc: true,
+		}, qm, func(frame *data.Frame) error {
+			require.Equal(suite.T(), "id", frame.Fields[0].Name)
+			rows += frame.Rows()
+			return nil
+		})
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), 4, rows)
+	})
+}
+
+func (suite *FSQLTestSuite) TestIntegration_RunStream() {
+	suite.Run("subscribers joining a running stream get its result", func() {
+		dsInfo := &models.DatasourceInfo{
+			URL:          "http://" + suite.addr,
+			InsecureGrpc: true,
+		}
+		raw := mustQueryJSON(suite.T(), "A", "select * from intTable")
+		sender := &packetSender{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- RunStream(ctx, dsInfo, "sql/intTable", raw, backend.NewStreamSender(sender))
+		}()
+
+		// the first subscriber gets the frames of the stream
+		var initialData *backend.InitialData
+		require.Eventually(suite.T(), func() bool {
+			var err error
+			initialData, err = StreamInitialData(dsInfo, "sql/intTable")
+			require.NoError(suite.T(), err)
+			return initialData != nil
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NotEmpty(suite.T(), sender.packets())
+
+		// the second subscriber gets the result as initial data
+		var frame data.Frame
+		require.NoError(suite.T(), json.Unmarshal(initialData.Data(), &frame))
+		require.Equal(suite.T(), "id", frame.Fields[0].Name)
+		require.Equal(suite.T(), 4, frame.Rows())
+
+		cancel()
+		require.NoError(suite.T(), <-done)
+		initialData, err := StreamInitialData(dsInfo, "sql/intTable")
+		require.NoError(suite.T(), err)
+		require.Nil(suite.T(), initialData)
+	})
+}
+
+// packetSender collects the packets sent to the subscribers of a stream
+type packetSender struct {
+	mu   sync.Mutex
+	sent []*backend.StreamPacket
+}
+
+func (s *packetSender) Send(packet *backend.StreamPacket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, packet)
+	return nil
+}
+
+func (s *packetSender) packets() []*backend.StreamPacket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent
+}
+
 func mustQueryJSON(t *testing.T, refID, sql string) []byte {
 	t.Helper()
 
@@ -2,34 +2,132 @@ package fsql
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 )
 
-var macros = sqlutil.Macros{
-	"dateBin":        macroDateBin(""),
-	"dateBinAlias":   macroDateBin("_binned"),
-	"interval":       macroInterval,
-	"timeGroup":      macroTimeGroup,
-	"timeGroupAlias": macroTimeGroupAlias,
-
-	// The behaviors of timeFrom and timeTo as defined in the SDK are different
-	// from all other Grafana SQL plugins. Instead we'll take the implementations,
-	// rename them and define timeFrom and timeTo ourselves.
-	"timeTo":   macroTo,
-	"timeFrom": macroFrom,
+// timeGroupFill holds the fill mode and the interval of the $__timeGroup macro of a query, the query
+// result is resampled to fill the missing intervals
+type timeGroupFill struct {
+	missing  *data.FillMissing
+	interval time.Duration
 }
 
-func macroTimeGroup(query *sqlutil.Query, args []string) (string, error) {
-	if len(args) != 2 {
-		return "", fmt.Errorf("%w: expected 1 argument, received %d", sqlutil.ErrorBadArgumentCount, len(args))
+func newMacros(fill *timeGroupFill) sqlutil.Macros {
+	return sqlutil.Macros{
+		"dateBin":        macroDateBin(""),
+		"dateBinAlias":   macroDateBin("_binned"),
+		"interval":       macroInterval,
+		"timeGroup":      macroTimeGroup(fill, false),
+		"timeGroupAlias": macroTimeGroup(fill, true),
+
+		// The behaviors of timeFrom and timeTo as defined in the SDK are different
+		// from all other Grafana SQL plugins. Instead we'll take the implementations,
+		// rename them and define timeFrom and timeTo ourselves.
+		"timeTo":   macroTo,
+		"timeFrom": macroFrom,
+	}
+}
+
+// macroTimeGroup either groups by the date parts down to a unit, e.g. $__timeGroup(time, 'hour'), or like the
+// other SQL data sources into time buckets of an interval, e.g. $__timeGroup(time, $__interval, previous),
+// with an optional fill mode of NULL, previous or a value for the missing buckets
+func macroTimeGroup(fill *timeGroupFill, alias bool) sqlutil.MacroFunc {
+	return func(query *sqlutil.Query, args []string) (string, error) {
+		if len(args) == 2 && isDatePartUnit(args[1]) {
+			if alias {
+				return datePartsAlias(args[0], args[1]), nil
+			}
+			return dateParts(args[0], args[1]), nil
+		}
+		if len(args) != 2 && len(args) != 3 {
+			return "", fmt.Errorf("%w: expected 2 or 3 arguments, received %d", sqlutil.ErrorBadArgumentCount, len(args))
+		}
+
+		column := args[0]
+		interval, err := timeGroupInterval(query, args[1])
+		if err != nil {
+			return "", err
+		}
+		if len(args) == 3 {
+			missing, err := parseFillMode(args[2])
+			if err != nil {
+				return "", err
+			}
+			fill.missing = missing
+			fill.interval = interval
+		}
+
+		res := fmt.Sprintf("date_bin(%s, %s, timestamp '1970-01-01T00:00:00Z')", sqlInterval(interval), column)
+		if alias {
+			res += " as time"
+		}
+		return res, nil
+	}
+}
+
+// timeGroupInterval parses the interval argument of $__timeGroup. Like in the other SQL data sources the interval
+// variables are resolved to the interval of the query, depending on the order in which the macros are expanded the
+// argument is either still the variable or already the expanded $__interval macro.
+func timeGroupInterval(query *sqlutil.Query, arg string) (time.Duration, error) {
+	arg = strings.TrimSpace(arg)
+	if expanded, _ := macroInterval(query, nil); arg == "$__interval" || arg == "$__interval_ms" || arg == expanded {
+		if query.Interval < time.Millisecond {
+			return 0, fmt.Errorf("the interval of the query must be at least 1ms")
+		}
+		return query.Interval, nil
 	}
 
-	column := args[0]
+	interval, err := gtime.ParseInterval(strings.Trim(arg, `'"`))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing interval %v", arg)
+	}
+	if interval < time.Millisecond {
+		return 0, fmt.Errorf("interval %v must be at least 1ms", arg)
+	}
+	return interval, nil
+}
 
+// sqlInterval formats the interval in seconds, or in milliseconds for intervals which are not whole seconds
+func sqlInterval(interval time.Duration) string {
+	if interval%time.Second != 0 {
+		return fmt.Sprintf("interval '%d millisecond'", interval.Milliseconds())
+	}
+	return fmt.Sprintf("interval '%d second'", int64(interval.Seconds()))
+}
+
+// parseFillMode parses the fill argument of $__timeGroup like the other SQL data sources do
+func parseFillMode(arg string) (*data.FillMissing, error) {
+	switch arg = strings.TrimSpace(arg); arg {
+	case "NULL":
+		return &data.FillMissing{Mode: data.FillModeNull}, nil
+	case "previous":
+		return &data.FillMissing{Mode: data.FillModePrevious}, nil
+	default:
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing fill value %v", arg)
+		}
+		return &data.FillMissing{Mode: data.FillModeValue, Value: value}, nil
+	}
+}
+
+func isDatePartUnit(unit string) bool {
+	switch strings.Trim(unit, `'"`) {
+	case "minute", "hour", "day", "month", "year":
+		return true
+	}
+	return false
+}
+
+func dateParts(column, unit string) string {
 	res := ""
-	switch args[1] {
+	switch strings.Trim(unit, `'"`) {
 	case "minute":
 		res += fmt.Sprintf("datepart('minute', %s),", column)
 		fallthrough
@@ -46,18 +144,12 @@ func macroTimeGroup(query *sqlutil.Query, args []string) (string, error) {
 		res += fmt.Sprintf("datepart('year', %s)", column)
 	}
 
-	return res, nil
+	return res
 }
 
-func macroTimeGroupAlias(query *sqlutil.Query, args []string) (string, error) {
-	if len(args) != 2 {
-		return "", fmt.Errorf("%w: expected 1 argument, received %d", sqlutil.ErrorBadArgumentCount, len(args))
-	}
-
-	column := args[0]
-
+func datePartsAlias(column, unit string) string {
 	res := ""
-	switch args[1] {
+	switch strings.Trim(unit, `'"`) {
 	case "minute":
 		res += fmt.Sprintf("datepart('minute', %s) as %s_minute,", column, column)
 		fallthrough
@@ -74,11 +166,11 @@ func macroTimeGroupAlias(query *sqlutil.Query, args []string) (string, error) {
 		res += fmt.Sprintf("datepart('year', %s) as %s_year", column, column)
 	}
 
-	return res, nil
+	return res
 }
 
 func macroInterval(query *sqlutil.Query, _ []string) (string, error) {
-	return fmt.Sprintf("interval '%d second'", int64(query.Interval.Seconds())), nil
+	return sqlInterval(query.Interval), nil
 }
 
 // https://docs.influxdata.com/influxdb/cloud-serverless/query-data/sql/cast-types/?t=CAST%28%29#cast-to-a-timestamp-type
@@ -103,6 +195,6 @@ func macroDateBin(suffix string) sqlutil.MacroFunc {
 			}
 			return fmt.Sprintf(" as %s%s", column, suffix)
 		}()
-		return fmt.Sprintf("date_bin(interval '%d second', %s, timestamp '1970-01-01T00:00:00Z')%s", int64(query.Interval.Seconds()), column, aliasing), nil
+		return fmt.Sprintf("date_bin(%s, %s, timestamp '1970-01-01T00:00:00Z')%s", sqlInterval(query.Interval), column, aliasing), nil
 	}
 }
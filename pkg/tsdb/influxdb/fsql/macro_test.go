@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 	"github.com/stretchr/testify/require"
 )
@@ -55,9 +56,86 @@ func TestMacros(t *testing.T) {
 	}
 	for _, c := range cs {
 		t.Run(c.in, func(t *testing.T) {
-			sql, err := sqlutil.Interpolate(query.WithSQL(c.in), macros)
+			sql, err := sqlutil.Interpolate(query.WithSQL(c.in), newMacros(&timeGroupFill{}))
 			require.NoError(t, err)
 			require.Equal(t, c.out, sql)
 		})
 	}
 }
+
+func TestTimeGroupMacro(t *testing.T) {
+	query := sqlutil.Query{Interval: 10 * time.Second}
+
+	cs := []struct {
+		in   string
+		out  string
+		fill *timeGroupFill
+	}{
+		{
+			in:   `select $__timeGroup(time, hour)`,
+			out:  `select datepart('hour', time),datepart('day', time),datepart('month', time),datepart('year', time)`,
+			fill: &timeGroupFill{},
+		},
+		{
+			in:   `select $__timeGroup(time, 5m)`,
+			out:  `select date_bin(interval '300 second', time, timestamp '1970-01-01T00:00:00Z')`,
+			fill: &timeGroupFill{},
+		},
+		{
+			in:   `select $__timeGroupAlias(time, '1h', NULL)`,
+			out:  `select date_bin(interval '3600 second', time, timestamp '1970-01-01T00:00:00Z') as time`,
+			fill: &timeGroupFill{missing: &data.FillMissing{Mode: data.FillModeNull}, interval: time.Hour},
+		},
+		{
+			in:   `select $__timeGroupAlias(time, 1m, previous)`,
+			out:  `select date_bin(interval '60 second', time, timestamp '1970-01-01T00:00:00Z') as time`,
+			fill: &timeGroupFill{missing: &data.FillMissing{Mode: data.FillModePrevious}, interval: time.Minute},
+		},
+		{
+			in:   `select $__timeGroupAlias(time, $__interval, previous)`,
+			out:  `select date_bin(interval '10 second', time, timestamp '1970-01-01T00:00:00Z') as time`,
+			fill: &timeGroupFill{missing: &data.FillMissing{Mode: data.FillModePrevious}, interval: 10 * time.Second},
+		},
+		{
+			in:   `select $__timeGroup(time, $__interval_ms)`,
+			out:  `select date_bin(interval '10 second', time, timestamp '1970-01-01T00:00:00Z')`,
+			fill: &timeGroupFill{},
+		},
+		{
+			in:   `select $__timeGroup(time, 500ms)`,
+			out:  `select date_bin(interval '500 millisecond', time, timestamp '1970-01-01T00:00:00Z')`,
+			fill: &timeGroupFill{},
+		},
+		{
+			in:   `select $__timeGroup(time, 1m, 1.5)`,
+			out:  `select date_bin(interval '60 second', time, timestamp '1970-01-01T00:00:00Z')`,
+			fill: &timeGroupFill{missing: &data.FillMissing{Mode: data.FillModeValue, Value: 1.5}, interval: time.Minute},
+		},
+	}
+	for _, c := range cs {
+		t.Run(c.in, func(t *testing.T) {
+			fill := &timeGroupFill{}
+			sql, err := sqlutil.Interpolate(query.WithSQL(c.in), newMacros(fill))
+			require.NoError(t, err)
+			require.Equal(t, c.out, sql)
+			require.Equal(t, c.fill, fill)
+		})
+	}
+
+	t.Run("interval below a millisecond", func(t *testing.T) {
+		_, err := sqlutil.Interpolate(query.WithSQL(`select $__timeGroup(time, 500us)`), newMacros(&timeGroupFill{}))
+		require.Error(t, err)
+	})
+
+	t.Run("interval variable with a sub second query interval", func(t *testing.T) {
+		query := sqlutil.Query{Interval: 250 * time.Millisecond}
+		sql, err := sqlutil.Interpolate(query.WithSQL(`select $__timeGroup(time, $__interval)`), newMacros(&timeGroupFill{}))
+		require.NoError(t, err)
+		require.Equal(t, `select date_bin(interval '250 millisecond', time, timestamp '1970-01-01T00:00:00Z')`, sql)
+	})
+
+	t.Run("invalid fill value", func(t *testing.T) {
+		_, err := sqlutil.Interpolate(query.WithSQL(`select $__timeGroup(time, 1m, zero)`), newMacros(&timeGroupFill{}))
+		require.Error(t, err)
+	})
+}
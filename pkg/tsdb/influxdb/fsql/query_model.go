@@ -49,11 +49,17 @@ func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
 	}
 
 	// Process macros and execute the query.
-	sql, err := sqlutil.Interpolate(query, macros)
+	fill := &timeGroupFill{}
+	sql, err := sqlutil.Interpolate(query, newMacros(fill))
 	if err != nil {
 		return nil, fmt.Errorf("macro interpolation: %w", err)
 	}
 	query.RawSQL = sql
+	// the interval of the query is only replaced once the macros are interpolated, they use the original one
+	if fill.missing != nil {
+		query.FillMissing = fill.missing
+		query.Interval = fill.interval
+	}
 
 	return &queryModel{query}, nil
 }
@@ -0,0 +1,252 @@
+package fsql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/flight"
+	"github.com/apache/arrow/go/v15/arrow/flight/flightsql"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+const (
+	// ioxColumnTypeKey is the field metadata InfluxDB 3 describes the role of a column with
+	ioxColumnTypeKey = "iox::column::type"
+
+	columnKindTag   = "tag"
+	columnKindField = "field"
+	columnKindTime  = "time"
+)
+
+var errMissingTable = errors.New("missing table")
+
+// Table is a table returned by the FlightSQL GetTables call
+type Table struct {
+	Catalog string `json:"catalog,omitempty"`
+	Schema  string `json:"schema,omitempty"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+}
+
+// Column is a column of a table, its kind is either tag, field or time
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Kind string `json:"kind"`
+}
+
+// CallResource serves the schema resources of the data source from FlightSQL metadata calls:
+//
+//	/tables                          lists the tables
+//	/columns?table=<table>[&schema=] lists the tag, field and time columns of a table
+func CallResource(ctx context.Context, dsInfo *models.DatasourceInfo, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return httpadapter.New(newResourceMux(dsInfo)).CallResource(ctx, req, sender)
+}
+
+func newResourceMux(dsInfo *models.DatasourceInfo) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tables", handleSchemaResource(dsInfo, func(ctx context.Context, r *runner, req *http.Request) (any, error) {
+		return r.tables(ctx)
+	}))
+	mux.HandleFunc("/columns", handleSchemaResource(dsInfo, func(ctx context.Context, r *runner, req *http.Request) (any, error) {
+		table := req.URL.Query().Get("table")
+		if table == "" {
+			return nil, errMissingTable
+		}
+		return r.columns(ctx, req.URL.Query().Get("schema"), table)
+	}))
+	return mux
+}
+
+func handleSchemaResource(dsInfo *models.DatasourceInfo, fn func(ctx context.Context, r *runner, req *http.Request) (any, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		logger := glog.FromContext(req.Context())
+		if req.Method != http.MethodGet {
+			writeResourceError(rw, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+
+		r, err := runnerFromDataSource(dsInfo)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadRequest, err)
+			return
+		}
+		defer func() {
+			if err := r.client.Close(); err != nil {
+				logger.Warn("Failed to close fsql client", "err", err)
+			}
+		}()
+
+		ctx := req.Context()
+		if r.client.md.Len() != 0 {
+			ctx = metadata.NewOutgoingContext(ctx, r.client.md)
+		}
+
+		res, err := fn(ctx, r, req)
+		if err != nil {
+			if errors.Is(err, errMissingTable) {
+				writeResourceError(rw, http.StatusBadRequest, err)
+				return
+			}
+			logger.Error("Failed to query schema", "path", req.URL.Path, "error", err)
+			writeResourceError(rw, http.StatusInternalServerError, fmt.Errorf("flightsql: %w", err))
+			return
+		}
+
+		body, err := json.Marshal(res)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write(body)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
+
+func (r *runner) tables(ctx context.Context) ([]Table, error) {
+	tables := []Table{}
+	err := r.getTables(ctx, &flightsql.GetTablesOpts{}, func(record arrow.Record, i int) error {
+		tables = append(tables, Table{
+			Catalog: stringValue(record, "catalog_name", i),
+			Schema:  stringValue(record, "db_schema_name", i),
+			Name:    stringValue(record, "table_name", i),
+			Type:    stringValue(record, "table_type", i),
+		})
+		return nil
+	})
+	return tables, err
+}
+
+func (r *runner) columns(ctx context.Context, schema, table string) ([]Column, error) {
+	opts := &flightsql.GetTablesOpts{
+		TableNameFilterPattern: &table,
+		IncludeSchema:          true,
+	}
+	if schema != "" {
+		opts.DbSchemaFilterPattern = &schema
+	}
+
+	columns := []Column{}
+	found := false
+	err := r.getTables(ctx, opts, func(record arrow.Record, i int) error {
+		// the filter is a LIKE pattern, it can match other tables as well
+		if found || stringValue(record, "table_name", i) != table {
+			return nil
+		}
+		found = true
+
+		col, ok := columnByName(record, "table_schema").(*array.Binary)
+		if !ok {
+			return fmt.Errorf("missing table schema")
+		}
+		tableSchema, err := flight.DeserializeSchema(col.Value(i), r.client.Alloc)
+		if err != nil {
+			return err
+		}
+		for _, f := range tableSchema.Fields() {
+			columns = append(columns, newColumn(f))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("table %q not found", table)
+	}
+	return columns, nil
+}
+
+// getTables calls fn for each row of the GetTables result
+func (r *runner) getTables(ctx context.Context, opts *flightsql.GetTablesOpts, fn func(record arrow.Record, i int) error) error {
+	info, err := r.client.GetTables(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range info.Endpoint {
+		reader, err := r.client.DoGet(ctx, endpoint.Ticket)
+		if err != nil {
+			return err
+		}
+		for reader.Next() {
+			record := reader.Record()
+			for i := 0; i < int(record.NumRows()); i++ {
+				if err := fn(record, i); err != nil {
+					reader.Release()
+					return err
+				}
+			}
+		}
+		err = reader.Err()
+		reader.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newColumn(f arrow.Field) Column {
+	dataType := f.Type
+	if dict, ok := dataType.(*arrow.DictionaryType); ok {
+		dataType = dict.ValueType
+	}
+	return Column{Name: f.Name, Type: dataType.String(), Kind: columnKind(f)}
+}
+
+// columnKind returns the role of a column from the InfluxDB 3 metadata, other FlightSQL servers don't
+// describe it, dictionary encoded columns are assumed to be tags then
+func columnKind(f arrow.Field) string {
+	if value, ok := f.Metadata.GetValue(ioxColumnTypeKey); ok {
+		switch {
+		case value == "iox::column_type::tag":
+			return columnKindTag
+		case value == "iox::column_type::timestamp":
+			return columnKindTime
+		case strings.HasPrefix(value, "iox::column_type::field"):
+			return columnKindField
+		}
+	}
+	switch f.Type.ID() {
+	case arrow.DICTIONARY:
+		return columnKindTag
+	case arrow.TIMESTAMP:
+		return columnKindTime
+	}
+	return columnKindField
+}
+
+func columnByName(record arrow.Record, name string) arrow.Array {
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return nil
+	}
+	return record.Column(indices[0])
+}
+
+func stringValue(record arrow.Record, name string, i int) string {
+	col, ok := columnByName(record, name).(*array.String)
+	if !ok || col.IsNull(i) {
+		return ""
+	}
+	return col.Value(i)
+}
@@ -0,0 +1,198 @@
+package fsql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// defaultStreamTimeRange is the time range of stream queries sent without one
+const defaultStreamTimeRange = time.Hour
+
+// streamRequest is the query of a stream with the time range of its macros in epoch milliseconds
+type streamRequest struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// parseStreamQuery parses the query of a stream, the query model of a stream is the query model of
+// QueryData with the time range of the query
+func parseStreamQuery(raw json.RawMessage, now time.Time) (*queryModel, error) {
+	var req streamRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %w", err)
+	}
+
+	timeRange := backend.TimeRange{From: now.Add(-defaultStreamTimeRange), To: now}
+	if req.From > 0 && req.To > 0 {
+		timeRange = backend.TimeRange{From: time.UnixMilli(req.From), To: time.UnixMilli(req.To)}
+	}
+
+	qm, err := getQueryModel(backend.DataQuery{JSON: raw, TimeRange: timeRange})
+	if err != nil {
+		return nil, err
+	}
+	if qm.RawSQL == "" {
+		return nil, errors.New("missing rawSql in stream query")
+	}
+	return qm, nil
+}
+
+// CheckStreamQuery validates the query of a stream before it is subscribed to
+func CheckStreamQuery(raw json.RawMessage) error {
+	_, err := parseStreamQuery(raw, time.Now())
+	return err
+}
+
+// RunStream sends the result of the stream query to the subscribers. The query is executed once, its result is
+// kept until the stream is canceled and sent to the subscribers joining later with StreamInitialData.
+func RunStream(ctx context.Context, dsInfo *models.DatasourceInfo, path string, raw json.RawMessage, sender *backend.StreamSender) error {
+	qm, err := parseStreamQuery(raw, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// the batches of a result share their schema, it is only sent with the first frame
+	include := data.IncludeAll
+	var result *data.Frame
+	err = streamQuery(ctx, dsInfo, qm, func(frame *data.Frame) error {
+		if err := sender.SendFrame(frame, include); err != nil {
+			return err
+		}
+		include = data.IncludeDataOnly
+		if result == nil {
+			result = frame
+			return nil
+		}
+		appendFrame(result, frame)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if result != nil {
+		cache, err := data.FrameToJSONCache(result)
+		if err != nil {
+			return err
+		}
+		dsInfo.StreamsMu.Lock()
+		if dsInfo.Streams == nil {
+			dsInfo.Streams = make(map[string]data.FrameJSONCache)
+		}
+		dsInfo.Streams[path] = cache
+		dsInfo.StreamsMu.Unlock()
+		defer func() {
+			dsInfo.StreamsMu.Lock()
+			delete(dsInfo.Streams, path)
+			dsInfo.StreamsMu.Unlock()
+		}()
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// StreamInitialData returns the result of the running stream of the channel path, nil if there is no result yet
+func StreamInitialData(dsInfo *models.DatasourceInfo, path string) (*backend.InitialData, error) {
+	dsInfo.StreamsMu.RLock()
+	defer dsInfo.StreamsMu.RUnlock()
+
+	cache, ok := dsInfo.Streams[path]
+	if !ok {
+		return nil, nil
+	}
+	return backend.NewInitialData(cache.Bytes(data.IncludeAll))
+}
+
+// appendFrame appends the rows and notices of a frame to a frame with the same fields
+func appendFrame(dst *data.Frame, src *data.Frame) {
+	for i, field := range src.Fields {
+		for row := 0; row < field.Len(); row++ {
+			dst.Fields[i].Append(field.At(row))
+		}
+	}
+	if src.Meta != nil {
+		dst.AppendNotices(src.Meta.Notices...)
+	}
+}
+
+// streamQuery executes the query and sends the result as one frame per Arrow record batch, so that large results
+// don't have to be held in memory at once. The frames have the columns of the query result as they are, the
+// time series format and the fill mode only apply to QueryData.
+func streamQuery(ctx context.Context, dsInfo *models.DatasourceInfo, qm *queryModel, send func(frame *data.Frame) error) error {
+	logger := glog.FromContext(ctx)
+	r, err := runnerFromDataSource(dsInfo)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.client.Close(); err != nil {
+			logger.Warn("Failed to close fsql client", "err", err)
+		}
+	}()
+
+	if r.client.md.Len() != 0 {
+		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
+	}
+
+	logger.Info(fmt.Sprintf("InfluxDB streaming SQL: %s", qm.RawSQL))
+	info, err := r.client.Execute(ctx, qm.RawSQL)
+	if err != nil {
+		return fmt.Errorf("flightsql: %s", err)
+	}
+
+	var rows int64
+	for _, endpoint := range info.Endpoint {
+		reader, err := r.client.DoGet(ctx, endpoint.Ticket)
+		if err != nil {
+			return fmt.Errorf("flightsql: %s", err)
+		}
+		rows, err = sendRecordFrames(reader, qm.RawSQL, rows, send)
+		reader.Release()
+		if err != nil || rows >= rowLimit {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendRecordFrames sends a frame for each record of the reader, only the current record and its frame are held
+// in memory. It returns the number of rows sent including the given ones and stops at the row limit.
+func sendRecordFrames(reader recordReader, query string, rows int64, send func(frame *data.Frame) error) (int64, error) {
+	for reader.Next() {
+		record := reader.Record()
+		frame := newFrame(reader.Schema())
+		frame.Meta.ExecutedQueryString = query
+
+		limit := record.NumRows()
+		if rows+limit > rowLimit {
+			limit = rowLimit - rows
+			frame.AppendNotices(rowLimitNotice())
+		}
+		if err := copyRecord(frame.Fields, record, limit); err != nil {
+			return rows, err
+		}
+
+		rows += limit
+		if err := send(frame); err != nil {
+			return rows, err
+		}
+		if rows >= rowLimit {
+			return rows, nil
+		}
+	}
+	if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return rows, err
+	}
+	return rows, nil
+}
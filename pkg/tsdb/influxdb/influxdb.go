@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -85,6 +86,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			InsecureGrpc:  jsonData.InsecureGrpc,
 			Token:         settings.DecryptedSecureJSONData["token"],
 			Timeout:       opts.Timeouts.Timeout,
+			Streams:       make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
 	}
@@ -115,6 +117,21 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 }
 
+// CallResource serves the schema resources of InfluxDB 3 data sources using SQL
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	if dsInfo.Version != influxVersionSQL {
+		return sender.Send(&backend.CallResourceResponse{
+			Status: http.StatusNotFound,
+			Body:   []byte(`{"error":"resources are only supported by the SQL query language"}`),
+		})
+	}
+	return fsql.CallResource(ctx, dsInfo, req, sender)
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*models.DatasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
@@ -2,7 +2,10 @@ package models
 
 import (
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 type DatasourceInfo struct {
@@ -22,4 +25,8 @@ type DatasourceInfo struct {
 
 	// FlightSQL grpc connection
 	InsecureGrpc bool `json:"insecureGrpc"`
+
+	// result of the open FlightSQL streams by channel path, sent to the subscribers joining a stream
+	Streams   map[string]data.FrameJSONCache
+	StreamsMu sync.RWMutex
 }
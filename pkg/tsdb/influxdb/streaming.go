@@ -0,0 +1,62 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
+)
+
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	// Expect sql/${key}
+	if !strings.HasPrefix(req.Path, "sql/") {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("expected sql in channel path")
+	}
+	if dsInfo.Version != influxVersionSQL {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("streaming is only supported by the SQL query language")
+	}
+	if err := fsql.CheckStreamQuery(req.Data); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	// subscribers joining a running stream get its result, the query is only executed once per stream
+	initialData, err := fsql.StreamInitialData(dsInfo, req.Path)
+	return &backend.SubscribeStreamResponse{
+		Status:      backend.SubscribeStreamStatusOK,
+		InitialData: initialData,
+	}, err
+}
+
+// Single instance for each channel (results are shared with all listeners)
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	if dsInfo.Version != influxVersionSQL {
+		return fmt.Errorf("streaming is only supported by the SQL query language")
+	}
+	return fsql.RunStream(ctx, dsInfo, req.Path, req.Data, sender)
+}
+
+func (s *Service) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
@@ -238,6 +238,94 @@ func (api *LokiAPI) DataQuery(ctx context.Context, query lokiQuery, responseOpts
 	return &res, nil
 }
 
+func makePatternsRequest(ctx context.Context, lokiDsUrl string, query lokiQuery) (*http.Request, error) {
+	qs := url.Values{}
+	qs.Set("query", query.Expr)
+	qs.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+	qs.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+	// the step is sent at millisecond precision, same as for range queries
+	qs.Set("step", fmt.Sprintf("%dms", query.Step.Milliseconds()))
+
+	lokiUrl, err := url.Parse(lokiDsUrl)
+	if err != nil {
+		return nil, err
+	}
+	lokiUrl.Path = path.Join(lokiUrl.Path, "/loki/api/v1/patterns")
+	lokiUrl.RawQuery = qs.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", lokiUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	setXScopeOrgIDHeader(req, ctx)
+
+	return req, nil
+}
+
+// PatternsQuery returns a frame with the count of the log lines over time for each pattern
+// detected by Loki in the streams of the query
+func (api *LokiAPI) PatternsQuery(ctx context.Context, query lokiQuery) (*backend.DataResponse, error) {
+	req, err := makePatternsRequest(ctx, api.url, query)
+	if err != nil {
+		return nil, err
+	}
+
+	queryAttrs := []any{"start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr, "queryType", query.QueryType, "lokiHost", req.URL.Host, "lokiPath", req.URL.Path}
+	api.log.Debug("Sending patterns query to loki", queryAttrs...)
+	start := time.Now()
+	resp, err := api.client.Do(req)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		lp := []any{"error", err, "status", status, "duration", time.Since(start), "stage", stageDatabaseRequest}
+		lp = append(lp, queryAttrs...)
+		api.log.Error("Error received from Loki", lp...)
+		res := backend.DataResponse{
+			Error: err,
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			res.ErrorSource = backend.ErrorSourceDownstream
+		}
+		return &res, nil
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			api.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	lp := []any{"duration", time.Since(start), "stage", stageDatabaseRequest, "statusCode", resp.StatusCode, "contentLength", resp.Header.Get("Content-Length")}
+	lp = append(lp, queryAttrs...)
+	if resp.StatusCode/100 != 2 {
+		err := readLokiError(resp.Body)
+		res := backend.DataResponse{
+			Error:       err,
+			ErrorSource: backend.ErrorSourceFromHTTPStatus(resp.StatusCode),
+		}
+		lp = append(lp, "status", "error", "error", err, "statusSource", res.ErrorSource)
+		api.log.Error("Error received from Loki", lp...)
+		return &res, nil
+	}
+	lp = append(lp, "status", "ok")
+	api.log.Info("Response received from loki", lp...)
+
+	start = time.Now()
+	var patterns lokiPatternsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&patterns); err != nil {
+		instrumentation.UpdatePluginParsingResponseDurationSeconds(ctx, time.Since(start), "error")
+		api.log.Error("Error parsing response from loki", "error", err, "duration", time.Since(start), "stage", stageParseResponse)
+		return nil, fmt.Errorf("failed to parse the patterns response: %w", err)
+	}
+	instrumentation.UpdatePluginParsingResponseDurationSeconds(ctx, time.Since(start), "ok")
+	api.log.Info("Response parsed from loki", "duration", time.Since(start), "patternsLength", len(patterns.Data), "stage", stageParseResponse)
+
+	return &backend.DataResponse{Frames: patternsToFrames(patterns, query)}, nil
+}
+
 func makeRawRequest(ctx context.Context, lokiDsUrl string, resourcePath string) (*http.Request, error) {
 	lokiUrl, err := url.Parse(lokiDsUrl)
 	if err != nil {
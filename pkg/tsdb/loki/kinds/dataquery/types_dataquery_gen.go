@@ -17,9 +17,10 @@ const (
 
 // Defines values for LokiQueryType.
 const (
-	LokiQueryTypeInstant LokiQueryType = "instant"
-	LokiQueryTypeRange   LokiQueryType = "range"
-	LokiQueryTypeStream  LokiQueryType = "stream"
+	LokiQueryTypeInstant  LokiQueryType = "instant"
+	LokiQueryTypePatterns LokiQueryType = "patterns"
+	LokiQueryTypeRange    LokiQueryType = "range"
+	LokiQueryTypeStream   LokiQueryType = "stream"
 )
 
 // Defines values for QueryEditorMode.
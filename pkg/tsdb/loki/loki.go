@@ -72,6 +72,7 @@ type QueryJSONModel struct {
 	dataquery.LokiDataQuery
 	Direction           *string `json:"direction,omitempty"`
 	SupportingQueryType *string `json:"supportingQueryType"`
+	SplitDuration       *string `json:"splitDuration,omitempty"`
 }
 
 type ResponseOpts struct {
@@ -168,7 +169,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		s.applyHeaders(ctx, req)
 	}
 
-	return queryData(ctx, req, dsInfo, responseOpts, s.tracer, logger, isFeatureEnabled(ctx, featuremgmt.FlagLokiRunQueriesInParallel), isFeatureEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata))
+	return queryData(ctx, req, dsInfo, responseOpts, s.tracer, logger, isFeatureEnabled(ctx, featuremgmt.FlagLokiRunQueriesInParallel), isFeatureEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata), isFeatureEnabled(ctx, featuremgmt.FlagLokiBackendQuerySplitting))
 }
 
 func (s *Service) applyHeaders(ctx context.Context, req backend.ForwardHTTPHeaders) {
@@ -188,7 +189,7 @@ func (s *Service) applyHeaders(ctx context.Context, req backend.ForwardHTTPHeade
 	}
 }
 
-func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datasourceInfo, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger, runInParallel bool, requestStructuredMetadata bool) (*backend.QueryDataResponse, error) {
+func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datasourceInfo, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger, runInParallel bool, requestStructuredMetadata bool, splitQueries bool) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
 
 	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, plog, tracer, requestStructuredMetadata)
@@ -200,7 +201,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		return result, err
 	}
 
-	plog.Info("Prepared request to Loki", "duration", time.Since(start), "queriesLength", len(queries), "stage", stagePrepareRequest, "runInParallel", runInParallel)
+	plog.Info("Prepared request to Loki", "duration", time.Since(start), "queriesLength", len(queries), "stage", stagePrepareRequest, "runInParallel", runInParallel, "splitQueries", splitQueries)
 
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
@@ -217,7 +218,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, splitQueries, api, responseOpts, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -226,7 +227,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, splitQueries, api, responseOpts, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -234,7 +235,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, splitQueries bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -247,7 +248,13 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	queryRes, err := runQuery(ctx, api, query, responseOpts, plog)
+	var queryRes *backend.DataResponse
+	var err error
+	if splitQueries {
+		queryRes, err = runSplitQuery(ctx, api, query, responseOpts, plog)
+	} else {
+		queryRes, err = runQuery(ctx, api, query, responseOpts, plog)
+	}
 	if queryRes == nil {
 		// we always want to return a backend.DataResponse object, even if we received just an error
 		queryRes = &backend.DataResponse{}
@@ -264,6 +271,14 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 // we extracted this part of the functionality to make it easy to unit-test it
 func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	if query.QueryType == QueryTypePatterns {
+		res, err := api.PatternsQuery(ctx, *query)
+		if err != nil {
+			plog.Error("Error querying loki patterns", "error", err)
+		}
+		return res, err
+	}
+
 	res, err := api.DataQuery(ctx, *query, responseOpts)
 	if err != nil {
 		plog.Error("Error querying loki", "error", err)
@@ -50,7 +50,7 @@ func interpolateVariables(expr string, interval time.Duration, timeRange time.Du
 		expr = strings.ReplaceAll(expr, varAuto, rangeSText+"s")
 	}
 
-	if queryType == dataquery.LokiQueryTypeRange {
+	if queryType == dataquery.LokiQueryTypeRange || queryType == dataquery.LokiQueryTypePatterns {
 		expr = strings.ReplaceAll(expr, varAuto, stepText)
 	}
 
@@ -76,6 +76,8 @@ func parseQueryType(jsonPointerValue *string) (QueryType, error) {
 			return QueryTypeInstant, nil
 		case "range":
 			return QueryTypeRange, nil
+		case "patterns":
+			return QueryTypePatterns, nil
 		default:
 			return QueryTypeRange, fmt.Errorf("invalid queryType: %s", jsonValue)
 		}
@@ -171,6 +173,11 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 
 		supportingQueryType := parseSupportingQueryType(model.SupportingQueryType)
 
+		splitDuration, err := parseSplitDuration(model.SplitDuration, queryType, depointerizer(model.Expr))
+		if err != nil {
+			return nil, err
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:                expr,
 			QueryType:           queryType,
@@ -182,6 +189,7 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			End:                 end,
 			RefID:               query.RefID,
 			SupportingQueryType: supportingQueryType,
+			SplitDuration:       splitDuration,
 		})
 	}
 
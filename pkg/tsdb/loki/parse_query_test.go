@@ -114,6 +114,32 @@ func TestParseQuery(t *testing.T) {
 		require.Equal(t, SupportingQueryNone, models[0].SupportingQueryType)
 	})
 
+	t.Run("parsing query model with patterns query type", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`
+					{
+						"expr": "{service_name=\"checkout\"}",
+						"queryType": "patterns",
+						"refId": "A"
+					}`,
+					),
+					TimeRange: backend.TimeRange{
+						From: time.Now().Add(-3000 * time.Second),
+						To:   time.Now(),
+					},
+					Interval:      time.Second * 15,
+					MaxDataPoints: 200,
+				},
+			},
+		}
+		models, err := parseQuery(queryContext)
+		require.NoError(t, err)
+		require.Equal(t, QueryTypePatterns, models[0].QueryType)
+		require.Equal(t, time.Duration(0), models[0].SplitDuration)
+	})
+
 	t.Run("interpolate variables, range between 1s and 0.5s", func(t *testing.T) {
 		expr := "go_goroutines $__interval $__interval_ms $__range $__range_s $__range_ms"
 		queryType := dataquery.LokiQueryTypeRange
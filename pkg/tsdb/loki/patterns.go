@@ -0,0 +1,53 @@
+package loki
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// lokiPatternsResponse is the response of the /loki/api/v1/patterns endpoint, for example:
+//
+//	{"status": "success", "data": [{"pattern": "<_> level=info <_>", "samples": [[1711839260, 1], [1711839270, 3]]}]}
+type lokiPatternsResponse struct {
+	Status string `json:"status"`
+	Data   []struct {
+		Pattern string `json:"pattern"`
+		// Level is only returned by the Loki versions detecting the level of the log lines
+		Level string `json:"level,omitempty"`
+		// Samples are pairs of a unix timestamp in seconds and the count of log lines matching the pattern
+		Samples [][2]float64 `json:"samples"`
+	} `json:"data"`
+}
+
+// patternsToFrames returns a time series frame for each pattern, labeled with the pattern,
+// so the rate of the patterns can be graphed and alerted on
+func patternsToFrames(response lokiPatternsResponse, query lokiQuery) data.Frames {
+	frames := make(data.Frames, 0, len(response.Data))
+	for _, pattern := range response.Data {
+		times := make([]time.Time, 0, len(pattern.Samples))
+		counts := make([]float64, 0, len(pattern.Samples))
+		for _, sample := range pattern.Samples {
+			times = append(times, time.Unix(0, int64(sample[0]*float64(time.Second))).UTC())
+			counts = append(counts, sample[1])
+		}
+
+		labels := data.Labels{"pattern": pattern.Pattern}
+		if pattern.Level != "" {
+			labels["level"] = pattern.Level
+		}
+
+		timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, times)
+		timeField.Config = &data.FieldConfig{Interval: float64(query.Step.Milliseconds())}
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, counts)
+		valueField.Config = &data.FieldConfig{DisplayNameFromDS: pattern.Pattern}
+
+		frame := data.NewFrame("", timeField, valueField)
+		frame.Meta = &data.FrameMeta{
+			Type:                data.FrameTypeTimeSeriesMulti,
+			ExecutedQueryString: "Expr: " + query.Expr + "\n" + "Step: " + query.Step.String(),
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
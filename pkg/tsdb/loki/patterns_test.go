@@ -0,0 +1,78 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternsQuery(t *testing.T) {
+	query := lokiQuery{
+		Expr:      `{service_name="checkout"}`,
+		QueryType: QueryTypePatterns,
+		Step:      10 * time.Second,
+		Start:     time.Unix(1711839200, 0),
+		End:       time.Unix(1711839300, 0),
+		RefID:     "A",
+	}
+
+	t.Run("patterns queries should call the patterns endpoint", func(t *testing.T) {
+		called := false
+		api := makeMockedAPI(200, "application/json", []byte(`{"status":"success","data":[]}`), func(req *http.Request) {
+			called = true
+			require.Equal(t, "/loki/api/v1/patterns", req.URL.Path)
+			require.Equal(t, `{service_name="checkout"}`, req.URL.Query().Get("query"))
+			require.Equal(t, "1711839200000000000", req.URL.Query().Get("start"))
+			require.Equal(t, "1711839300000000000", req.URL.Query().Get("end"))
+			require.Equal(t, "10000ms", req.URL.Query().Get("step"))
+		}, false)
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, log.NewNullLogger())
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.True(t, called)
+		require.Empty(t, res.Frames)
+	})
+
+	t.Run("patterns should be returned as time series of the count of log lines", func(t *testing.T) {
+		response := []byte(`{
+			"status": "success",
+			"data": [
+				{"pattern": "<_> level=info msg=\"order placed\" <_>", "samples": [[1711839260, 1], [1711839270, 3]]},
+				{"pattern": "<_> level=error msg=<_>", "level": "error", "samples": [[1711839270, 2]]}
+			]
+		}`)
+		api := makeMockedAPI(200, "application/json", response, nil, false)
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, log.NewNullLogger())
+		require.NoError(t, err)
+		require.Len(t, res.Frames, 2)
+
+		frame := res.Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		require.Equal(t, "Expr: {service_name=\"checkout\"}\nStep: 10s", frame.Meta.ExecutedQueryString)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.Unix(1711839270, 0).UTC(), frame.Fields[0].At(1))
+		require.Equal(t, float64(10000), frame.Fields[0].Config.Interval)
+		require.Equal(t, 3.0, frame.Fields[1].At(1))
+		require.Equal(t, data.Labels{"pattern": `<_> level=info msg="order placed" <_>`}, frame.Fields[1].Labels)
+		require.Equal(t, `<_> level=info msg="order placed" <_>`, frame.Fields[1].Config.DisplayNameFromDS)
+
+		require.Equal(t, data.Labels{"pattern": "<_> level=error msg=<_>", "level": "error"}, res.Frames[1].Fields[1].Labels)
+	})
+
+	t.Run("patterns query errors should be returned in the response", func(t *testing.T) {
+		api := makeMockedAPI(400, "application/json", []byte(`{"message":"pattern ingester is not enabled"}`), nil, false)
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, log.NewNullLogger())
+		require.NoError(t, err)
+		require.EqualError(t, res.Error, "pattern ingester is not enabled")
+		require.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+}
@@ -0,0 +1,346 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	defaultSplitDuration = 24 * time.Hour
+	// maxConcurrentChunks is the number of time chunks of a split query that are queried at the same time
+	maxConcurrentChunks = 4
+
+	totalBytesStat = "Summary: total bytes processed"
+)
+
+// queries with a $__range variable must not be split, it would be interpolated with the range of the chunk
+var rangeVariableRegex = regexp.MustCompile(`\[\s*\$\{?__range(_s|_ms)?\}?\s*\]`)
+
+// parseSplitDuration returns the duration of the time chunks of a query, this is the same logic the frontend
+// uses for its query splitting
+func parseSplitDuration(jsonPointerValue *string, queryType QueryType, expr string) (time.Duration, error) {
+	if queryType != QueryTypeRange || rangeVariableRegex.MatchString(expr) {
+		return 0, nil
+	}
+	if jsonPointerValue == nil || *jsonPointerValue == "" {
+		return defaultSplitDuration, nil
+	}
+	duration, err := gtime.ParseDuration(*jsonPointerValue)
+	if err != nil {
+		return 0, fmt.Errorf("invalid splitDuration: %w", err)
+	}
+	return duration, nil
+}
+
+// isLogsQuery reports whether the expression is a log query. Log queries start with a stream selector,
+// metric queries with a function, an aggregation or an operator.
+func isLogsQuery(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), "{")
+}
+
+// splitLogsTimeRange splits the range into chunks of the duration. The start of a logs query is inclusive and
+// its end exclusive, so the chunks can share their boundaries. The smaller chunk is the oldest one.
+func splitLogsTimeRange(start, end time.Time, duration time.Duration) [][2]time.Time {
+	if end.Sub(start) <= duration {
+		return [][2]time.Time{{start, end}}
+	}
+
+	chunks := [][2]time.Time{}
+	for chunkEnd := end; chunkEnd.After(start); chunkEnd = chunkEnd.Add(-duration) {
+		chunkStart := chunkEnd.Add(-duration)
+		if chunkStart.Before(start) {
+			chunkStart = start
+		}
+		chunks = append(chunks, [2]time.Time{chunkStart, chunkEnd})
+	}
+
+	// oldest chunk first
+	for i, j := 0, len(chunks)-1; i < j; i, j = i+1, j-1 {
+		chunks[i], chunks[j] = chunks[j], chunks[i]
+	}
+	return chunks
+}
+
+// splitMetricTimeRange splits the range into chunks of the duration aligned to the step, both ends of a metric
+// query are inclusive, so a chunk ends one step before the start of the next one. This is compatible with
+// https://github.com/grafana/loki/blob/089ec1b05f5ec15a8851d0e8230153e0eeb4dcec/pkg/querier/queryrange/split_by_interval.go#L327-L336
+func splitMetricTimeRange(start, end time.Time, step, duration time.Duration) [][2]time.Time {
+	stepMs := step.Milliseconds()
+	if stepMs <= 0 || duration < step {
+		// we cannot create chunks smaller than the step
+		return [][2]time.Time{{start, end}}
+	}
+
+	alignedDurationMs := duration.Milliseconds() / stepMs * stepMs
+	startMs := start.UnixMilli()
+	endMs := end.UnixMilli()
+	alignedStartMs := startMs - startMs%stepMs
+
+	// unlike in the frontend the end is included, a last sample at the end is needed by alert rules
+	chunks := [][2]time.Time{}
+	for chunkStartMs := alignedStartMs; chunkStartMs <= endMs; chunkStartMs += alignedDurationMs {
+		chunkEndMs := min(chunkStartMs+alignedDurationMs-stepMs, endMs)
+		chunks = append(chunks, [2]time.Time{time.UnixMilli(chunkStartMs), time.UnixMilli(chunkEndMs)})
+	}
+	return chunks
+}
+
+// splitQuery returns the queries of the time chunks of the query, in the order they have to be queried in
+func splitQuery(query *lokiQuery) []*lokiQuery {
+	if query.SplitDuration <= 0 || query.End.Sub(query.Start) <= query.SplitDuration {
+		return []*lokiQuery{query}
+	}
+
+	logs := isLogsQuery(query.Expr)
+	var chunks [][2]time.Time
+	if logs {
+		chunks = splitLogsTimeRange(query.Start, query.End, query.SplitDuration)
+	} else {
+		chunks = splitMetricTimeRange(query.Start, query.End, query.Step, query.SplitDuration)
+	}
+
+	queries := make([]*lokiQuery, 0, len(chunks))
+	for _, chunk := range chunks {
+		q := *query
+		q.Start = chunk[0]
+		q.End = chunk[1]
+		queries = append(queries, &q)
+	}
+
+	// the line limit is reached with the chunks in the direction of the query first
+	if logs && query.Direction == DirectionBackward {
+		for i, j := 0, len(queries)-1; i < j; i, j = i+1, j-1 {
+			queries[i], queries[j] = queries[j], queries[i]
+		}
+	}
+	return queries
+}
+
+// runSplitQuery splits range queries into time chunks, queries the chunks concurrently and merges their frames.
+// The chunks of log queries with a line limit are queried in batches in the direction of the query, no further
+// batches are queried once the limit is reached.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	queries := splitQuery(query)
+	if len(queries) == 1 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	logs := isLogsQuery(query.Expr)
+	plog.Debug("Splitting query", "refId", query.RefID, "chunks", len(queries), "splitDuration", query.SplitDuration)
+
+	batchSize := len(queries)
+	if logs && query.MaxLines > 0 {
+		batchSize = maxConcurrentChunks
+	}
+
+	responses := make([]*backend.DataResponse, len(queries))
+	var failed *backend.DataResponse
+	failedMu := sync.Mutex{}
+	for from := 0; from < len(queries); from += batchSize {
+		to := min(from+batchSize, len(queries))
+		err := concurrency.ForEachJob(ctx, to-from, maxConcurrentChunks, func(ctx context.Context, idx int) error {
+			res, err := runQuery(ctx, api, queries[from+idx], responseOpts, plog)
+			if err == nil && res != nil && res.Error != nil {
+				failedMu.Lock()
+				if failed == nil {
+					failed = res
+				}
+				failedMu.Unlock()
+				err = res.Error
+			}
+			if err != nil {
+				return err
+			}
+			responses[from+idx] = res
+			return nil
+		})
+		if err != nil {
+			if failed != nil {
+				return failed, nil
+			}
+			return nil, err
+		}
+
+		if logs && query.MaxLines > 0 && countRows(responses[:to]) >= query.MaxLines {
+			break
+		}
+	}
+
+	return mergeChunkResponses(responses, query, logs), nil
+}
+
+func countRows(responses []*backend.DataResponse) int {
+	rows := 0
+	for _, res := range responses {
+		if res == nil {
+			continue
+		}
+		for _, frame := range res.Frames {
+			rows += frame.Rows()
+		}
+	}
+	return rows
+}
+
+// mergeChunkResponses merges the frames of the same series of the chunks. The rows of the merged frames are
+// ordered by time and deduplicated, log lines by their id and samples by their timestamp. Log lines beyond
+// the line limit of the query are dropped.
+func mergeChunkResponses(responses []*backend.DataResponse, query *lokiQuery, logs bool) *backend.DataResponse {
+	merged := &backend.DataResponse{}
+	byKey := map[string]*data.Frame{}
+	for _, res := range responses {
+		if res == nil {
+			continue
+		}
+		for _, frame := range res.Frames {
+			key := frameKey(frame)
+			dest, ok := byKey[key]
+			if !ok {
+				byKey[key] = frame
+				merged.Frames = append(merged.Frames, frame)
+				continue
+			}
+			appendFrame(dest, frame)
+		}
+	}
+
+	for i, frame := range merged.Frames {
+		descending := logs && query.Direction == DirectionBackward
+		dedupField := frameTimeField(frame)
+		if logs {
+			dedupField, _ = frame.FieldByName("id")
+		}
+		sorted := sortFrame(frame, descending, dedupField)
+		if logs && query.MaxLines > 0 && sorted.Rows() > query.MaxLines {
+			sorted = truncateFrame(sorted, query.MaxLines)
+		}
+		merged.Frames[i] = sorted
+	}
+	return merged
+}
+
+// frameKey identifies the frames of the same series in the responses of the chunks
+func frameKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("|" + field.Name + ":" + field.Type().String() + ":" + field.Labels.String())
+	}
+	return sb.String()
+}
+
+// appendFrame appends the rows of the source frame to the frame, the frames have the same fields
+func appendFrame(dest, src *data.Frame) {
+	for i, field := range dest.Fields {
+		for row := 0; row < src.Fields[i].Len(); row++ {
+			field.Append(src.Fields[i].CopyAt(row))
+		}
+	}
+	if dest.Meta != nil {
+		var srcStats []data.QueryStat
+		if src.Meta != nil {
+			srcStats = src.Meta.Stats
+		}
+		dest.Meta.Stats = combineStats(dest.Meta.Stats, srcStats)
+	}
+}
+
+// combineStats sums the total bytes processed by the chunks, the other stats of a chunk don't apply to the
+// merged frame. This is the same as in the frontend.
+func combineStats(dest, src []data.QueryStat) []data.QueryStat {
+	var combined *data.QueryStat
+	for _, stats := range [][]data.QueryStat{dest, src} {
+		for _, stat := range stats {
+			if stat.DisplayName != totalBytesStat {
+				continue
+			}
+			if combined == nil {
+				s := stat
+				combined = &s
+			} else {
+				combined.Value += stat.Value
+			}
+		}
+	}
+	if combined == nil {
+		return nil
+	}
+	return []data.QueryStat{*combined}
+}
+
+func frameTimeField(frame *data.Frame) *data.Field {
+	for _, field := range frame.Fields {
+		if field.Type() == data.FieldTypeTime {
+			return field
+		}
+	}
+	return nil
+}
+
+// sortFrame returns a copy of the frame ordered by time, rows with a value of the dedup field that was already
+// seen are dropped
+func sortFrame(frame *data.Frame, descending bool, dedupField *data.Field) *data.Frame {
+	timeField := frameTimeField(frame)
+	if timeField == nil {
+		return frame
+	}
+
+	rows := make([]int, frame.Rows())
+	for i := range rows {
+		rows[i] = i
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti := timeField.At(rows[i]).(time.Time)
+		tj := timeField.At(rows[j]).(time.Time)
+		if descending {
+			return ti.After(tj)
+		}
+		return ti.Before(tj)
+	})
+
+	out := emptyCopy(frame)
+	seen := map[any]bool{}
+	for _, row := range rows {
+		if dedupField != nil {
+			key := dedupField.At(row)
+			if t, ok := key.(time.Time); ok {
+				key = t.UnixNano()
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		out.AppendRow(frame.RowCopy(row)...)
+	}
+	return out
+}
+
+func truncateFrame(frame *data.Frame, rows int) *data.Frame {
+	out := emptyCopy(frame)
+	for row := 0; row < rows; row++ {
+		out.AppendRow(frame.RowCopy(row)...)
+	}
+	return out
+}
+
+// emptyCopy returns a copy of the frame without rows, with the metadata and the field configs of the frame
+func emptyCopy(frame *data.Frame) *data.Frame {
+	out := frame.EmptyCopy()
+	out.Meta = frame.Meta
+	for i, field := range out.Fields {
+		field.Config = frame.Fields[i].Config
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// makeChunkedAPI returns an API answering each request with the body returned for its start and end
+func makeChunkedAPI(t *testing.T, body func(start, end int64) string) (*LokiAPI, func() int) {
+	t.Helper()
+	requests := 0
+	mu := sync.Mutex{}
+	client := http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+
+			start, err := strconv.ParseInt(req.URL.Query().Get("start"), 10, 64)
+			require.NoError(t, err)
+			end, err := strconv.ParseInt(req.URL.Query().Get("end"), 10, 64)
+			require.NoError(t, err)
+
+			header := http.Header{}
+			header.Add("Content-Type", "application/json")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewReader([]byte(body(start, end)))),
+			}, nil
+		}),
+	}
+	api := newLokiAPI(&client, "http://localhost:9999", backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest(), false)
+	return api, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestParseSplitDuration(t *testing.T) {
+	duration, err := parseSplitDuration(nil, QueryTypeRange, `{app="a"}`)
+	require.NoError(t, err)
+	require.Equal(t, defaultSplitDuration, duration)
+
+	splitDuration := "6h"
+	duration, err = parseSplitDuration(&splitDuration, QueryTypeRange, `{app="a"}`)
+	require.NoError(t, err)
+	require.Equal(t, 6*time.Hour, duration)
+
+	duration, err = parseSplitDuration(nil, QueryTypeInstant, `count_over_time({app="a"}[5m])`)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), duration)
+
+	duration, err = parseSplitDuration(nil, QueryTypeRange, `count_over_time({app="a"}[$__range])`)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), duration)
+
+	invalid := "invalid"
+	_, err = parseSplitDuration(&invalid, QueryTypeRange, `{app="a"}`)
+	require.Error(t, err)
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	t.Run("logs time range is split from the end", func(t *testing.T) {
+		start := time.UnixMilli(10)
+		end := time.UnixMilli(150)
+		chunks := splitLogsTimeRange(start, end, 60*time.Millisecond)
+		require.Equal(t, [][2]time.Time{
+			{time.UnixMilli(10), time.UnixMilli(30)},
+			{time.UnixMilli(30), time.UnixMilli(90)},
+			{time.UnixMilli(90), time.UnixMilli(150)},
+		}, chunks)
+	})
+
+	t.Run("metric time range is aligned to the step", func(t *testing.T) {
+		start := time.UnixMilli(12)
+		end := time.UnixMilli(100)
+		chunks := splitMetricTimeRange(start, end, 10*time.Millisecond, 35*time.Millisecond)
+		require.Equal(t, [][2]time.Time{
+			{time.UnixMilli(10), time.UnixMilli(30)},
+			{time.UnixMilli(40), time.UnixMilli(60)},
+			{time.UnixMilli(70), time.UnixMilli(90)},
+			{time.UnixMilli(100), time.UnixMilli(100)},
+		}, chunks)
+	})
+
+	t.Run("metric time range is not split into chunks smaller than the step", func(t *testing.T) {
+		start := time.UnixMilli(0)
+		end := time.UnixMilli(100)
+		chunks := splitMetricTimeRange(start, end, 50*time.Millisecond, 20*time.Millisecond)
+		require.Equal(t, [][2]time.Time{{start, end}}, chunks)
+	})
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	logger := backend.NewLoggerWith("logger", "test")
+	day := 24 * time.Hour
+	end := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("metric chunks are merged into one frame per series", func(t *testing.T) {
+		step := time.Hour
+		api, requests := makeChunkedAPI(t, func(start, end int64) string {
+			// the sample after the end of the chunk is also returned by the next chunk
+			startS := start / int64(time.Second)
+			endS := end / int64(time.Second)
+			afterS := endS + int64(step/time.Second)
+			return fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"app":"a"},"values":[[%d,"1"],[%d,"2"],[%d,"3"]]}
+			]}}`, startS, endS, afterS)
+		})
+
+		query := &lokiQuery{
+			Expr:          `count_over_time({app="a"}[1h])`,
+			QueryType:     QueryTypeRange,
+			Direction:     DirectionBackward,
+			Step:          step,
+			Start:         end.Add(-3 * day),
+			End:           end,
+			RefID:         "A",
+			SplitDuration: day,
+		}
+		res, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Equal(t, 4, requests())
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		timeField := frame.Fields[0]
+		for i := 1; i < timeField.Len(); i++ {
+			require.True(t, timeField.At(i-1).(time.Time).Before(timeField.At(i).(time.Time)))
+		}
+		require.Equal(t, end.Add(-3*day), timeField.At(0).(time.Time).UTC())
+		require.Equal(t, end.Add(step), timeField.At(timeField.Len()-1).(time.Time).UTC())
+		require.Equal(t, 8, frame.Rows())
+	})
+
+	t.Run("log chunks stop once the line limit is reached", func(t *testing.T) {
+		api, requests := makeChunkedAPI(t, func(start, end int64) string {
+			return fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"app":"a"},"values":[["%d","line at the end"],["%d","line at the start"]]}
+			]}}`, end-1, start)
+		})
+
+		query := &lokiQuery{
+			Expr:          `{app="a"}`,
+			QueryType:     QueryTypeRange,
+			Direction:     DirectionBackward,
+			Step:          time.Minute,
+			MaxLines:      3,
+			Start:         end.Add(-10 * day),
+			End:           end,
+			RefID:         "A",
+			SplitDuration: day,
+		}
+		res, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Equal(t, maxConcurrentChunks, requests())
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		// newest lines first
+		timeField := frame.Fields[1]
+		require.Equal(t, end.Add(-time.Nanosecond), timeField.At(0).(time.Time).UTC())
+		require.Equal(t, end.Add(-day), timeField.At(1).(time.Time).UTC())
+		require.Equal(t, end.Add(-day-time.Nanosecond), timeField.At(2).(time.Time).UTC())
+	})
+
+	t.Run("queries shorter than the split duration are not split", func(t *testing.T) {
+		api, requests := makeChunkedAPI(t, func(start, end int64) string {
+			return `{"status":"success","data":{"resultType":"streams","result":[]}}`
+		})
+
+		query := &lokiQuery{
+			Expr:          `{app="a"}`,
+			QueryType:     QueryTypeRange,
+			Direction:     DirectionBackward,
+			Step:          time.Minute,
+			Start:         end.Add(-time.Hour),
+			End:           end,
+			RefID:         "A",
+			SplitDuration: day,
+		}
+		_, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, logger)
+		require.NoError(t, err)
+		require.Equal(t, 1, requests())
+	})
+}
@@ -11,8 +11,9 @@ type SupportingQueryType = dataquery.SupportingQueryType
 type Direction = dataquery.LokiQueryDirection
 
 const (
-	QueryTypeRange   = dataquery.LokiQueryTypeRange
-	QueryTypeInstant = dataquery.LokiQueryTypeInstant
+	QueryTypeRange    = dataquery.LokiQueryTypeRange
+	QueryTypeInstant  = dataquery.LokiQueryTypeInstant
+	QueryTypePatterns = dataquery.LokiQueryTypePatterns
 )
 
 const (
@@ -39,4 +40,7 @@ type lokiQuery struct {
 	End                 time.Time
 	RefID               string
 	SupportingQueryType SupportingQueryType
+	// SplitDuration is the duration of the time chunks range queries are split into, zero for queries
+	// that must not be split
+	SplitDuration time.Duration
 }
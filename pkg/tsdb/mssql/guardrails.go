@@ -0,0 +1,68 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+)
+
+// mssqlQueryGuard estimates queries with the estimated execution plan of SHOWPLAN_XML. SQL Server has no
+// statement timeout setting, the statement timeout is enforced by the deadline of the query context instead. Once
+// the context expires the driver sends an attention to the server, which aborts the statement.
+type mssqlQueryGuard struct{}
+
+func (g *mssqlQueryGuard) Explain(ctx context.Context, conn *sql.Conn, query string, args ...any) (estimate sqleng.QueryEstimate, err error) {
+	// SHOWPLAN_XML has to be set in a batch of its own
+	if _, err := conn.ExecContext(ctx, "SET SHOWPLAN_XML ON"); err != nil {
+		return estimate, err
+	}
+	defer func() {
+		if _, offErr := conn.ExecContext(context.Background(), "SET SHOWPLAN_XML OFF"); offErr != nil && err == nil {
+			err = offErr
+		}
+	}()
+
+	var plan string
+	if err := conn.QueryRowContext(ctx, query, args...).Scan(&plan); err != nil {
+		return estimate, err
+	}
+	return parseShowPlan([]byte(plan))
+}
+
+func (g *mssqlQueryGuard) SetStatementTimeout(_ context.Context, _ *sql.Conn, _ time.Duration) error {
+	return nil
+}
+
+// IsStatementTimeout reports whether the statement was aborted by the driver because the query context expired
+func (g *mssqlQueryGuard) IsStatementTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+type showPlan struct {
+	Statements []struct {
+		EstRows     float64 `xml:"StatementEstRows,attr"`
+		SubTreeCost float64 `xml:"StatementSubTreeCost,attr"`
+	} `xml:"BatchSequence>Batch>Statements>StmtSimple"`
+}
+
+// parseShowPlan returns the summed cost and the largest number of rows of the statements of the plan
+func parseShowPlan(plan []byte) (sqleng.QueryEstimate, error) {
+	var sp showPlan
+	if err := xml.Unmarshal(plan, &sp); err != nil {
+		return sqleng.QueryEstimate{}, err
+	}
+	if len(sp.Statements) == 0 {
+		return sqleng.QueryEstimate{}, fmt.Errorf("unexpected execution plan")
+	}
+	estimate := sqleng.QueryEstimate{}
+	for _, stmt := range sp.Statements {
+		estimate.Cost += stmt.SubTreeCost
+		estimate.Rows = max(estimate.Rows, stmt.EstRows)
+	}
+	return estimate, nil
+}
@@ -0,0 +1,34 @@
+package mssql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+)
+
+func TestParseShowPlan(t *testing.T) {
+	plan := `<ShowPlanXML xmlns="http://schemas.microsoft.com/sqlserver/2004/07/showplan" Version="1.564">
+		<BatchSequence><Batch><Statements>
+			<StmtSimple StatementText="SELECT * FROM host" StatementEstRows="10" StatementSubTreeCost="0.5" />
+			<StmtSimple StatementText="SELECT * FROM metric" StatementEstRows="5000" StatementSubTreeCost="12.25" />
+		</Statements></Batch></BatchSequence>
+	</ShowPlanXML>`
+	estimate, err := parseShowPlan([]byte(plan))
+	require.NoError(t, err)
+	require.Equal(t, sqleng.QueryEstimate{Rows: 5000, Cost: 12.75}, estimate)
+
+	_, err = parseShowPlan([]byte(`<ShowPlanXML />`))
+	require.Error(t, err)
+}
+
+func TestIsStatementTimeout(t *testing.T) {
+	g := &mssqlQueryGuard{}
+	require.True(t, g.IsStatementTimeout(context.DeadlineExceeded))
+	require.True(t, g.IsStatementTimeout(fmt.Errorf("mssql: %w", context.Canceled)))
+	require.False(t, g.IsStatementTimeout(errors.New("Invalid column name 'foo'.")))
+}
@@ -0,0 +1,55 @@
+package mssql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mssqlSchemaProvider provides the catalog queries of the schema resources. Catalog views can't be
+// parameterized by database, the quoted database name prefixes them instead.
+type mssqlSchemaProvider struct{}
+
+func (p *mssqlSchemaProvider) DatabasesQuery() string {
+	// database ids up to 4 are the system databases
+	return `SELECT name FROM sys.databases
+		WHERE database_id > 4 AND HAS_DBACCESS(name) = 1
+		ORDER BY name`
+}
+
+func (p *mssqlSchemaProvider) SchemasQuery(database string) (string, []any) {
+	return fmt.Sprintf(`SELECT SCHEMA_NAME FROM %sINFORMATION_SCHEMA.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') AND SCHEMA_NAME NOT LIKE 'db[_]%%'
+		ORDER BY SCHEMA_NAME`, catalogPrefix(database)), nil
+}
+
+func (p *mssqlSchemaProvider) TablesQuery(database, schema string) (string, []any) {
+	return fmt.Sprintf(`SELECT TABLE_NAME, TABLE_TYPE FROM %sINFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())
+		ORDER BY TABLE_NAME`, catalogPrefix(database)), []any{schema}
+}
+
+func (p *mssqlSchemaProvider) ColumnsQuery(database, schema, table string) (string, []any) {
+	return fmt.Sprintf(`SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE FROM %sINFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME()) AND TABLE_NAME = @p2
+		ORDER BY ORDINAL_POSITION`, catalogPrefix(database)), []any{schema, table}
+}
+
+func (p *mssqlSchemaProvider) IndexesQuery(database, schema, table string) (string, []any) {
+	db := catalogPrefix(database)
+	return fmt.Sprintf(`SELECT i.name, c.name, i.is_unique FROM %[1]ssys.indexes i
+		JOIN %[1]ssys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN %[1]ssys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		JOIN %[1]ssys.tables t ON t.object_id = i.object_id
+		JOIN %[1]ssys.schemas s ON s.schema_id = t.schema_id
+		WHERE s.name = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME()) AND t.name = @p2
+			AND i.name IS NOT NULL AND ic.is_included_column = 0
+		ORDER BY i.name, ic.key_ordinal`, db), []any{schema, table}
+}
+
+// catalogPrefix returns the quoted database name to prefix catalog views with, or nothing for the current database
+func catalogPrefix(database string) string {
+	if database == "" {
+		return ""
+	}
+	return "[" + strings.ReplaceAll(database, "]", "]]") + "]."
+}
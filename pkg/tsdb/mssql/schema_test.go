@@ -0,0 +1,17 @@
+package mssql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogPrefix(t *testing.T) {
+	require.Equal(t, "", catalogPrefix(""))
+	require.Equal(t, "[grafana].", catalogPrefix("grafana"))
+	require.Equal(t, "[my]]db].", catalogPrefix("my]db"))
+
+	query, args := (&mssqlSchemaProvider{}).TablesQuery("my db", "dbo")
+	require.Contains(t, query, "FROM [my db].INFORMATION_SCHEMA.TABLES")
+	require.Equal(t, []any{"dbo"}, args)
+}
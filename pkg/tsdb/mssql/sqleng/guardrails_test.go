@@ -0,0 +1,138 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestTimeout = errors.New("maximum statement execution time exceeded")
+
+type testQueryGuard struct {
+	estimate    QueryEstimate
+	explainErr  error
+	explained   []string
+	setTimeouts []time.Duration
+}
+
+func (g *testQueryGuard) Explain(_ context.Context, _ *sql.Conn, query string, _ ...any) (QueryEstimate, error) {
+	g.explained = append(g.explained, query)
+	return g.estimate, g.explainErr
+}
+
+func (g *testQueryGuard) SetStatementTimeout(_ context.Context, _ *sql.Conn, timeout time.Duration) error {
+	g.setTimeouts = append(g.setTimeouts, timeout)
+	return nil
+}
+
+func (g *testQueryGuard) IsStatementTimeout(err error) bool {
+	return errors.Is(err, errTestTimeout)
+}
+
+func TestGuardedQuery(t *testing.T) {
+	const query = "SELECT * FROM metric"
+
+	setup := func(t *testing.T, jsonData JsonData, guard *testQueryGuard) (*DataSourceHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		return &DataSourceHandler{
+			db:         db,
+			log:        backend.NewLoggerWith("logger", "test"),
+			dsInfo:     DataSourceInfo{JsonData: jsonData},
+			queryGuard: guard,
+		}, mock
+	}
+
+	run := func(t *testing.T, dp *DataSourceHandler) ([]data.Notice, error) {
+		t.Helper()
+		rows, release, notices, err := dp.guardedQuery(context.Background(), dp.log, query)
+		if err != nil {
+			return notices, err
+		}
+		require.NoError(t, rows.Close())
+		release()
+		return notices, nil
+	}
+
+	t.Run("Queries are not explained without cost limits", func(t *testing.T) {
+		guard := &testQueryGuard{}
+		dp, mock := setup(t, JsonData{}, guard)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		notices, err := run(t, dp)
+		require.NoError(t, err)
+		require.Empty(t, notices)
+		require.Empty(t, guard.explained)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rejects queries exceeding the estimated rows", func(t *testing.T) {
+		guard := &testQueryGuard{estimate: QueryEstimate{Rows: 5000, Cost: 10}}
+		dp, mock := setup(t, JsonData{MaxEstimatedRows: 1000}, guard)
+
+		notices, err := run(t, dp)
+		var guardErr *GuardrailError
+		require.ErrorAs(t, err, &guardErr)
+		require.EqualError(t, err, "query rejected, the estimated number of rows 5000 exceeds the limit of 1000 of the data source")
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityError, Text: err.Error()}}, notices)
+		require.Equal(t, []string{query}, guard.explained)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Warns about queries exceeding the estimated cost", func(t *testing.T) {
+		guard := &testQueryGuard{estimate: QueryEstimate{Rows: 10, Cost: 2500.5}}
+		dp, mock := setup(t, JsonData{MaxEstimatedCost: 1000, CostLimitAction: CostLimitActionWarn}, guard)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		notices, err := run(t, dp)
+		require.NoError(t, err)
+		require.Len(t, notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, notices[0].Severity)
+		require.Contains(t, notices[0].Text, "the estimated cost 2500.5 exceeds the limit of 1000")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Executes queries which can't be explained with a warning", func(t *testing.T) {
+		guard := &testQueryGuard{explainErr: errors.New("syntax error")}
+		dp, mock := setup(t, JsonData{MaxEstimatedRows: 1000}, guard)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		notices, err := run(t, dp)
+		require.NoError(t, err)
+		require.Len(t, notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, notices[0].Severity)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Sets and resets the statement timeout", func(t *testing.T) {
+		guard := &testQueryGuard{}
+		dp, mock := setup(t, JsonData{StatementTimeout: 30}, guard)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		_, err := run(t, dp)
+		require.NoError(t, err)
+		require.Equal(t, []time.Duration{30 * time.Second, 0}, guard.setTimeouts)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Returns a clear error for queries exceeding the statement timeout", func(t *testing.T) {
+		guard := &testQueryGuard{}
+		dp, mock := setup(t, JsonData{StatementTimeout: 30}, guard)
+		mock.ExpectQuery(query).WillReturnError(errTestTimeout)
+
+		notices, err := run(t, dp)
+		require.EqualError(t, err, "query aborted, the execution time exceeds the statement timeout of 30s of the data source")
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityError, Text: err.Error()}}, notices)
+		require.Equal(t, []time.Duration{30 * time.Second, 0}, guard.setTimeouts)
+	})
+}
@@ -0,0 +1,256 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	schemaCacheTTL             = time.Minute
+	schemaCacheCleanupInterval = 5 * time.Minute
+)
+
+var (
+	errDatabaseNotAllowed = errors.New("the data source is restricted to its configured database")
+	errMissingTable       = errors.New("missing table")
+)
+
+// SchemaProvider provides the catalog queries of a dialect for the schema resources. The queries take the
+// database, schema and table as arguments in the placeholder syntax of the driver and return the columns
+// documented on each method.
+type SchemaProvider interface {
+	// DatabasesQuery returns the name of the databases
+	DatabasesQuery() string
+	// SchemasQuery returns the name of the schemas of a database
+	SchemasQuery(database string) (string, []any)
+	// TablesQuery returns the name and the type of the tables and views of a schema
+	TablesQuery(database, schema string) (string, []any)
+	// ColumnsQuery returns the name, the type and whether the column is nullable, ordered by position
+	ColumnsQuery(database, schema, table string) (string, []any)
+	// IndexesQuery returns the name, the column and whether the index is unique, one row per indexed column ordered by index and position
+	IndexesQuery(database, schema, table string) (string, []any)
+}
+
+type Table struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+func (e *DataSourceHandler) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return e.resourceHandler.CallResource(ctx, req, sender)
+}
+
+func (e *DataSourceHandler) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/databases", e.handleSchemaResource(func(ctx context.Context, _ url.Values) (any, error) {
+		if e.dsInfo.Database != "" {
+			return []string{e.dsInfo.Database}, nil
+		}
+		return e.queryNames(ctx, e.schemaProvider.DatabasesQuery(), nil)
+	}))
+	mux.HandleFunc("/schemas", e.handleSchemaResource(func(ctx context.Context, params url.Values) (any, error) {
+		query, args := e.schemaProvider.SchemasQuery(params.Get("database"))
+		return e.queryNames(ctx, query, args)
+	}))
+	mux.HandleFunc("/tables", e.handleSchemaResource(func(ctx context.Context, params url.Values) (any, error) {
+		return e.queryTables(ctx, params.Get("database"), params.Get("schema"))
+	}))
+	mux.HandleFunc("/columns", e.handleSchemaResource(func(ctx context.Context, params url.Values) (any, error) {
+		if params.Get("table") == "" {
+			return nil, errMissingTable
+		}
+		return e.queryColumns(ctx, params.Get("database"), params.Get("schema"), params.Get("table"))
+	}))
+	mux.HandleFunc("/indexes", e.handleSchemaResource(func(ctx context.Context, params url.Values) (any, error) {
+		if params.Get("table") == "" {
+			return nil, errMissingTable
+		}
+		return e.queryIndexes(ctx, params.Get("database"), params.Get("schema"), params.Get("table"))
+	}))
+	return mux
+}
+
+// handleSchemaResource checks the requested database, serves the result from the cache of the data source
+// and writes it as JSON
+func (e *DataSourceHandler) handleSchemaResource(fn func(ctx context.Context, params url.Values) (any, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeResourceError(rw, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+		if e.schemaProvider == nil {
+			writeResourceError(rw, http.StatusNotFound, errors.New("schema introspection is not supported by the data source"))
+			return
+		}
+
+		params := req.URL.Query()
+		if err := e.checkDatabase(params.Get("database")); err != nil {
+			writeResourceError(rw, http.StatusForbidden, err)
+			return
+		}
+
+		key := req.URL.Path + "?" + params.Encode()
+		if res, ok := e.schemaCache.Get(key); ok {
+			writeResourceResponse(rw, res)
+			return
+		}
+
+		res, err := fn(req.Context(), params)
+		if err != nil {
+			if errors.Is(err, errMissingTable) {
+				writeResourceError(rw, http.StatusBadRequest, err)
+				return
+			}
+			logger := e.log.FromContext(req.Context())
+			logger.Error("Failed to query schema", "path", req.URL.Path, "error", err)
+			writeResourceError(rw, http.StatusInternalServerError, e.TransformQueryError(logger, err))
+			return
+		}
+		e.schemaCache.SetDefault(key, res)
+		writeResourceResponse(rw, res)
+	}
+}
+
+// checkDatabase only allows the configured database of the data source, which is the only database /databases
+// lists for it. Data sources without a configured database can browse every database the user of the connection
+// has access to.
+func (e *DataSourceHandler) checkDatabase(database string) error {
+	if database == "" || e.dsInfo.Database == "" || database == e.dsInfo.Database {
+		return nil
+	}
+	return errDatabaseNotAllowed
+}
+
+func (e *DataSourceHandler) queryNames(ctx context.Context, query string, args []any) ([]string, error) {
+	names := []string{}
+	err := e.queryRows(ctx, query, args, func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	return names, err
+}
+
+func (e *DataSourceHandler) queryTables(ctx context.Context, database, schema string) ([]Table, error) {
+	query, args := e.schemaProvider.TablesQuery(database, schema)
+	tables := []Table{}
+	err := e.queryRows(ctx, query, args, func(rows *sql.Rows) error {
+		var t Table
+		if err := rows.Scan(&t.Name, &t.Type); err != nil {
+			return err
+		}
+		tables = append(tables, t)
+		return nil
+	})
+	return tables, err
+}
+
+func (e *DataSourceHandler) queryColumns(ctx context.Context, database, schema, table string) ([]Column, error) {
+	query, args := e.schemaProvider.ColumnsQuery(database, schema, table)
+	columns := []Column{}
+	err := e.queryRows(ctx, query, args, func(rows *sql.Rows) error {
+		var c Column
+		var nullable string
+		if err := rows.Scan(&c.Name, &c.Type, &nullable); err != nil {
+			return err
+		}
+		c.Nullable = parseCatalogBool(nullable)
+		columns = append(columns, c)
+		return nil
+	})
+	return columns, err
+}
+
+func (e *DataSourceHandler) queryIndexes(ctx context.Context, database, schema, table string) ([]Index, error) {
+	query, args := e.schemaProvider.IndexesQuery(database, schema, table)
+	indexes := []Index{}
+	err := e.queryRows(ctx, query, args, func(rows *sql.Rows) error {
+		var name, column, unique string
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{Name: name, Columns: []string{}, Unique: parseCatalogBool(unique)})
+		}
+		last := &indexes[len(indexes)-1]
+		last.Columns = append(last.Columns, column)
+		return nil
+	})
+	return indexes, err
+}
+
+func (e *DataSourceHandler) queryRows(ctx context.Context, query string, args []any, scan func(rows *sql.Rows) error) error {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// parseCatalogBool parses the boolean columns of the catalog queries, which depending on the dialect are
+// returned as a number, a boolean or YES and NO
+func parseCatalogBool(s string) bool {
+	if s == "YES" {
+		return true
+	}
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
+func newSchemaCache() *cache.Cache {
+	return cache.New(schemaCacheTTL, schemaCacheCleanupInterval)
+}
+
+func writeResourceResponse(rw http.ResponseWriter, res any) {
+	body, err := json.Marshal(res)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(body)
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
@@ -0,0 +1,133 @@
+package sqleng
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type testSchemaProvider struct{}
+
+func (p *testSchemaProvider) DatabasesQuery() string {
+	return "SELECT databases"
+}
+
+func (p *testSchemaProvider) SchemasQuery(database string) (string, []any) {
+	return "SELECT schemas", []any{database}
+}
+
+func (p *testSchemaProvider) TablesQuery(database, schema string) (string, []any) {
+	return "SELECT tables", []any{database, schema}
+}
+
+func (p *testSchemaProvider) ColumnsQuery(database, schema, table string) (string, []any) {
+	return "SELECT columns", []any{database, schema, table}
+}
+
+func (p *testSchemaProvider) IndexesQuery(database, schema, table string) (string, []any) {
+	return "SELECT indexes", []any{database, schema, table}
+}
+
+func TestSchemaResources(t *testing.T) {
+	setup := func(t *testing.T, database string) (*http.ServeMux, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		dp := &DataSourceHandler{
+			db:                     db,
+			log:                    backend.NewLoggerWith("logger", "test"),
+			dsInfo:                 DataSourceInfo{Database: database},
+			queryResultTransformer: &testQueryResultTransformer{},
+			schemaProvider:         &testSchemaProvider{},
+			schemaCache:            newSchemaCache(),
+		}
+		return dp.newResourceMux(), mock
+	}
+
+	get := func(t *testing.T, mux *http.ServeMux, url string, v any) int {
+		t.Helper()
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, url, nil))
+		if v != nil && rw.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), v))
+		}
+		return rw.Code
+	}
+
+	t.Run("Lists the databases and caches the result", func(t *testing.T) {
+		mux, mock := setup(t, "")
+		mock.ExpectQuery("SELECT databases").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("grafana").AddRow("metrics"))
+
+		var databases []string
+		require.Equal(t, http.StatusOK, get(t, mux, "/databases", &databases))
+		require.Equal(t, []string{"grafana", "metrics"}, databases)
+
+		databases = nil
+		require.Equal(t, http.StatusOK, get(t, mux, "/databases", &databases))
+		require.Equal(t, []string{"grafana", "metrics"}, databases)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lists only the configured database", func(t *testing.T) {
+		mux, mock := setup(t, "grafana")
+
+		var databases []string
+		require.Equal(t, http.StatusOK, get(t, mux, "/databases", &databases))
+		require.Equal(t, []string{"grafana"}, databases)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lists the columns of a table", func(t *testing.T) {
+		mux, mock := setup(t, "grafana")
+		mock.ExpectQuery("SELECT columns").WithArgs("grafana", "public", "metric").WillReturnRows(
+			sqlmock.NewRows([]string{"name", "type", "nullable"}).AddRow("time", "timestamp", "NO").AddRow("value", "double", "YES"))
+
+		var columns []Column
+		require.Equal(t, http.StatusOK, get(t, mux, "/columns?database=grafana&schema=public&table=metric", &columns))
+		require.Equal(t, []Column{{Name: "time", Type: "timestamp"}, {Name: "value", Type: "double", Nullable: true}}, columns)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Groups the columns of an index", func(t *testing.T) {
+		mux, mock := setup(t, "")
+		mock.ExpectQuery("SELECT indexes").WithArgs("", "", "metric").WillReturnRows(
+			sqlmock.NewRows([]string{"name", "column", "unique"}).
+				AddRow("metric_pkey", "id", "1").
+				AddRow("metric_time_name", "time", "false").
+				AddRow("metric_time_name", "name", "false"))
+
+		var indexes []Index
+		require.Equal(t, http.StatusOK, get(t, mux, "/indexes?table=metric", &indexes))
+		require.Equal(t, []Index{
+			{Name: "metric_pkey", Columns: []string{"id"}, Unique: true},
+			{Name: "metric_time_name", Columns: []string{"time", "name"}},
+		}, indexes)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Requires a table for columns and indexes", func(t *testing.T) {
+		mux, _ := setup(t, "")
+		require.Equal(t, http.StatusBadRequest, get(t, mux, "/columns", nil))
+		require.Equal(t, http.StatusBadRequest, get(t, mux, "/indexes", nil))
+	})
+
+	t.Run("Restricts the data source to its configured database", func(t *testing.T) {
+		mux, mock := setup(t, "grafana")
+		require.Equal(t, http.StatusForbidden, get(t, mux, "/tables?database=other", nil))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Returns the error of a catalog query", func(t *testing.T) {
+		mux, mock := setup(t, "")
+		mock.ExpectQuery("SELECT tables").WillReturnError(errors.New("permission denied"))
+		require.Equal(t, http.StatusInternalServerError, get(t, mux, "/tables", nil))
+	})
+}
@@ -0,0 +1,284 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+type testStreamQueryBuilder struct{}
+
+func (b *testStreamQueryBuilder) InitialQuery(query, column string) string {
+	return query + " ORDER BY " + column
+}
+
+func (b *testStreamQueryBuilder) IncrementalQuery(query, column string) string {
+	return query + " WHERE " + column + " > ?"
+}
+
+func TestParseStreamQuery(t *testing.T) {
+	t.Run("Applies the defaults", func(t *testing.T) {
+		q, err := parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM events", "streamColumn": "id"}`))
+		require.NoError(t, err)
+		require.Equal(t, defaultStreamInterval, q.interval)
+		require.Equal(t, defaultStreamLookback, q.lookback)
+	})
+
+	t.Run("Limits the interval", func(t *testing.T) {
+		q, err := parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM events", "streamColumn": "id", "streamIntervalMs": 100, "streamLookback": "15m"}`))
+		require.NoError(t, err)
+		require.Equal(t, minStreamInterval, q.interval)
+		require.Equal(t, 15*time.Minute, q.lookback)
+	})
+
+	t.Run("Requires a stream column", func(t *testing.T) {
+		_, err := parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM events"}`))
+		require.EqualError(t, err, "missing streamColumn in stream query")
+	})
+}
+
+func TestSQLTail(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	dp := &DataSourceHandler{
+		db:                     db,
+		log:                    backend.NewLoggerWith("logger", "test"),
+		macroEngine:            &testMacroEngine{},
+		queryResultTransformer: &testQueryResultTransformer{},
+		streamQueryBuilder:     &testStreamQueryBuilder{},
+		rowLimit:               1000,
+	}
+	q, err := parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT id, message FROM events", "streamColumn": "id"}`))
+	require.NoError(t, err)
+	tail := &sqlTail{handler: dp, query: q, logger: dp.log}
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRowsWithColumnDefinition(
+			sqlmock.NewColumn("id").OfType("BIGINT", int64(0)),
+			sqlmock.NewColumn("message").OfType("VARCHAR", ""),
+		)
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Sends the rows of the query first", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, message FROM events ORDER BY id").WillReturnRows(
+			newRows().AddRow(int64(1), "a").AddRow(int64(2), "b").AddRow(int64(3), "c"))
+
+		frame, err := tail.poll(context.Background(), now)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, int64(3), tail.highWaterMark)
+	})
+
+	t.Run("Sends only the rows above the high-water mark", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, message FROM events WHERE id > ?").WithArgs(int64(3)).WillReturnRows(
+			newRows().AddRow(int64(4), "d").AddRow(int64(5), "e"))
+
+		frame, err := tail.poll(context.Background(), now.Add(time.Second))
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, int64(5), tail.highWaterMark)
+	})
+
+	t.Run("Returns no frame without new rows", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, message FROM events WHERE id > ?").WithArgs(int64(5)).WillReturnRows(
+			newRows())
+
+		frame, err := tail.poll(context.Background(), now.Add(2*time.Second))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+		require.Equal(t, int64(5), tail.highWaterMark)
+	})
+
+	t.Run("Returns an error for a missing stream column", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, message FROM events WHERE id > ?").WithArgs(int64(5)).WillReturnRows(
+			sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("message").OfType("VARCHAR", "")).AddRow("f"))
+
+		_, err := tail.poll(context.Background(), now.Add(3*time.Second))
+		require.EqualError(t, err, `stream column "id" not found in the query result`)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLTailGuardrails(t *testing.T) {
+	setup := func(t *testing.T, jsonData JsonData, guard *testQueryGuard) (*sqlTail, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		dp := &DataSourceHandler{
+			db:                     db,
+			log:                    backend.NewLoggerWith("logger", "test"),
+			dsInfo:                 DataSourceInfo{JsonData: jsonData},
+			macroEngine:            &testMacroEngine{},
+			queryResultTransformer: &testQueryResultTransformer{},
+			streamQueryBuilder:     &testStreamQueryBuilder{},
+			queryGuard:             guard,
+			rowLimit:               1000,
+		}
+		q, err := parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT id FROM events", "streamColumn": "id"}`))
+		require.NoError(t, err)
+		return &sqlTail{handler: dp, query: q, logger: dp.log, highWaterMark: int64(3)}, mock
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Rejects polls exceeding the cost limits", func(t *testing.T) {
+		guard := &testQueryGuard{estimate: QueryEstimate{Rows: 5000}}
+		tail, mock := setup(t, JsonData{MaxEstimatedRows: 1000}, guard)
+
+		_, err := tail.poll(context.Background(), now)
+		require.ErrorAs(t, err, new(*GuardrailError))
+		require.Equal(t, []string{"SELECT id FROM events WHERE id > ?"}, guard.explained)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Applies the statement timeout to polls", func(t *testing.T) {
+		guard := &testQueryGuard{}
+		tail, mock := setup(t, JsonData{StatementTimeout: 10}, guard)
+		mock.ExpectQuery("SELECT id FROM events WHERE id > ?").WithArgs(int64(3)).WillReturnRows(
+			sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("id").OfType("BIGINT", int64(0))).AddRow(int64(4)))
+
+		frame, err := tail.poll(context.Background(), now)
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, []time.Duration{10 * time.Second, 0}, guard.setTimeouts)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSQLTailRowLimit(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	dp := &DataSourceHandler{
+		db:                     db,
+		log:                    backend.NewLoggerWith("logger", "test"),
+		macroEngine:            &testMacroEngine{},
+		queryResultTransformer: &testQueryResultTransformer{},
+		streamQueryBuilder:     &testStreamQueryBuilder{},
+		rowLimit:               3,
+	}
+	q, err := parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT id FROM events", "streamColumn": "id"}`))
+	require.NoError(t, err)
+	tail := &sqlTail{handler: dp, query: q, logger: dp.log}
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("id").OfType("BIGINT", int64(0)))
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Leaves the rows of the last value of a truncated batch to the next poll", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id FROM events ORDER BY id").WillReturnRows(
+			newRows().AddRow(int64(1)).AddRow(int64(2)).AddRow(int64(2)).AddRow(int64(2)))
+
+		frame, err := tail.poll(context.Background(), now)
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, int64(1), tail.highWaterMark)
+
+		mock.ExpectQuery("SELECT id FROM events WHERE id > ?").WithArgs(int64(1)).WillReturnRows(
+			newRows().AddRow(int64(2)).AddRow(int64(2)).AddRow(int64(2)))
+
+		frame, err = tail.poll(context.Background(), now.Add(time.Second))
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, int64(2), tail.highWaterMark)
+		notices := frame.Meta.Notices
+		require.NotEmpty(t, notices)
+		require.Equal(t, data.NoticeSeverityWarning, notices[len(notices)-1].Severity)
+		require.Contains(t, notices[len(notices)-1].Text, "have the same value of the stream column")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type testPacketSender struct {
+	packets []*backend.StreamPacket
+}
+
+func (s *testPacketSender) Send(packet *backend.StreamPacket) error {
+	s.packets = append(s.packets, packet)
+	return nil
+}
+
+func TestRunStreamErrors(t *testing.T) {
+	setup := func(t *testing.T) (*DataSourceHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		return &DataSourceHandler{
+			db:                     db,
+			log:                    backend.NewLoggerWith("logger", "test"),
+			macroEngine:            &testMacroEngine{},
+			queryResultTransformer: &testQueryResultTransformer{},
+			streamQueryBuilder:     &testStreamQueryBuilder{},
+			rowLimit:               1000,
+			streams:                map[string]data.FrameJSONCache{},
+		}, mock
+	}
+	req := &backend.RunStreamRequest{
+		Path: "tail/1",
+		Data: json.RawMessage(`{"rawSql": "SELECT id FROM events", "streamColumn": "id", "streamIntervalMs": 1000}`),
+	}
+
+	t.Run("Stops the stream on errors that fail every poll", func(t *testing.T) {
+		dp, mock := setup(t)
+		mock.ExpectQuery("SELECT id FROM events ORDER BY id").WillReturnError(errors.New("unknown column 'id'"))
+
+		packets := &testPacketSender{}
+		err := dp.RunStream(context.Background(), req, backend.NewStreamSender(packets))
+		require.NoError(t, err)
+		require.Len(t, packets.packets, 1)
+		require.Contains(t, string(packets.packets[0].Data), "unknown column")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Retries transient errors on the next tick", func(t *testing.T) {
+		dp, mock := setup(t)
+		mock.ExpectQuery("SELECT id FROM events ORDER BY id").WillReturnError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")})
+		mock.ExpectQuery("SELECT id FROM events ORDER BY id").WillReturnRows(
+			sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("id").OfType("BIGINT", int64(0))).AddRow(int64(1)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			done <- dp.RunStream(ctx, req, backend.NewStreamSender(&testPacketSender{}))
+		}()
+		require.Eventually(t, func() bool {
+			return mock.ExpectationsWereMet() == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+func TestStreamValueAfter(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.True(t, streamValueAfter(ts, nil))
+	require.True(t, streamValueAfter(ts.Add(time.Millisecond), ts))
+	require.False(t, streamValueAfter(ts, ts))
+	require.True(t, streamValueAfter(normalizeStreamValue(int32(7)), int64(6)))
+	require.False(t, streamValueAfter(normalizeStreamValue(float32(1.5)), 2.0))
+	require.Nil(t, normalizeStreamValue(driver.Value(true)))
+}
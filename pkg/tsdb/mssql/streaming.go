@@ -0,0 +1,28 @@
+package mssql
+
+import (
+	"strings"
+)
+
+// mssqlStreamQueryBuilder builds the queries of streams. SQL Server doesn't allow an ORDER BY
+// clause in a subquery without TOP or OFFSET, stream queries must not be ordered.
+type mssqlStreamQueryBuilder struct{}
+
+func (b *mssqlStreamQueryBuilder) InitialQuery(query, column string) string {
+	column = quoteStreamColumn(column)
+	return "SELECT * FROM (" + trimQuery(query) + ") AS grafana_stream ORDER BY " + column
+}
+
+func (b *mssqlStreamQueryBuilder) IncrementalQuery(query, column string) string {
+	column = quoteStreamColumn(column)
+	return "SELECT * FROM (" + trimQuery(query) + ") AS grafana_stream WHERE " + column + " > @p1 ORDER BY " + column
+}
+
+func quoteStreamColumn(column string) string {
+	return "[" + strings.ReplaceAll(column, "]", "]]") + "]"
+}
+
+// trimQuery removes the trailing semicolon of a query, so that it can be used as a subquery
+func trimQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), ";")
+}
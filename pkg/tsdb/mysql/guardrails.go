@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+)
+
+// errQueryTimeout is ER_QUERY_TIMEOUT, returned for statements exceeding max_execution_time
+const errQueryTimeout = 3024
+
+// mysqlQueryGuard estimates queries with EXPLAIN FORMAT=JSON and limits them with max_execution_time,
+// which MySQL only applies to read-only SELECT statements.
+type mysqlQueryGuard struct{}
+
+func (g *mysqlQueryGuard) Explain(ctx context.Context, conn *sql.Conn, query string, args ...any) (sqleng.QueryEstimate, error) {
+	var plan string
+	if err := conn.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query, args...).Scan(&plan); err != nil {
+		return sqleng.QueryEstimate{}, err
+	}
+	return parseExplainPlan([]byte(plan))
+}
+
+func (g *mysqlQueryGuard) SetStatementTimeout(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	value := "DEFAULT"
+	if timeout > 0 {
+		value = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+	_, err := conn.ExecContext(ctx, "SET SESSION max_execution_time = "+value)
+	return err
+}
+
+func (g *mysqlQueryGuard) IsStatementTimeout(err error) bool {
+	var driverErr *mysql.MySQLError
+	return errors.As(err, &driverErr) && driverErr.Number == errQueryTimeout
+}
+
+// parseExplainPlan returns the cost of the query block and, as rows, the largest number of rows produced
+// by a join step of the plan
+func parseExplainPlan(plan []byte) (sqleng.QueryEstimate, error) {
+	var explain map[string]any
+	if err := json.Unmarshal(plan, &explain); err != nil {
+		return sqleng.QueryEstimate{}, err
+	}
+	queryBlock, ok := explain["query_block"].(map[string]any)
+	if !ok {
+		return sqleng.QueryEstimate{}, fmt.Errorf("unexpected explain plan")
+	}
+
+	estimate := sqleng.QueryEstimate{}
+	if costInfo, ok := queryBlock["cost_info"].(map[string]any); ok {
+		estimate.Cost = planNumber(costInfo["query_cost"])
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if rows, ok := v["rows_produced_per_join"]; ok {
+				estimate.Rows = max(estimate.Rows, planNumber(rows))
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(queryBlock)
+	return estimate, nil
+}
+
+// planNumber parses the numbers of the plan, costs are formatted as strings
+func planNumber(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
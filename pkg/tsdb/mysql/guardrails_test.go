@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+)
+
+func TestParseExplainPlan(t *testing.T) {
+	plan := `{
+		"query_block": {
+			"select_id": 1,
+			"cost_info": { "query_cost": "1510.75" },
+			"nested_loop": [
+				{ "table": { "table_name": "host", "rows_examined_per_scan": 10, "rows_produced_per_join": 10 } },
+				{ "table": { "table_name": "metric", "rows_examined_per_scan": 500, "rows_produced_per_join": 5000 } }
+			]
+		}
+	}`
+	estimate, err := parseExplainPlan([]byte(plan))
+	require.NoError(t, err)
+	require.Equal(t, sqleng.QueryEstimate{Rows: 5000, Cost: 1510.75}, estimate)
+
+	_, err = parseExplainPlan([]byte(`{}`))
+	require.Error(t, err)
+}
@@ -130,10 +130,13 @@ func NewInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 		}
 
 		config := sqleng.DataPluginConfiguration{
-			DSInfo:            dsInfo,
-			TimeColumnNames:   []string{"time", "time_sec"},
-			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
-			RowLimit:          sqlCfg.RowLimit,
+			DSInfo:             dsInfo,
+			TimeColumnNames:    []string{"time", "time_sec"},
+			MetricColumnTypes:  []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+			RowLimit:           sqlCfg.RowLimit,
+			SchemaProvider:     &mysqlSchemaProvider{},
+			QueryGuard:         &mysqlQueryGuard{},
+			StreamQueryBuilder: &mysqlStreamQueryBuilder{},
 		}
 
 		userFacingDefaultError, err := cfg.UserFacingDefaultError()
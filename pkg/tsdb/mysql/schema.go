@@ -0,0 +1,34 @@
+package mysql
+
+// mysqlSchemaProvider provides the catalog queries of the schema resources. MySQL has no schemas
+// within a database, the tables of a database are listed instead and the schema parameter is ignored.
+type mysqlSchemaProvider struct{}
+
+func (p *mysqlSchemaProvider) DatabasesQuery() string {
+	return `SELECT SCHEMA_NAME FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+		ORDER BY SCHEMA_NAME`
+}
+
+func (p *mysqlSchemaProvider) SchemasQuery(database string) (string, []any) {
+	return `SELECT SCHEMA_NAME FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME = COALESCE(NULLIF(?, ''), DATABASE())`, []any{database}
+}
+
+func (p *mysqlSchemaProvider) TablesQuery(database, _ string) (string, []any) {
+	return `SELECT TABLE_NAME, TABLE_TYPE FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+		ORDER BY TABLE_NAME`, []any{database}
+}
+
+func (p *mysqlSchemaProvider) ColumnsQuery(database, _, table string) (string, []any) {
+	return `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, []any{database, table}
+}
+
+func (p *mysqlSchemaProvider) IndexesQuery(database, _, table string) (string, []any) {
+	return `SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0 FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX`, []any{database, table}
+}
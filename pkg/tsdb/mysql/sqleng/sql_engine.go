@@ -18,8 +18,10 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/patrickmn/go-cache"
 )
 
 // MetaKeyExecutedQueryString is the key where the executed query should get stored
@@ -59,6 +61,13 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// MaxEstimatedRows and MaxEstimatedCost limit the estimate of the query planner for a query
+	MaxEstimatedRows int64   `json:"maxEstimatedRows"`
+	MaxEstimatedCost float64 `json:"maxEstimatedCost"`
+	// CostLimitAction either rejects queries exceeding the cost limits or executes them with a warning
+	CostLimitAction string `json:"costLimitAction"`
+	// StatementTimeout limits the execution time of a query on the server, in seconds
+	StatementTimeout int `json:"statementTimeout"`
 }
 
 type DataSourceInfo struct {
@@ -77,6 +86,12 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// SchemaProvider enables the schema resources of the data source when set
+	SchemaProvider SchemaProvider
+	// QueryGuard enforces the cost limits and the statement timeout of the data source when set
+	QueryGuard QueryGuard
+	// StreamQueryBuilder enables the streaming mode of the data source when set
+	StreamQueryBuilder StreamQueryBuilder
 }
 
 type DataSourceHandler struct {
@@ -89,6 +104,13 @@ type DataSourceHandler struct {
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
 	userError              string
+	schemaProvider         SchemaProvider
+	schemaCache            *cache.Cache
+	resourceHandler        backend.CallResourceHandler
+	queryGuard             QueryGuard
+	streamQueryBuilder     StreamQueryBuilder
+	streams                map[string]data.FrameJSONCache
+	streamsMu              sync.RWMutex
 }
 
 type QueryJson struct {
@@ -124,7 +146,13 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
 		userError:              userFacingDefaultError,
+		schemaProvider:         config.SchemaProvider,
+		schemaCache:            newSchemaCache(),
+		queryGuard:             config.QueryGuard,
+		streamQueryBuilder:     config.StreamQueryBuilder,
+		streams:                map[string]data.FrameJSONCache{},
 	}
+	queryDataHandler.resourceHandler = httpadapter.New(queryDataHandler.newResourceMux())
 
 	if len(config.TimeColumnNames) > 0 {
 		queryDataHandler.timeColumnNames = config.TimeColumnNames
@@ -237,10 +265,11 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	timeRange := query.TimeRange
 
-	errAppendDebug := func(frameErr string, err error, query string) {
+	errAppendDebug := func(frameErr string, err error, query string, notices ...data.Notice) {
 		var emptyFrame data.Frame
 		emptyFrame.SetMeta(&data.FrameMeta{
 			ExecutedQueryString: query,
+			Notices:             notices,
 		})
 		queryResult.dataResponse.Error = fmt.Errorf("%s: %w", frameErr, err)
 		queryResult.dataResponse.Frames = data.Frames{&emptyFrame}
@@ -257,11 +286,17 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	rows, release, notices, err := e.guardedQuery(queryContext, logger, interpolatedQuery)
 	if err != nil {
-		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
+		var guardErr *GuardrailError
+		if errors.As(err, &guardErr) {
+			errAppendDebug("query guardrails", err, interpolatedQuery, notices...)
+			return
+		}
+		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, notices...)
 		return
 	}
+	defer release()
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Warn("Failed to close rows", "err", err)
@@ -278,6 +313,10 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	stringConverters := e.queryResultTransformer.GetConverterList()
 	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
+		if err := e.statementTimeoutError(queryContext, err); errors.As(err, new(*GuardrailError)) {
+			errAppendDebug("query guardrails", err, interpolatedQuery, append(notices, guardrailNotices(err)...)...)
+			return
+		}
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
 	}
@@ -287,6 +326,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	}
 
 	frame.Meta.ExecutedQueryString = interpolatedQuery
+	frame.Meta.Notices = append(frame.Meta.Notices, notices...)
 
 	// If no rows were returned, clear any previously set `Fields` with a single empty `data.Field` slice.
 	// Then assign `queryResult.dataResponse.Frames` the current single frame with that single empty Field.
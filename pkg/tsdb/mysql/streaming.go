@@ -0,0 +1,26 @@
+package mysql
+
+import (
+	"strings"
+)
+
+type mysqlStreamQueryBuilder struct{}
+
+func (b *mysqlStreamQueryBuilder) InitialQuery(query, column string) string {
+	column = quoteStreamColumn(column)
+	return "SELECT * FROM (" + trimQuery(query) + ") AS grafana_stream ORDER BY " + column
+}
+
+func (b *mysqlStreamQueryBuilder) IncrementalQuery(query, column string) string {
+	column = quoteStreamColumn(column)
+	return "SELECT * FROM (" + trimQuery(query) + ") AS grafana_stream WHERE " + column + " > ? ORDER BY " + column
+}
+
+func quoteStreamColumn(column string) string {
+	return "`" + strings.ReplaceAll(column, "`", "``") + "`"
+}
+
+// trimQuery removes the trailing semicolon of a query, so that it can be used as a subquery
+func trimQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), ";")
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -25,22 +26,42 @@ import (
 var logger = log.New("tsdb.opentsdb")
 
 type Service struct {
-	im instancemgmt.InstanceManager
+	im              instancemgmt.InstanceManager
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// TSDBVersion is 1 for <=2.1, 2 for 2.2, 3 for 2.3 and 4 for 2.4
+	TSDBVersion int
+	// TSDBResolution is 1 for second and 2 for millisecond resolution
+	TSDBResolution int
 }
 
 type DsAccess string
 
+// queryRef describes the query of a request a sub query of the OpenTSDB request was built from
+type queryRef struct {
+	RefID string
+	// Annotation is set for annotation queries, they return the annotations of the metric instead of its series
+	Annotation *annotationModel
+}
+
+type annotationModel struct {
+	FromAnnotations bool   `json:"fromAnnotations"`
+	Target          string `json:"target"`
+	IsGlobal        bool   `json:"isGlobal"`
+}
+
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
 	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions(ctx)
@@ -53,42 +74,86 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		var jsonData struct {
+			TSDBVersion    int `json:"tsdbVersion"`
+			TSDBResolution int `json:"tsdbResolution"`
+		}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("failed to parse settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jsonData.TSDBVersion,
+			TSDBResolution: jsonData.TSDBResolution,
 		}
 
 		return model, nil
 	}
 }
 
+// QueryData sends all queries of the request as sub queries of a single OpenTSDB query. OpenTSDB versions before
+// 2.3 don't return the index of the sub query of the series, so each query is sent in its own request instead.
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// the index of the sub query is only part of the response since OpenTSDB 2.3
+	if dsInfo.TSDBVersion >= 3 || len(req.Queries) == 1 {
+		return s.runQueries(ctx, logger, dsInfo, req.Queries)
+	}
+
+	result := backend.NewQueryDataResponse()
+	for _, query := range req.Queries {
+		res, err := s.runQueries(ctx, logger, dsInfo, []backend.DataQuery{query})
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		for refID, r := range res.Responses {
+			result.Responses[refID] = r
+		}
+	}
+	return result, nil
+}
+
+// runQueries sends the queries as sub queries of a single OpenTSDB query
+func (s *Service) runQueries(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, queries []backend.DataQuery) (*backend.QueryDataResponse, error) {
+	var tsdbQuery OpenTsdbQuery
 
-	myRefID := q.RefID
+	q := queries[0]
 
 	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+	refs := make([]queryRef, 0, len(queries))
+	for _, query := range queries {
+		ref := queryRef{RefID: query.RefID}
+		var annotation annotationModel
+		if err := json.Unmarshal(query.JSON, &annotation); err == nil && annotation.FromAnnotations && annotation.Target != "" {
+			ref.Annotation = &annotation
+			tsdbQuery.Queries = append(tsdbQuery.Queries, map[string]any{"aggregator": "sum", "metric": annotation.Target})
+			tsdbQuery.GlobalAnnotations = tsdbQuery.GlobalAnnotations || annotation.IsGlobal
+		} else {
+			tsdbQuery.Queries = append(tsdbQuery.Queries, s.buildMetric(query))
+		}
+		refs = append(refs, ref)
 	}
 
+	tsdbQuery.MsResolution = dsInfo.TSDBResolution == 2
+	tsdbQuery.ShowQuery = len(refs) > 1
+
 	// TODO: Don't use global variable
 	if setting.Env == setting.Dev {
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
@@ -106,7 +171,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	result, err := s.parseResponse(logger, res, myRefID)
+	result, err := s.parseResponse(logger, res, refs, tsdbQuery.MsResolution)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
 	}
@@ -140,7 +205,7 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, refs []queryRef, msResolution bool) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -165,8 +230,27 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		return nil, err
 	}
 
-	frames := data.Frames{}
+	for _, ref := range refs {
+		resp.Responses[ref.RefID] = backend.DataResponse{Frames: data.Frames{}}
+	}
+
+	annotations := make(map[string][]OpenTsdbAnnotation)
 	for _, val := range responseData {
+		// without the index of the sub query, all series belong to the first query
+		ref := refs[0]
+		if val.Query != nil && val.Query.Index >= 0 && val.Query.Index < len(refs) {
+			ref = refs[val.Query.Index]
+		}
+
+		if ref.Annotation != nil {
+			if ref.Annotation.IsGlobal {
+				annotations[ref.RefID] = append(annotations[ref.RefID], val.GlobalAnnotations...)
+			} else {
+				annotations[ref.RefID] = append(annotations[ref.RefID], val.Annotations...)
+			}
+			continue
+		}
+
 		labels := data.Labels{}
 		for label, value := range val.Tags {
 			labels[label] = value
@@ -174,7 +258,7 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 		frame := data.NewFrameOfFieldTypes(val.Metric, len(val.DataPoints), data.FieldTypeTime, data.FieldTypeFloat64)
 		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti, TypeVersion: data.FrameTypeVersion{0, 1}}
-		frame.RefID = myRefID
+		frame.RefID = ref.RefID
 		timeField := frame.Fields[0]
 		timeField.Name = data.TimeSeriesTimeFieldName
 		dataField := frame.Fields[1]
@@ -183,16 +267,73 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 		points := val.DataPoints
 		for i, point := range points {
-			frame.SetRow(i, time.Unix(int64(point[0]), 0).UTC(), point[1])
+			frame.SetRow(i, parseTimestamp(point[0], msResolution), point[1])
 		}
-		frames = append(frames, frame)
+
+		result := resp.Responses[ref.RefID]
+		result.Frames = append(result.Frames, frame)
+		resp.Responses[ref.RefID] = result
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
+
+	for _, ref := range refs {
+		if ref.Annotation == nil {
+			continue
+		}
+		result := resp.Responses[ref.RefID]
+		result.Frames = append(result.Frames, annotationsToFrame(ref.RefID, annotations[ref.RefID]))
+		resp.Responses[ref.RefID] = result
+	}
+
 	return resp, nil
 }
 
+// parseTimestamp parses the timestamp of a data point, which is in milliseconds when the query asked for
+// millisecond resolution
+func parseTimestamp(ts float64, msResolution bool) time.Time {
+	if msResolution {
+		return time.UnixMilli(int64(ts)).UTC()
+	}
+	return time.Unix(int64(ts), 0).UTC()
+}
+
+// annotationsToFrame converts OpenTSDB annotations to an annotation frame. Every series of a sub query contains the
+// global annotations, so duplicates are removed.
+func annotationsToFrame(refID string, annotations []OpenTsdbAnnotation) *data.Frame {
+	times := make([]time.Time, 0, len(annotations))
+	timeEnds := make([]*time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+	tsuids := make([]string, 0, len(annotations))
+
+	seen := make(map[string]bool, len(annotations))
+	for _, a := range annotations {
+		key := fmt.Sprintf("%s|%d|%d|%s", a.TSUID, a.StartTime, a.EndTime, a.Description)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		times = append(times, time.Unix(a.StartTime, 0).UTC())
+		var timeEnd *time.Time
+		if a.EndTime > 0 {
+			end := time.Unix(a.EndTime, 0).UTC()
+			timeEnd = &end
+		}
+		timeEnds = append(timeEnds, timeEnd)
+		texts = append(texts, a.Description)
+		tsuids = append(tsuids, a.TSUID)
+	}
+
+	frame := data.NewFrame(refID,
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("text", nil, texts),
+		data.NewField("tsuid", nil, tsuids),
+	)
+	frame.RefID = refID
+	frame.Meta = &data.FrameMeta{DataTopic: data.DataTopicAnnotations}
+	return frame
+}
+
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	metric := make(map[string]any)
 
@@ -2,8 +2,11 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -33,7 +36,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A")
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, []queryRef{{RefID: "A"}}, false)
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -68,7 +71,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A")
+		result, err := service.parseResponse(logger, &resp, []queryRef{{RefID: "A"}}, false)
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -110,7 +113,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, myRefid)
+		result, err := service.parseResponse(logger, &resp, []queryRef{{RefID: myRefid}}, false)
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(testFrame, result.Responses[myRefid].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
@@ -118,6 +121,82 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		}
 	})
 
+	t.Run("Parse response maps series to the queries by sub query index", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "dps": [[1405544146, 1.0]], "query": {"index": 1}},
+			{"metric": "mem", "dps": [[1405544146, 2.0]], "query": {"index": 0}},
+			{"metric": "disk", "dps": [[1405544146, 3.0]], "query": {"index": 0}}
+		]`
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, []queryRef{{RefID: "A"}, {RefID: "B"}}, false)
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses["A"].Frames, 2)
+		assert.Equal(t, "mem", result.Responses["A"].Frames[0].Name)
+		assert.Equal(t, "disk", result.Responses["A"].Frames[1].Name)
+		assert.Equal(t, time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC), result.Responses["A"].Frames[1].Fields[0].At(0))
+		require.Len(t, result.Responses["B"].Frames, 1)
+		assert.Equal(t, "cpu", result.Responses["B"].Frames[0].Name)
+		assert.Equal(t, "B", result.Responses["B"].Frames[0].RefID)
+	})
+
+	t.Run("Parse response reads millisecond timestamps with millisecond resolution", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "dps": [[1405544146123, 1.0], [12345, 2.0]]}
+		]`
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, []queryRef{{RefID: "A"}}, true)
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses["A"].Frames, 1)
+		frame := result.Responses["A"].Frames[0]
+		assert.Equal(t, time.Date(2014, 7, 16, 20, 55, 46, 123000000, time.UTC), frame.Fields[0].At(0))
+		assert.Equal(t, time.UnixMilli(12345).UTC(), frame.Fields[0].At(1))
+	})
+
+	t.Run("Parse response returns annotation frames for annotation queries", func(t *testing.T) {
+		response := `
+		[
+			{
+				"metric": "deploys", "dps": [[1405544146, 1.0]], "query": {"index": 0},
+				"annotations": [{"tsuid": "000001", "description": "deploy v1", "startTime": 1405544100, "endTime": 1405544200}],
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405544000}]
+			},
+			{
+				"metric": "deploys", "dps": [[1405544146, 1.0]], "query": {"index": 1},
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405544000}]
+			},
+			{
+				"metric": "deploys", "dps": [[1405544146, 1.0]], "query": {"index": 1},
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405544000}]
+			}
+		]`
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, []queryRef{
+			{RefID: "A", Annotation: &annotationModel{FromAnnotations: true, Target: "deploys"}},
+			{RefID: "B", Annotation: &annotationModel{FromAnnotations: true, Target: "deploys", IsGlobal: true}},
+		}, false)
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses["A"].Frames, 1)
+		metricFrame := result.Responses["A"].Frames[0]
+		assert.Equal(t, data.DataTopicAnnotations, metricFrame.Meta.DataTopic)
+		require.Equal(t, 1, metricFrame.Rows())
+		assert.Equal(t, time.Unix(1405544100, 0).UTC(), metricFrame.Fields[0].At(0))
+		assert.Equal(t, "deploy v1", metricFrame.Fields[2].At(0))
+
+		require.Len(t, result.Responses["B"].Frames, 1)
+		globalFrame := result.Responses["B"].Frames[0]
+		require.Equal(t, 1, globalFrame.Rows())
+		assert.Equal(t, "maintenance", globalFrame.Fields[2].At(0))
+		assert.Nil(t, globalFrame.Fields[1].At(0))
+	})
+
 	t.Run("Build metric with downsampling enabled", func(t *testing.T) {
 		query := backend.DataQuery{
 			JSON: []byte(`
@@ -287,3 +366,63 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
 }
+
+func TestQueryData(t *testing.T) {
+	queries := []backend.DataQuery{
+		{RefID: "A", JSON: []byte(`{"metric": "cpu", "aggregator": "sum"}`)},
+		{RefID: "B", JSON: []byte(`{"metric": "mem", "aggregator": "sum"}`)},
+	}
+
+	for _, version := range []int{0, 1, 2} {
+		t.Run(fmt.Sprintf("OpenTSDB version %d sends a request per query", version), func(t *testing.T) {
+			var requests []OpenTsdbQuery
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var query OpenTsdbQuery
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+				requests = append(requests, query)
+				// older versions return the series without the index of their sub query
+				metric := query.Queries[0]["metric"]
+				_, _ = fmt.Fprintf(w, `[{"metric": %q, "dps": [[1405544146, 1.0]]}]`, metric)
+			}))
+			defer srv.Close()
+
+			service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, TSDBVersion: version}}}
+			result, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries})
+			require.NoError(t, err)
+
+			require.Len(t, requests, 2)
+			for _, request := range requests {
+				assert.Len(t, request.Queries, 1)
+				assert.False(t, request.ShowQuery)
+			}
+			require.Len(t, result.Responses["A"].Frames, 1)
+			assert.Equal(t, "cpu", result.Responses["A"].Frames[0].Name)
+			require.Len(t, result.Responses["B"].Frames, 1)
+			assert.Equal(t, "mem", result.Responses["B"].Frames[0].Name)
+		})
+	}
+
+	t.Run("OpenTSDB 2.3 sends the queries in a single request", func(t *testing.T) {
+		var requests []OpenTsdbQuery
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var query OpenTsdbQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+			requests = append(requests, query)
+			_, _ = w.Write([]byte(`[
+				{"metric": "mem", "dps": [[1405544146, 2.0]], "query": {"index": 1}},
+				{"metric": "cpu", "dps": [[1405544146, 1.0]], "query": {"index": 0}}
+			]`))
+		}))
+		defer srv.Close()
+
+		service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, TSDBVersion: 3}}}
+		result, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		assert.Len(t, requests[0].Queries, 2)
+		assert.True(t, requests[0].ShowQuery)
+		assert.Equal(t, "cpu", result.Responses["A"].Frames[0].Name)
+		assert.Equal(t, "mem", result.Responses["B"].Frames[0].Name)
+	})
+}
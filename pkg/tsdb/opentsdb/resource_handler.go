@@ -0,0 +1,116 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handleResource("api/suggest", []string{"type", "q", "max"}, func(body []byte) (any, error) {
+		var suggestions SuggestResponse
+		err := json.Unmarshal(body, &suggestions)
+		return suggestions, err
+	}))
+	mux.HandleFunc("/api/search/lookup", s.handleResource("api/search/lookup", []string{"m", "limit", "useMeta"}, func(body []byte) (any, error) {
+		var lookup LookupResponse
+		err := json.Unmarshal(body, &lookup)
+		return lookup, err
+	}))
+	return mux
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
+// handleResource forwards the allowed query parameters to the endpoint of the OpenTSDB API with the client of the
+// datasource and writes the typed response
+func (s *Service) handleResource(endpoint string, params []string, parse func(body []byte) (any, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+
+		dsInfo, err := s.getDSInfo(ctx, backend.PluginConfigFromContext(ctx))
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to get datasource: %v", err))
+			return
+		}
+
+		query := url.Values{}
+		for _, param := range params {
+			if v := req.URL.Query().Get(param); v != "" {
+				query.Set(param, v)
+			}
+		}
+
+		u, err := url.Parse(dsInfo.URL)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		u.Path = path.Join(u.Path, endpoint)
+		u.RawQuery = query.Encode()
+
+		tsdbReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to create request: %v", err))
+			return
+		}
+
+		res, err := dsInfo.HTTPClient.Do(tsdbReq)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				logger.Warn("Failed to close response body", "error", err)
+			}
+		}()
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+		if res.StatusCode/100 != 2 {
+			logger.Info("Request failed", "endpoint", endpoint, "status", res.Status, "body", string(body))
+			writeResourceError(rw, res.StatusCode, fmt.Sprintf("request failed, status: %s", res.Status))
+			return
+		}
+
+		result, err := parse(body)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to parse opentsdb response: %v", err))
+			return
+		}
+
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+			return
+		}
+		writeResourceResponse(rw, http.StatusOK, encoded)
+	}
+}
+
+func writeResourceResponse(rw http.ResponseWriter, code int, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, code int, msg string) {
+	body, _ := json.Marshal(map[string]string{"error": msg})
+	writeResourceResponse(rw, code, body)
+}
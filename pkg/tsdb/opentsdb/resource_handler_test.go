@@ -0,0 +1,87 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (f fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.dsInfo, nil
+}
+
+func (f fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestResourceHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/suggest":
+			assert.Equal(t, "tagk", r.URL.Query().Get("type"))
+			assert.Equal(t, "ho", r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(`["host","hostgroup"]`))
+		case "/api/search/lookup":
+			assert.Equal(t, "cpu{host=*}", r.URL.Query().Get("m"))
+			_, _ = w.Write([]byte(`{"type":"LOOKUP","metric":"cpu","limit":1000,"totalResults":1,"results":[{"tsuid":"0001","metric":"cpu","tags":{"host":"web01"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}}}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+
+	t.Run("suggest", func(t *testing.T) {
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/suggest",
+			URL:    "api/suggest?type=tagk&q=ho&max=1000",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.resp.Status)
+
+		var suggestions SuggestResponse
+		require.NoError(t, json.Unmarshal(sender.resp.Body, &suggestions))
+		assert.Equal(t, SuggestResponse{"host", "hostgroup"}, suggestions)
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/search/lookup",
+			URL:    "api/search/lookup?m=cpu%7Bhost%3D%2A%7D&limit=1000",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.resp.Status)
+
+		var lookup LookupResponse
+		require.NoError(t, json.Unmarshal(sender.resp.Body, &lookup))
+		require.Len(t, lookup.Results, 1)
+		assert.Equal(t, map[string]string{"host": "web01"}, lookup.Results[0].Tags)
+	})
+}
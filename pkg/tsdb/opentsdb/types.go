@@ -4,10 +4,49 @@ type OpenTsdbQuery struct {
 	Start   int64            `json:"start"`
 	End     int64            `json:"end"`
 	Queries []map[string]any `json:"queries"`
+	// MsResolution returns the data points with millisecond instead of second timestamps
+	MsResolution bool `json:"msResolution,omitempty"`
+	// ShowQuery adds the sub query, with its index, to each series of the response
+	ShowQuery bool `json:"showQuery,omitempty"`
+	// GlobalAnnotations adds the annotations which are not bound to a series to the response
+	GlobalAnnotations bool `json:"globalAnnotations,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string            `json:"metric"`
-	Tags       map[string]string `json:"tags"`
-	DataPoints [][]float64       `json:"dps"`
+	Metric            string               `json:"metric"`
+	Tags              map[string]string    `json:"tags"`
+	DataPoints        [][]float64          `json:"dps"`
+	Query             *OpenTsdbSubQuery    `json:"query,omitempty"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations,omitempty"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations,omitempty"`
+}
+
+type OpenTsdbSubQuery struct {
+	Index int `json:"index"`
+}
+
+type OpenTsdbAnnotation struct {
+	TSUID       string            `json:"tsuid"`
+	Description string            `json:"description"`
+	Notes       string            `json:"notes"`
+	Custom      map[string]string `json:"custom"`
+	StartTime   int64             `json:"startTime"`
+	EndTime     int64             `json:"endTime"`
+}
+
+type SuggestResponse []string
+
+type LookupResponse struct {
+	Type         string         `json:"type"`
+	Metric       string         `json:"metric"`
+	Limit        int            `json:"limit"`
+	Time         float64        `json:"time"`
+	TotalResults int            `json:"totalResults"`
+	Results      []LookupResult `json:"results"`
+}
+
+type LookupResult struct {
+	TSUID  string            `json:"tsuid"`
+	Metric string            `json:"metric"`
+	Tags   map[string]string `json:"tags"`
 }
@@ -0,0 +1,189 @@
+package parca
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	v1alpha1 "buf.build/gen/go/parca-dev/parca/protocolbuffers/go/parca/query/v1alpha1"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/utils"
+	"github.com/grafana/grafana/pkg/tsdb/parca/kinds/dataquery"
+)
+
+// diffNode is a node of the merged tree of the baseline (left) and the comparison (right) profiles of a diff query
+type diffNode struct {
+	level      int64
+	name       string
+	value      int64
+	self       int64
+	valueRight int64
+	selfRight  int64
+	children   []*diffNode
+
+	childrenByName map[string]*diffNode
+}
+
+func (n *diffNode) child(name string) *diffNode {
+	if child, ok := n.childrenByName[name]; ok {
+		return child
+	}
+	child := &diffNode{level: n.level + 1, name: name}
+	if n.childrenByName == nil {
+		n.childrenByName = map[string]*diffNode{}
+	}
+	n.childrenByName[name] = child
+	n.children = append(n.children, child)
+	return child
+}
+
+// diffTree merges the nodes of flame graphs, visited depth first, into a single tree. Nodes with the same path of
+// names in both profiles are merged into a single node with the values of both profiles.
+type diffTree struct {
+	root *diffNode
+	// path holds the merged nodes of the current branch, indexed by level
+	path []*diffNode
+}
+
+func (t *diffTree) add(name string, level, value, self int64, right bool) {
+	var node *diffNode
+	if level == 0 {
+		if t.root == nil {
+			t.root = &diffNode{name: name}
+		}
+		node = t.root
+		t.path = []*diffNode{node}
+	} else {
+		if level > int64(len(t.path)) {
+			logger.Error("Flame graph skips a level", "level", level, "function", logEntrypoint())
+			return
+		}
+		node = t.path[level-1].child(name)
+		t.path = append(t.path[:level], node)
+	}
+
+	if right {
+		node.valueRight += value
+		node.selfRight += self
+	} else {
+		node.value += value
+		node.self += self
+	}
+}
+
+// addFlamegraph adds the nodes of the flame graph of a profile to the tree
+func (t *diffTree) addFlamegraph(flamegraph *v1alpha1.FlamegraphArrow, right bool) error {
+	arrowReader, err := ipc.NewReader(bytes.NewBuffer(flamegraph.GetRecord()))
+	if err != nil {
+		return err
+	}
+	defer arrowReader.Release()
+
+	arrowReader.Next()
+	fi, err := newFlamegraphIterator(arrowReader.Record())
+	if err != nil {
+		return fmt.Errorf("failed to create flamegraph iterator: %w", err)
+	}
+
+	fi.iterate(func(name string, level, value, self int64) {
+		t.add(name, level, value, self, right)
+	})
+	return nil
+}
+
+// profileSelection returns the label selector and the time range of the baseline or the comparison profile of
+// a diff query, the label selector and the time range of the query are used for the values not set
+func profileSelection(selection *dataquery.ProfileSelection, labelSelector string, timeRange backend.TimeRange) (string, time.Time, time.Time) {
+	start, end := timeRange.From, timeRange.To
+	if selection == nil {
+		return labelSelector, start, end
+	}
+	if selection.LabelSelector != nil && *selection.LabelSelector != "" {
+		labelSelector = *selection.LabelSelector
+	}
+	if selection.From != nil {
+		start = time.UnixMilli(*selection.From)
+	}
+	if selection.To != nil {
+		end = time.UnixMilli(*selection.To)
+	}
+	return labelSelector, start, end
+}
+
+// queryDiff queries the baseline and the comparison profiles concurrently and returns a single flame graph frame
+// with the values of both profiles
+func (d *ParcaDatasource) queryDiff(ctx context.Context, qm queryModel, query backend.DataQuery) (*data.Frame, error) {
+	profileTypeID := utils.Depointerizer(qm.ProfileTypeId)
+	labelSelector := utils.Depointerizer(qm.LabelSelector)
+
+	selections := []*dataquery.ProfileSelection{qm.Baseline, qm.Comparison}
+	flamegraphs := make([]*v1alpha1.FlamegraphArrow, len(selections))
+	g, gCtx := errgroup.WithContext(ctx)
+	for i, selection := range selections {
+		selector, start, end := profileSelection(selection, labelSelector, query.TimeRange)
+		g.Go(func() error {
+			resp, err := d.client.Query(gCtx, makeMergeProfileRequest(profileTypeID, selector, start, end))
+			if err != nil {
+				return err
+			}
+			flameResponse, ok := resp.Msg.Report.(*v1alpha1.QueryResponse_FlamegraphArrow)
+			if !ok {
+				return fmt.Errorf("unknown report type returned from query. update parca")
+			}
+			flamegraphs[i] = flameResponse.FlamegraphArrow
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	tree := &diffTree{}
+	for i, flamegraph := range flamegraphs {
+		if err := tree.addFlamegraph(flamegraph, i == 1); err != nil {
+			return nil, err
+		}
+	}
+	return diffTreeToNestedSetDataFrame(tree.root, normalizeUnit(flamegraphs[1].Unit)), nil
+}
+
+// diffTreeToNestedSetDataFrame walks the tree depth first into the nested set format of arrowToNestedSetDataFrame.
+// The value and self fields hold the values of the baseline profile and the valueRight and selfRight fields the
+// values of the comparison profile, which is the format of diff flame graphs.
+func diffTreeToNestedSetDataFrame(root *diffNode, unit string) *data.Frame {
+	frame := data.NewFrame("response")
+	frame.Meta = &data.FrameMeta{PreferredVisualization: "flamegraph"}
+
+	levelField := data.NewField("level", nil, []int64{})
+	valueField := data.NewField("value", nil, []int64{})
+	selfField := data.NewField("self", nil, []int64{})
+	labelField := data.NewField("label", nil, []string{})
+	valueRightField := data.NewField("valueRight", nil, []int64{})
+	selfRightField := data.NewField("selfRight", nil, []int64{})
+	for _, field := range []*data.Field{valueField, selfField, valueRightField, selfRightField} {
+		field.Config = &data.FieldConfig{Unit: unit}
+	}
+	frame.Fields = data.Fields{levelField, valueField, selfField, labelField, valueRightField, selfRightField}
+
+	if root == nil {
+		return frame
+	}
+	stack := []*diffNode{root}
+	for len(stack) > 0 {
+		node := stack[0]
+		stack = append(append([]*diffNode{}, node.children...), stack[1:]...)
+
+		levelField.Append(node.level)
+		valueField.Append(node.value)
+		selfField.Append(node.self)
+		labelField.Append(node.name)
+		valueRightField.Append(node.valueRight)
+		selfRightField.Append(node.selfRight)
+	}
+	return frame
+}
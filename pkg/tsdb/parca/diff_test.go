@@ -0,0 +1,82 @@
+package parca
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_queryDiff(t *testing.T) {
+	client := &FakeClient{}
+	ds := &ParcaDatasource{
+		client: client,
+	}
+
+	dataQuery := backend.DataQuery{
+		RefID:     "A",
+		QueryType: queryTypeDiff,
+		TimeRange: backend.TimeRange{
+			From: time.UnixMilli(10000),
+			To:   time.UnixMilli(20000),
+		},
+		JSON: []byte(`{"profileTypeId":"foo:bar","labelSelector":"{app=\"baz\"}","comparison":{"from":30000,"to":40000}}`),
+	}
+
+	resp := ds.query(context.Background(), backend.PluginContext{}, dataQuery)
+	require.Nil(t, resp.Error)
+	require.Equal(t, 1, len(resp.Frames))
+
+	frame := resp.Frames[0]
+	require.Equal(t, data.NewField("level", nil, []int64{0, 1, 2, 3, 4, 4}), frame.Fields[0])
+	require.Equal(t, data.NewField("label", nil, []string{"total", "[a] 1", "2", "[a] 3", "[a] 4", "[a] 5"}), frame.Fields[3])
+
+	values := data.NewField("value", nil, []int64{11, 11, 11, 8, 3, 5})
+	values.Config = &data.FieldConfig{Unit: "ns"}
+	require.Equal(t, values, frame.Fields[1])
+	valuesRight := data.NewField("valueRight", nil, []int64{11, 11, 11, 8, 3, 5})
+	valuesRight.Config = &data.FieldConfig{Unit: "ns"}
+	require.Equal(t, valuesRight, frame.Fields[4])
+}
+
+func Test_profileSelection(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.UnixMilli(10000), To: time.UnixMilli(20000)}
+
+	selector, start, end := profileSelection(nil, "{}", timeRange)
+	require.Equal(t, "{}", selector)
+	require.Equal(t, timeRange.From, start)
+	require.Equal(t, timeRange.To, end)
+
+	request := makeMergeProfileRequest("foo:bar", `{app="baz"}`, time.UnixMilli(30000), time.UnixMilli(40000))
+	require.Equal(t, `foo:bar{app="baz"}`, request.Msg.GetMerge().Query)
+	require.Equal(t, int64(30), request.Msg.GetMerge().Start.Seconds)
+	require.Equal(t, int64(40), request.Msg.GetMerge().End.Seconds)
+}
+
+func Test_diffTreeToNestedSetDataFrame(t *testing.T) {
+	tree := &diffTree{}
+	// baseline
+	tree.add("total", 0, 100, 0, false)
+	tree.add("func1", 1, 60, 60, false)
+	tree.add("func2", 1, 40, 10, false)
+	tree.add("func3", 2, 30, 30, false)
+	// comparison
+	tree.add("total", 0, 80, 0, true)
+	tree.add("func2", 1, 50, 20, true)
+	tree.add("func4", 2, 30, 30, true)
+	tree.add("func5", 1, 30, 30, true)
+
+	frame := diffTreeToNestedSetDataFrame(tree.root, "short")
+	require.Equal(t,
+		[]*data.Field{
+			data.NewField("level", nil, []int64{0, 1, 1, 2, 2, 1}),
+			data.NewField("value", nil, []int64{100, 60, 40, 30, 0, 0}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("self", nil, []int64{0, 60, 10, 30, 0, 0}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("label", nil, []string{"total", "func1", "func2", "func3", "func4", "func5"}),
+			data.NewField("valueRight", nil, []int64{80, 0, 50, 0, 30, 30}).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("selfRight", nil, []int64{0, 0, 20, 0, 30, 30}).SetConfig(&data.FieldConfig{Unit: "short"}),
+		}, frame.Fields)
+}
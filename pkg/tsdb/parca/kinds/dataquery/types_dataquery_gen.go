@@ -12,6 +12,7 @@ package dataquery
 // Defines values for ParcaQueryType.
 const (
 	ParcaQueryTypeBoth    ParcaQueryType = "both"
+	ParcaQueryTypeDiff    ParcaQueryType = "diff"
 	ParcaQueryTypeMetrics ParcaQueryType = "metrics"
 	ParcaQueryTypeProfile ParcaQueryType = "profile"
 )
@@ -41,6 +42,12 @@ type DataQuery struct {
 
 // ParcaDataQuery defines model for ParcaDataQuery.
 type ParcaDataQuery struct {
+	// Selects the baseline profile of diff queries, the label selector and the time range of the query are used when not set.
+	Baseline *ProfileSelection `json:"baseline,omitempty"`
+
+	// Selects the comparison profile of diff queries, the label selector and the time range of the query are used when not set.
+	Comparison *ProfileSelection `json:"comparison,omitempty"`
+
 	// For mixed data sources the selected datasource is on the query level.
 	// For non mixed scenarios this is undefined.
 	// TODO find a better way to do this ^ that's friendly to schema
@@ -68,3 +75,15 @@ type ParcaDataQuery struct {
 
 // ParcaQueryType defines model for ParcaQueryType.
 type ParcaQueryType string
+
+// ProfileSelection Selects a profile by its label selector and time range.
+type ProfileSelection struct {
+	// Start of the time range of the profile in milliseconds since epoch.
+	From *int64 `json:"from,omitempty"`
+
+	// Specifies the label selectors of the profile.
+	LabelSelector *string `json:"labelSelector,omitempty"`
+
+	// End of the time range of the profile in milliseconds since epoch.
+	To *int64 `json:"to,omitempty"`
+}
@@ -34,6 +34,7 @@ const (
 	queryTypeProfile = string(dataquery.ParcaQueryTypeProfile)
 	queryTypeMetrics = string(dataquery.ParcaQueryTypeMetrics)
 	queryTypeBoth    = string(dataquery.ParcaQueryTypeBoth)
+	queryTypeDiff    = string(dataquery.ParcaQueryTypeDiff)
 )
 
 // query processes single Parca query transforming the response to data.Frame packaged in DataResponse
@@ -93,21 +94,43 @@ func (d *ParcaDatasource) query(ctx context.Context, pCtx backend.PluginContext,
 		response.Frames = append(response.Frames, frame)
 	}
 
+	if query.QueryType == queryTypeDiff {
+		ctxLogger.Debug("Querying SelectMergeStacktraces() of the baseline and the comparison", "queryModel", qm, "function", logEntrypoint())
+		frame, err := d.queryDiff(ctx, qm, query)
+		if err != nil {
+			if strings.Contains(err.Error(), "invalid report type") {
+				response.Error = fmt.Errorf("try updating Parca to v0.19+: %v", err)
+			} else {
+				response.Error = err
+			}
+
+			ctxLogger.Error("Failed to process query", "error", err, "queryType", query.QueryType, "function", logEntrypoint())
+			span.RecordError(response.Error)
+			span.SetStatus(codes.Error, response.Error.Error())
+			return response
+		}
+		response.Frames = append(response.Frames, frame)
+	}
+
 	return response
 }
 
 func makeProfileRequest(qm queryModel, query backend.DataQuery) *connect.Request[v1alpha1.QueryRequest] {
+	return makeMergeProfileRequest(utils.Depointerizer(qm.ProfileTypeId), utils.Depointerizer(qm.LabelSelector), query.TimeRange.From, query.TimeRange.To)
+}
+
+func makeMergeProfileRequest(profileTypeID, labelSelector string, start, end time.Time) *connect.Request[v1alpha1.QueryRequest] {
 	return &connect.Request[v1alpha1.QueryRequest]{
 		Msg: &v1alpha1.QueryRequest{
 			Mode: v1alpha1.QueryRequest_MODE_MERGE,
 			Options: &v1alpha1.QueryRequest_Merge{
 				Merge: &v1alpha1.MergeProfile{
-					Query: fmt.Sprintf("%s%s", utils.Depointerizer(qm.ProfileTypeId), utils.Depointerizer(qm.LabelSelector)),
+					Query: fmt.Sprintf("%s%s", profileTypeID, labelSelector),
 					Start: &timestamppb.Timestamp{
-						Seconds: query.TimeRange.From.Unix(),
+						Seconds: start.Unix(),
 					},
 					End: &timestamppb.Timestamp{
-						Seconds: query.TimeRange.To.Unix(),
+						Seconds: end.Unix(),
 					},
 				},
 			},
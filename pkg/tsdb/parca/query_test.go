@@ -3,6 +3,7 @@ package parca
 import (
 	"bytes"
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -319,6 +320,7 @@ func flamegraphResponse() *connect.Response[v1alpha1.QueryResponse] {
 
 type FakeClient struct {
 	Req *connect.Request[v1alpha1.QueryRequest]
+	mu  sync.Mutex
 }
 
 func (f *FakeClient) QueryRange(ctx context.Context, c *connect.Request[v1alpha1.QueryRangeRequest]) (*connect.Response[v1alpha1.QueryRangeResponse], error) {
@@ -326,6 +328,8 @@ func (f *FakeClient) QueryRange(ctx context.Context, c *connect.Request[v1alpha1
 }
 
 func (f *FakeClient) Query(ctx context.Context, c *connect.Request[v1alpha1.QueryRequest]) (*connect.Response[v1alpha1.QueryResponse], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.Req = c
 	return flamegraphResponse(), nil
 }
@@ -0,0 +1,145 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryRangeResponse is the response of the Tempo TraceQL metrics query_range API
+type queryRangeResponse struct {
+	Series []timeSeries `json:"series"`
+}
+
+type timeSeries struct {
+	Labels  []keyValue `json:"labels"`
+	Samples []sample   `json:"samples"`
+}
+
+type sample struct {
+	TimestampMs int64String `json:"timestampMs"`
+	Value       float64     `json:"value"`
+}
+
+// queryRange executes TraceQL metrics queries and returns a time series frame for each series of the result
+func (s *Service) queryRange(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Querying TraceQL metrics", "function", logEntrypoint())
+
+	result := &backend.DataResponse{}
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.queryRange", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model, err := unmarshalTempoQuery(query.JSON)
+	if err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("q", *model.Query)
+	params.Set("start", strconv.FormatInt(query.TimeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(query.TimeRange.To.Unix(), 10))
+	if model.Step != nil && *model.Step != "" {
+		params.Set("step", *model.Step)
+	}
+
+	body, err := s.getTempoJSON(ctx, dsInfo, "/api/metrics/query_range", params)
+	if err != nil {
+		ctxLogger.Error("Failed to query TraceQL metrics", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		result.Error = err
+		return result, nil
+	}
+
+	var res queryRangeResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		ctxLogger.Error("Failed to unmarshal query range response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to unmarshal query range response: %w", err)
+	}
+	span.SetAttributes(attribute.Int("series_count", len(res.Series)))
+
+	result.Frames = seriesToFrames(query.RefID, *model.Query, res.Series)
+	ctxLogger.Debug("Successfully queried TraceQL metrics", "function", logEntrypoint())
+	return result, nil
+}
+
+// seriesToFrames returns a frame with a time and a value field for each series. The value field has the labels
+// of the series, its display name is the value of the only label, the labels of the series or the query
+// of a single series without labels, like in the frontend.
+func seriesToFrames(refID, query string, series []timeSeries) data.Frames {
+	frames := make(data.Frames, 0, len(series))
+	for _, ts := range series {
+		labels := data.Labels{}
+		pairs := make([]string, 0, len(ts.Labels))
+		for _, l := range ts.Labels {
+			labels[l.Key] = l.Value.String()
+			pairs = append(pairs, fmt.Sprintf("%s=%s", l.Key, l.Value.String()))
+		}
+
+		name := ""
+		switch {
+		case len(ts.Labels) == 1:
+			name = ts.Labels[0].Value.String()
+		case len(ts.Labels) > 1:
+			name = "{" + strings.Join(pairs, ", ") + "}"
+		case len(series) == 1:
+			name = query
+		}
+
+		samples := make([]sample, len(ts.Samples))
+		copy(samples, ts.Samples)
+		sort.SliceStable(samples, func(i, j int) bool {
+			return samples[i].TimestampMs < samples[j].TimestampMs
+		})
+
+		times := make([]time.Time, 0, len(samples))
+		values := make([]float64, 0, len(samples))
+		for _, smp := range samples {
+			times = append(times, time.UnixMilli(int64(smp.TimestampMs)))
+			values = append(values, smp.Value)
+		}
+
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, values)
+		if name != "" {
+			valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
+		}
+		frame := data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			valueField,
+		)
+		frame.RefID = refID
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeTimeSeriesMulti,
+			TypeVersion:            data.FrameTypeVersion{0, 1},
+			PreferredVisualization: data.VisTypeGraph,
+			ExecutedQueryString:    query,
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
@@ -0,0 +1,68 @@
+package tempo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRange(t *testing.T) {
+	t.Run("metrics query returns a time series for each series", func(t *testing.T) {
+		var path, q, step string
+		s := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+			q = req.URL.Query().Get("q")
+			step = req.URL.Query().Get("step")
+			_, _ = rw.Write([]byte(`{
+				"series": [
+					{
+						"labels": [{"key": "resource.service.name", "value": {"stringValue": "frontend"}}],
+						"samples": [{"timestampMs": "1700000060000", "value": 2}, {"timestampMs": "1700000000000", "value": 1.5}]
+					},
+					{
+						"labels": [{"key": "resource.service.name", "value": {"stringValue": "backend"}}, {"key": "span.http.status_code", "value": {"intValue": "500"}}],
+						"samples": [{"timestampMs": "1700000000000", "value": 0.5}]
+					}
+				]
+			}`))
+		})
+
+		query := `{ status = error } | rate() by (resource.service.name)`
+		res := queryTempo(t, s, string(dataquery.TempoQueryTypeTraceql), map[string]any{"query": query, "step": "1m"})
+		require.NoError(t, res.Error)
+		assert.Equal(t, "/api/metrics/query_range", path)
+		assert.Equal(t, query, q)
+		assert.Equal(t, "1m", step)
+
+		require.Len(t, res.Frames, 2)
+		frame := res.Frames[0]
+		assert.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		assert.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.UnixMilli(1700000000000), frame.Fields[0].At(0))
+		assert.Equal(t, 1.5, frame.Fields[1].At(0))
+		assert.Equal(t, data.Labels{"resource.service.name": "frontend"}, frame.Fields[1].Labels)
+		assert.Equal(t, "frontend", frame.Fields[1].Config.DisplayNameFromDS)
+
+		frame = res.Frames[1]
+		assert.Equal(t, data.Labels{"resource.service.name": "backend", "span.http.status_code": "500"}, frame.Fields[1].Labels)
+		assert.Equal(t, "{resource.service.name=backend, span.http.status_code=500}", frame.Fields[1].Config.DisplayNameFromDS)
+	})
+
+	t.Run("a single series without labels is named after the query", func(t *testing.T) {
+		s := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+			assert.False(t, req.URL.Query().Has("step"))
+			_, _ = rw.Write([]byte(`{"series": [{"samples": [{"timestampMs": "1700000000000", "value": 3}]}]}`))
+		})
+
+		query := `{} | count_over_time()`
+		res := queryTempo(t, s, string(dataquery.TempoQueryTypeTraceql), map[string]any{"query": query})
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, query, res.Frames[0].Fields[1].Config.DisplayNameFromDS)
+	})
+}
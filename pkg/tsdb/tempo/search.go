@@ -0,0 +1,260 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultSearchLimit = 20
+	defaultSearchSpss  = 3
+)
+
+// searchResponse is the response of the Tempo search API
+type searchResponse struct {
+	Traces []traceSearchMetadata `json:"traces"`
+}
+
+type traceSearchMetadata struct {
+	TraceID           string      `json:"traceID"`
+	RootServiceName   string      `json:"rootServiceName"`
+	RootTraceName     string      `json:"rootTraceName"`
+	StartTimeUnixNano int64String `json:"startTimeUnixNano"`
+	DurationMs        *float64    `json:"durationMs"`
+	// SpanSet is the only span set of responses of older Tempo versions
+	SpanSet  *spanSet  `json:"spanSet"`
+	SpanSets []spanSet `json:"spanSets"`
+}
+
+type spanSet struct {
+	Spans []spanSearchMetadata `json:"spans"`
+}
+
+type spanSearchMetadata struct {
+	SpanID            string      `json:"spanID"`
+	Name              string      `json:"name"`
+	StartTimeUnixNano int64String `json:"startTimeUnixNano"`
+	DurationNanos     int64String `json:"durationNanos"`
+	Attributes        []keyValue  `json:"attributes"`
+}
+
+// spanSets returns the span sets of the trace from the response of any Tempo version
+func (t traceSearchMetadata) spanSets() []spanSet {
+	if len(t.SpanSets) > 0 {
+		return t.SpanSets
+	}
+	if t.SpanSet != nil {
+		return []spanSet{*t.SpanSet}
+	}
+	return nil
+}
+
+// search executes TraceQL search queries, the query of the traceqlSearch query type is built from its filters.
+// Depending on the table type the traces are returned as a table of traces and a table of their matching spans,
+// as a table of spans only or as the raw response.
+func (s *Service) search(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Searching traces", "function", logEntrypoint())
+
+	result := &backend.DataResponse{}
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.search", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model, err := unmarshalTempoQuery(query.JSON)
+	if err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	traceQL := ""
+	if model.Query != nil {
+		traceQL = *model.Query
+	}
+	if query.QueryType == string(dataquery.TempoQueryTypeTraceqlSearch) {
+		traceQL = queryFromFilters(model.Filters)
+	}
+	if traceQL == "" {
+		result.Error = fmt.Errorf("TraceQL query is required")
+		return result, nil
+	}
+
+	params := url.Values{}
+	params.Set("q", traceQL)
+	params.Set("limit", strconv.FormatInt(valueOrDefault(model.Limit, defaultSearchLimit), 10))
+	params.Set("spss", strconv.FormatInt(valueOrDefault(model.Spss, defaultSearchSpss), 10))
+	params.Set("start", strconv.FormatInt(query.TimeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(query.TimeRange.To.Unix(), 10))
+
+	body, err := s.getTempoJSON(ctx, dsInfo, "/api/search", params)
+	if err != nil {
+		ctxLogger.Error("Failed to search traces", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		result.Error = err
+		return result, nil
+	}
+
+	var res searchResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		ctxLogger.Error("Failed to unmarshal search response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to unmarshal search response: %w", err)
+	}
+	span.SetAttributes(attribute.Int("traces_count", len(res.Traces)))
+
+	tableType := dataquery.SearchTableTypeTraces
+	if model.TableType != nil {
+		tableType = *model.TableType
+	}
+
+	var frames data.Frames
+	switch tableType {
+	case dataquery.SearchTableTypeRaw:
+		frames = data.Frames{rawSearchFrame(body)}
+	case dataquery.SearchTableTypeSpans:
+		frames = data.Frames{spansFrame(res.Traces)}
+	default:
+		frames = data.Frames{tracesFrame(res.Traces), spansFrame(res.Traces)}
+	}
+	for _, frame := range frames {
+		frame.RefID = query.RefID
+		frame.Meta.ExecutedQueryString = traceQL
+	}
+	result.Frames = frames
+
+	ctxLogger.Debug("Successfully searched traces", "function", logEntrypoint())
+	return result, nil
+}
+
+// tracesFrame returns a table of the traces, the most recent trace first
+func tracesFrame(traces []traceSearchMetadata) *data.Frame {
+	traces = sortTracesByStartTime(traces)
+
+	traceIDs := make([]string, 0, len(traces))
+	startTimes := make([]time.Time, 0, len(traces))
+	services := make([]string, 0, len(traces))
+	names := make([]string, 0, len(traces))
+	durations := make([]*float64, 0, len(traces))
+	for _, t := range traces {
+		traceIDs = append(traceIDs, t.TraceID)
+		startTimes = append(startTimes, time.Unix(0, int64(t.StartTimeUnixNano)))
+		services = append(services, t.RootServiceName)
+		names = append(names, t.RootTraceName)
+		durations = append(durations, t.DurationMs)
+	}
+
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, traceIDs).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace ID"}),
+		data.NewField("startTime", nil, startTimes).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("traceService", nil, services).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Service"}),
+		data.NewField("traceName", nil, names).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Name"}),
+		data.NewField("traceDuration", nil, durations).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+	return frame
+}
+
+// spansFrame returns a table of the matching spans of the traces with a column for each of their attributes
+func spansFrame(traces []traceSearchMetadata) *data.Frame {
+	traces = sortTracesByStartTime(traces)
+
+	traceIDs := []string{}
+	services := []string{}
+	traceNames := []string{}
+	spanIDs := []string{}
+	startTimes := []time.Time{}
+	names := []string{}
+	durations := []int64{}
+
+	attributes := map[string]*data.Field{}
+	attributeFields := []*data.Field{}
+	rows := 0
+	for _, t := range traces {
+		for _, set := range t.spanSets() {
+			for _, s := range set.Spans {
+				traceIDs = append(traceIDs, t.TraceID)
+				services = append(services, t.RootServiceName)
+				traceNames = append(traceNames, t.RootTraceName)
+				spanIDs = append(spanIDs, s.SpanID)
+				startTimes = append(startTimes, time.Unix(0, int64(s.StartTimeUnixNano)))
+				names = append(names, s.Name)
+				durations = append(durations, int64(s.DurationNanos))
+
+				for _, attr := range s.Attributes {
+					field, ok := attributes[attr.Key]
+					if !ok {
+						field = data.NewFieldFromFieldType(data.FieldTypeNullableString, rows+1)
+						field.Name = attr.Key
+						attributes[attr.Key] = field
+						attributeFields = append(attributeFields, field)
+					}
+					field.Extend(rows + 1 - field.Len())
+					value := attr.Value.String()
+					field.Set(rows, &value)
+				}
+				rows++
+			}
+		}
+	}
+
+	frame := data.NewFrame("Spans",
+		data.NewField("traceID", nil, traceIDs).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace ID"}),
+		data.NewField("traceService", nil, services).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace service"}),
+		data.NewField("traceName", nil, traceNames).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace name"}),
+		data.NewField("spanID", nil, spanIDs).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Span ID"}),
+		data.NewField("time", nil, startTimes).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("name", nil, names).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Name"}),
+		data.NewField("duration", nil, durations).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ns"}),
+	)
+	for _, field := range attributeFields {
+		field.Extend(rows - field.Len())
+		frame.Fields = append(frame.Fields, field)
+	}
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+	return frame
+}
+
+func rawSearchFrame(body []byte) *data.Frame {
+	frame := data.NewFrame("Raw response", data.NewField("response", nil, []string{string(body)}))
+	frame.Meta = &data.FrameMeta{}
+	return frame
+}
+
+func sortTracesByStartTime(traces []traceSearchMetadata) []traceSearchMetadata {
+	sorted := make([]traceSearchMetadata, len(traces))
+	copy(sorted, traces)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTimeUnixNano > sorted[j].StartTimeUnixNano
+	})
+	return sorted
+}
+
+func valueOrDefault(v *int64, def int64) int64 {
+	if v == nil || *v <= 0 {
+		return def
+	}
+	return *v
+}
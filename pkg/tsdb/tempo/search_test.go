@@ -0,0 +1,170 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const searchResponseBody = `{
+	"traces": [
+		{
+			"traceID": "1a2b",
+			"rootServiceName": "frontend",
+			"rootTraceName": "GET /",
+			"startTimeUnixNano": "1700000000000000000",
+			"durationMs": 12,
+			"spanSets": [{
+				"spans": [
+					{"spanID": "s1", "name": "GET /", "startTimeUnixNano": "1700000000000000000", "durationNanos": "12000000", "attributes": [{"key": "http.status_code", "value": {"intValue": "500"}}]},
+					{"spanID": "s2", "name": "query", "startTimeUnixNano": "1700000000001000000", "durationNanos": "3000000"}
+				]
+			}]
+		},
+		{
+			"traceID": "3c4d",
+			"rootServiceName": "backend",
+			"rootTraceName": "POST /api",
+			"startTimeUnixNano": "1700000060000000000",
+			"durationMs": 5,
+			"spanSet": {
+				"spans": [
+					{"spanID": "s3", "name": "POST /api", "startTimeUnixNano": "1700000060000000000", "durationNanos": "5000000", "attributes": [{"key": "db.system", "value": {"stringValue": "mysql"}}]}
+				]
+			}
+		}
+	]
+}`
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Service{
+		logger: backend.NewLoggerWith("logger", "tempo-test"),
+		im: datasource.NewInstanceManager(func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &Datasource{HTTPClient: srv.Client(), URL: srv.URL}, nil
+		}),
+	}
+}
+
+func queryTempo(t *testing.T, s *Service, queryType string, model any) backend.DataResponse {
+	t.Helper()
+	raw, err := json.Marshal(model)
+	require.NoError(t, err)
+	res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			QueryType: queryType,
+			JSON:      raw,
+			TimeRange: backend.TimeRange{From: time.Unix(1700000000, 0), To: time.Unix(1700003600, 0)},
+		}},
+	})
+	require.NoError(t, err)
+	return res.Responses["A"]
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("search returns trace and span tables", func(t *testing.T) {
+		var params map[string]string
+		s := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "/api/search", req.URL.Path)
+			params = map[string]string{}
+			for k := range req.URL.Query() {
+				params[k] = req.URL.Query().Get(k)
+			}
+			_, _ = rw.Write([]byte(searchResponseBody))
+		})
+
+		res := queryTempo(t, s, string(dataquery.TempoQueryTypeTraceql), map[string]any{"query": `{ status = error }`, "limit": 10})
+		require.NoError(t, res.Error)
+		assert.Equal(t, map[string]string{"q": `{ status = error }`, "limit": "10", "spss": "3", "start": "1700000000", "end": "1700003600"}, params)
+
+		require.Len(t, res.Frames, 2)
+		traces := res.Frames[0]
+		assert.Equal(t, "Traces", traces.Name)
+		assert.Equal(t, 2, traces.Rows())
+		// the most recent trace first
+		assert.Equal(t, "3c4d", traces.Fields[0].At(0))
+		assert.Equal(t, "backend", traces.Fields[2].At(0))
+		assert.Equal(t, time.Unix(1700000000, 0), traces.Fields[1].At(1))
+
+		spans := res.Frames[1]
+		assert.Equal(t, "Spans", spans.Name)
+		assert.Equal(t, 3, spans.Rows())
+		assert.Equal(t, "s3", spans.Fields[3].At(0))
+		assert.Equal(t, int64(12000000), spans.Fields[6].At(1))
+
+		dbSystem, _ := spans.FieldByName("db.system")
+		require.NotNil(t, dbSystem)
+		assert.Equal(t, "mysql", *(dbSystem.At(0).(*string)))
+		assert.Nil(t, dbSystem.At(1))
+		statusCode, _ := spans.FieldByName("http.status_code")
+		require.NotNil(t, statusCode)
+		assert.Equal(t, "500", *(statusCode.At(1).(*string)))
+		assert.Nil(t, statusCode.At(2))
+	})
+
+	t.Run("search with spans table type returns the span table only", func(t *testing.T) {
+		s := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte(searchResponseBody))
+		})
+
+		res := queryTempo(t, s, string(dataquery.TempoQueryTypeTraceql), map[string]any{"query": `{}`, "tableType": "spans"})
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, "Spans", res.Frames[0].Name)
+	})
+
+	t.Run("traceqlSearch builds the query from the filters", func(t *testing.T) {
+		var q string
+		s := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+			q = req.URL.Query().Get("q")
+			_, _ = rw.Write([]byte(`{"traces": []}`))
+		})
+
+		res := queryTempo(t, s, string(dataquery.TempoQueryTypeTraceqlSearch), map[string]any{
+			"filters": []map[string]any{
+				{"id": "service-name", "tag": "service.name", "operator": "=", "value": []string{"frontend"}, "valueType": "string", "scope": "resource"},
+				{"id": "status", "tag": "status", "operator": "=", "value": "error", "valueType": "keyword"},
+				{"id": "min-duration", "tag": "duration", "operator": ">", "value": "100ms", "valueType": "duration"},
+				{"id": "duration-type", "value": "trace"},
+				{"id": "empty", "tag": "http.method", "operator": "="},
+			},
+		})
+		require.NoError(t, res.Error)
+		assert.Equal(t, `{resource.service.name="frontend" && status=error && traceDuration>100ms}`, q)
+		require.Len(t, res.Frames, 2)
+		assert.Equal(t, 0, res.Frames[0].Rows())
+	})
+
+	t.Run("search errors are returned in the response", func(t *testing.T) {
+		s := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte("invalid TraceQL query"))
+		})
+
+		res := queryTempo(t, s, string(dataquery.TempoQueryTypeTraceql), map[string]any{"query": `{ foo`})
+		require.Error(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "invalid TraceQL query")
+	})
+}
+
+func TestIsTraceQLQuery(t *testing.T) {
+	assert.True(t, isTraceIDQuery("1a2b3c"))
+	assert.False(t, isTraceIDQuery(`{ status = error }`))
+	assert.True(t, isMetricsQuery(`{ status = error } | rate() by (resource.service.name)`))
+	assert.True(t, isMetricsQuery(`{} | quantile_over_time(duration, .99)`))
+	assert.False(t, isMetricsQuery(`{ status = error } | select(span.http.method)`))
+}
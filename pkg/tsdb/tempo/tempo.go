@@ -109,8 +109,24 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 }
 
 func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
-	if query.QueryType == string(dataquery.TempoQueryTypeTraceId) {
+	switch query.QueryType {
+	case string(dataquery.TempoQueryTypeTraceId):
 		return s.getTrace(ctx, pCtx, query)
+	case string(dataquery.TempoQueryTypeTraceql):
+		model, err := unmarshalTempoQuery(query.JSON)
+		if err != nil {
+			return nil, err
+		}
+		// the TraceQL query type is also used for trace IDs, like in the frontend
+		switch {
+		case model.Query != nil && isTraceIDQuery(*model.Query):
+			return s.getTrace(ctx, pCtx, query)
+		case model.Query != nil && isMetricsQuery(*model.Query):
+			return s.queryRange(ctx, pCtx, query)
+		}
+		return s.search(ctx, pCtx, query)
+	case string(dataquery.TempoQueryTypeTraceqlSearch):
+		return s.search(ctx, pCtx, query)
 	}
 	return nil, fmt.Errorf("unsupported query type: '%s' for query with refID '%s'", query.QueryType, query.RefID)
 }
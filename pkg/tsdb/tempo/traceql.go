@@ -0,0 +1,198 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+// metricsFunctionRegex matches the metrics functions of TraceQL, queries piping spans into one of them
+// are metrics queries
+var metricsFunctionRegex = regexp.MustCompile(`\|\s*(rate|count_over_time|avg_over_time|max_over_time|min_over_time|quantile_over_time|histogram_over_time|compare)\s*\(`)
+
+// traceIDRegex matches queries of hex characters only, those are trace IDs rather than TraceQL queries
+var traceIDRegex = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+// intrinsics are the TraceQL intrinsic fields, they are not prefixed with a scope
+var intrinsics = []string{
+	"duration",
+	"kind",
+	"name",
+	"rootName",
+	"rootServiceName",
+	"status",
+	"statusMessage",
+	"traceDuration",
+}
+
+func isTraceIDQuery(query string) bool {
+	return traceIDRegex.MatchString(strings.TrimSpace(query))
+}
+
+func isMetricsQuery(query string) bool {
+	return metricsFunctionRegex.MatchString(strings.TrimSpace(query))
+}
+
+// queryFromFilters builds the TraceQL query of the search query editor from its filters, in the same way
+// as the frontend does
+func queryFromFilters(filters []dataquery.TraceqlFilter) string {
+	conditions := []string{}
+	for _, f := range filters {
+		if f.Tag == nil || *f.Tag == "" || f.Operator == nil || *f.Operator == "" {
+			continue
+		}
+		value, ok := filterValue(f)
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, filterScope(f)+filterTag(f, filters)+*f.Operator+value)
+	}
+	return "{" + strings.Join(conditions, " && ") + "}"
+}
+
+func filterValue(f dataquery.TraceqlFilter) (string, bool) {
+	if f.Value == nil {
+		return "", false
+	}
+	var values []string
+	switch v := (*f.Value).(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+	case []string:
+		values = v
+	}
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		return "", false
+	}
+	if len(values) > 1 {
+		return `"` + strings.Join(values, "|") + `"`, true
+	}
+	if f.ValueType != nil && *f.ValueType == "string" {
+		return `"` + values[0] + `"`, true
+	}
+	return values[0], true
+}
+
+func filterScope(f dataquery.TraceqlFilter) string {
+	if slices.Contains(intrinsics, *f.Tag) {
+		return ""
+	}
+	if f.Scope != nil && (*f.Scope == dataquery.TraceqlSearchScopeResource || *f.Scope == dataquery.TraceqlSearchScopeSpan) {
+		return string(*f.Scope) + "."
+	}
+	return "."
+}
+
+func filterTag(f dataquery.TraceqlFilter, filters []dataquery.TraceqlFilter) string {
+	if *f.Tag != "duration" {
+		return *f.Tag
+	}
+	for _, durationType := range filters {
+		if durationType.Id == "duration-type" {
+			if durationType.Value != nil && *durationType.Value == "trace" {
+				return "traceDuration"
+			}
+			return "duration"
+		}
+	}
+	return *f.Tag
+}
+
+// getTempoJSON sends a GET request for the path of the Tempo HTTP API and returns the body of the response.
+// Responses with a status other than 200 are returned as an error with the status and the body.
+func (s *Service) getTempoJSON(ctx context.Context, dsInfo *Datasource, path string, params url.Values) ([]byte, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	request, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s?%s", dsInfo.URL, path, params.Encode()), nil)
+	if err != nil {
+		ctxLogger.Error("Failed to create request", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	resp, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "function", logEntrypoint())
+		return nil, fmt.Errorf("failed get to tempo: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctxLogger.Error("Failed to read response body", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed, Status: %s Body: %s", path, resp.Status, string(body))
+	}
+	return body, nil
+}
+
+// int64String is an int64 of the JSON responses of Tempo, which encodes 64 bit integers as strings
+type int64String int64
+
+func (i *int64String) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s: %w", string(b), err)
+	}
+	*i = int64String(v)
+	return nil
+}
+
+// keyValue is an attribute or a label of the Tempo responses
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	IntValue    *int64String `json:"intValue,omitempty"`
+	DoubleValue *float64     `json:"doubleValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+}
+
+func (v anyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	}
+	return ""
+}
+
+func unmarshalTempoQuery(raw json.RawMessage) (*dataquery.TempoQuery, error) {
+	model := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(raw, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
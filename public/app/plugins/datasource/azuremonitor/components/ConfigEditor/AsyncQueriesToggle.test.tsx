@@ -0,0 +1,29 @@
+import { render, screen } from '@testing-library/react';
+
+import { createMockInstanceSetttings } from '../../__mocks__/instanceSettings';
+
+import { AsyncQueriesToggle, Props } from './AsyncQueriesToggle';
+
+const mockInstanceSettings = createMockInstanceSetttings();
+
+const defaultProps: Props = {
+  options: mockInstanceSettings.jsonData,
+  onAsyncQueriesChange: jest.fn(),
+};
+
+describe('AsyncQueriesToggle', () => {
+  it('should render component', () => {
+    render(<AsyncQueriesToggle {...defaultProps} />);
+
+    expect(screen.getByText('Async Log Analytics queries')).toBeInTheDocument();
+    expect(screen.queryByText('Async query timeout')).not.toBeInTheDocument();
+  });
+
+  it('should render the timeout when enabled', () => {
+    render(
+      <AsyncQueriesToggle {...defaultProps} options={{ ...defaultProps.options, logAnalyticsAsyncQueries: true }} />
+    );
+
+    expect(screen.getByText('Async query timeout')).toBeInTheDocument();
+  });
+});
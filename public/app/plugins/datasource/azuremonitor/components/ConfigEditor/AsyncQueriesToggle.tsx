@@ -0,0 +1,44 @@
+import * as React from 'react';
+
+import { Field, Input, Switch } from '@grafana/ui';
+
+import { AzureDataSourceJsonData } from '../../types';
+
+export interface Props {
+  options: AzureDataSourceJsonData;
+  onAsyncQueriesChange: (asyncQueries: boolean, asyncTimeout?: string) => void;
+}
+
+export const AsyncQueriesToggle = (props: Props) => {
+  const { options, onAsyncQueriesChange } = props;
+  const enabled = options.logAnalyticsAsyncQueries ?? false;
+
+  const onChange = (e: React.ChangeEvent<HTMLInputElement>) =>
+    onAsyncQueriesChange(e.target.checked, options.logAnalyticsAsyncTimeout);
+  const onTimeoutChange = (e: React.FormEvent<HTMLInputElement>) =>
+    onAsyncQueriesChange(enabled, e.currentTarget.value || undefined);
+
+  return (
+    <>
+      <Field
+        description="Run long Log Analytics queries asynchronously, polling for their results and reading large results in pages."
+        label="Async Log Analytics queries"
+      >
+        <div>
+          <Switch aria-label="Async Log Analytics queries" onChange={onChange} value={enabled} />
+        </div>
+      </Field>
+      {enabled && (
+        <Field description="How long to wait for an async query to complete, for example 10m." label="Async query timeout">
+          <Input
+            aria-label="Async query timeout"
+            width={20}
+            placeholder="10m"
+            value={options.logAnalyticsAsyncTimeout ?? ''}
+            onChange={onTimeoutChange}
+          />
+        </Field>
+      )}
+    </>
+  );
+};
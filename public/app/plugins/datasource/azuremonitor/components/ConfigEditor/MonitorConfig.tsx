@@ -7,6 +7,7 @@ import { config } from '@grafana/runtime';
 import { getCredentials, updateCredentials } from '../../credentials';
 import { AzureDataSourceSettings, AzureCredentials } from '../../types';
 
+import { AsyncQueriesToggle } from './AsyncQueriesToggle';
 import { AzureCredentialsForm, getAzureCloudOptions } from './AzureCredentialsForm';
 import { BasicLogsToggle } from './BasicLogsToggle';
 import { DefaultSubscription } from './DefaultSubscription';
@@ -40,6 +41,12 @@ export const MonitorConfig = (props: Props) => {
   const onBasicLogsEnabledChange = (enableBasicLogs: boolean) =>
     updateOptions((options) => ({ ...options, jsonData: { ...options.jsonData, basicLogsEnabled: enableBasicLogs } }));
 
+  const onAsyncQueriesChange = (logAnalyticsAsyncQueries: boolean, logAnalyticsAsyncTimeout?: string) =>
+    updateOptions((options) => ({
+      ...options,
+      jsonData: { ...options.jsonData, logAnalyticsAsyncQueries, logAnalyticsAsyncTimeout },
+    }));
+
   // The auth type needs to be set on the first load of the data source
   useEffectOnce(() => {
     if (!options.jsonData.authType || !credentials.authType) {
@@ -69,6 +76,7 @@ export const MonitorConfig = (props: Props) => {
             options={options.jsonData}
           />
           <BasicLogsToggle options={options.jsonData} onBasicLogsEnabledChange={onBasicLogsEnabledChange} />
+          <AsyncQueriesToggle options={options.jsonData} onAsyncQueriesChange={onAsyncQueriesChange} />
         </>
       </AzureCredentialsForm>
     </>
@@ -82,6 +82,8 @@ export interface AzureDataSourceJsonData extends DataSourceJsonData {
   oauthPassThru?: boolean;
   azureCredentials?: AzureCredentials;
   basicLogsEnabled?: boolean;
+  logAnalyticsAsyncQueries?: boolean;
+  logAnalyticsAsyncTimeout?: string;
 
   // logs
   /** @deprecated Azure Logs credentials */
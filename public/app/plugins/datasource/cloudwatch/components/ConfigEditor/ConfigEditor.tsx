@@ -1,5 +1,5 @@
 import { css } from '@emotion/css';
-import { useEffect, useState } from 'react';
+import { FormEvent, useEffect, useState } from 'react';
 import { useDebounce } from 'react-use';
 
 import { ConnectionConfig } from '@grafana/aws-sdk';
@@ -46,6 +46,12 @@ export const ConfigEditor = (props: Props) => {
   });
 
   useEffect(() => setLogGroupFieldState({ invalid: false }), [props.options]);
+  const onUpdateBudget =
+    (key: 'maxApiCalls' | 'maxMetricsRequested' | 'maxLogBytesScanned') =>
+    (event: FormEvent<HTMLInputElement>) => {
+      const value = event.currentTarget.valueAsNumber;
+      updateDatasourcePluginJsonDataOption(props, key, Number.isNaN(value) || value <= 0 ? undefined : value);
+    };
   const report = usePluginInteractionReporter();
   useEffect(() => {
     const successSubscription = getAppEvents().subscribe<DataSourceTestSucceeded>(DataSourceTestSucceeded, () => {
@@ -188,6 +194,58 @@ export const ConfigEditor = (props: Props) => {
         </Field>
       </ConfigSection>
       <Divider />
+      <ConfigSection
+        title="Cost budgets"
+        description="Queries that go over a limit are rejected. Leave a limit empty to not limit the queries."
+        isCollapsible
+        isInitiallyOpen={Boolean(
+          options.jsonData.maxApiCalls || options.jsonData.maxMetricsRequested || options.jsonData.maxLogBytesScanned
+        )}
+      >
+        <Field
+          htmlFor="maxApiCalls"
+          label="Max GetMetricData calls"
+          description="The maximum number of GetMetricData API calls for a batch of metric queries."
+        >
+          <Input
+            id="maxApiCalls"
+            type="number"
+            min={0}
+            width={30}
+            value={options.jsonData.maxApiCalls || ''}
+            onChange={onUpdateBudget('maxApiCalls')}
+          />
+        </Field>
+        <Field
+          htmlFor="maxMetricsRequested"
+          label="Max metrics requested"
+          description="The maximum number of metrics requested by a batch of metric queries. AWS bills GetMetricData by the number of metrics requested."
+        >
+          <Input
+            id="maxMetricsRequested"
+            type="number"
+            min={0}
+            width={30}
+            value={options.jsonData.maxMetricsRequested || ''}
+            onChange={onUpdateBudget('maxMetricsRequested')}
+          />
+        </Field>
+        <Field
+          htmlFor="maxLogBytesScanned"
+          label="Max log bytes scanned"
+          description="The maximum number of bytes a CloudWatch Logs query scans before it is stopped."
+        >
+          <Input
+            id="maxLogBytesScanned"
+            type="number"
+            min={0}
+            width={30}
+            value={options.jsonData.maxLogBytesScanned || ''}
+            onChange={onUpdateBudget('maxLogBytesScanned')}
+          />
+        </Field>
+      </ConfigSection>
+      <Divider />
       <XrayLinkConfig
         newFormStyling={true}
         onChange={(uid) => updateDatasourcePluginJsonDataOption(props, 'tracingDatasourceUid', uid)}
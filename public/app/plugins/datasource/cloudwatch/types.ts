@@ -41,6 +41,10 @@ export interface CloudWatchJsonData extends AwsAuthDataSourceJsonData {
   logsTimeout?: string;
   // Used to create links if logs contain traceId.
   tracingDatasourceUid?: string;
+  // Cost budgets, queries that use the CloudWatch APIs over a limit are rejected. Zero or unset means no limit.
+  maxApiCalls?: number;
+  maxMetricsRequested?: number;
+  maxLogBytesScanned?: number;
 
   logGroups?: raw.LogGroup[];
   /**
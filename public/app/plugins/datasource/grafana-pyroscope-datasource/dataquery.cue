@@ -33,13 +33,28 @@ composableKinds: DataQuery: {
 				labelSelector: string | *"{}"
 				// Specifies the query span selectors.
 				spanSelector?: [...string]
+				// Specifies sets of span IDs, like the matching span sets of a trace search. The profile of the spans of all sets is queried.
+				spanSets?: [...[...string]]
 				// Specifies the type of profile to query.
 				profileTypeId: string
 				// Allows to group the results.
 				groupBy: [...string]
 				// Sets the maximum number of nodes in the flamegraph.
-				maxNodes?:           int64
-				#PyroscopeQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				maxNodes?: int64
+				// Selects the baseline profile of diff queries, the label selector and the time range of the query are used when not set.
+				baseline?: #ProfileSelection
+				// Selects the comparison profile of diff queries, the label selector and the time range of the query are used when not set.
+				comparison?:         #ProfileSelection
+				#PyroscopeQueryType: "metrics" | "profile" | "diff" | *"both" @cuetsy(kind="type")
+				// Selects a profile by its label selector and time range.
+				#ProfileSelection: {
+					// Specifies the label selectors of the profile.
+					labelSelector?: string
+					// Start of the time range of the profile in milliseconds since epoch.
+					from?: int64
+					// End of the time range of the profile in milliseconds since epoch.
+					to?: int64
+				} @cuetsy(kind="interface")
 			}
 		}]
 		lenses: []
@@ -10,11 +10,37 @@
 
 import * as common from '@grafana/schema';
 
-export type PyroscopeQueryType = ('metrics' | 'profile' | 'both');
+export type PyroscopeQueryType = ('metrics' | 'profile' | 'diff' | 'both');
 
 export const defaultPyroscopeQueryType: PyroscopeQueryType = 'both';
 
+/**
+ * Selects a profile by its label selector and time range.
+ */
+export interface ProfileSelection {
+  /**
+   * Start of the time range of the profile in milliseconds since epoch.
+   */
+  from?: number;
+  /**
+   * Specifies the label selectors of the profile.
+   */
+  labelSelector?: string;
+  /**
+   * End of the time range of the profile in milliseconds since epoch.
+   */
+  to?: number;
+}
+
 export interface GrafanaPyroscopeDataQuery extends common.DataQuery {
+  /**
+   * Selects the baseline profile of diff queries, the label selector and the time range of the query are used when not set.
+   */
+  baseline?: ProfileSelection;
+  /**
+   * Selects the comparison profile of diff queries, the label selector and the time range of the query are used when not set.
+   */
+  comparison?: ProfileSelection;
   /**
    * Allows to group the results.
    */
@@ -35,10 +61,15 @@ export interface GrafanaPyroscopeDataQuery extends common.DataQuery {
    * Specifies the query span selectors.
    */
   spanSelector?: Array<string>;
+  /**
+   * Specifies sets of span IDs, like the matching span sets of a trace search. The profile of the spans of all sets is queried.
+   */
+  spanSets?: Array<Array<string>>;
 }
 
 export const defaultGrafanaPyroscopeDataQuery: Partial<GrafanaPyroscopeDataQuery> = {
   groupBy: [],
   labelSelector: '{}',
   spanSelector: [],
+  spanSets: [],
 };
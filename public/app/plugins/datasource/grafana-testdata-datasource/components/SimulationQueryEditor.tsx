@@ -2,7 +2,7 @@ import { FormEvent, useMemo, useState } from 'react';
 import { useAsync } from 'react-use';
 
 import { DataFrameJSON, SelectableValue } from '@grafana/data';
-import { InlineField, InlineFieldRow, InlineSwitch, Input, Label, Select } from '@grafana/ui';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Label, Select, TextArea } from '@grafana/ui';
 
 import { EditorProps } from '../QueryEditor';
 import { SimulationQuery } from '../dataquery';
@@ -85,6 +85,11 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
     onChange({ ...query, sim: { ...simQuery, last: !simQuery.last } });
   };
 
+  const onTimelineChange = (e: FormEvent<HTMLTextAreaElement>) => {
+    const timeline = e.currentTarget.value;
+    onChange({ ...query, sim: { ...simQuery, timeline: timeline || undefined } });
+  };
+
   const onSchemaFormChange = (config: Record<string, any>) => {
     let path = simKey.type + '/' + simKey.tick + 'hz';
     if (simKey.uid) {
@@ -133,6 +138,21 @@ export const SimulationQueryEditor = ({ onChange, query, ds }: EditorProps) => {
           <Input type="text" placeholder="optional" value={simQuery.key.uid} onChange={onUIDChanged} />
         </InlineField>
       </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          labelWidth={14}
+          label="Timeline"
+          tooltip="YAML timeline of incidents (spike, flatline, gap or nan) replayed on top of the simulation"
+          grow
+        >
+          <TextArea
+            defaultValue={simQuery.timeline}
+            rows={4}
+            placeholder={'incidents:\n  - type: spike\n    offset: 10m\n    duration: 1m\n    value: 50'}
+            onBlur={onTimelineChange}
+          />
+        </InlineField>
+      </InlineFieldRow>
       <SimulationSchemaForm
         onChange={onSchemaFormChange}
         config={cfgValue ?? config.value}
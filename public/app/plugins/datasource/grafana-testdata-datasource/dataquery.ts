@@ -15,6 +15,7 @@ export enum TestDataQueryType {
   GrafanaAPI = 'grafana_api',
   LinearHeatmapBucketData = 'linear_heatmap_bucket_data',
   Live = 'live',
+  Load = 'load',
   Logs = 'logs',
   ManualEntry = 'manual_entry',
   NoDataPoints = 'no_data_points',
@@ -29,6 +30,8 @@ export enum TestDataQueryType {
   Simulation = 'simulation',
   SlowQuery = 'slow_query',
   StreamingClient = 'streaming_client',
+  SyntheticFlameGraph = 'synthetic_flame_graph',
+  SyntheticTraces = 'synthetic_traces',
   TableStatic = 'table_static',
   Trace = 'trace',
   USA = 'usa',
@@ -61,6 +64,7 @@ export interface SimulationQuery {
   };
   last?: boolean;
   stream?: boolean;
+  timeline?: string;
 }
 
 export interface NodesQuery {
@@ -69,6 +73,32 @@ export interface NodesQuery {
   type?: 'random' | 'response_small' | 'response_medium' | 'random edges' | 'feature_showcase';
 }
 
+export interface TracesQuery {
+  count?: number;
+  depth?: number;
+  fanout?: number;
+  seed?: number;
+  serviceGraph?: boolean;
+  services?: number;
+}
+
+export interface FlameGraphQuery {
+  depth?: number;
+  fanout?: number;
+  seed?: number;
+}
+
+export interface LoadQuery {
+  /**
+   * Share of the series, between 0 and 1, replaced by new series every churn interval
+   */
+  churn?: number;
+  churnInterval?: string;
+  labelCount?: number;
+  seed?: number;
+  seriesCount?: number;
+}
+
 export interface USAQuery {
   fields?: string[];
   mode?: string;
@@ -110,10 +140,12 @@ export interface TestDataDataQuery extends common.DataQuery {
    */
   dropPercent?: number;
   errorType?: 'server_panic' | 'frontend_exception' | 'frontend_observable';
+  flameGraph?: FlameGraphQuery;
   flamegraphDiff?: boolean;
   labels?: string;
   levelColumn?: boolean;
   lines?: number;
+  load?: LoadQuery;
   nodes?: NodesQuery;
   points?: Array<Array<string | number>>;
   pulseWave?: PulseWaveQuery;
@@ -124,6 +156,7 @@ export interface TestDataDataQuery extends common.DataQuery {
   spanCount?: number;
   stream?: StreamingQuery;
   stringInput?: string;
+  traces?: TracesQuery;
   usa?: USAQuery;
 }
 
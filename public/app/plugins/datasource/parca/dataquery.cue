@@ -32,8 +32,21 @@ composableKinds: DataQuery: {
 				// Specifies the query label selectors.
 				labelSelector: string | *"{}"
 				// Specifies the type of profile to query.
-				profileTypeId:   string
-				#ParcaQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				profileTypeId: string
+				// Selects the baseline profile of diff queries, the label selector and the time range of the query are used when not set.
+				baseline?: #ProfileSelection
+				// Selects the comparison profile of diff queries, the label selector and the time range of the query are used when not set.
+				comparison?:     #ProfileSelection
+				#ParcaQueryType: "metrics" | "profile" | "diff" | *"both" @cuetsy(kind="type")
+				// Selects a profile by its label selector and time range.
+				#ProfileSelection: {
+					// Specifies the label selectors of the profile.
+					labelSelector?: string
+					// Start of the time range of the profile in milliseconds since epoch.
+					from?: int64
+					// End of the time range of the profile in milliseconds since epoch.
+					to?: int64
+				} @cuetsy(kind="interface")
 			}
 		}]
 		lenses: []